@@ -0,0 +1,350 @@
+// anilist_methods.go - Métodos de tracking da AniList para o frontend
+// Login OAuth2, vínculo de animes da biblioteca e sincronização bidirecional da lista
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"GoAnimeGUI/pkg/anilist"
+	"GoAnimeGUI/pkg/retry"
+	"GoAnimeGUI/pkg/store"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// AniListStatus é o estado da integração para o frontend
+type AniListStatus struct {
+	Configured  bool            `json:"configured"`
+	LoggedIn    bool            `json:"loggedIn"`
+	User        *anilist.Viewer `json:"user"`
+	RedirectURI string          `json:"redirectUri"`
+}
+
+// ==============================
+// LOGIN
+// ==============================
+
+// GetAniListStatus retorna se a AniList está configurada e conectada
+func (a *App) GetAniListStatus() AniListStatus {
+	auth := anilist.GetAuth()
+	return AniListStatus{
+		Configured:  auth.IsConfigured(),
+		LoggedIn:    auth.IsLoggedIn(),
+		User:        auth.CurrentViewer(),
+		RedirectURI: auth.RedirectURI(),
+	}
+}
+
+// SaveAniListConfig salva o Client ID/Secret do aplicativo registrado na AniList
+func (a *App) SaveAniListConfig(clientID, clientSecret string) error {
+	return anilist.GetAuth().Configure(clientID, clientSecret)
+}
+
+// StartAniListLogin abre o navegador para autorizar o GoAnime na AniList.
+// O resultado chega pelos eventos "anilist:connected" ou "anilist:error".
+func (a *App) StartAniListLogin() error {
+	auth := anilist.GetAuth()
+	if !auth.IsConfigured() {
+		return fmt.Errorf("credenciais da AniList não configuradas")
+	}
+
+	authURL, err := auth.AuthURL()
+	if err != nil {
+		return fmt.Errorf("erro ao gerar URL de autorização: %w", err)
+	}
+	runtime.BrowserOpenURL(a.ctx, authURL)
+	fmt.Println("[AniList OAuth] Aguardando autorização do usuário...")
+
+	go func() {
+		code, err := auth.WaitForCallback()
+		if err != nil {
+			fmt.Printf("[AniList OAuth] Erro: %v\n", err)
+			runtime.EventsEmit(a.ctx, "anilist:error", err.Error())
+			return
+		}
+
+		viewer, err := auth.ExchangeCode(code)
+		if err != nil {
+			fmt.Printf("[AniList OAuth] Erro ao completar OAuth: %v\n", err)
+			runtime.EventsEmit(a.ctx, "anilist:error", err.Error())
+			return
+		}
+
+		fmt.Printf("[AniList OAuth] Usuário conectado: %s\n", viewer.Name)
+		runtime.EventsEmit(a.ctx, "anilist:connected", viewer)
+
+		// Primeira sincronização logo após o login
+		if _, err := a.SyncAniList(); err != nil {
+			fmt.Printf("[AniList] Erro na sincronização inicial: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// LogoutAniList desconecta a conta AniList
+func (a *App) LogoutAniList() error {
+	return anilist.GetAuth().Logout()
+}
+
+// ==============================
+// VÍNCULO COM A BIBLIOTECA
+// ==============================
+
+// LinkAniList vincula um anime da biblioteca a um ID da AniList (0 desfaz o vínculo)
+func (a *App) LinkAniList(animeURL string, mediaID int) error {
+	db, err := store.Default()
+	if err != nil {
+		return err
+	}
+	if err := db.LinkAniList(animeURL, mediaID); err != nil {
+		return err
+	}
+	if mediaID > 0 {
+		a.onLibraryChanged(animeURL)
+	}
+	return nil
+}
+
+// resolveAniListID retorna o ID vinculado ou tenta vincular pelo resolver de identidade
+func (a *App) resolveAniListID(db *store.DB, item *store.LibraryItem) int {
+	if item.AniListID == 0 {
+		linkLibraryIdentity(db, item)
+	}
+	if item.AniListID == 0 {
+		fmt.Printf("[AniList] Nenhum resultado confiável para %q, vincule manualmente\n", item.Title)
+	}
+	return item.AniListID
+}
+
+// aniListTitleMatches compara o título local com os títulos da AniList
+func aniListTitleMatches(title string, t anilist.Title) bool {
+	return titleMatchesAny(title, t.Romaji, t.English, t.Native)
+}
+
+// ==============================
+// ENVIO DE PROGRESSO
+// ==============================
+
+// pushAniListEntry envia estado, nota e progresso de uma entrada para a AniList.
+// Chamado pela fila de sincronização: um erro agenda uma nova tentativa.
+func (a *App) pushAniListEntry(animeURL string) error {
+	auth := anilist.GetAuth()
+	if !auth.IsLoggedIn() {
+		return nil
+	}
+
+	db, err := store.Default()
+	if err != nil {
+		return err
+	}
+	item, err := db.GetLibraryItem(animeURL)
+	if err != nil || item == nil {
+		return err
+	}
+	mediaID := a.resolveAniListID(db, item)
+	if mediaID == 0 {
+		return nil
+	}
+
+	entry, err := auth.SaveListEntry(aniListUpdateFor(item, mediaID))
+	if errors.Is(err, anilist.ErrNotLoggedIn) {
+		return retry.Permanent(err)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar %s: %w", item.Title, err)
+	}
+	fmt.Printf("[AniList] %s atualizado: %s, episódio %d\n", item.Title, entry.Status, entry.Progress)
+
+	// Último episódio assistido: conclui na AniList e na biblioteca
+	if entry.Media.Episodes > 0 && entry.Progress >= entry.Media.Episodes && entry.Status == anilist.StatusCurrent {
+		if err := db.SetLibraryStatus(animeURL, store.StatusCompleted); err == nil {
+			if _, err := auth.SaveListEntry(anilist.ListEntryUpdate{MediaID: mediaID, Status: anilist.StatusCompleted}); err != nil {
+				return fmt.Errorf("erro ao concluir %s: %w", item.Title, err)
+			}
+		}
+	}
+	return nil
+}
+
+func aniListUpdateFor(item *store.LibraryItem, mediaID int) anilist.ListEntryUpdate {
+	update := anilist.ListEntryUpdate{
+		MediaID:  mediaID,
+		Status:   toAniListStatus(item.Status),
+		Progress: item.Progress,
+		Score:    item.Score,
+	}
+	if update.Score == 0 {
+		update.Score = -1 // Nota removida localmente
+	}
+	return update
+}
+
+func toAniListStatus(status store.WatchStatus) anilist.MediaListStatus {
+	switch status {
+	case store.StatusWatching:
+		return anilist.StatusCurrent
+	case store.StatusCompleted:
+		return anilist.StatusCompleted
+	case store.StatusOnHold:
+		return anilist.StatusPaused
+	case store.StatusDropped:
+		return anilist.StatusDropped
+	default:
+		return anilist.StatusPlanning
+	}
+}
+
+func fromAniListStatus(status anilist.MediaListStatus) store.WatchStatus {
+	switch status {
+	case anilist.StatusCurrent, anilist.StatusRepeating:
+		return store.StatusWatching
+	case anilist.StatusCompleted:
+		return store.StatusCompleted
+	case anilist.StatusPaused:
+		return store.StatusOnHold
+	case anilist.StatusDropped:
+		return store.StatusDropped
+	default:
+		return store.StatusPlanToWatch
+	}
+}
+
+// ==============================
+// SINCRONIZAÇÃO
+// ==============================
+
+// SyncAniList baixa a lista da AniList e reconcilia com a biblioteca local.
+// Estado e nota seguem o lado alterado mais recentemente; o progresso fica com o maior valor.
+func (a *App) SyncAniList() (TrackerSyncResult, error) {
+	result := newTrackerSyncResult()
+
+	auth := anilist.GetAuth()
+	remote, err := auth.GetAnimeList()
+	if err != nil {
+		return result, err
+	}
+	db, err := store.Default()
+	if err != nil {
+		return result, err
+	}
+	items, err := db.QueryLibrary(store.LibraryFilter{})
+	if err != nil {
+		return result, err
+	}
+
+	byID := make(map[int]*anilist.ListEntry, len(remote))
+	for i := range remote {
+		byID[remote[i].MediaID] = &remote[i]
+	}
+	matched := make(map[int]bool)
+
+	for i := range items {
+		item := &items[i]
+
+		// Entradas sem vínculo: tenta casar pelo título com a lista remota (sem chamadas extras à API)
+		if item.AniListID == 0 {
+			for _, entry := range remote {
+				if aniListTitleMatches(item.Title, entry.Media.Title) {
+					if err := db.LinkAniList(item.URL, entry.MediaID); err == nil {
+						item.AniListID = entry.MediaID
+						result.Linked++
+					}
+					break
+				}
+			}
+			if item.AniListID == 0 {
+				continue
+			}
+		}
+		matched[item.AniListID] = true
+
+		entry := byID[item.AniListID]
+		if entry == nil {
+			// Ainda não está na lista remota
+			if _, err := auth.SaveListEntry(aniListUpdateFor(item, item.AniListID)); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", item.Title, err))
+			} else {
+				result.Pushed++
+			}
+			continue
+		}
+
+		pulled, pushed, err := a.reconcileAniListEntry(db, item, entry)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", item.Title, err))
+		}
+		if pulled {
+			result.Pulled++
+		}
+		if pushed {
+			result.Pushed++
+		}
+	}
+
+	for _, entry := range remote {
+		if matched[entry.MediaID] {
+			continue
+		}
+		title := entry.Media.Title.English
+		if title == "" {
+			title = entry.Media.Title.Romaji
+		}
+		result.RemoteOnly = append(result.RemoteOnly, TrackerRemoteEntry{
+			ID:       entry.MediaID,
+			Title:    title,
+			Image:    entry.Media.CoverImage.Large,
+			Status:   string(fromAniListStatus(entry.Status)),
+			Progress: entry.Progress,
+			Episodes: entry.Media.Episodes,
+		})
+	}
+
+	result.log("AniList")
+	runtime.EventsEmit(a.ctx, "anilist:synced", result)
+	return result, nil
+}
+
+// reconcileAniListEntry aplica a regra de reconciliação em uma entrada vinculada
+func (a *App) reconcileAniListEntry(db *store.DB, item *store.LibraryItem, entry *anilist.ListEntry) (pulled, pushed bool, err error) {
+	status, score := item.Status, item.Score
+	localUpdated, _ := time.Parse(time.RFC3339, item.UpdatedAt)
+	if time.Unix(entry.UpdatedAt, 0).After(localUpdated) {
+		status, score = fromAniListStatus(entry.Status), entry.Score
+	}
+	progress := item.Progress
+	if entry.Progress > progress {
+		progress = entry.Progress
+	}
+
+	if status != item.Status || !sameScore(score, item.Score) || progress != item.Progress {
+		if err := db.SetLibraryStatus(item.URL, status); err != nil {
+			return false, false, err
+		}
+		if err := db.SetLibraryScore(item.URL, score); err != nil {
+			return false, false, err
+		}
+		if err := db.SetLibraryProgress(item.URL, progress); err != nil {
+			return false, false, err
+		}
+		item.Status, item.Score, item.Progress = status, score, progress
+		pulled = true
+	}
+
+	if toAniListStatus(status) != entry.Status && entry.Status != anilist.StatusRepeating ||
+		!sameScore(score, entry.Score) || progress != entry.Progress {
+		if _, err := anilist.GetAuth().SaveListEntry(aniListUpdateFor(item, entry.MediaID)); err != nil {
+			return pulled, false, err
+		}
+		pushed = true
+	}
+	return pulled, pushed, nil
+}
+
+// sameScore compara notas com uma casa decimal (precisão da AniList)
+func sameScore(a, b float64) bool {
+	return math.Round(a*10) == math.Round(b*10)
+}
@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"GoAnimeGUI/internal/manga"
+	"GoAnimeGUI/internal/proxy"
 	"GoAnimeGUI/pkg/anilist"
 	"GoAnimeGUI/pkg/animesflix"
 	"GoAnimeGUI/pkg/aniskip"
@@ -26,8 +27,10 @@ import (
 	"GoAnimeGUI/pkg/discord"
 	"GoAnimeGUI/pkg/enime"
 	"GoAnimeGUI/pkg/gofilecloud"
+	"GoAnimeGUI/pkg/hostrules"
 	"GoAnimeGUI/pkg/jikan"
 	"GoAnimeGUI/pkg/smartrouter"
+	"GoAnimeGUI/pkg/sourcehealth"
 	"GoAnimeGUI/pkg/store"
 	"GoAnimeGUI/pkg/videoextractor"
 
@@ -82,15 +85,6 @@ type StreamCacheEntry struct {
 	IsValidated bool
 }
 
-// SourceFailure rastreia falhas de fontes especÃ­ficas
-type SourceFailure struct {
-	Source     string
-	FailedAt   time.Time
-	FailCount  int
-	LastError  string
-	RetryAfter time.Time
-}
-
 // toTitleCase converte string para Title Case (substitui strings.Title deprecated)
 func toTitleCase(s string) string {
 	words := strings.Fields(s)
@@ -140,10 +134,6 @@ type App struct {
 	streamCache      map[string]*StreamCacheEntry
 	streamCacheMutex sync.RWMutex
 
-	// Rastreamento de falhas por fonte
-	sourceFailures      map[string]*SourceFailure
-	sourceFailuresMutex sync.RWMutex
-
 	// Prefetch de episÃ³dios (carrega prÃ³ximos episÃ³dios em background)
 	prefetchQueue  chan PrefetchRequest
 	prefetchActive map[string]bool
@@ -153,15 +143,12 @@ type App struct {
 	hdImageCache map[string]*anilist.AnimeMedia
 
 	// Proxy de vÃ­deo para contornar CORS
-	proxyServer     *http.Server
-	proxyPort       int
-	currentVideoURL string
-	proxyMutex      sync.RWMutex
+	proxyServer *http.Server
+	proxyPort   int
 
 	// Cache de imagens de mangÃ¡ para carregamento rÃ¡pido
 	imageCache      map[string][]byte
 	imageCacheMutex sync.RWMutex
-	imageClient     *http.Client
 
 	// Estado de inicializaÃ§Ã£o
 	initialized bool
@@ -191,24 +178,15 @@ const (
 
 func NewApp() *App {
 	app := &App{
-		cache:          make(map[string]*CacheEntry),
-		episodesCache:  make(map[string][]store.Episode),
-		urlCache:       make(map[string]string),
-		hdImageCache:   make(map[string]*anilist.AnimeMedia),
-		streamCache:    make(map[string]*StreamCacheEntry),
-		sourceFailures: make(map[string]*SourceFailure),
-		imageCache:     make(map[string][]byte),
-		imageClient: &http.Client{
-			Timeout: 30 * time.Second,
-			Transport: &http.Transport{
-				MaxIdleConns:        50,
-				MaxIdleConnsPerHost: 20,
-				IdleConnTimeout:     90 * time.Second,
-				DisableCompression:  false,
-			},
-		},
+		cache:         make(map[string]*CacheEntry),
+		episodesCache: make(map[string][]store.Episode),
+		urlCache:      make(map[string]string),
+		hdImageCache:  make(map[string]*anilist.AnimeMedia),
+		streamCache:   make(map[string]*StreamCacheEntry),
+		imageCache:    make(map[string][]byte),
 		validationClient: &http.Client{
-			Timeout: 3 * time.Second, // Reduzido para resposta mais rÃ¡pida
+			Transport: hostrules.Default().Transport(nil),
+			Timeout:   3 * time.Second, // Reduzido para resposta mais rÃ¡pida
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
 				// Permite redirecionamentos normalmente
 				if len(via) >= 10 {
@@ -226,6 +204,9 @@ func NewApp() *App {
 		CircuitThreshold: 3,                // 3 falhas abre o circuit
 		CircuitResetTime: 30 * time.Second, // Tenta resetar apÃ³s 30s
 		DefaultTimeout:   5 * time.Second,
+		Hedging:          true,        // Paralelo comeca pela melhor fonte
+		HedgeDelay:       time.Second, // Espera pela fonte sem histórico de latência
+		HedgeMaxFanOut:   2,           // No maximo 2 fontes buscando ao mesmo tempo
 	})
 
 	// Adiciona fontes de streaming em ordem de prioridade
@@ -244,9 +225,12 @@ func NewApp() *App {
 		Name:     "Consumet",
 		Priority: 2,
 		Timeout:  5 * time.Second,
-		Fetcher: func(ctx context.Context, title string, ep int) (string, error) {
-			url, _, err := consumet.FindAnimeAndGetStream(title, ep)
-			return url, err
+		Resolve: func(ctx context.Context, req smartrouter.StreamRequest) ([]smartrouter.Candidate, error) {
+			info, subOrDub, err := consumet.FindEpisodeSources(req.AnimeTitle, req.EpisodeNumber)
+			if err != nil {
+				return nil, err
+			}
+			return consumetCandidates(info, subOrDub), nil
 		},
 	})
 
@@ -321,9 +305,7 @@ func (a *App) ClearAllCache() {
 	a.streamCache = make(map[string]*StreamCacheEntry)
 	a.streamCacheMutex.Unlock()
 
-	a.sourceFailuresMutex.Lock()
-	a.sourceFailures = make(map[string]*SourceFailure)
-	a.sourceFailuresMutex.Unlock()
+	sourcehealth.Default().Reset()
 
 	fmt.Println("[Cache] Todo o cache foi limpo")
 }
@@ -345,17 +327,9 @@ func (a *App) ValidateStreamURL(url string) (bool, error) {
 		return false, err
 	}
 
-	// Configura headers baseado no tipo de URL
+	// Configura headers baseado no host (regras de pkg/hostrules)
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-
-	if strings.Contains(url, "lightspeedst.net") || strings.Contains(url, "animefire") {
-		req.Header.Set("Referer", "https://animefire.plus/")
-		req.Header.Set("Origin", "https://animefire.plus")
-	} else if strings.Contains(url, "sharepoint") || strings.Contains(url, "microsoft") {
-		req.Header.Set("Referer", "https://myanime.sharepoint.com/")
-	} else if strings.Contains(url, "allanime") || strings.Contains(url, "gogoanime") {
-		req.Header.Set("Referer", "https://allanime.to/")
-	}
+	hostrules.Default().Apply(req)
 
 	resp, err := a.validationClient.Do(req)
 	if err != nil {
@@ -451,60 +425,26 @@ func (a *App) InvalidateStreamCache(key string) {
 	}
 }
 
-// recordSourceFailure registra falha de uma fonte
+// recordSourceFailure registra falha de uma fonte no registro de saúde compartilhado.
+// O Smart Router já registra cada fonte que tentou, então o resultado agregado dele fica de fora.
 func (a *App) recordSourceFailure(source string, reason string) {
-	a.sourceFailuresMutex.Lock()
-	defer a.sourceFailuresMutex.Unlock()
-
-	if failure, exists := a.sourceFailures[source]; exists {
-		failure.FailCount++
-		failure.FailedAt = time.Now()
-		failure.LastError = reason
-
-		// Backoff exponencial: 30s, 1min, 2min, 5min, 10min
-		backoffMinutes := []time.Duration{30 * time.Second, 1 * time.Minute, 2 * time.Minute, 5 * time.Minute, 10 * time.Minute}
-		backoffIndex := failure.FailCount - 1
-		if backoffIndex >= len(backoffMinutes) {
-			backoffIndex = len(backoffMinutes) - 1
-		}
-		failure.RetryAfter = time.Now().Add(backoffMinutes[backoffIndex])
-
-		fmt.Printf("[SourceTracker] Falha %d para %s: %s (retry apÃ³s %v)\n",
-			failure.FailCount, source, reason, backoffMinutes[backoffIndex])
-	} else {
-		a.sourceFailures[source] = &SourceFailure{
-			Source:     source,
-			FailedAt:   time.Now(),
-			FailCount:  1,
-			LastError:  reason,
-			RetryAfter: time.Now().Add(30 * time.Second),
-		}
-		fmt.Printf("[SourceTracker] Primeira falha para %s: %s\n", source, reason)
+	if !strings.HasPrefix(source, "SmartRouter") {
+		sourcehealth.Default().RecordFailure(source, reason)
 	}
 }
 
-// recordSourceSuccess registra sucesso de uma fonte
+// recordSourceSuccess registra sucesso de uma fonte (encerra o cooldown)
 func (a *App) recordSourceSuccess(source string) {
-	a.sourceFailuresMutex.Lock()
-	defer a.sourceFailuresMutex.Unlock()
-
-	// Reseta falhas apÃ³s sucesso
-	if failure, exists := a.sourceFailures[source]; exists {
-		fmt.Printf("[SourceTracker] Fonte %s recuperada apÃ³s %d falhas\n", source, failure.FailCount)
-		delete(a.sourceFailures, source)
+	if !strings.HasPrefix(source, "SmartRouter") {
+		sourcehealth.Default().RecordSuccess(source)
 	}
 }
 
 // IsSourceAvailable verifica se uma fonte estÃ¡ disponÃ­vel (nÃ£o em cooldown)
 func (a *App) IsSourceAvailable(source string) bool {
-	a.sourceFailuresMutex.RLock()
-	defer a.sourceFailuresMutex.RUnlock()
-
-	if failure, exists := a.sourceFailures[source]; exists {
-		if time.Now().Before(failure.RetryAfter) {
-			fmt.Printf("[SourceTracker] Fonte %s em cooldown atÃ© %v\n", source, failure.RetryAfter)
-			return false
-		}
+	if remaining := sourcehealth.Default().CooldownRemaining(source); remaining > 0 {
+		fmt.Printf("[SourceHealth] Fonte %s em cooldown por mais %v\n", source, remaining.Round(time.Second))
+		return false
 	}
 	return true
 }
@@ -518,44 +458,29 @@ func (a *App) GetAlternativeSource(excludeSources ...string) string {
 		excludeMap[s] = true
 	}
 
-	a.sourceFailuresMutex.RLock()
-	defer a.sourceFailuresMutex.RUnlock()
-
+	// Entre as fontes fora de cooldown, a de melhor pontuação; se todas estiverem em cooldown,
+	// a que sai primeiro
+	health := sourcehealth.Default()
+	var bestSource string
+	bestScore, shortestWait := -1.0, time.Duration(-1)
 	for _, source := range sources {
 		if excludeMap[source] {
 			continue
 		}
-
-		// Verifica se a fonte estÃ¡ disponÃ­vel
-		if failure, exists := a.sourceFailures[source]; exists {
-			if time.Now().Before(failure.RetryAfter) {
-				continue // Ainda em cooldown
+		if wait := health.CooldownRemaining(source); wait > 0 {
+			if bestScore < 0 && (shortestWait < 0 || wait < shortestWait) {
+				bestSource, shortestWait = source, wait
 			}
-		}
-
-		fmt.Printf("[SourceTracker] Fonte alternativa selecionada: %s\n", source)
-		return source
-	}
-
-	// Se todas estÃ£o em cooldown, retorna a com menor tempo de espera
-	var bestSource string
-	var earliestRetry time.Time
-
-	for _, source := range sources {
-		if excludeMap[source] {
 			continue
 		}
-
-		if failure, exists := a.sourceFailures[source]; exists {
-			if bestSource == "" || failure.RetryAfter.Before(earliestRetry) {
-				bestSource = source
-				earliestRetry = failure.RetryAfter
-			}
-		} else {
-			return source // Fonte sem falhas registradas
+		if score := health.Score(source); score > bestScore {
+			bestSource, bestScore = source, score
 		}
 	}
 
+	if bestScore >= 0 {
+		fmt.Printf("[SourceHealth] Fonte alternativa selecionada: %s\n", bestSource)
+	}
 	return bestSource
 }
 
@@ -600,9 +525,7 @@ func (a *App) GetCacheStats() CacheStats {
 	// Status de cada fonte
 	sources := []string{"AllAnime", "AnimeFire", "Enime", "Consumet"}
 
-	a.sourceFailuresMutex.RLock()
-	defer a.sourceFailuresMutex.RUnlock()
-
+	health := sourcehealth.Default()
 	for _, name := range sources {
 		status := SourceStatus{
 			Name:        name,
@@ -610,14 +533,12 @@ func (a *App) GetCacheStats() CacheStats {
 			CachedURLs:  sourceCounts[name],
 		}
 
-		if failure, exists := a.sourceFailures[name]; exists {
-			status.FailCount = failure.FailCount
-			status.LastError = failure.LastError
-
-			if time.Now().Before(failure.RetryAfter) {
-				status.IsAvailable = false
-				status.RetryAfter = failure.RetryAfter.Format("15:04:05")
-			}
+		current := health.Status(name)
+		status.FailCount = current.FailCount
+		status.LastError = current.LastError
+		if current.InCooldown {
+			status.IsAvailable = false
+			status.RetryAfter = current.CooldownUntil.Local().Format("15:04:05")
 		}
 
 		stats.Sources = append(stats.Sources, status)
@@ -628,11 +549,7 @@ func (a *App) GetCacheStats() CacheStats {
 
 // ResetSourceFailures reseta todas as falhas de fontes (Ãºtil para debug)
 func (a *App) ResetSourceFailures() {
-	a.sourceFailuresMutex.Lock()
-	defer a.sourceFailuresMutex.Unlock()
-
-	a.sourceFailures = make(map[string]*SourceFailure)
-	fmt.Println("[SourceTracker] Todas as falhas foram resetadas")
+	sourcehealth.Default().Reset()
 }
 
 // startVideoProxy inicia um servidor HTTP local para fazer proxy do vÃ­deo
@@ -650,9 +567,12 @@ func (a *App) startVideoProxy() error {
 	listener.Close()
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/video", a.handleVideoProxy)
-	mux.HandleFunc("/proxy/", a.handleGenericProxy)         // Para segmentos HLS
+	mux.Handle("/video/", a.videoStreamHandler())
+	mux.HandleFunc("/proxy", a.handleGenericProxy)
+	mux.HandleFunc("/proxy/", a.handleGenericProxy)         // URLs assinadas (proxy.Guard)
 	mux.HandleFunc("/manga-image", a.handleMangaImageProxy) // Para imagens de mangÃ¡ com cache
+	mux.HandleFunc("/offline/", a.handleOfflineMedia)       // Episodios baixados (com Range)
+	mux.Handle("/subtitle", &proxy.SubtitleHandler{Guard: videoProxyGuard, Client: genericProxyClient})
 
 	a.proxyServer = &http.Server{
 		Addr:    fmt.Sprintf("127.0.0.1:%d", a.proxyPort),
@@ -671,22 +591,17 @@ func (a *App) startVideoProxy() error {
 	return nil
 }
 
-// handleGenericProxy faz proxy de qualquer URL (para segmentos HLS)
+// handleGenericProxy faz proxy das URLs assinadas pelo app (/proxy?url=...&sig=..., veja proxy.Guard).
+// URLs sem assinatura e destinos na rede local são recusados.
 func (a *App) handleGenericProxy(w http.ResponseWriter, r *http.Request) {
-	// URL estÃ¡ no path: /proxy/https://...
-	targetURL := strings.TrimPrefix(r.URL.Path, "/proxy/")
-	if r.URL.RawQuery != "" {
-		targetURL += "?" + r.URL.RawQuery
-	}
-
-	if targetURL == "" {
-		http.Error(w, "URL nÃ£o especificada", http.StatusBadRequest)
+	targetURL, err := videoProxyGuard.Target(r)
+	if err != nil {
+		videoProxyGuard.Reject(w, r, targetURL, err)
 		return
 	}
 
 	fmt.Printf("[GenericProxy] Proxy de: %s\n", targetURL)
 
-	client := &http.Client{Timeout: 30 * time.Second}
 	req, err := http.NewRequest("GET", targetURL, nil)
 	if err != nil {
 		http.Error(w, "Erro ao criar request", http.StatusInternalServerError)
@@ -700,38 +615,61 @@ func (a *App) handleGenericProxy(w http.ResponseWriter, r *http.Request) {
 		req.Header.Set("Range", rangeHeader)
 	}
 
-	resp, err := client.Do(req)
+	resp, err := genericProxyClient.Do(req)
 	if err != nil {
+		if proxy.IsBlocked(err) {
+			videoProxyGuard.Reject(w, r, targetURL, err)
+			return
+		}
 		fmt.Printf("[GenericProxy] Erro: %v\n", err)
 		http.Error(w, "Erro ao acessar recurso", http.StatusBadGateway)
 		return
 	}
 	defer resp.Body.Close()
 
+	body, err := videoProxyGuard.Body(resp)
+	if err != nil {
+		fmt.Printf("[GenericProxy] %v\n", err)
+		http.Error(w, "Resposta grande demais", http.StatusBadGateway)
+		return
+	}
+
 	// Headers CORS
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
 
 	// Copia headers da resposta
 	for key, values := range resp.Header {
+		if strings.HasPrefix(key, "Access-Control-") {
+			continue
+		}
 		for _, value := range values {
 			w.Header().Add(key, value)
 		}
 	}
 
 	w.WriteHeader(resp.StatusCode)
-	io.Copy(w, resp.Body)
+	io.Copy(w, body)
+}
+
+// maxMangaImageSize limita uma página de mangá guardada no cache em memória
+const maxMangaImageSize = 32 << 20
+
+// mangaImageURL retorna o endereço assinado de /manga-image para uma página de mangá
+func (a *App) mangaImageURL(imageURL, referer string) string {
+	return videoProxyGuard.URL(fmt.Sprintf("http://127.0.0.1:%d/manga-image", a.proxyPort), imageURL) +
+		"&referer=" + url.QueryEscape(referer)
 }
 
 // handleMangaImageProxy faz proxy de imagens de mangÃ¡ com cache em memÃ³ria
 func (a *App) handleMangaImageProxy(w http.ResponseWriter, r *http.Request) {
-	imageURL := r.URL.Query().Get("url")
-	referer := r.URL.Query().Get("referer")
-
-	if imageURL == "" {
-		http.Error(w, "URL nÃ£o especificada", http.StatusBadRequest)
+	// So URLs assinadas por mangaImageURL e em enderecos publicos (proxy.Guard)
+	imageURL, err := videoProxyGuard.Target(r)
+	if err != nil {
+		videoProxyGuard.Reject(w, r, imageURL, err)
 		return
 	}
+	referer := r.URL.Query().Get("referer")
 
 	// Verifica cache
 	a.imageCacheMutex.RLock()
@@ -768,8 +706,12 @@ func (a *App) handleMangaImageProxy(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	resp, err := a.imageClient.Do(req)
+	resp, err := genericProxyClient.Do(req)
 	if err != nil {
+		if proxy.IsBlocked(err) {
+			videoProxyGuard.Reject(w, r, imageURL, err)
+			return
+		}
 		fmt.Printf("[MangaImageProxy] Erro ao baixar: %v\n", err)
 		http.Error(w, "Erro ao baixar imagem", http.StatusBadGateway)
 		return
@@ -782,7 +724,13 @@ func (a *App) handleMangaImageProxy(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// LÃª a imagem
-	data, err := io.ReadAll(resp.Body)
+	body, err := videoProxyGuard.Body(resp)
+	if err != nil {
+		fmt.Printf("[MangaImageProxy] %s: %v\n", imageURL, err)
+		http.Error(w, "Imagem grande demais", http.StatusBadGateway)
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxMangaImageSize))
 	if err != nil {
 		http.Error(w, "Erro ao ler imagem", http.StatusInternalServerError)
 		return
@@ -819,172 +767,19 @@ func (a *App) handleMangaImageProxy(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
-// handleVideoProxy faz proxy do vÃ­deo remoto para o cliente local
-func (a *App) handleVideoProxy(w http.ResponseWriter, r *http.Request) {
-	a.proxyMutex.RLock()
-	videoURL := a.currentVideoURL
-	a.proxyMutex.RUnlock()
-
-	if videoURL == "" {
-		http.Error(w, "Nenhum vÃ­deo configurado", http.StatusBadRequest)
-		return
-	}
-
-	fmt.Printf("[VideoProxy] Fazendo proxy de: %s\n", videoURL)
-
-	// Cria request para o servidor remoto
-	client := &http.Client{
-		Timeout: 0, // Sem timeout para streaming
-	}
-
-	req, err := http.NewRequest("GET", videoURL, nil)
-	if err != nil {
-		http.Error(w, "Erro ao criar request", http.StatusInternalServerError)
-		return
-	}
-
-	// Copia headers da requisiÃ§Ã£o original (para suportar Range requests)
-	for key, values := range r.Header {
-		for _, value := range values {
-			if key == "Range" || key == "Accept" || key == "Accept-Encoding" {
-				req.Header.Add(key, value)
-			}
-		}
-	}
-
+// setVideoRequestHeaders define os headers de navegador e o Referer exigidos pelo CDN de origem
+func setVideoRequestHeaders(req *http.Request, videoURL string) {
 	// Headers comuns para parecer um navegador real
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-
-	// Define Referer baseado na URL de origem
-	if strings.Contains(videoURL, "lightspeedst.net") {
-		// LightSpeed CDN - precisa do referer correto do AnimeFire
-		req.Header.Set("Referer", "https://animefire.plus/")
-		req.Header.Set("Origin", "https://animefire.plus")
-	} else if strings.Contains(videoURL, "animefire") {
-		req.Header.Set("Referer", "https://animefire.plus/")
-		req.Header.Set("Origin", "https://animefire.plus")
-	} else if strings.Contains(videoURL, "sharepoint") || strings.Contains(videoURL, "microsoft") {
-		// SharePoint precisa de headers especÃ­ficos
-		req.Header.Set("Referer", "https://myanime.sharepoint.com/")
-		req.Header.Set("Origin", "https://myanime.sharepoint.com")
-		req.Header.Set("Accept", "*/*")
-	} else if strings.Contains(videoURL, "allanime") || strings.Contains(videoURL, "gogoanime") {
-		req.Header.Set("Referer", "https://allanime.to/")
-		req.Header.Set("Origin", "https://allanime.to")
-	} else {
-		req.Header.Set("Referer", "https://google.com/")
-	}
-
-	// Headers adicionais para compatibilidade
+	req.Header.Set("Referer", "https://google.com/")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
 	req.Header.Set("Sec-Fetch-Dest", "video")
 	req.Header.Set("Sec-Fetch-Mode", "no-cors")
 	req.Header.Set("Sec-Fetch-Site", "cross-site")
 	req.Header.Set("Accept", "*/*")
 
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Printf("[VideoProxy] Erro na requisiÃ§Ã£o: %v\n", err)
-		http.Error(w, "Erro ao acessar vÃ­deo", http.StatusBadGateway)
-		return
-	}
-	defer resp.Body.Close()
-
-	// Verifica se a resposta foi bem sucedida
-	if resp.StatusCode >= 400 {
-		fmt.Printf("[VideoProxy] Servidor remoto retornou erro: %d %s\n", resp.StatusCode, resp.Status)
-		// Se for um erro de autenticaÃ§Ã£o, tenta sem proxy
-		if resp.StatusCode == 401 || resp.StatusCode == 403 {
-			fmt.Printf("[VideoProxy] Erro de autenticaÃ§Ã£o - URL pode requerer acesso direto\n")
-		}
-	}
-
-	// Headers CORS e de resposta
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Range, Accept, Content-Type")
-	w.Header().Set("Access-Control-Expose-Headers", "Content-Length, Content-Range, Accept-Ranges")
-
-	// Se for m3u8, reescreve as URLs para usar nosso proxy
-	isM3U8 := strings.Contains(videoURL, ".m3u8") || strings.Contains(resp.Header.Get("Content-Type"), "mpegurl")
-
-	if isM3U8 {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			http.Error(w, "Erro ao ler m3u8", http.StatusInternalServerError)
-			return
-		}
-
-		// Reescreve URLs no m3u8 para usar nosso proxy
-		content := string(body)
-		lines := strings.Split(content, "\n")
-		var newLines []string
-
-		baseURL := videoURL[:strings.LastIndex(videoURL, "/")+1]
-
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "" || strings.HasPrefix(line, "#") {
-				// MantÃ©m comentÃ¡rios e linhas vazias
-				newLines = append(newLines, line)
-			} else {
-				// Ã‰ uma URL de segmento
-				var fullURL string
-				if strings.HasPrefix(line, "http://") || strings.HasPrefix(line, "https://") {
-					fullURL = line
-				} else {
-					fullURL = baseURL + line
-				}
-				// Reescreve para usar nosso proxy
-				proxyURL := fmt.Sprintf("http://127.0.0.1:%d/proxy/%s", a.proxyPort, fullURL)
-				newLines = append(newLines, proxyURL)
-			}
-		}
-
-		newContent := strings.Join(newLines, "\n")
-		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
-		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(newContent)))
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(newContent))
-		return
-	}
-
-	// Copia headers da resposta remota
-	for key, values := range resp.Header {
-		for _, value := range values {
-			w.Header().Add(key, value)
-		}
-	}
-
-	// Garante Content-Type correto para MP4
-	if strings.HasSuffix(videoURL, ".mp4") {
-		w.Header().Set("Content-Type", "video/mp4")
-	}
-
-	w.WriteHeader(resp.StatusCode)
-
-	// Faz streaming do corpo
-	_, err = io.Copy(w, resp.Body)
-	if err != nil {
-		fmt.Printf("[VideoProxy] Erro no streaming: %v\n", err)
-	}
-}
-
-// GetProxyURLForVideo retorna a URL do proxy local para um vÃ­deo
-func (a *App) GetProxyURLForVideo(videoURL string) (string, error) {
-	// Inicia o proxy se ainda nÃ£o estiver rodando
-	if err := a.startVideoProxy(); err != nil {
-		return "", err
-	}
-
-	// Configura a URL atual
-	a.proxyMutex.Lock()
-	a.currentVideoURL = videoURL
-	a.proxyMutex.Unlock()
-
-	proxyURL := fmt.Sprintf("http://127.0.0.1:%d/video", a.proxyPort)
-	fmt.Printf("[GetProxyURLForVideo] Proxy URL: %s -> %s\n", proxyURL, videoURL)
-	return proxyURL, nil
+	// Referer, Origin e cookies exigidos pelo CDN vem das regras por host (pkg/hostrules)
+	hostrules.Default().ApplyStream(req, videoURL)
 }
 
 func (a *App) startup(ctx context.Context) {
@@ -995,6 +790,26 @@ func (a *App) startup(ctx context.Context) {
 	// Inicializa Discord OAuth
 	initDiscordOAuth()
 
+	// Fila de sincronização (trackers e servidor social)
+	a.initSyncQueue()
+
+	// Verificação de novos episódios da biblioteca
+	a.initUpdateChecker()
+
+	// Biblioteca offline e fila de downloads de episódios
+	a.initOfflineLibrary()
+	a.initDownloadManager()
+	a.initMangaDownloads()
+
+	// Saúde das fontes salva entre execuções: o Smart Router registra nela cada tentativa
+	// e parte das pontuações dela para ordenar as fontes
+	a.streamRouter.SetHealth(sourcehealth.Default())
+
+	// Mudanças de estado dos circuit breakers das fontes de streaming
+	a.streamRouter.OnCircuitChange(func(event smartrouter.CircuitEvent) {
+		runtime.EventsEmit(a.ctx, "stream:circuit", event)
+	})
+
 	// PrÃ©-carrega dados em background para inicializaÃ§Ã£o rÃ¡pida
 	go a.preloadData()
 
@@ -1021,6 +836,15 @@ func (a *App) preloadData() {
 		}
 	}()
 
+	// Sincroniza a lista da AniList se houver conta conectada
+	if anilist.GetAuth().IsLoggedIn() {
+		go func() {
+			if _, err := a.SyncAniList(); err != nil {
+				fmt.Printf("[AniList] Erro ao sincronizar: %v\n", err)
+			}
+		}()
+	}
+
 	// Carrega top animes em background (prioridade baixa)
 	// Usa versÃ£o otimizada que nÃ£o bloqueia
 	go func() {
@@ -1204,6 +1028,7 @@ func (a *App) RemoveFromFavorites(animeURL string) bool {
 		if fav.URL == animeURL {
 			a.User.Favorites = append(a.User.Favorites[:i], a.User.Favorites[i+1:]...)
 			store.SaveUser(a.User)
+			store.DeleteLibraryEntry(animeURL)
 			return true
 		}
 	}
@@ -1230,6 +1055,10 @@ func (a *App) GetWatchHistory() []store.WatchedEpisode {
 	if a.User == nil {
 		return []store.WatchedEpisode{}
 	}
+	// A posição é gravada só no banco (o mpv salva de outra goroutine), então lê de lá
+	if history, err := store.RecentWatchedEpisodes(store.WatchHistoryLimit); err == nil {
+		return history
+	}
 	return a.User.WatchHistory
 }
 
@@ -1244,7 +1073,15 @@ func (a *App) AddToWatchHistory(episode store.WatchedEpisode) {
 		episode.WatchedAt = time.Now().Format(time.RFC3339)
 	}
 
-	// Remove entrada duplicada (mesmo episÃ³dio)
+	// Remove entrada duplicada (mesmo episÃ³dio), mantendo a posiÃ§Ã£o salva
+	if prev, err := store.GetWatchedEpisode(episode.EpisodeURL); err == nil && prev != nil {
+		if episode.Position == 0 {
+			episode.Position, episode.Duration = prev.Position, prev.Duration
+		}
+		if episode.Progress == 0 {
+			episode.Progress = prev.Progress
+		}
+	}
 	for i, e := range a.User.WatchHistory {
 		if e.EpisodeURL == episode.EpisodeURL {
 			a.User.WatchHistory = append(a.User.WatchHistory[:i], a.User.WatchHistory[i+1:]...)
@@ -1255,12 +1092,18 @@ func (a *App) AddToWatchHistory(episode store.WatchedEpisode) {
 	// Adiciona no inÃ­cio (mais recente primeiro)
 	a.User.WatchHistory = append([]store.WatchedEpisode{episode}, a.User.WatchHistory...)
 
-	// Limita a 50 entradas
-	if len(a.User.WatchHistory) > 50 {
-		a.User.WatchHistory = a.User.WatchHistory[:50]
+	// Limita as entradas em memória (o banco guarda o histórico completo)
+	if len(a.User.WatchHistory) > store.WatchHistoryLimit {
+		a.User.WatchHistory = a.User.WatchHistory[:store.WatchHistoryLimit]
 	}
 
-	store.SaveUser(a.User)
+	if err := store.SaveWatchedEpisode(a.User, episode); err != nil {
+		fmt.Printf("[Store] Erro ao salvar histórico: %v\n", err)
+	}
+	store.MarkWatching(episode.AnimeURL)
+	if episode.IsCompleted() {
+		a.onEpisodeFinished(episode)
+	}
 }
 
 // === CONFIGURAÃ‡Ã•ES ===
@@ -1280,6 +1123,7 @@ func (a *App) SaveSettings(settings store.UserSettings) bool {
 	}
 	a.User.Settings = settings
 	store.SaveUser(a.User)
+	a.applyVideoCacheSettings()
 	return true
 }
 
@@ -1291,7 +1135,13 @@ func (a *App) ExportUserData() (string, error) {
 		return "", fmt.Errorf("utilizador nÃ£o encontrado")
 	}
 
-	data, err := json.MarshalIndent(a.User, "", "  ")
+	// a.User só tem os episódios mais recentes; a exportação leva o histórico completo do banco
+	export, err := store.ExportUser(a.User)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler o histórico de episódios: %w", err)
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
 	if err != nil {
 		return "", err
 	}
@@ -1316,7 +1166,9 @@ func (a *App) ImportUserData(jsonData string) error {
 	}
 
 	a.User = &userData
-	store.SaveUser(a.User)
+	if err := store.ReplaceUser(a.User); err != nil {
+		return fmt.Errorf("erro ao salvar dados importados: %w", err)
+	}
 	return nil
 }
 
@@ -1482,7 +1334,7 @@ func (a *App) SearchAniList(query string, limit int) ([]*AniListAnime, error) {
 
 // GetAnimeHDImage busca imagem HD de um anime pelo tÃ­tulo
 func (a *App) GetAnimeHDImage(title string) (map[string]string, error) {
-	image, banner, color, err := anilist.GetHDImage(title)
+	image, banner, color, err := hdImageFor(title)
 	if err != nil {
 		return nil, err
 	}
@@ -1608,11 +1460,13 @@ func (a *App) GetStreamWithFallback(animeTitle string, episodeNumber int) (strin
 
 // SmartStreamResult Ã© o resultado da busca inteligente de stream
 type SmartStreamResult struct {
-	URL      string  `json:"url"`
-	Source   string  `json:"source"`
-	Duration float64 `json:"duration"` // em milissegundos
-	Success  bool    `json:"success"`
-	Error    string  `json:"error,omitempty"`
+	URL        string                  `json:"url"`
+	Source     string                  `json:"source"`
+	Duration   float64                 `json:"duration"` // em milissegundos
+	Success    bool                    `json:"success"`
+	Error      string                  `json:"error,omitempty"`
+	Headers    map[string]string       `json:"headers,omitempty"`    // Headers exigidos pela fonte (passar a GetProxyURLForStream)
+	Candidates []smartrouter.Candidate `json:"candidates,omitempty"` // Alternativas para o player, em ordem
 }
 
 // GetSmartStream usa o Smart Router para buscar stream com fallback automÃ¡tico
@@ -1629,13 +1483,18 @@ func (a *App) GetSmartStream(animeTitle string, episodeNumber int) (*SmartStream
 	}
 
 	// Usa o Smart Router
-	result := a.streamRouter.GetStream(animeTitle, episodeNumber)
+	ctx, done := a.beginStreamSearch("")
+	defer done()
+	result := a.streamRouter.GetStreamContext(ctx,
+		smartrouter.StreamRequest{AnimeTitle: animeTitle, EpisodeNumber: episodeNumber})
 
 	smartResult := &SmartStreamResult{
-		URL:      result.URL,
-		Source:   result.Source,
-		Duration: float64(result.Duration.Milliseconds()),
-		Success:  result.Error == nil && result.URL != "",
+		URL:        result.URL,
+		Source:     result.Source,
+		Duration:   float64(result.Duration.Milliseconds()),
+		Success:    result.Error == nil && result.URL != "",
+		Headers:    result.Headers,
+		Candidates: result.Candidates,
 	}
 
 	if result.Error != nil {
@@ -1662,13 +1521,18 @@ func (a *App) GetSmartStreamParallel(animeTitle string, episodeNumber int) (*Sma
 	}
 
 	// Busca em paralelo
-	result := a.streamRouter.GetStreamParallel(animeTitle, episodeNumber)
+	ctx, done := a.beginStreamSearch("")
+	defer done()
+	result := a.streamRouter.GetStreamParallelContext(ctx,
+		smartrouter.StreamRequest{AnimeTitle: animeTitle, EpisodeNumber: episodeNumber})
 
 	smartResult := &SmartStreamResult{
-		URL:      result.URL,
-		Source:   result.Source,
-		Duration: float64(result.Duration.Milliseconds()),
-		Success:  result.Error == nil && result.URL != "",
+		URL:        result.URL,
+		Source:     result.Source,
+		Duration:   float64(result.Duration.Milliseconds()),
+		Success:    result.Error == nil && result.URL != "",
+		Headers:    result.Headers,
+		Candidates: result.Candidates,
 	}
 
 	if result.Error != nil {
@@ -1685,6 +1549,10 @@ func (a *App) GetSmartStreamParallel(animeTitle string, episodeNumber int) (*Sma
 // GetStreamSourceStats retorna estatÃ­sticas das fontes de streaming
 func (a *App) GetStreamSourceStats() map[string]interface{} {
 	stats := a.streamRouter.GetAllStats()
+	rank := make(map[string]int)
+	for i, name := range a.streamRouter.Ranking() {
+		rank[name] = i + 1
+	}
 
 	result := make(map[string]interface{})
 	for name, s := range stats {
@@ -1701,6 +1569,16 @@ func (a *App) GetStreamSourceStats() map[string]interface{} {
 			"isCircuitOpen": s.IsCircuitOpen,
 			"lastSuccess":   s.LastSuccess.Format(time.RFC3339),
 			"lastFailure":   s.LastFailure.Format(time.RFC3339),
+			"successRate":   s.SuccessRate,
+			"ewmaLatencyMs": s.AvgLatency,
+			"p90LatencyMs":  s.P90Latency.Milliseconds(),
+			"score":         s.Score,
+			"rank":          rank[name],
+
+			"circuitState":        s.CircuitState,
+			"openIntervalSec":     s.OpenInterval.Seconds(),
+			"consecutiveFailures": s.ConsecutiveFailures,
+			"circuitEvents":       s.CircuitEvents,
 		}
 	}
 
@@ -2884,6 +2762,11 @@ func (a *App) PlayVideo(url string, title string) error {
 
 // PlayAnime reproduz o anime no MPV (com suporte a yt-dlp para URLs complexas)
 func (a *App) PlayAnime(url string) error {
+	return a.playAnimeFrom(url, 0)
+}
+
+// playAnimeFrom reproduz no MPV comeÃ§ando na posiÃ§Ã£o indicada (segundos)
+func (a *App) playAnimeFrom(url string, start float64, extraArgs ...string) error {
 	if url == "" {
 		return fmt.Errorf("URL invÃ¡lida")
 	}
@@ -2924,6 +2807,11 @@ func (a *App) PlayAnime(url string) error {
 		fmt.Printf("[PlayAnime] URL Ã© stream direto, reproduzindo diretamente\n")
 	}
 
+	if start > 0 {
+		args = append(args, fmt.Sprintf("--start=%.0f", start))
+	}
+
+	args = append(args, extraArgs...)
 	args = append(args, url)
 
 	fmt.Printf("Executando: %s %v\n", mpvPath, args)
@@ -3076,6 +2964,12 @@ func (a *App) GetStreamURLForEpisode(animeURL string, episodeURL string) (string
 
 	fmt.Printf("[GetStreamURLForEpisode] AnimeURL: %s, EpisodeURL: %s\n", animeURL, episodeURL)
 
+	// Episodio baixado: toca do disco, sem depender da fonte
+	if localURL, ok := a.offlineStreamURL(episodeURL); ok {
+		fmt.Println("[GetStreamURLForEpisode] Episódio disponível offline")
+		return localURL, nil
+	}
+
 	// === CACHE INTELIGENTE COM VALIDAÃ‡ÃƒO ===
 	cacheKey := fmt.Sprintf("stream:%s", episodeURL)
 
@@ -3237,7 +3131,10 @@ func (a *App) GetStreamURLForEpisode(animeURL string, episodeURL string) (string
 	go func() {
 		defer wg.Done()
 		fmt.Println("[GetStreamURLForEpisode] [Parallel] Buscando via Smart Router...")
-		result := a.streamRouter.GetStream(targetEpisode.Title, targetEpisode.Number)
+		ctx, done := a.beginStreamSearch("")
+		defer done()
+		result := a.streamRouter.GetStreamContext(ctx,
+			smartrouter.StreamRequest{AnimeTitle: targetEpisode.Title, EpisodeNumber: targetEpisode.Number})
 		if result != nil && result.URL != "" && result.Error == nil {
 			if valid, _ := a.ValidateStreamURL(result.URL); valid {
 				resultChan <- streamResult{url: result.URL, source: "SmartRouter:" + result.Source, err: nil}
@@ -3390,6 +3287,11 @@ func (a *App) tryGetStreamFromSource(episode *store.Episode, animeURL, episodeUR
 func (a *App) AssistirEpisodio(animeURL string, episodeURL string, episodeTitle string) error {
 	fmt.Printf("[AssistirEpisodio] Iniciando processo para: %s\n", episodeTitle)
 
+	// Episodio baixado: toca do disco com as legendas locais
+	if ep := a.lookupOffline(episodeURL); ep != nil {
+		return a.playOffline(ep, a.GetResumePosition(episodeURL))
+	}
+
 	// 1. Extrai o link real do vÃ­deo (MP4/M3U8) usando sua funÃ§Ã£o existente
 	streamURL, err := a.GetStreamURLForEpisode(animeURL, episodeURL)
 	if err != nil {
@@ -3403,8 +3305,8 @@ func (a *App) AssistirEpisodio(animeURL string, episodeURL string, episodeTitle
 
 	fmt.Printf("[AssistirEpisodio] Link extraÃ­do com sucesso: %s\n", streamURL)
 
-	// 2. Manda o MPV tocar o link REAL do vÃ­deo
-	return a.PlayVideo(streamURL, episodeTitle)
+	// 2. Manda o MPV tocar o link REAL do vÃ­deo, retomando de onde parou
+	return a.playAnimeFrom(streamURL, a.GetResumePosition(episodeURL))
 }
 
 // ============================================
@@ -4224,10 +4126,7 @@ func (a *App) GetChapterPages(chapterURL string) []MangaPageInfo {
 		// Usa proxy local para cache e evitar CORS/hotlink protection
 		proxyURL := p.URL
 		if a.proxyPort > 0 {
-			proxyURL = fmt.Sprintf("http://127.0.0.1:%d/manga-image?url=%s&referer=%s",
-				a.proxyPort,
-				url.QueryEscape(p.URL),
-				url.QueryEscape(referer))
+			proxyURL = a.mangaImageURL(p.URL, referer)
 		}
 		result[i] = MangaPageInfo{
 			Number: p.Number,
@@ -4265,14 +4164,14 @@ func (a *App) preloadMangaImage(imageURL, referer string) {
 	req.Header.Set("Accept", "image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8")
 	req.Header.Set("Referer", referer)
 
-	resp, err := a.imageClient.Do(req)
+	resp, err := genericProxyClient.Do(req)
 	if err != nil {
 		return
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == 200 {
-		data, err := io.ReadAll(resp.Body)
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxMangaImageSize))
 		if err == nil && len(data) > 0 {
 			a.imageCacheMutex.Lock()
 			a.imageCache[imageURL] = data
@@ -5059,10 +4958,7 @@ func (a *App) GetChapterPagesAuto(chapterURL string) []MangaPageInfo {
 		// Usa proxy local para cache e evitar CORS/hotlink protection
 		proxyURL := p.URL
 		if a.proxyPort > 0 {
-			proxyURL = fmt.Sprintf("http://127.0.0.1:%d/manga-image?url=%s&referer=%s",
-				a.proxyPort,
-				url.QueryEscape(p.URL),
-				url.QueryEscape(referer))
+			proxyURL = a.mangaImageURL(p.URL, referer)
 		}
 		result[i] = MangaPageInfo{
 			Number: p.Number,
@@ -0,0 +1,89 @@
+// calendar_methods.go - Calendário de exibição para o frontend
+// Episódios da semana vindos do airingSchedule da AniList, agrupados por dia no fuso do usuário
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"GoAnimeGUI/pkg/calendar"
+	"GoAnimeGUI/pkg/store"
+)
+
+// GetAiringCalendar retorna os episódios que vão ao ar nos próximos 7 dias.
+// libraryOnly limita aos animes em andamento da biblioteca; sem ele, vem a temporada inteira.
+// timezone é um nome IANA (ex.: "America/Sao_Paulo"); vazio usa o fuso do sistema.
+func (a *App) GetAiringCalendar(libraryOnly bool, timezone string) (*calendar.Calendar, error) {
+	loc := time.Local
+	if timezone != "" {
+		if l, err := time.LoadLocation(timezone); err == nil {
+			loc = l
+		} else {
+			fmt.Printf("[Calendar] Fuso horário inválido %q, usando o do sistema\n", timezone)
+		}
+	}
+
+	library, err := a.calendarLibrary()
+	if err != nil {
+		return nil, err
+	}
+
+	var mediaIDs []int
+	if libraryOnly {
+		if len(library) == 0 {
+			return &calendar.Calendar{
+				TimeZone:  loc.String(),
+				Days:      calendar.Group(nil, time.Now().In(loc), calendar.Days, loc),
+				UpdatedAt: time.Now().Format(time.RFC3339),
+			}, nil
+		}
+		for id := range library {
+			mediaIDs = append(mediaIDs, id)
+		}
+	}
+
+	cal, err := calendar.Default().Week(time.Now(), loc, mediaIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Marca o que está na biblioteca para o frontend abrir o anime direto
+	for d := range cal.Days {
+		for i := range cal.Days[d].Entries {
+			entry := &cal.Days[d].Entries[i]
+			if item, ok := library[entry.MediaID]; ok {
+				entry.AnimeURL = item.URL
+				entry.Progress = item.Progress
+			}
+		}
+	}
+	return cal, nil
+}
+
+// calendarLibrary retorna os animes da biblioteca que podem ter episódios novos, por ID da AniList.
+// Entradas ainda sem vínculo passam pelo resolver de identidade.
+func (a *App) calendarLibrary() (map[int]store.LibraryItem, error) {
+	db, err := store.Default()
+	if err != nil {
+		return nil, err
+	}
+	items, err := db.QueryLibrary(store.LibraryFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	library := make(map[int]store.LibraryItem)
+	for i := range items {
+		item := &items[i]
+		if item.Status == store.StatusCompleted || item.Status == store.StatusDropped {
+			continue
+		}
+		if item.AniListID == 0 {
+			linkLibraryIdentity(db, item)
+		}
+		if item.AniListID > 0 {
+			library[item.AniListID] = *item
+		}
+	}
+	return library, nil
+}
@@ -0,0 +1,201 @@
+// downloads_methods.go - Downloads de episódios para assistir offline
+// A fila e o progresso ficam no banco; o frontend acompanha pelos eventos download:*
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"GoAnimeGUI/pkg/downloader"
+	"GoAnimeGUI/pkg/store"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// downloadManager é nil se o banco não estiver disponível
+var downloadManager *downloader.Manager
+
+// torboxSourcePrefix marca downloads do TorBox: torbox://<torrentID>/<fileID>
+const torboxSourcePrefix = "torbox://"
+
+// initDownloadManager cria o gerenciador e inicia a fila
+func (a *App) initDownloadManager() {
+	db, err := store.Default()
+	if err != nil {
+		fmt.Printf("[Downloads] Banco indisponível, downloads desativados: %v\n", err)
+		return
+	}
+
+	downloadManager = downloader.New(db, a.resolveDownload, a.downloadDir, a.maxDownloads)
+	downloadManager.ConfigureHLS(a.downloadQuality, findFFmpegPath())
+	downloadManager.OnProgress(func(p downloader.Progress) {
+		runtime.EventsEmit(a.ctx, "download:progress", p)
+	})
+	downloadManager.OnChange(func(d store.Download) {
+		runtime.EventsEmit(a.ctx, "download:changed", d)
+		if d.State == store.DownloadCompleted {
+			a.addToOfflineLibrary(d)
+		}
+	})
+	go downloadManager.Run(a.ctx)
+}
+
+// downloadDir lê a pasta da biblioteca offline das configurações
+func (a *App) downloadDir() string {
+	if a.User != nil && a.User.Settings.DownloadDir != "" {
+		return a.User.Settings.DownloadDir
+	}
+	return downloader.DefaultDir()
+}
+
+// maxDownloads lê o limite de downloads simultâneos das configurações
+func (a *App) maxDownloads() int {
+	if a.User == nil || a.User.Settings.MaxDownloads <= 0 {
+		return store.DefaultMaxDownloads
+	}
+	return a.User.Settings.MaxDownloads
+}
+
+// downloadQuality é a qualidade escolhida nas variantes de streams HLS
+func (a *App) downloadQuality() string {
+	if a.User == nil || a.User.Settings.DefaultQuality == "" {
+		return "auto"
+	}
+	return a.User.Settings.DefaultQuality
+}
+
+// resolveDownload obtém um link novo a cada tentativa (os das fontes e do TorBox expiram)
+func (a *App) resolveDownload(ctx context.Context, d store.Download) (*downloader.Source, error) {
+	if strings.HasPrefix(d.SourceURL, torboxSourcePrefix) {
+		var torrentID, fileID int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(d.SourceURL, torboxSourcePrefix), "%d/%d", &torrentID, &fileID); err != nil {
+			return nil, fmt.Errorf("link TorBox inválido: %s", d.SourceURL)
+		}
+		if torboxClient == nil {
+			return nil, fmt.Errorf("TorBox não configurado")
+		}
+		link, err := torboxClient.GetDownloadLink(ctx, torrentID, fileID)
+		if err != nil {
+			return nil, err
+		}
+		return &downloader.Source{URL: link}, nil
+	}
+
+	videoURL := d.SourceURL
+	if videoURL == "" {
+		var err error
+		videoURL, err = a.GetStreamURLForEpisode(d.AnimeURL, d.EpisodeURL)
+		if err != nil {
+			// Episódios ainda não carregados nesta sessão: busca a lista e tenta de novo
+			if _, loadErr := a.GetEpisodes(d.AnimeURL); loadErr != nil {
+				return nil, err
+			}
+			if videoURL, err = a.GetStreamURLForEpisode(d.AnimeURL, d.EpisodeURL); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	req, err := http.NewRequest("GET", videoURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("URL de vídeo inválida: %w", err)
+	}
+	setVideoRequestHeaders(req, videoURL)
+	return &downloader.Source{URL: videoURL, Header: req.Header}, nil
+}
+
+// DownloadEpisode coloca um episódio na fila de downloads
+func (a *App) DownloadEpisode(animeURL, animeTitle, episodeURL string, episodeNum int, episodeTitle string) (*store.Download, error) {
+	if downloadManager == nil {
+		return nil, fmt.Errorf("downloads indisponíveis")
+	}
+	return downloadManager.Enqueue(store.Download{
+		Key:          episodeURL,
+		AnimeURL:     animeURL,
+		AnimeTitle:   animeTitle,
+		EpisodeURL:   episodeURL,
+		EpisodeNum:   episodeNum,
+		EpisodeTitle: episodeTitle,
+	})
+}
+
+// DownloadTorBoxFile coloca um arquivo do TorBox na fila de downloads.
+// fileName é o nome do arquivo no torrent (usado como nome no disco).
+func (a *App) DownloadTorBoxFile(torrentID, fileID int, animeTitle string, episodeNum int, fileName string) (*store.Download, error) {
+	if downloadManager == nil {
+		return nil, fmt.Errorf("downloads indisponíveis")
+	}
+	source := fmt.Sprintf("%s%d/%d", torboxSourcePrefix, torrentID, fileID)
+	return downloadManager.Enqueue(store.Download{
+		Key:          source,
+		AnimeTitle:   animeTitle,
+		EpisodeNum:   episodeNum,
+		EpisodeTitle: fileName,
+		SourceURL:    source,
+	})
+}
+
+// GetDownloads retorna a fila e os downloads concluídos
+func (a *App) GetDownloads() ([]store.Download, error) {
+	db, err := store.Default()
+	if err != nil {
+		return nil, err
+	}
+	return db.Downloads()
+}
+
+// PauseDownload pausa um download mantendo o que já foi baixado
+func (a *App) PauseDownload(id int64) error {
+	if downloadManager == nil {
+		return fmt.Errorf("downloads indisponíveis")
+	}
+	return downloadManager.Pause(id)
+}
+
+// ResumeDownload retoma um download pausado de onde parou
+func (a *App) ResumeDownload(id int64) error {
+	if downloadManager == nil {
+		return fmt.Errorf("downloads indisponíveis")
+	}
+	return downloadManager.Resume(id)
+}
+
+// RetryDownload tenta de novo um download que falhou
+func (a *App) RetryDownload(id int64) (*store.Download, error) {
+	if downloadManager == nil {
+		return nil, fmt.Errorf("downloads indisponíveis")
+	}
+	return downloadManager.Retry(id)
+}
+
+// CancelDownload remove um download da lista; deleteFile apaga também o arquivo
+func (a *App) CancelDownload(id int64, deleteFile bool) error {
+	if downloadManager == nil {
+		return fmt.Errorf("downloads indisponíveis")
+	}
+	return downloadManager.Cancel(id, deleteFile)
+}
+
+// GetDownloadFolder retorna a pasta onde os episódios são salvos
+func (a *App) GetDownloadFolder() string {
+	return a.downloadDir()
+}
+
+// ChooseDownloadFolder abre o seletor de pasta e salva a escolha nas configurações.
+// Downloads já iniciados continuam na pasta antiga.
+func (a *App) ChooseDownloadFolder() (string, error) {
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:            "Pasta de downloads",
+		DefaultDirectory: a.downloadDir(),
+	})
+	if err != nil || dir == "" {
+		return a.downloadDir(), err
+	}
+	if a.User != nil {
+		a.User.Settings.DownloadDir = dir
+		store.SaveUser(a.User)
+	}
+	return dir, nil
+}
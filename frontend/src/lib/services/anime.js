@@ -5,10 +5,10 @@
 import { 
     GetCurrentUser, CreateUser, BuscarAnimes, BuscarAnimesMulti, 
     GetTopAnimes, GetAnimeURL, GetEpisodes, GetEpisodesForSource, 
-    PlayAnime, GetStreamURLForEpisode, AssistirEpisodio, GetProxyURLForVideo,
+    PlayAnime, GetStreamURLForEpisode, AssistirEpisodio, GetProxyURLForVideo, GetProxyURLForStream,
     GetTrendingAnimes, GetPopularAnimes, SearchAniList, GetAnimeHDImage,
     ClearEpisodesCache, ClearAllCache, GetCacheStats, ResetSourceFailures,
-    GetSkipTimes
+    GetSkipTimes, GetSubtitleURL
 } from '../../../wailsjs/go/main/App';
 import { withTimeout } from '../utils/helpers.js';
 
@@ -92,12 +92,28 @@ export async function getStreamUrl(animeUrl, episodeUrl) {
 /**
  * Obtém URL proxy para o vídeo
  * @param {string} streamUrl - URL original do stream
+ * @param {Object<string, string>} [headers] - Headers exigidos pela fonte (Referer, Cookie...)
  * @returns {Promise<string>}
  */
-export async function getProxyUrl(streamUrl) {
+export async function getProxyUrl(streamUrl, headers) {
+    if (headers && Object.keys(headers).length > 0) {
+        return await GetProxyURLForStream(streamUrl, headers);
+    }
     return await GetProxyURLForVideo(streamUrl);
 }
 
+/**
+ * Obtém URL local da legenda convertida para WebVTT (o <track> do player só aceita WebVTT)
+ * @param {string} subtitleUrl - URL da legenda (.srt, .ass, .ssa ou .vtt)
+ * @param {string} [format] - Formato da legenda; vazio detecta pela extensão ou pelo conteúdo
+ * @param {number} [offsetSeconds] - Deslocamento em segundos (negativo adianta a legenda)
+ * @returns {Promise<string>}
+ */
+export async function getSubtitleUrl(subtitleUrl, format = '', offsetSeconds = 0) {
+    const url = await GetSubtitleURL(subtitleUrl, format);
+    return offsetSeconds ? `${url}&offset=${offsetSeconds}` : url;
+}
+
 /**
  * Reproduz episódio no MPV
  * @param {string} animeUrl - URL do anime
@@ -3,8 +3,14 @@
 import {social} from '../models';
 import {store} from '../models';
 import {auth} from '../models';
+import {offline} from '../models';
+import {identity} from '../models';
 import {main} from '../models';
+import {calendar} from '../models';
 import {extensions} from '../models';
+import {sourcehealth} from '../models';
+import {smartrouter} from '../models';
+import {syncqueue} from '../models';
 import {embeddedplayer} from '../models';
 import {time} from '../models';
 import {torbox} from '../models';
@@ -41,26 +47,50 @@ export function BuscarAnimes(arg1:string):Promise<Array<store.SavedAnime>>;
 
 export function BuscarAnimesMulti(arg1:Array<string>):Promise<Array<store.SavedAnime>>;
 
+export function CancelDownload(arg1:number,arg2:boolean):Promise<void>;
+
+export function CancelMangaDownload(arg1:number,arg2:boolean):Promise<void>;
+
+export function CancelStreamSearch(arg1:string):Promise<void>;
+
 export function CheckExtensionUpdates():Promise<Record<string, string>>;
 
+export function CheckLibraryUpdates():Promise<void>;
+
+export function ChooseDownloadFolder():Promise<string>;
+
+export function CleanupOfflineLibrary():Promise<offline.CleanupResult>;
+
 export function ClearAllCache():Promise<void>;
 
+export function ClearAnimeIdentity(arg1:identity.Query):Promise<void>;
+
 export function ClearEpisodesCache():Promise<void>;
 
 export function ClearSocialWatchingStatus():Promise<void>;
 
+export function ClearVideoCache():Promise<void>;
+
 export function CompleteDiscordOAuthWithCode(arg1:string):Promise<main.DiscordUserInfo>;
 
 export function ConfigurePlayerSkipSegments(arg1:main.SkipTimesResult):Promise<void>;
 
 export function ConnectDiscord(arg1:string):Promise<void>;
 
+export function CreateCategory(arg1:string):Promise<store.Category>;
+
 export function CreateSocialProfile(arg1:string):Promise<social.UserProfile>;
 
 export function CreateUser(arg1:string,arg2:string):Promise<store.UserData>;
 
+export function DeleteCategory(arg1:number):Promise<void>;
+
+export function DeleteOfflineEpisode(arg1:number):Promise<void>;
+
 export function DeleteSocialProfile():Promise<void>;
 
+export function DiscardSyncJob(arg1:number):Promise<void>;
+
 export function DisconnectDiscord():Promise<void>;
 
 export function DisconnectDiscordUser():Promise<void>;
@@ -77,6 +107,16 @@ export function DiscordRPCSetWatching(arg1:string,arg2:string,arg3:number,arg4:s
 
 export function DiscordRPCUpdatePosition(arg1:number,arg2:boolean):Promise<void>;
 
+export function DownloadEpisode(arg1:string,arg2:string,arg3:string,arg4:number,arg5:string):Promise<store.Download>;
+
+export function DownloadMangaChapters(arg1:string,arg2:Array<string>):Promise<Array<store.MangaDownload>>;
+
+export function DownloadMangaRange(arg1:string,arg2:number,arg3:number):Promise<Array<store.MangaDownload>>;
+
+export function DownloadMangaSeries(arg1:string):Promise<Array<store.MangaDownload>>;
+
+export function DownloadTorBoxFile(arg1:number,arg2:number,arg3:string,arg4:number,arg5:string):Promise<store.Download>;
+
 export function ExportUserData():Promise<string>;
 
 export function FetchRepositoryExtensions(arg1:string):Promise<Array<main.RemoteExtensionInfo>>;
@@ -85,6 +125,8 @@ export function FormatEpisodeTitle(arg1:string):Promise<string>;
 
 export function GenerateDiscordLinkCode():Promise<string>;
 
+export function GetAiringCalendar(arg1:boolean,arg2:string):Promise<calendar.Calendar>;
+
 export function GetAllMangaSources():Promise<Array<main.MangaSourceDetail>>;
 
 export function GetAllMangas(arg1:number):Promise<main.MangaListResult>;
@@ -99,8 +141,12 @@ export function GetAllMangasSafe():Promise<Array<main.MangaInfo>>;
 
 export function GetAlternativeSource(arg1:Array<string>):Promise<string>;
 
+export function GetAniListStatus():Promise<main.AniListStatus>;
+
 export function GetAnimeHDImage(arg1:string):Promise<Record<string, string>>;
 
+export function GetAnimeIdentities():Promise<Array<store.AnimeIdentity>>;
+
 export function GetAnimePoster(arg1:string):Promise<string>;
 
 export function GetAnimePostersMulti(arg1:Array<string>):Promise<Record<string, string>>;
@@ -115,6 +161,8 @@ export function GetAvailableLanguages():Promise<Array<string>>;
 
 export function GetCacheStats():Promise<main.CacheStats>;
 
+export function GetCategories():Promise<Array<store.Category>>;
+
 export function GetChapterPages(arg1:string):Promise<Array<main.MangaPageInfo>>;
 
 export function GetChapterPagesAuto(arg1:string):Promise<Array<main.MangaPageInfo>>;
@@ -123,6 +171,8 @@ export function GetConsumetEpisodes(arg1:string,arg2:string):Promise<Array<main.
 
 export function GetConsumetStream(arg1:string,arg2:string):Promise<string>;
 
+export function GetContinueWatching(arg1:number):Promise<Array<main.ContinueWatchingItem>>;
+
 export function GetCurrentUser():Promise<store.UserData>;
 
 export function GetDiscordConfigStatus():Promise<Record<string, any>>;
@@ -141,6 +191,10 @@ export function GetDiscordStatus():Promise<main.DiscordStatus>;
 
 export function GetDiscordUser():Promise<main.DiscordUserInfo>;
 
+export function GetDownloadFolder():Promise<string>;
+
+export function GetDownloads():Promise<Array<store.Download>>;
+
 export function GetEnabledMangaSources():Promise<Array<main.MangaSourceDetail>>;
 
 export function GetEnimeStream(arg1:string,arg2:number):Promise<string>;
@@ -169,12 +223,22 @@ export function GetFriendsActivity():Promise<Array<social.FriendActivity>>;
 
 export function GetFriendsList():Promise<Array<social.Friend>>;
 
+export function GetHostRules():Promise<main.HostRulesInfo>;
+
+export function GetIdentityCandidates(arg1:identity.Query):Promise<Array<identity.Candidate>>;
+
 export function GetInstalledExtensions():Promise<Array<main.ExtensionInfo>>;
 
 export function GetLatestMangas():Promise<Array<main.MangaInfo>>;
 
 export function GetLatestMangasFromSource(arg1:string):Promise<Array<main.MangaInfo>>;
 
+export function GetLibrary(arg1:store.LibraryFilter):Promise<Array<store.LibraryItem>>;
+
+export function GetLibraryUpdates(arg1:boolean):Promise<main.UpdatesFeed>;
+
+export function GetMALStatus():Promise<main.MALStatus>;
+
 export function GetMangaChapters(arg1:string):Promise<Array<main.MangaChapterInfo>>;
 
 export function GetMangaChaptersAuto(arg1:string):Promise<Array<main.MangaChapterInfo>>;
@@ -183,6 +247,8 @@ export function GetMangaDetails(arg1:string):Promise<main.MangaInfo>;
 
 export function GetMangaDetailsAuto(arg1:string):Promise<main.MangaInfo>;
 
+export function GetMangaDownloads():Promise<Array<store.MangaDownload>>;
+
 export function GetMangaGenres():Promise<Array<string>>;
 
 export function GetMangaSources():Promise<Array<string>>;
@@ -203,6 +269,10 @@ export function GetMergedMangasComplete():Promise<Array<main.MangaInfo>>;
 
 export function GetMergedMangasWithBestSource():Promise<Array<main.MangaInfo>>;
 
+export function GetOfflineLibrary():Promise<main.OfflineLibraryInfo>;
+
+export function GetOfflinePlayback(arg1:number):Promise<main.OfflinePlayback>;
+
 export function GetPlayer4KModes():Promise<Array<main.QualityModeInfo>>;
 
 export function GetPopularAnimes(arg1:number):Promise<Array<main.AniListAnime>>;
@@ -217,10 +287,14 @@ export function GetPopularMangasFromSource(arg1:string):Promise<Array<main.Manga
 
 export function GetPopularMangasSafe():Promise<Array<main.MangaInfo>>;
 
+export function GetProxyURLForStream(arg1:string,arg2:Record<string, string>):Promise<string>;
+
 export function GetProxyURLForVideo(arg1:string):Promise<string>;
 
 export function GetQualityModes():Promise<Array<main.QualityModeInfo>>;
 
+export function GetResumePosition(arg1:string):Promise<number>;
+
 export function GetSeedingStats():Promise<main.SeedingStats>;
 
 export function GetSettings():Promise<store.UserSettings>;
@@ -229,6 +303,8 @@ export function GetSkipTimes(arg1:number,arg2:number):Promise<main.SkipTimesResu
 
 export function GetSkipTimesAsync(arg1:number,arg2:number):Promise<void>;
 
+export function GetSkipTimesForAnime(arg1:identity.Query,arg2:number):Promise<main.SkipTimesResult>;
+
 export function GetSmartStream(arg1:string,arg2:number):Promise<main.SmartStreamResult>;
 
 export function GetSmartStreamParallel(arg1:string,arg2:number):Promise<main.SmartStreamResult>;
@@ -237,12 +313,20 @@ export function GetSocialConnectionStatus():Promise<boolean|string>;
 
 export function GetSocialProfile():Promise<social.UserProfile>;
 
+export function GetSourceHealthHistory(arg1:number):Promise<Array<sourcehealth.History>>;
+
+export function GetStreamCandidates(arg1:string,arg2:string,arg3:number,arg4:string,arg5:string):Promise<Array<smartrouter.Candidate>>;
+
 export function GetStreamSourceStats():Promise<Record<string, any>>;
 
 export function GetStreamURLForEpisode(arg1:string,arg2:string):Promise<string>;
 
 export function GetStreamWithFallback(arg1:string,arg2:number):Promise<string>;
 
+export function GetSubtitleURL(arg1:string,arg2:string):Promise<string>;
+
+export function GetSyncQueue():Promise<syncqueue.State>;
+
 export function GetTopAnimes():Promise<Array<store.SavedAnime>>;
 
 export function GetTorrentSources():Promise<Array<main.TorrentSource>>;
@@ -251,8 +335,12 @@ export function GetTrendingAnimes(arg1:number):Promise<Array<main.AniListAnime>>
 
 export function GetValidatedStreamCache(arg1:string):Promise<string|boolean>;
 
+export function GetVideoCacheStats():Promise<main.VideoCacheStats>;
+
 export function GetWatchHistory():Promise<Array<store.WatchedEpisode>>;
 
+export function GetWatchStatuses():Promise<Array<store.WatchStatus>>;
+
 export function HasSocialProfile():Promise<boolean>;
 
 export function ImportUserData(arg1:string):Promise<void>;
@@ -269,6 +357,8 @@ export function InstallExtensionFromFile(arg1:string):Promise<void>;
 
 export function InvalidateStreamCache(arg1:string):Promise<void>;
 
+export function IsEpisodeOffline(arg1:string):Promise<boolean>;
+
 export function IsFavorite(arg1:string):Promise<boolean>;
 
 export function IsMPVInstalled():Promise<boolean>;
@@ -285,8 +375,22 @@ export function IsTorBoxConfigured():Promise<boolean>;
 
 export function LikeDiscordRecommendation(arg1:string):Promise<boolean>;
 
+export function LinkAniList(arg1:string,arg2:number):Promise<void>;
+
 export function LinkDiscordWithCode(arg1:string):Promise<main.DiscordLinkInfo>;
 
+export function LinkMAL(arg1:string,arg2:number):Promise<void>;
+
+export function LogoutAniList():Promise<void>;
+
+export function LogoutMAL():Promise<void>;
+
+export function MarkAnimeUpdatesSeen(arg1:string):Promise<void>;
+
+export function MarkLibraryUpdatesSeen(arg1:Array<number>):Promise<void>;
+
+export function MoveLibraryEntries(arg1:Array<string>,arg2:Array<number>):Promise<void>;
+
 export function ParseEpisodeFilenames(arg1:Array<string>):Promise<main.EpisodeGroupResultInfo>;
 
 export function ParseEpisodeFilenamesJSON(arg1:Array<string>):Promise<string>;
@@ -295,6 +399,10 @@ export function ParseEpisodeFilenamesV2(arg1:Array<string>):Promise<main.Episode
 
 export function ParseSingleEpisodeFilename(arg1:string):Promise<main.ParsedEpisodeInfo>;
 
+export function PauseDownload(arg1:number):Promise<void>;
+
+export function PauseMangaDownload(arg1:number):Promise<void>;
+
 export function PlayAnime(arg1:string):Promise<void>;
 
 export function PlayVideo(arg1:string,arg2:string):Promise<void>;
@@ -311,6 +419,8 @@ export function PlayerGetVolume():Promise<number>;
 
 export function PlayerLoad(arg1:string,arg2:string):Promise<void>;
 
+export function PlayerLoadEpisode(arg1:string,arg2:store.WatchedEpisode):Promise<void>;
+
 export function PlayerLoadSubtitle(arg1:string):Promise<void>;
 
 export function PlayerPause():Promise<void>;
@@ -347,6 +457,8 @@ export function PlayerToggleMute():Promise<void>;
 
 export function RegenerateSocialShareCode():Promise<string>;
 
+export function ReloadHostRules():Promise<void>;
+
 export function RemoteCleanupTorrents(arg1:number):Promise<number>;
 
 export function RemoteDeleteTorrent(arg1:number):Promise<boolean>;
@@ -381,14 +493,38 @@ export function RemoveFriend(arg1:string):Promise<void>;
 
 export function RemoveFromFavorites(arg1:string):Promise<boolean>;
 
+export function RenameCategory(arg1:number,arg2:string):Promise<void>;
+
+export function ReorderCategories(arg1:Array<number>):Promise<void>;
+
 export function ResetMangaSources():Promise<void>;
 
 export function ResetSourceFailures():Promise<void>;
 
+export function ResetSourceHealth(arg1:string):Promise<void>;
+
 export function ResetStreamCircuits():Promise<void>;
 
+export function ResolveAnimeIdentity(arg1:identity.Query):Promise<store.AnimeIdentity>;
+
+export function ResumeDownload(arg1:number):Promise<void>;
+
+export function ResumeMangaDownload(arg1:number):Promise<void>;
+
+export function RetryDownload(arg1:number):Promise<store.Download>;
+
+export function RetryMangaDownload(arg1:number):Promise<store.MangaDownload>;
+
+export function RetrySyncQueue():Promise<void>;
+
+export function SaveAniListConfig(arg1:string,arg2:string):Promise<void>;
+
 export function SaveDiscordConfig(arg1:string,arg2:string):Promise<void>;
 
+export function SaveEpisodePosition(arg1:string,arg2:number,arg3:number):Promise<void>;
+
+export function SaveMALConfig(arg1:string,arg2:string):Promise<void>;
+
 export function SaveSettings(arg1:store.UserSettings):Promise<boolean>;
 
 export function SearchAniList(arg1:string,arg2:number):Promise<Array<main.AniListAnime>>;
@@ -415,6 +551,8 @@ export function SearchWithExtension(arg1:string,arg2:string,arg3:number):Promise
 
 export function SendDiscordRecommendation(arg1:string,arg2:string,arg3:number,arg4:string):Promise<void>;
 
+export function SetAnimeIdentity(arg1:identity.Query,arg2:number):Promise<store.AnimeIdentity>;
+
 export function SetDiscordClientSecret(arg1:string):Promise<void>;
 
 export function SetDiscordShareAnimes(arg1:boolean):Promise<void>;
@@ -423,6 +561,14 @@ export function SetDiscordShowStatus(arg1:boolean):Promise<void>;
 
 export function SetFullscreen(arg1:boolean):Promise<void>;
 
+export function SetLibraryNotes(arg1:string,arg2:string):Promise<void>;
+
+export function SetLibraryScore(arg1:string,arg2:number):Promise<void>;
+
+export function SetLibraryStatus(arg1:string,arg2:string):Promise<void>;
+
+export function SetMangaSourceLimit(arg1:string,arg2:number):Promise<void>;
+
 export function SetSocialShareAnimes(arg1:boolean):Promise<void>;
 
 export function SetSocialShowStatus(arg1:boolean):Promise<void>;
@@ -435,14 +581,22 @@ export function SkipEnding():Promise<void>;
 
 export function SkipOpening():Promise<void>;
 
+export function StartAniListLogin():Promise<void>;
+
 export function StartDiscordOAuth():Promise<void>;
 
+export function StartMALLogin():Promise<void>;
+
 export function StartSeeding():Promise<void>;
 
 export function StopPlayer4K():Promise<void>;
 
 export function StopSeeding():Promise<void>;
 
+export function SyncAniList():Promise<main.TrackerSyncResult>;
+
+export function SyncMAL():Promise<main.TrackerSyncResult>;
+
 export function SyncSocialWithServer():Promise<void>;
 
 export function ToggleExtension(arg1:string,arg2:boolean):Promise<void>;
@@ -505,6 +659,8 @@ export function VPSGetEpisodeGoFile(arg1:string,arg2:number):Promise<string>;
 
 export function VPSGetStreamURL(arg1:string):Promise<string>;
 
+export function VPSGetSubtitleVTT(arg1:string,arg2:string,arg3:string,arg4:number):Promise<string>;
+
 export function VPSSearchStream(arg1:string):Promise<main.VPSSearchResult>;
 
 export function VPSSearchSubtitles(arg1:string,arg2:number):Promise<main.SubtitleSearchResponse>;
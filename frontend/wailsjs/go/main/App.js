@@ -66,14 +66,42 @@ export function BuscarAnimesMulti(arg1) {
   return window['go']['main']['App']['BuscarAnimesMulti'](arg1);
 }
 
+export function CancelDownload(arg1, arg2) {
+  return window['go']['main']['App']['CancelDownload'](arg1, arg2);
+}
+
+export function CancelMangaDownload(arg1, arg2) {
+  return window['go']['main']['App']['CancelMangaDownload'](arg1, arg2);
+}
+
+export function CancelStreamSearch(arg1) {
+  return window['go']['main']['App']['CancelStreamSearch'](arg1);
+}
+
 export function CheckExtensionUpdates() {
   return window['go']['main']['App']['CheckExtensionUpdates']();
 }
 
+export function CheckLibraryUpdates() {
+  return window['go']['main']['App']['CheckLibraryUpdates']();
+}
+
+export function ChooseDownloadFolder() {
+  return window['go']['main']['App']['ChooseDownloadFolder']();
+}
+
+export function CleanupOfflineLibrary() {
+  return window['go']['main']['App']['CleanupOfflineLibrary']();
+}
+
 export function ClearAllCache() {
   return window['go']['main']['App']['ClearAllCache']();
 }
 
+export function ClearAnimeIdentity(arg1) {
+  return window['go']['main']['App']['ClearAnimeIdentity'](arg1);
+}
+
 export function ClearEpisodesCache() {
   return window['go']['main']['App']['ClearEpisodesCache']();
 }
@@ -82,6 +110,10 @@ export function ClearSocialWatchingStatus() {
   return window['go']['main']['App']['ClearSocialWatchingStatus']();
 }
 
+export function ClearVideoCache() {
+  return window['go']['main']['App']['ClearVideoCache']();
+}
+
 export function CompleteDiscordOAuthWithCode(arg1) {
   return window['go']['main']['App']['CompleteDiscordOAuthWithCode'](arg1);
 }
@@ -94,6 +126,10 @@ export function ConnectDiscord(arg1) {
   return window['go']['main']['App']['ConnectDiscord'](arg1);
 }
 
+export function CreateCategory(arg1) {
+  return window['go']['main']['App']['CreateCategory'](arg1);
+}
+
 export function CreateSocialProfile(arg1) {
   return window['go']['main']['App']['CreateSocialProfile'](arg1);
 }
@@ -102,10 +138,22 @@ export function CreateUser(arg1, arg2) {
   return window['go']['main']['App']['CreateUser'](arg1, arg2);
 }
 
+export function DeleteCategory(arg1) {
+  return window['go']['main']['App']['DeleteCategory'](arg1);
+}
+
+export function DeleteOfflineEpisode(arg1) {
+  return window['go']['main']['App']['DeleteOfflineEpisode'](arg1);
+}
+
 export function DeleteSocialProfile() {
   return window['go']['main']['App']['DeleteSocialProfile']();
 }
 
+export function DiscardSyncJob(arg1) {
+  return window['go']['main']['App']['DiscardSyncJob'](arg1);
+}
+
 export function DisconnectDiscord() {
   return window['go']['main']['App']['DisconnectDiscord']();
 }
@@ -138,6 +186,26 @@ export function DiscordRPCUpdatePosition(arg1, arg2) {
   return window['go']['main']['App']['DiscordRPCUpdatePosition'](arg1, arg2);
 }
 
+export function DownloadEpisode(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['DownloadEpisode'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function DownloadMangaChapters(arg1, arg2) {
+  return window['go']['main']['App']['DownloadMangaChapters'](arg1, arg2);
+}
+
+export function DownloadMangaRange(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DownloadMangaRange'](arg1, arg2, arg3);
+}
+
+export function DownloadMangaSeries(arg1) {
+  return window['go']['main']['App']['DownloadMangaSeries'](arg1);
+}
+
+export function DownloadTorBoxFile(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['DownloadTorBoxFile'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function ExportUserData() {
   return window['go']['main']['App']['ExportUserData']();
 }
@@ -154,6 +222,10 @@ export function GenerateDiscordLinkCode() {
   return window['go']['main']['App']['GenerateDiscordLinkCode']();
 }
 
+export function GetAiringCalendar(arg1, arg2) {
+  return window['go']['main']['App']['GetAiringCalendar'](arg1, arg2);
+}
+
 export function GetAllMangaSources() {
   return window['go']['main']['App']['GetAllMangaSources']();
 }
@@ -182,10 +254,18 @@ export function GetAlternativeSource(arg1) {
   return window['go']['main']['App']['GetAlternativeSource'](arg1);
 }
 
+export function GetAniListStatus() {
+  return window['go']['main']['App']['GetAniListStatus']();
+}
+
 export function GetAnimeHDImage(arg1) {
   return window['go']['main']['App']['GetAnimeHDImage'](arg1);
 }
 
+export function GetAnimeIdentities() {
+  return window['go']['main']['App']['GetAnimeIdentities']();
+}
+
 export function GetAnimePoster(arg1) {
   return window['go']['main']['App']['GetAnimePoster'](arg1);
 }
@@ -214,6 +294,10 @@ export function GetCacheStats() {
   return window['go']['main']['App']['GetCacheStats']();
 }
 
+export function GetCategories() {
+  return window['go']['main']['App']['GetCategories']();
+}
+
 export function GetChapterPages(arg1) {
   return window['go']['main']['App']['GetChapterPages'](arg1);
 }
@@ -230,6 +314,10 @@ export function GetConsumetStream(arg1, arg2) {
   return window['go']['main']['App']['GetConsumetStream'](arg1, arg2);
 }
 
+export function GetContinueWatching(arg1) {
+  return window['go']['main']['App']['GetContinueWatching'](arg1);
+}
+
 export function GetCurrentUser() {
   return window['go']['main']['App']['GetCurrentUser']();
 }
@@ -266,6 +354,14 @@ export function GetDiscordUser() {
   return window['go']['main']['App']['GetDiscordUser']();
 }
 
+export function GetDownloadFolder() {
+  return window['go']['main']['App']['GetDownloadFolder']();
+}
+
+export function GetDownloads() {
+  return window['go']['main']['App']['GetDownloads']();
+}
+
 export function GetEnabledMangaSources() {
   return window['go']['main']['App']['GetEnabledMangaSources']();
 }
@@ -322,6 +418,14 @@ export function GetFriendsList() {
   return window['go']['main']['App']['GetFriendsList']();
 }
 
+export function GetHostRules() {
+  return window['go']['main']['App']['GetHostRules']();
+}
+
+export function GetIdentityCandidates(arg1) {
+  return window['go']['main']['App']['GetIdentityCandidates'](arg1);
+}
+
 export function GetInstalledExtensions() {
   return window['go']['main']['App']['GetInstalledExtensions']();
 }
@@ -334,6 +438,18 @@ export function GetLatestMangasFromSource(arg1) {
   return window['go']['main']['App']['GetLatestMangasFromSource'](arg1);
 }
 
+export function GetLibrary(arg1) {
+  return window['go']['main']['App']['GetLibrary'](arg1);
+}
+
+export function GetLibraryUpdates(arg1) {
+  return window['go']['main']['App']['GetLibraryUpdates'](arg1);
+}
+
+export function GetMALStatus() {
+  return window['go']['main']['App']['GetMALStatus']();
+}
+
 export function GetMangaChapters(arg1) {
   return window['go']['main']['App']['GetMangaChapters'](arg1);
 }
@@ -350,6 +466,10 @@ export function GetMangaDetailsAuto(arg1) {
   return window['go']['main']['App']['GetMangaDetailsAuto'](arg1);
 }
 
+export function GetMangaDownloads() {
+  return window['go']['main']['App']['GetMangaDownloads']();
+}
+
 export function GetMangaGenres() {
   return window['go']['main']['App']['GetMangaGenres']();
 }
@@ -390,6 +510,14 @@ export function GetMergedMangasWithBestSource() {
   return window['go']['main']['App']['GetMergedMangasWithBestSource']();
 }
 
+export function GetOfflineLibrary() {
+  return window['go']['main']['App']['GetOfflineLibrary']();
+}
+
+export function GetOfflinePlayback(arg1) {
+  return window['go']['main']['App']['GetOfflinePlayback'](arg1);
+}
+
 export function GetPlayer4KModes() {
   return window['go']['main']['App']['GetPlayer4KModes']();
 }
@@ -418,6 +546,10 @@ export function GetPopularMangasSafe() {
   return window['go']['main']['App']['GetPopularMangasSafe']();
 }
 
+export function GetProxyURLForStream(arg1, arg2) {
+  return window['go']['main']['App']['GetProxyURLForStream'](arg1, arg2);
+}
+
 export function GetProxyURLForVideo(arg1) {
   return window['go']['main']['App']['GetProxyURLForVideo'](arg1);
 }
@@ -426,6 +558,10 @@ export function GetQualityModes() {
   return window['go']['main']['App']['GetQualityModes']();
 }
 
+export function GetResumePosition(arg1) {
+  return window['go']['main']['App']['GetResumePosition'](arg1);
+}
+
 export function GetSeedingStats() {
   return window['go']['main']['App']['GetSeedingStats']();
 }
@@ -442,6 +578,10 @@ export function GetSkipTimesAsync(arg1, arg2) {
   return window['go']['main']['App']['GetSkipTimesAsync'](arg1, arg2);
 }
 
+export function GetSkipTimesForAnime(arg1, arg2) {
+  return window['go']['main']['App']['GetSkipTimesForAnime'](arg1, arg2);
+}
+
 export function GetSmartStream(arg1, arg2) {
   return window['go']['main']['App']['GetSmartStream'](arg1, arg2);
 }
@@ -458,6 +598,14 @@ export function GetSocialProfile() {
   return window['go']['main']['App']['GetSocialProfile']();
 }
 
+export function GetSourceHealthHistory(arg1) {
+  return window['go']['main']['App']['GetSourceHealthHistory'](arg1);
+}
+
+export function GetStreamCandidates(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['GetStreamCandidates'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function GetStreamSourceStats() {
   return window['go']['main']['App']['GetStreamSourceStats']();
 }
@@ -470,6 +618,14 @@ export function GetStreamWithFallback(arg1, arg2) {
   return window['go']['main']['App']['GetStreamWithFallback'](arg1, arg2);
 }
 
+export function GetSubtitleURL(arg1, arg2) {
+  return window['go']['main']['App']['GetSubtitleURL'](arg1, arg2);
+}
+
+export function GetSyncQueue() {
+  return window['go']['main']['App']['GetSyncQueue']();
+}
+
 export function GetTopAnimes() {
   return window['go']['main']['App']['GetTopAnimes']();
 }
@@ -486,10 +642,18 @@ export function GetValidatedStreamCache(arg1) {
   return window['go']['main']['App']['GetValidatedStreamCache'](arg1);
 }
 
+export function GetVideoCacheStats() {
+  return window['go']['main']['App']['GetVideoCacheStats']();
+}
+
 export function GetWatchHistory() {
   return window['go']['main']['App']['GetWatchHistory']();
 }
 
+export function GetWatchStatuses() {
+  return window['go']['main']['App']['GetWatchStatuses']();
+}
+
 export function HasSocialProfile() {
   return window['go']['main']['App']['HasSocialProfile']();
 }
@@ -522,6 +686,10 @@ export function InvalidateStreamCache(arg1) {
   return window['go']['main']['App']['InvalidateStreamCache'](arg1);
 }
 
+export function IsEpisodeOffline(arg1) {
+  return window['go']['main']['App']['IsEpisodeOffline'](arg1);
+}
+
 export function IsFavorite(arg1) {
   return window['go']['main']['App']['IsFavorite'](arg1);
 }
@@ -554,10 +722,38 @@ export function LikeDiscordRecommendation(arg1) {
   return window['go']['main']['App']['LikeDiscordRecommendation'](arg1);
 }
 
+export function LinkAniList(arg1, arg2) {
+  return window['go']['main']['App']['LinkAniList'](arg1, arg2);
+}
+
 export function LinkDiscordWithCode(arg1) {
   return window['go']['main']['App']['LinkDiscordWithCode'](arg1);
 }
 
+export function LinkMAL(arg1, arg2) {
+  return window['go']['main']['App']['LinkMAL'](arg1, arg2);
+}
+
+export function LogoutAniList() {
+  return window['go']['main']['App']['LogoutAniList']();
+}
+
+export function LogoutMAL() {
+  return window['go']['main']['App']['LogoutMAL']();
+}
+
+export function MarkAnimeUpdatesSeen(arg1) {
+  return window['go']['main']['App']['MarkAnimeUpdatesSeen'](arg1);
+}
+
+export function MarkLibraryUpdatesSeen(arg1) {
+  return window['go']['main']['App']['MarkLibraryUpdatesSeen'](arg1);
+}
+
+export function MoveLibraryEntries(arg1, arg2) {
+  return window['go']['main']['App']['MoveLibraryEntries'](arg1, arg2);
+}
+
 export function ParseEpisodeFilenames(arg1) {
   return window['go']['main']['App']['ParseEpisodeFilenames'](arg1);
 }
@@ -574,6 +770,14 @@ export function ParseSingleEpisodeFilename(arg1) {
   return window['go']['main']['App']['ParseSingleEpisodeFilename'](arg1);
 }
 
+export function PauseDownload(arg1) {
+  return window['go']['main']['App']['PauseDownload'](arg1);
+}
+
+export function PauseMangaDownload(arg1) {
+  return window['go']['main']['App']['PauseMangaDownload'](arg1);
+}
+
 export function PlayAnime(arg1) {
   return window['go']['main']['App']['PlayAnime'](arg1);
 }
@@ -606,6 +810,10 @@ export function PlayerLoad(arg1, arg2) {
   return window['go']['main']['App']['PlayerLoad'](arg1, arg2);
 }
 
+export function PlayerLoadEpisode(arg1, arg2) {
+  return window['go']['main']['App']['PlayerLoadEpisode'](arg1, arg2);
+}
+
 export function PlayerLoadSubtitle(arg1) {
   return window['go']['main']['App']['PlayerLoadSubtitle'](arg1);
 }
@@ -678,6 +886,10 @@ export function RegenerateSocialShareCode() {
   return window['go']['main']['App']['RegenerateSocialShareCode']();
 }
 
+export function ReloadHostRules() {
+  return window['go']['main']['App']['ReloadHostRules']();
+}
+
 export function RemoteCleanupTorrents(arg1) {
   return window['go']['main']['App']['RemoteCleanupTorrents'](arg1);
 }
@@ -746,6 +958,14 @@ export function RemoveFromFavorites(arg1) {
   return window['go']['main']['App']['RemoveFromFavorites'](arg1);
 }
 
+export function RenameCategory(arg1, arg2) {
+  return window['go']['main']['App']['RenameCategory'](arg1, arg2);
+}
+
+export function ReorderCategories(arg1) {
+  return window['go']['main']['App']['ReorderCategories'](arg1);
+}
+
 export function ResetMangaSources() {
   return window['go']['main']['App']['ResetMangaSources']();
 }
@@ -754,14 +974,54 @@ export function ResetSourceFailures() {
   return window['go']['main']['App']['ResetSourceFailures']();
 }
 
+export function ResetSourceHealth(arg1) {
+  return window['go']['main']['App']['ResetSourceHealth'](arg1);
+}
+
 export function ResetStreamCircuits() {
   return window['go']['main']['App']['ResetStreamCircuits']();
 }
 
+export function ResolveAnimeIdentity(arg1) {
+  return window['go']['main']['App']['ResolveAnimeIdentity'](arg1);
+}
+
+export function ResumeDownload(arg1) {
+  return window['go']['main']['App']['ResumeDownload'](arg1);
+}
+
+export function ResumeMangaDownload(arg1) {
+  return window['go']['main']['App']['ResumeMangaDownload'](arg1);
+}
+
+export function RetryDownload(arg1) {
+  return window['go']['main']['App']['RetryDownload'](arg1);
+}
+
+export function RetryMangaDownload(arg1) {
+  return window['go']['main']['App']['RetryMangaDownload'](arg1);
+}
+
+export function RetrySyncQueue() {
+  return window['go']['main']['App']['RetrySyncQueue']();
+}
+
+export function SaveAniListConfig(arg1, arg2) {
+  return window['go']['main']['App']['SaveAniListConfig'](arg1, arg2);
+}
+
 export function SaveDiscordConfig(arg1, arg2) {
   return window['go']['main']['App']['SaveDiscordConfig'](arg1, arg2);
 }
 
+export function SaveEpisodePosition(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SaveEpisodePosition'](arg1, arg2, arg3);
+}
+
+export function SaveMALConfig(arg1, arg2) {
+  return window['go']['main']['App']['SaveMALConfig'](arg1, arg2);
+}
+
 export function SaveSettings(arg1) {
   return window['go']['main']['App']['SaveSettings'](arg1);
 }
@@ -814,6 +1074,10 @@ export function SendDiscordRecommendation(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['SendDiscordRecommendation'](arg1, arg2, arg3, arg4);
 }
 
+export function SetAnimeIdentity(arg1, arg2) {
+  return window['go']['main']['App']['SetAnimeIdentity'](arg1, arg2);
+}
+
 export function SetDiscordClientSecret(arg1) {
   return window['go']['main']['App']['SetDiscordClientSecret'](arg1);
 }
@@ -830,6 +1094,22 @@ export function SetFullscreen(arg1) {
   return window['go']['main']['App']['SetFullscreen'](arg1);
 }
 
+export function SetLibraryNotes(arg1, arg2) {
+  return window['go']['main']['App']['SetLibraryNotes'](arg1, arg2);
+}
+
+export function SetLibraryScore(arg1, arg2) {
+  return window['go']['main']['App']['SetLibraryScore'](arg1, arg2);
+}
+
+export function SetLibraryStatus(arg1, arg2) {
+  return window['go']['main']['App']['SetLibraryStatus'](arg1, arg2);
+}
+
+export function SetMangaSourceLimit(arg1, arg2) {
+  return window['go']['main']['App']['SetMangaSourceLimit'](arg1, arg2);
+}
+
 export function SetSocialShareAnimes(arg1) {
   return window['go']['main']['App']['SetSocialShareAnimes'](arg1);
 }
@@ -854,10 +1134,18 @@ export function SkipOpening() {
   return window['go']['main']['App']['SkipOpening']();
 }
 
+export function StartAniListLogin() {
+  return window['go']['main']['App']['StartAniListLogin']();
+}
+
 export function StartDiscordOAuth() {
   return window['go']['main']['App']['StartDiscordOAuth']();
 }
 
+export function StartMALLogin() {
+  return window['go']['main']['App']['StartMALLogin']();
+}
+
 export function StartSeeding() {
   return window['go']['main']['App']['StartSeeding']();
 }
@@ -870,6 +1158,14 @@ export function StopSeeding() {
   return window['go']['main']['App']['StopSeeding']();
 }
 
+export function SyncAniList() {
+  return window['go']['main']['App']['SyncAniList']();
+}
+
+export function SyncMAL() {
+  return window['go']['main']['App']['SyncMAL']();
+}
+
 export function SyncSocialWithServer() {
   return window['go']['main']['App']['SyncSocialWithServer']();
 }
@@ -994,6 +1290,10 @@ export function VPSGetStreamURL(arg1) {
   return window['go']['main']['App']['VPSGetStreamURL'](arg1);
 }
 
+export function VPSGetSubtitleVTT(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['VPSGetSubtitleVTT'](arg1, arg2, arg3, arg4);
+}
+
 export function VPSSearchStream(arg1) {
   return window['go']['main']['App']['VPSSearchStream'](arg1);
 }
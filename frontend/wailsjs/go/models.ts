@@ -1,3 +1,24 @@
+export namespace anilist {
+	
+	export class Viewer {
+	    id: number;
+	    name: string;
+	    avatar: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Viewer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.avatar = source["avatar"];
+	    }
+	}
+
+}
+
 export namespace auth {
 	
 	export class UserSession {
@@ -57,6 +78,114 @@ export namespace auth {
 
 }
 
+export namespace calendar {
+	
+	export class Entry {
+	    mediaId: number;
+	    malId: number;
+	    title: string;
+	    image: string;
+	    color: string;
+	    format: string;
+	    episode: number;
+	    episodes: number;
+	    airingAt: number;
+	    time: string;
+	    animeUrl: string;
+	    progress: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Entry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mediaId = source["mediaId"];
+	        this.malId = source["malId"];
+	        this.title = source["title"];
+	        this.image = source["image"];
+	        this.color = source["color"];
+	        this.format = source["format"];
+	        this.episode = source["episode"];
+	        this.episodes = source["episodes"];
+	        this.airingAt = source["airingAt"];
+	        this.time = source["time"];
+	        this.animeUrl = source["animeUrl"];
+	        this.progress = source["progress"];
+	    }
+	}
+	export class Day {
+	    date: string;
+	    weekday: number;
+	    entries: Entry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Day(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.weekday = source["weekday"];
+	        this.entries = this.convertValues(source["entries"], Entry);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Calendar {
+	    timeZone: string;
+	    days: Day[];
+	    updatedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Calendar(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timeZone = source["timeZone"];
+	        this.days = this.convertValues(source["days"], Day);
+	        this.updatedAt = source["updatedAt"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+
+}
+
 export namespace embeddedplayer {
 	
 	export class TrackInfo {
@@ -304,6 +433,114 @@ export namespace extensions {
 
 }
 
+export namespace hostrules {
+	
+	export class TLSSettings {
+	    insecureSkipVerify?: boolean;
+	    minVersion?: string;
+	    serverName?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TLSSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.insecureSkipVerify = source["insecureSkipVerify"];
+	        this.minVersion = source["minVersion"];
+	        this.serverName = source["serverName"];
+	    }
+	}
+	export class Rule {
+	    hosts: string[];
+	    headers?: Record<string, string>;
+	    cookies?: Record<string, string>;
+	    tls?: TLSSettings;
+	    origin?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Rule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hosts = source["hosts"];
+	        this.headers = source["headers"];
+	        this.cookies = source["cookies"];
+	        this.tls = this.convertValues(source["tls"], TLSSettings);
+	        this.origin = source["origin"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace identity {
+	
+	export class Candidate {
+	    anilistId: number;
+	    malId: number;
+	    title: string;
+	    image: string;
+	    year: number;
+	    episodes: number;
+	    score: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Candidate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.anilistId = source["anilistId"];
+	        this.malId = source["malId"];
+	        this.title = source["title"];
+	        this.image = source["image"];
+	        this.year = source["year"];
+	        this.episodes = source["episodes"];
+	        this.score = source["score"];
+	    }
+	}
+	export class Query {
+	    title: string;
+	    source: string;
+	    url: string;
+	    year: number;
+	    episodes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Query(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.source = source["source"];
+	        this.url = source["url"];
+	        this.year = source["year"];
+	        this.episodes = source["episodes"];
+	    }
+	}
+
+}
+
 export namespace main {
 	
 	export class AniListAnime {
@@ -358,6 +595,42 @@ export namespace main {
 	        this.nextEpisode = source["nextEpisode"];
 	    }
 	}
+	export class AniListStatus {
+	    configured: boolean;
+	    loggedIn: boolean;
+	    user?: anilist.Viewer;
+	    redirectUri: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AniListStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.configured = source["configured"];
+	        this.loggedIn = source["loggedIn"];
+	        this.user = this.convertValues(source["user"], anilist.Viewer);
+	        this.redirectUri = source["redirectUri"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class AnimeSourceInfo {
 	    id: string;
 	    name: string;
@@ -478,6 +751,38 @@ export namespace main {
 	        this.provider = source["provider"];
 	    }
 	}
+	export class ContinueWatchingItem {
+	    animeTitle: string;
+	    animeImage: string;
+	    animeUrl: string;
+	    episodeTitle: string;
+	    episodeUrl: string;
+	    episodeNum: number;
+	    position: number;
+	    duration: number;
+	    progress: number;
+	    isNext: boolean;
+	    watchedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ContinueWatchingItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.animeTitle = source["animeTitle"];
+	        this.animeImage = source["animeImage"];
+	        this.animeUrl = source["animeUrl"];
+	        this.episodeTitle = source["episodeTitle"];
+	        this.episodeUrl = source["episodeUrl"];
+	        this.episodeNum = source["episodeNum"];
+	        this.position = source["position"];
+	        this.duration = source["duration"];
+	        this.progress = source["progress"];
+	        this.isNext = source["isNext"];
+	        this.watchedAt = source["watchedAt"];
+	    }
+	}
 	export class DiscordFriendActivity {
 	    userId: string;
 	    username: string;
@@ -835,37 +1140,105 @@ export namespace main {
 	    }
 	}
 	
-	export class MangaChapterInfo {
-	    number: string;
-	    title: string;
-	    url: string;
-	    date: string;
-	    mangaId: string;
-	    mangaName: string;
+	export class HostRulesInfo {
+	    path: string;
+	    rules: hostrules.Rule[];
 	
 	    static createFrom(source: any = {}) {
-	        return new MangaChapterInfo(source);
+	        return new HostRulesInfo(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.number = source["number"];
-	        this.title = source["title"];
-	        this.url = source["url"];
-	        this.date = source["date"];
-	        this.mangaId = source["mangaId"];
-	        this.mangaName = source["mangaName"];
+	        this.path = source["path"];
+	        this.rules = this.convertValues(source["rules"], hostrules.Rule);
 	    }
-	}
-	export class MangaInfo {
-	    id: string;
-	    title: string;
-	    image: string;
-	    url: string;
-	    latestChapter: string;
-	    genres: string[];
-	    description: string;
-	    status: string;
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class MALStatus {
+	    configured: boolean;
+	    loggedIn: boolean;
+	    user?: mal.User;
+	    redirectUri: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MALStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.configured = source["configured"];
+	        this.loggedIn = source["loggedIn"];
+	        this.user = this.convertValues(source["user"], mal.User);
+	        this.redirectUri = source["redirectUri"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class MangaChapterInfo {
+	    number: string;
+	    title: string;
+	    url: string;
+	    date: string;
+	    mangaId: string;
+	    mangaName: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MangaChapterInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.number = source["number"];
+	        this.title = source["title"];
+	        this.url = source["url"];
+	        this.date = source["date"];
+	        this.mangaId = source["mangaId"];
+	        this.mangaName = source["mangaName"];
+	    }
+	}
+	export class MangaInfo {
+	    id: string;
+	    title: string;
+	    image: string;
+	    url: string;
+	    latestChapter: string;
+	    genres: string[];
+	    description: string;
+	    status: string;
 	    source: string;
 	
 	    static createFrom(source: any = {}) {
@@ -981,6 +1354,54 @@ export namespace main {
 	        this.url = source["url"];
 	    }
 	}
+	export class OfflineLibraryInfo {
+	    anime: offline.Anime[];
+	    totalBytes: number;
+	    quotaBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new OfflineLibraryInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.anime = this.convertValues(source["anime"], offline.Anime);
+	        this.totalBytes = source["totalBytes"];
+	        this.quotaBytes = source["quotaBytes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OfflinePlayback {
+	    url: string;
+	    subtitles: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new OfflinePlayback(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.subtitles = source["subtitles"];
+	    }
+	}
 	
 	export class PipelineResult {
 	    success: boolean;
@@ -1340,6 +1761,8 @@ export namespace main {
 	    duration: number;
 	    success: boolean;
 	    error?: string;
+	    headers?: Record<string, string>;
+	    candidates?: smartrouter.Candidate[];
 	
 	    static createFrom(source: any = {}) {
 	        return new SmartStreamResult(source);
@@ -1352,7 +1775,27 @@ export namespace main {
 	        this.duration = source["duration"];
 	        this.success = source["success"];
 	        this.error = source["error"];
+	        this.headers = source["headers"];
+	        this.candidates = this.convertValues(source["candidates"], smartrouter.Candidate);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	
 	export class SubtitleSearchResult {
@@ -1566,6 +2009,118 @@ export namespace main {
 	        this.available = source["available"];
 	    }
 	}
+	export class TrackerConflict {
+	    animeUrl: string;
+	    title: string;
+	    localProgress: number;
+	    remoteProgress: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TrackerConflict(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.animeUrl = source["animeUrl"];
+	        this.title = source["title"];
+	        this.localProgress = source["localProgress"];
+	        this.remoteProgress = source["remoteProgress"];
+	    }
+	}
+	export class TrackerRemoteEntry {
+	    id: number;
+	    title: string;
+	    image: string;
+	    status: string;
+	    progress: number;
+	    episodes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TrackerRemoteEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.image = source["image"];
+	        this.status = source["status"];
+	        this.progress = source["progress"];
+	        this.episodes = source["episodes"];
+	    }
+	}
+	export class TrackerSyncResult {
+	    linked: number;
+	    pulled: number;
+	    pushed: number;
+	    remoteOnly: TrackerRemoteEntry[];
+	    conflicts: TrackerConflict[];
+	    errors: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TrackerSyncResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.linked = source["linked"];
+	        this.pulled = source["pulled"];
+	        this.pushed = source["pushed"];
+	        this.remoteOnly = this.convertValues(source["remoteOnly"], TrackerRemoteEntry);
+	        this.conflicts = this.convertValues(source["conflicts"], TrackerConflict);
+	        this.errors = source["errors"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UpdatesFeed {
+	    unseen: number;
+	    items: store.LibraryUpdate[];
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdatesFeed(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.unseen = source["unseen"];
+	        this.items = this.convertValues(source["items"], store.LibraryUpdate);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class VPSPipelineResponse {
 	    status: string;
 	    job_id: string;
@@ -1626,44 +2181,175 @@ export namespace main {
 	        this.error = source["error"];
 	    }
 	}
+	export class VideoCacheStats {
+	    entries: number;
+	    bytes: number;
+	    maxBytes: number;
+	    hits: number;
+	    misses: number;
+	    hitBytes: number;
+	    evictions: number;
+	    hitRate: number;
+	    prefetched: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new VideoCacheStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entries = source["entries"];
+	        this.bytes = source["bytes"];
+	        this.maxBytes = source["maxBytes"];
+	        this.hits = source["hits"];
+	        this.misses = source["misses"];
+	        this.hitBytes = source["hitBytes"];
+	        this.evictions = source["evictions"];
+	        this.hitRate = source["hitRate"];
+	        this.prefetched = source["prefetched"];
+	    }
+	}
 
 }
 
-export namespace social {
+export namespace mal {
 	
-	export class Friend {
-	    user_id: string;
-	    username: string;
-	    avatar?: string;
-	    share_code?: string;
-	    // Go type: time
-	    added_at: any;
-	    is_online: boolean;
-	    // Go type: time
-	    last_seen: any;
-	    current_anime?: string;
-	    current_ep?: number;
-	    total_watched: number;
+	export class User {
+	    id: number;
+	    name: string;
+	    picture: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new Friend(source);
+	        return new User(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.user_id = source["user_id"];
-	        this.username = source["username"];
-	        this.avatar = source["avatar"];
-	        this.share_code = source["share_code"];
-	        this.added_at = this.convertValues(source["added_at"], null);
-	        this.is_online = source["is_online"];
-	        this.last_seen = this.convertValues(source["last_seen"], null);
-	        this.current_anime = source["current_anime"];
-	        this.current_ep = source["current_ep"];
-	        this.total_watched = source["total_watched"];
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.picture = source["picture"];
 	    }
-	
-		convertValues(a: any, classs: any, asMap: boolean = false): any {
+	}
+
+}
+
+export namespace offline {
+	
+	export class Anime {
+	    animeUrl: string;
+	    title: string;
+	    size: number;
+	    episodes: store.OfflineEpisode[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Anime(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.animeUrl = source["animeUrl"];
+	        this.title = source["title"];
+	        this.size = source["size"];
+	        this.episodes = this.convertValues(source["episodes"], store.OfflineEpisode);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CleanupResult {
+	    removed: number;
+	    freedBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CleanupResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.removed = source["removed"];
+	        this.freedBytes = source["freedBytes"];
+	    }
+	}
+
+}
+
+export namespace smartrouter {
+	
+	export class Candidate {
+	    url: string;
+	    source: string;
+	    quality: string;
+	    language: string;
+	    format: string;
+	    headers?: Record<string, string>;
+	
+	    static createFrom(source: any = {}) {
+	        return new Candidate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.source = source["source"];
+	        this.quality = source["quality"];
+	        this.language = source["language"];
+	        this.format = source["format"];
+	        this.headers = source["headers"];
+	    }
+	}
+
+}
+
+export namespace social {
+	
+	export class Friend {
+	    user_id: string;
+	    username: string;
+	    avatar?: string;
+	    share_code?: string;
+	    // Go type: time
+	    added_at: any;
+	    is_online: boolean;
+	    // Go type: time
+	    last_seen: any;
+	    current_anime?: string;
+	    current_ep?: number;
+	    total_watched: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Friend(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.user_id = source["user_id"];
+	        this.username = source["username"];
+	        this.avatar = source["avatar"];
+	        this.share_code = source["share_code"];
+	        this.added_at = this.convertValues(source["added_at"], null);
+	        this.is_online = source["is_online"];
+	        this.last_seen = this.convertValues(source["last_seen"], null);
+	        this.current_anime = source["current_anime"];
+	        this.current_ep = source["current_ep"];
+	        this.total_watched = source["total_watched"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
 		    if (!a) {
 		        return a;
 		    }
@@ -1762,8 +2448,156 @@ export namespace social {
 
 }
 
+export namespace sourcehealth {
+	
+	export class HourStats {
+	    // Go type: time
+	    hour: any;
+	    successes: number;
+	    failures: number;
+	    successRate: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HourStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hour = this.convertValues(source["hour"], null);
+	        this.successes = source["successes"];
+	        this.failures = source["failures"];
+	        this.successRate = source["successRate"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Status {
+	    source: string;
+	    score: number;
+	    failCount: number;
+	    inCooldown: boolean;
+	    // Go type: time
+	    cooldownUntil: any;
+	    lastError: string;
+	    // Go type: time
+	    lastFailureAt: any;
+	    // Go type: time
+	    lastSuccessAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Status(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.score = source["score"];
+	        this.failCount = source["failCount"];
+	        this.inCooldown = source["inCooldown"];
+	        this.cooldownUntil = this.convertValues(source["cooldownUntil"], null);
+	        this.lastError = source["lastError"];
+	        this.lastFailureAt = this.convertValues(source["lastFailureAt"], null);
+	        this.lastSuccessAt = this.convertValues(source["lastSuccessAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class History {
+	    status: Status;
+	    successRate: number;
+	    hours: HourStats[];
+	
+	    static createFrom(source: any = {}) {
+	        return new History(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = this.convertValues(source["status"], Status);
+	        this.successRate = source["successRate"];
+	        this.hours = this.convertValues(source["hours"], HourStats);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+
+}
+
 export namespace store {
 	
+	export class AnimeIdentity {
+	    key: string;
+	    title: string;
+	    anilistId: number;
+	    malId: number;
+	    confidence: number;
+	    manual: boolean;
+	    updatedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AnimeIdentity(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.title = source["title"];
+	        this.anilistId = source["anilistId"];
+	        this.malId = source["malId"];
+	        this.confidence = source["confidence"];
+	        this.manual = source["manual"];
+	        this.updatedAt = source["updatedAt"];
+	    }
+	}
 	export class AnimeSource {
 	    Name: string;
 	    Language: string;
@@ -1780,6 +2614,64 @@ export namespace store {
 	        this.URL = source["URL"];
 	    }
 	}
+	export class Category {
+	    id: number;
+	    name: string;
+	    position: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Category(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.position = source["position"];
+	    }
+	}
+	export class Download {
+	    id: number;
+	    key: string;
+	    animeUrl: string;
+	    animeTitle: string;
+	    episodeUrl: string;
+	    episodeNum: number;
+	    episodeTitle: string;
+	    sourceUrl: string;
+	    path: string;
+	    state: string;
+	    totalBytes: number;
+	    downloadedBytes: number;
+	    attempts: number;
+	    lastError: string;
+	    createdAt: string;
+	    updatedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Download(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.key = source["key"];
+	        this.animeUrl = source["animeUrl"];
+	        this.animeTitle = source["animeTitle"];
+	        this.episodeUrl = source["episodeUrl"];
+	        this.episodeNum = source["episodeNum"];
+	        this.episodeTitle = source["episodeTitle"];
+	        this.sourceUrl = source["sourceUrl"];
+	        this.path = source["path"];
+	        this.state = source["state"];
+	        this.totalBytes = source["totalBytes"];
+	        this.downloadedBytes = source["downloadedBytes"];
+	        this.attempts = source["attempts"];
+	        this.lastError = source["lastError"];
+	        this.createdAt = source["createdAt"];
+	        this.updatedAt = source["updatedAt"];
+	    }
+	}
 	export class Episode {
 	    Title: string;
 	    URL: string;
@@ -1800,6 +2692,188 @@ export namespace store {
 	        this.Source = source["Source"];
 	    }
 	}
+	export class LibraryFilter {
+	    status: string;
+	    categoryId: number;
+	    source: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LibraryFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.categoryId = source["categoryId"];
+	        this.source = source["source"];
+	    }
+	}
+	export class LibraryItem {
+	    Title: string;
+	    Image: string;
+	    URL: string;
+	    Source?: string;
+	    Sources?: AnimeSource[];
+	    status: string;
+	    score: number;
+	    notes: string;
+	    categories: number[];
+	    progress: number;
+	    anilistId: number;
+	    malId: number;
+	    addedAt: string;
+	    updatedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LibraryItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Title = source["Title"];
+	        this.Image = source["Image"];
+	        this.URL = source["URL"];
+	        this.Source = source["Source"];
+	        this.Sources = this.convertValues(source["Sources"], AnimeSource);
+	        this.status = source["status"];
+	        this.score = source["score"];
+	        this.notes = source["notes"];
+	        this.categories = source["categories"];
+	        this.progress = source["progress"];
+	        this.anilistId = source["anilistId"];
+	        this.malId = source["malId"];
+	        this.addedAt = source["addedAt"];
+	        this.updatedAt = source["updatedAt"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LibraryUpdate {
+	    id: number;
+	    animeUrl: string;
+	    title: string;
+	    image: string;
+	    source: string;
+	    episodeFrom: number;
+	    episodeTo: number;
+	    seen: boolean;
+	    createdAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LibraryUpdate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.animeUrl = source["animeUrl"];
+	        this.title = source["title"];
+	        this.image = source["image"];
+	        this.source = source["source"];
+	        this.episodeFrom = source["episodeFrom"];
+	        this.episodeTo = source["episodeTo"];
+	        this.seen = source["seen"];
+	        this.createdAt = source["createdAt"];
+	    }
+	}
+	export class MangaDownload {
+	    id: number;
+	    chapterUrl: string;
+	    source: string;
+	    mangaUrl: string;
+	    mangaTitle: string;
+	    author: string;
+	    summary: string;
+	    genres: string[];
+	    chapterNumber: string;
+	    chapterTitle: string;
+	    path: string;
+	    state: string;
+	    pagesTotal: number;
+	    pagesDone: number;
+	    attempts: number;
+	    lastError: string;
+	    createdAt: string;
+	    updatedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MangaDownload(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.chapterUrl = source["chapterUrl"];
+	        this.source = source["source"];
+	        this.mangaUrl = source["mangaUrl"];
+	        this.mangaTitle = source["mangaTitle"];
+	        this.author = source["author"];
+	        this.summary = source["summary"];
+	        this.genres = source["genres"];
+	        this.chapterNumber = source["chapterNumber"];
+	        this.chapterTitle = source["chapterTitle"];
+	        this.path = source["path"];
+	        this.state = source["state"];
+	        this.pagesTotal = source["pagesTotal"];
+	        this.pagesDone = source["pagesDone"];
+	        this.attempts = source["attempts"];
+	        this.lastError = source["lastError"];
+	        this.createdAt = source["createdAt"];
+	        this.updatedAt = source["updatedAt"];
+	    }
+	}
+	export class OfflineEpisode {
+	    id: number;
+	    path: string;
+	    downloadId: number;
+	    animeUrl: string;
+	    animeTitle: string;
+	    season: number;
+	    episodeUrl: string;
+	    episodeNum: number;
+	    episodeTitle: string;
+	    size: number;
+	    subtitles: string[];
+	    addedAt: string;
+	    watchedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OfflineEpisode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.path = source["path"];
+	        this.downloadId = source["downloadId"];
+	        this.animeUrl = source["animeUrl"];
+	        this.animeTitle = source["animeTitle"];
+	        this.season = source["season"];
+	        this.episodeUrl = source["episodeUrl"];
+	        this.episodeNum = source["episodeNum"];
+	        this.episodeTitle = source["episodeTitle"];
+	        this.size = source["size"];
+	        this.subtitles = source["subtitles"];
+	        this.addedAt = source["addedAt"];
+	        this.watchedAt = source["watchedAt"];
+	    }
+	}
 	export class SavedAnime {
 	    Title: string;
 	    Image: string;
@@ -1838,6 +2912,36 @@ export namespace store {
 		    return a;
 		}
 	}
+	export class SyncJob {
+	    id: number;
+	    kind: string;
+	    key: string;
+	    payload: string;
+	    attempts: number;
+	    state: string;
+	    lastError: string;
+	    nextAttemptAt: number;
+	    createdAt: string;
+	    updatedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncJob(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.key = source["key"];
+	        this.payload = source["payload"];
+	        this.attempts = source["attempts"];
+	        this.state = source["state"];
+	        this.lastError = source["lastError"];
+	        this.nextAttemptAt = source["nextAttemptAt"];
+	        this.createdAt = source["createdAt"];
+	        this.updatedAt = source["updatedAt"];
+	    }
+	}
 	export class UserSettings {
 	    start_fullscreen: boolean;
 	    content_language: string;
@@ -1849,6 +2953,13 @@ export namespace store {
 	    seeding_only_wifi: boolean;
 	    seeding_schedule: string;
 	    seeding_contributed: number;
+	    update_check_hours: number;
+	    download_dir: string;
+	    max_downloads: number;
+	    offline_delete_watched: boolean;
+	    offline_quota_gb: number;
+	    video_cache_mb: number;
+	    video_read_ahead: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new UserSettings(source);
@@ -1866,6 +2977,13 @@ export namespace store {
 	        this.seeding_only_wifi = source["seeding_only_wifi"];
 	        this.seeding_schedule = source["seeding_schedule"];
 	        this.seeding_contributed = source["seeding_contributed"];
+	        this.update_check_hours = source["update_check_hours"];
+	        this.download_dir = source["download_dir"];
+	        this.max_downloads = source["max_downloads"];
+	        this.offline_delete_watched = source["offline_delete_watched"];
+	        this.offline_quota_gb = source["offline_quota_gb"];
+	        this.video_cache_mb = source["video_cache_mb"];
+	        this.video_read_ahead = source["video_read_ahead"];
 	    }
 	}
 	export class WatchedEpisode {
@@ -1877,6 +2995,8 @@ export namespace store {
 	    episode_num: number;
 	    watched_at: string;
 	    progress: number;
+	    position: number;
+	    duration: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new WatchedEpisode(source);
@@ -1892,6 +3012,8 @@ export namespace store {
 	        this.episode_num = source["episode_num"];
 	        this.watched_at = source["watched_at"];
 	        this.progress = source["progress"];
+	        this.position = source["position"];
+	        this.duration = source["duration"];
 	    }
 	}
 	export class UserData {
@@ -1942,6 +3064,45 @@ export namespace store {
 
 }
 
+export namespace syncqueue {
+	
+	export class State {
+	    pending: number;
+	    failed: number;
+	    jobs: store.SyncJob[];
+	
+	    static createFrom(source: any = {}) {
+	        return new State(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pending = source["pending"];
+	        this.failed = source["failed"];
+	        this.jobs = this.convertValues(source["jobs"], store.SyncJob);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace torbox {
 	
 	export class AnimeTorrent {
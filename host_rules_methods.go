@@ -0,0 +1,25 @@
+// host_rules_methods.go - Regras de headers por host (pkg/hostrules)
+// Referer, Origin, cookies e TLS dos CDNs ficam em host_rules.json na pasta de dados,
+// então um CDN quebrado se conserta editando o arquivo e recarregando, sem nova versão do app
+package main
+
+import "GoAnimeGUI/pkg/hostrules"
+
+// HostRulesInfo são as regras em uso para o frontend
+type HostRulesInfo struct {
+	Path  string           `json:"path"` // Arquivo editável pelo usuário
+	Rules []hostrules.Rule `json:"rules"`
+}
+
+// GetHostRules retorna todas as regras na ordem de aplicação (embutidas, arquivo, extensions)
+func (a *App) GetHostRules() HostRulesInfo {
+	return HostRulesInfo{
+		Path:  hostrules.DefaultPath(),
+		Rules: hostrules.Default().List(),
+	}
+}
+
+// ReloadHostRules relê host_rules.json; as próximas requisições já usam as regras novas
+func (a *App) ReloadHostRules() error {
+	return hostrules.Default().Reload()
+}
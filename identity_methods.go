@@ -0,0 +1,138 @@
+// identity_methods.go - Métodos de identidade canônica para o frontend
+// Liga entradas de qualquer fonte aos IDs da AniList e do MyAnimeList, com correção manual
+package main
+
+import (
+	"fmt"
+
+	"GoAnimeGUI/pkg/anilist"
+	"GoAnimeGUI/pkg/identity"
+	"GoAnimeGUI/pkg/store"
+)
+
+// ResolveAnimeIdentity retorna os IDs canônicos de uma entrada, buscando na AniList se preciso.
+// AniListID zero indica que nenhum resultado foi confiável o bastante.
+func (a *App) ResolveAnimeIdentity(query identity.Query) (*store.AnimeIdentity, error) {
+	r, err := identity.Default()
+	if err != nil {
+		return nil, err
+	}
+	return r.Resolve(query)
+}
+
+// GetIdentityCandidates lista os resultados da AniList para o usuário escolher o correto
+func (a *App) GetIdentityCandidates(query identity.Query) ([]identity.Candidate, error) {
+	r, err := identity.Default()
+	if err != nil {
+		return nil, err
+	}
+	return r.Candidates(query)
+}
+
+// SetAnimeIdentity corrige manualmente o vínculo de uma entrada.
+// O ID do MAL vem da AniList; se a entrada estiver na biblioteca, os trackers são atualizados.
+func (a *App) SetAnimeIdentity(query identity.Query, anilistID int) (*store.AnimeIdentity, error) {
+	if anilistID <= 0 {
+		return nil, fmt.Errorf("ID da AniList inválido: %d", anilistID)
+	}
+	r, err := identity.Default()
+	if err != nil {
+		return nil, err
+	}
+
+	media, err := anilist.GetAnimeByID(anilistID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar anime %d na AniList: %w", anilistID, err)
+	}
+	if media == nil {
+		return nil, fmt.Errorf("anime %d não encontrado na AniList", anilistID)
+	}
+
+	id, err := r.Override(query, media.ID, media.MALID)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("[Identity] %q corrigido para AniList %d / MAL %d\n", query.Title, id.AniListID, id.MALID)
+
+	if query.URL != "" {
+		db, err := store.Default()
+		if err != nil {
+			return id, nil
+		}
+		if err := db.LinkAniList(query.URL, id.AniListID); err == nil {
+			db.LinkMAL(query.URL, id.MALID)
+			a.onLibraryChanged(query.URL)
+		}
+	}
+	return id, nil
+}
+
+// ClearAnimeIdentity desfaz o vínculo (automático ou manual) para que seja resolvido de novo
+func (a *App) ClearAnimeIdentity(query identity.Query) error {
+	r, err := identity.Default()
+	if err != nil {
+		return err
+	}
+	return r.Forget(query)
+}
+
+// GetAnimeIdentities lista os vínculos conhecidos, as correções manuais primeiro
+func (a *App) GetAnimeIdentities() ([]store.AnimeIdentity, error) {
+	db, err := store.Default()
+	if err != nil {
+		return nil, err
+	}
+	return db.Identities()
+}
+
+// GetSkipTimesForAnime busca os skip times identificando o anime pela fonte,
+// sem depender de o frontend já conhecer o ID do MAL
+func (a *App) GetSkipTimesForAnime(query identity.Query, episodeNumber int) (*SkipTimesResult, error) {
+	id, err := a.ResolveAnimeIdentity(query)
+	if err != nil {
+		return nil, err
+	}
+	if id.MALID == 0 {
+		return nil, fmt.Errorf("ID do MAL desconhecido para %q", query.Title)
+	}
+	return a.GetSkipTimes(id.MALID, episodeNumber)
+}
+
+// linkLibraryIdentity preenche os IDs que faltam numa entrada da biblioteca pelo resolver
+func linkLibraryIdentity(db *store.DB, item *store.LibraryItem) {
+	r, err := identity.Default()
+	if err != nil {
+		return
+	}
+	id, err := r.Resolve(identity.Query{Title: item.Title, Source: item.Source, URL: item.URL})
+	if err != nil {
+		fmt.Printf("[Identity] Erro ao identificar %s: %v\n", item.Title, err)
+		return
+	}
+	if id.AniListID == 0 {
+		return
+	}
+
+	if item.AniListID == 0 {
+		if err := db.LinkAniList(item.URL, id.AniListID); err == nil {
+			item.AniListID = id.AniListID
+		}
+	}
+	if item.MALID == 0 && id.MALID > 0 {
+		if err := db.LinkMAL(item.URL, id.MALID); err == nil {
+			item.MALID = id.MALID
+		}
+	}
+}
+
+// hdImageFor busca a arte pelo anime identificado, caindo na busca por título se não houver vínculo
+func hdImageFor(title string) (image, banner, color string, err error) {
+	if r, err := identity.Default(); err == nil {
+		if id, err := r.Resolve(identity.Query{Title: title}); err == nil && id.AniListID > 0 {
+			if media, err := anilist.GetAnimeByID(id.AniListID); err == nil && media != nil {
+				return media.GetBestImage(), media.BannerImage, media.CoverImage.Color, nil
+			}
+		}
+	}
+	return anilist.GetHDImage(title)
+}
@@ -10,7 +10,9 @@ import (
 
 	"GoAnimeGUI/internal/cache"
 	"GoAnimeGUI/internal/proxy"
+	"GoAnimeGUI/pkg/segmentcache"
 	"GoAnimeGUI/pkg/smartrouter"
+	"GoAnimeGUI/pkg/sourcehealth"
 	"GoAnimeGUI/pkg/store"
 	"GoAnimeGUI/pkg/videoextractor"
 
@@ -23,7 +25,7 @@ type StreamService struct {
 	client        *goanime.Client
 	cache         *cache.Cache
 	streamCache   *cache.StreamCache
-	sourceTracker *cache.SourceTracker
+	sourceTracker *sourcehealth.Tracker
 	streamRouter  *smartrouter.SmartRouter
 	proxy         *proxy.Server
 	episodesCache map[string][]store.Episode
@@ -32,13 +34,21 @@ type StreamService struct {
 
 // NewStreamService cria um novo servii§o de streaming
 func NewStreamService() *StreamService {
+	// Mesmo registro de saúde do app: falhas vistas aqui valem para todos os caminhos
+	health := sourcehealth.Default()
+	router := smartrouter.New(smartrouter.DefaultConfig())
+	router.SetHealth(health)
+	// Cache de segmentos compartilhado com o proxy do app
+	videoProxy := proxy.New()
+	videoProxy.SetCache(segmentcache.Default(), store.DefaultVideoReadAhead)
+
 	return &StreamService{
 		client:        goanime.NewClient(),
 		cache:         cache.New(),
 		streamCache:   cache.NewStreamCache(),
-		sourceTracker: cache.NewSourceTracker(),
-		streamRouter:  smartrouter.New(smartrouter.DefaultConfig()),
-		proxy:         proxy.New(),
+		sourceTracker: health,
+		streamRouter:  router,
+		proxy:         videoProxy,
 		episodesCache: make(map[string][]store.Episode),
 	}
 }
@@ -146,7 +156,7 @@ func (s *StreamService) GetStreamURLForEpisode(animeURL, episodeURL string, cach
 
 	// Verifica disponibilidade da fonte
 	if !s.sourceTracker.IsAvailable(sourceName) {
-		altSource := s.sourceTracker.GetAlternative(sourceName)
+		altSource := s.alternativeSource(sourceName)
 		if altSource != "" {
 			fmt.Printf("[GetStreamURL] Fonte %s em cooldown, usando %s\n", sourceName, altSource)
 			sourceName = altSource
@@ -261,12 +271,12 @@ func (s *StreamService) GetStreamURLForEpisode(animeURL, episodeURL string, cach
 
 			if result.url != "" {
 				s.streamCache.Set(cacheKey, result.url, result.source, cache.TTLStream)
-				s.sourceTracker.RecordSuccess(result.source)
+				s.recordSuccess(result.source)
 				fmt.Printf("[GetStreamURL] âœ“ Sucesso com %s!\n", result.source)
 				return result.url, nil
 			} else if result.err != nil {
 				lastError = result.err
-				s.sourceTracker.RecordFailure(result.source, result.err.Error())
+				s.recordFailure(result.source, result.err.Error())
 			}
 
 		case <-timeout:
@@ -312,6 +322,10 @@ func (s *StreamService) tryGetStream(episode *store.Episode, animeURL, episodeUR
 // GetSourceStats retorna estatisticas das fontes
 func (s *StreamService) GetSourceStats() map[string]interface{} {
 	stats := s.streamRouter.GetAllStats()
+	rank := make(map[string]int)
+	for i, name := range s.streamRouter.Ranking() {
+		rank[name] = i + 1
+	}
 	result := make(map[string]interface{})
 
 	for name, stat := range stats {
@@ -328,12 +342,58 @@ func (s *StreamService) GetSourceStats() map[string]interface{} {
 			"isCircuitOpen": stat.IsCircuitOpen,
 			"lastSuccess":   stat.LastSuccess.Format(time.RFC3339),
 			"lastFailure":   stat.LastFailure.Format(time.RFC3339),
+			"successRate":   stat.SuccessRate,
+			"ewmaLatencyMs": stat.AvgLatency,
+			"p90LatencyMs":  stat.P90Latency.Milliseconds(),
+			"score":         stat.Score,
+			"rank":          rank[name],
+
+			"circuitState":        stat.CircuitState,
+			"openIntervalSec":     stat.OpenInterval.Seconds(),
+			"consecutiveFailures": stat.ConsecutiveFailures,
+			"circuitEvents":       stat.CircuitEvents,
 		}
 	}
 
 	return result
 }
 
+// alternativeSources são as fontes tentadas quando a escolhida está em cooldown, em ordem de preferência
+var alternativeSources = map[string][]string{
+	"AllAnime":  {"AnimeFire", "Consumet", "Enime"},
+	"AnimeFire": {"AllAnime", "Consumet", "Enime"},
+	"Consumet":  {"AllAnime", "AnimeFire", "Enime"},
+	"Enime":     {"AnimeFire", "AllAnime", "Consumet"},
+}
+
+// alternativeSource retorna a alternativa fora de cooldown com a melhor pontuação (vazio se não houver)
+func (s *StreamService) alternativeSource(current string) string {
+	best, bestScore := "", -1.0
+	for _, source := range alternativeSources[current] {
+		if !s.sourceTracker.IsAvailable(source) {
+			continue
+		}
+		if score := s.sourceTracker.Score(source); score > bestScore {
+			best, bestScore = source, score
+		}
+	}
+	return best
+}
+
+// recordSuccess registra o sucesso de uma fonte. O Smart Router já registra as fontes que tentou.
+func (s *StreamService) recordSuccess(source string) {
+	if !strings.HasPrefix(source, "SmartRouter") {
+		s.sourceTracker.RecordSuccess(source)
+	}
+}
+
+// recordFailure registra a falha de uma fonte. O Smart Router já registra as fontes que tentou.
+func (s *StreamService) recordFailure(source, reason string) {
+	if !strings.HasPrefix(source, "SmartRouter") {
+		s.sourceTracker.RecordFailure(source, reason)
+	}
+}
+
 // ResetCircuits reseta todos os circuit breakers
 func (s *StreamService) ResetCircuits() {
 	s.streamRouter.ResetAllCircuits()
@@ -82,6 +82,7 @@ func (s *UserService) RemoveFromFavorites(animeURL string) bool {
 		if fav.URL == animeURL {
 			s.user.Favorites = append(s.user.Favorites[:i], s.user.Favorites[i+1:]...)
 			_ = store.SaveUser(s.user)
+			_ = store.DeleteLibraryEntry(animeURL)
 			return true
 		}
 	}
@@ -121,9 +122,15 @@ func (s *UserService) AddToWatchHistory(episode store.WatchedEpisode) {
 		episode.WatchedAt = time.Now().Format(time.RFC3339)
 	}
 
-	// Remove duplicata
+	// Remove duplicata, mantendo a posição salva
 	for i, e := range s.user.WatchHistory {
 		if e.EpisodeURL == episode.EpisodeURL {
+			if episode.Position == 0 {
+				episode.Position, episode.Duration = e.Position, e.Duration
+			}
+			if episode.Progress == 0 {
+				episode.Progress = e.Progress
+			}
 			s.user.WatchHistory = append(s.user.WatchHistory[:i], s.user.WatchHistory[i+1:]...)
 			break
 		}
@@ -132,12 +139,13 @@ func (s *UserService) AddToWatchHistory(episode store.WatchedEpisode) {
 	// Adiciona no início
 	s.user.WatchHistory = append([]store.WatchedEpisode{episode}, s.user.WatchHistory...)
 
-	// Limita a 50 entradas
-	if len(s.user.WatchHistory) > 50 {
-		s.user.WatchHistory = s.user.WatchHistory[:50]
+	// Limita as entradas em memória (o banco guarda o histórico completo)
+	if len(s.user.WatchHistory) > store.WatchHistoryLimit {
+		s.user.WatchHistory = s.user.WatchHistory[:store.WatchHistoryLimit]
 	}
 
-	_ = store.SaveUser(s.user)
+	_ = store.SaveWatchedEpisode(s.user, episode)
+	_ = store.MarkWatching(episode.AnimeURL)
 }
 
 // === CONFIGURAÇÕES ===
@@ -191,7 +199,7 @@ func (s *UserService) ImportUserData(jsonData string) error {
 	}
 
 	s.user = &userData
-	_ = store.SaveUser(s.user)
+	_ = store.ReplaceUser(s.user)
 	return nil
 }
 
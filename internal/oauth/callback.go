@@ -0,0 +1,123 @@
+// Package oauth reúne o que os logins OAuth2 da AniList e do MyAnimeList compartilham:
+// o state aleatório e o servidor de callback local que espera o código de autorização.
+package oauth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// CallbackTimeout é quanto WaitForCode espera o usuário autorizar no navegador
+const CallbackTimeout = 5 * time.Minute
+
+// RandomString gera uma string URL-safe com n caracteres
+func RandomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)[:n], nil
+}
+
+// Callback descreve o callback de um provedor
+type Callback struct {
+	Name         string // Prefixo dos logs ("AniList OAuth")
+	RedirectURI  string // Define a porta e o caminho do servidor
+	State        string // Gerado junto com a URL de autorização
+	SuccessTitle string // Título da página de sucesso ("AniList Conectada!")
+	RefusedText  string // Mensagem quando o usuário recusa ("A AniList recusou a autorização.")
+}
+
+// WaitForCode sobe um servidor em 127.0.0.1 na porta da redirect URI e espera o código.
+// Callbacks com outro state (outra página, login CSRF) recebem 403 e são ignorados, inclusive
+// os de erro: só o provedor, que conhece o state, pode encerrar o login.
+func WaitForCode(cb Callback) (string, error) {
+	if cb.State == "" {
+		return "", fmt.Errorf("login não iniciado (chame AuthURL antes)")
+	}
+	parsed, err := url.Parse(cb.RedirectURI)
+	if err != nil {
+		return "", fmt.Errorf("redirect URI inválida: %w", err)
+	}
+
+	codeChan := make(chan string, 1)
+	errChan := make(chan error, 1)
+	// Só a primeira resposta válida conta; as seguintes não podem travar o handler
+	send := func(code string, err error) {
+		if err != nil {
+			select {
+			case errChan <- err:
+			default:
+			}
+			return
+		}
+		select {
+		case codeChan <- code:
+		default:
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(parsed.Path, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		query := r.URL.Query()
+		if query.Get("state") != cb.State {
+			fmt.Printf("[%s] Callback com state inválido ignorado\n", cb.Name)
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, CallbackPage("#F5576C", "❌ Erro na Conexão", "Esta autorização não foi iniciada pelo GoAnime."))
+			return
+		}
+		code := query.Get("code")
+		if code == "" {
+			fmt.Fprint(w, CallbackPage("#F5576C", "❌ Erro na Conexão", cb.RefusedText))
+			description := query.Get("error_description")
+			if description == "" {
+				description = query.Get("message") // MyAnimeList
+			}
+			send("", fmt.Errorf("autorização recusada: %s %s", query.Get("error"), description))
+			return
+		}
+
+		fmt.Fprint(w, CallbackPage("#4ade80", cb.SuccessTitle, "Sua conta foi vinculada ao GoAnime com sucesso."))
+		send(code, nil)
+	})
+
+	// Só a própria máquina chega ao callback
+	server := &http.Server{
+		Addr:              "127.0.0.1:" + parsed.Port(),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	defer server.Close()
+
+	fmt.Printf("[%s] Servidor de callback iniciado na porta %s\n", cb.Name, parsed.Port())
+	go func() {
+		if err := server.ListenAndServe(); err != http.ErrServerClosed {
+			send("", err)
+		}
+	}()
+
+	select {
+	case code := <-codeChan:
+		return code, nil
+	case err := <-errChan:
+		return "", err
+	case <-time.After(CallbackTimeout):
+		return "", fmt.Errorf("timeout aguardando callback OAuth2")
+	}
+}
+
+// CallbackPage é a página mostrada no navegador depois do callback
+func CallbackPage(color, title, message string) string {
+	return fmt.Sprintf(`<!DOCTYPE html><html><head><title>GoAnime</title><style>
+	body{font-family:Arial,sans-serif;background:#1a1a2e;color:#fff;display:flex;justify-content:center;align-items:center;height:100vh;margin:0}
+	.container{text-align:center;padding:40px;background:#16213e;border-radius:12px;box-shadow:0 4px 20px rgba(0,0,0,0.3)}
+	h1{color:%s}
+</style></head><body><div class="container"><h1>%s</h1><p>%s</p><p>Você pode fechar esta janela e voltar ao aplicativo.</p></div></body></html>`,
+		color, title, message)
+}
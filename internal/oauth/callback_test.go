@@ -0,0 +1,70 @@
+package oauth
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWaitForCode_IgnoresForeignState(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	callback := fmt.Sprintf("http://127.0.0.1:%d/callback", port)
+
+	type result struct {
+		code string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		code, err := WaitForCode(Callback{Name: "Test OAuth", RedirectURI: callback, State: "s3cr3t"})
+		done <- result{code, err}
+	}()
+
+	get := func(query string) int {
+		for i := 0; i < 50; i++ {
+			resp, err := http.Get(callback + "?" + query)
+			if err == nil {
+				resp.Body.Close()
+				return resp.StatusCode
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("callback server did not start")
+		return 0
+	}
+
+	// Nada disso vem do login em andamento: 403 e o login continua esperando
+	for _, query := range []string{"code=evil&state=x", "error=access_denied&state=x", "error=access_denied", "code=evil"} {
+		if status := get(query); status != http.StatusForbidden {
+			t.Errorf("GET ?%s = %d; want 403", query, status)
+		}
+	}
+	select {
+	case r := <-done:
+		t.Fatalf("login ended by a foreign callback: %+v", r)
+	default:
+	}
+
+	if status := get("code=good&state=s3cr3t"); status != http.StatusOK {
+		t.Errorf("valid callback = %d; want 200", status)
+	}
+	select {
+	case r := <-done:
+		if r.err != nil || r.code != "good" {
+			t.Errorf("WaitForCode() = %q, %v; want good", r.code, r.err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("WaitForCode() did not return after the valid callback")
+	}
+
+	if _, err := WaitForCode(Callback{RedirectURI: callback}); err == nil {
+		t.Error("WaitForCode() without a state should fail before starting the server")
+	}
+}
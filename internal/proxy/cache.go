@@ -0,0 +1,398 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"GoAnimeGUI/pkg/segmentcache"
+)
+
+const (
+	rangeChunkSize  = 2 << 20 // MP4 é cacheado em pedaços alinhados de 2 MB
+	prefetchWorkers = 2
+	prefetchTimeout = 2 * time.Minute
+)
+
+// progressiveExts são os formatos servidos em pedaços pelo cache (os demais passam direto)
+var progressiveExts = map[string]bool{".mp4": true, ".m4v": true, ".webm": true, ".mkv": true}
+
+// errNoRanges indica que o servidor ignorou o Range e o arquivo não pode ser cacheado em pedaços
+var errNoRanges = errors.New("servidor não aceita Range")
+
+// prefetcher baixa recursos em segundo plano, um por chave, e deixa quem chega depois esperar
+type prefetcher struct {
+	once     sync.Once
+	inflight map[string]chan struct{}
+	slots    chan struct{}
+	count    int64
+	mutex    sync.Mutex
+}
+
+func (p *prefetcher) init() {
+	p.once.Do(func() {
+		p.inflight = make(map[string]chan struct{})
+		p.slots = make(chan struct{}, prefetchWorkers)
+	})
+}
+
+// start roda fn em segundo plano se a chave ainda não estiver sendo baixada
+func (p *prefetcher) start(key string, fn func(ctx context.Context) error) {
+	p.init()
+	p.mutex.Lock()
+	if _, busy := p.inflight[key]; busy {
+		p.mutex.Unlock()
+		return
+	}
+	done := make(chan struct{})
+	p.inflight[key] = done
+	p.mutex.Unlock()
+
+	go func() {
+		p.slots <- struct{}{}
+		ctx, cancel := context.WithTimeout(context.Background(), prefetchTimeout)
+		err := fn(ctx)
+		cancel()
+		<-p.slots
+
+		p.mutex.Lock()
+		delete(p.inflight, key)
+		if err == nil {
+			p.count++
+		}
+		p.mutex.Unlock()
+		close(done)
+	}()
+}
+
+// wait espera o read-ahead da chave terminar, se houver um em andamento
+func (p *prefetcher) wait(ctx context.Context, key string) {
+	p.init()
+	p.mutex.Lock()
+	done, busy := p.inflight[key]
+	p.mutex.Unlock()
+	if !busy {
+		return
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// Prefetched retorna quantos recursos o read-ahead já colocou no cache
+func (h *StreamHandler) Prefetched() int64 {
+	h.prefetch.mutex.Lock()
+	defer h.prefetch.mutex.Unlock()
+	return h.prefetch.count
+}
+
+func (h *StreamHandler) readAhead() int {
+	if h.ReadAhead == nil {
+		return 0
+	}
+	return h.ReadAhead()
+}
+
+// fetch busca um recurso do stream com os headers padrão e os da sessão
+func (h *StreamHandler) fetch(ctx context.Context, session *Session, target, byteRange string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+	if h.Prepare != nil {
+		h.Prepare(req, session)
+	}
+	session.Apply(req)
+	return h.clientFor(session, target).Do(req)
+}
+
+// serveCachedSegment responde um segmento a partir do cache e agenda o read-ahead.
+// Retorna false se não estiver no cache (quem chama busca na origem).
+func (h *StreamHandler) serveCachedSegment(w http.ResponseWriter, r *http.Request, session *Session, target string) bool {
+	key := segmentcache.Key(target, r.Header.Get("Range"))
+	h.prefetch.wait(r.Context(), key)
+	if !serveFromCache(w, h.Cache, key) {
+		return false
+	}
+	h.readAheadSegments(session, target)
+	return true
+}
+
+// readAheadSegments baixa os próximos segmentos da playlist para o cache
+func (h *StreamHandler) readAheadSegments(session *Session, target string) {
+	for _, next := range h.Sessions.NextSegments(session.Token, target, h.readAhead()) {
+		key := segmentcache.Key(next, "")
+		if h.Cache.Contains(key) {
+			continue
+		}
+		h.prefetch.start(key, func(ctx context.Context) error {
+			resp, err := h.fetch(ctx, session, next, "")
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			writer := newCacheWriter(h.Cache, key, resp)
+			if writer == nil {
+				return fmt.Errorf("resposta não cacheável: %s", resp.Status)
+			}
+			n, err := io.Copy(writer, resp.Body)
+			return commitCacheWriter(writer, resp, n, err)
+		})
+	}
+}
+
+// serveFromCache escreve a resposta guardada para a chave. Retorna false se não houver.
+func serveFromCache(w http.ResponseWriter, cache *segmentcache.Cache, key string) bool {
+	if !cache.Enabled() {
+		return false
+	}
+	data, meta, ok := cache.Get(key)
+	if !ok {
+		return false
+	}
+	defer data.Close()
+
+	if meta.ContentType != "" {
+		w.Header().Set("Content-Type", meta.ContentType)
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(meta.Size, 10))
+	w.Header().Set("X-Cache", "HIT")
+	status := http.StatusOK
+	if meta.ContentRange != "" {
+		w.Header().Set("Content-Range", meta.ContentRange)
+		w.Header().Set("Accept-Ranges", "bytes")
+		status = http.StatusPartialContent
+	}
+	w.WriteHeader(status)
+	io.Copy(w, data)
+	return true
+}
+
+// newCacheWriter prepara a gravação de uma resposta no cache. Retorna nil se ela não puder
+// ser cacheada (erro, corpo comprimido, playlist ou cache desativado).
+func newCacheWriter(cache *segmentcache.Cache, key string, resp *http.Response) *segmentcache.Writer {
+	if !cache.Enabled() || (resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent) {
+		return nil
+	}
+	if resp.Header.Get("Content-Encoding") != "" || strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "mpegurl") {
+		return nil
+	}
+	meta := segmentcache.Meta{ContentType: resp.Header.Get("Content-Type")}
+	if resp.StatusCode == http.StatusPartialContent {
+		meta.ContentRange = resp.Header.Get("Content-Range")
+	}
+	writer, err := cache.Create(key, meta)
+	if err != nil {
+		fmt.Printf("[VideoCache] %v\n", err)
+		return nil
+	}
+	return writer
+}
+
+// commitCacheWriter só guarda a entrada se o corpo chegou inteiro
+func commitCacheWriter(writer *segmentcache.Writer, resp *http.Response, n int64, copyErr error) error {
+	if copyErr != nil || (resp.ContentLength >= 0 && n != resp.ContentLength) {
+		writer.Abort()
+		if copyErr == nil {
+			copyErr = io.ErrUnexpectedEOF
+		}
+		return copyErr
+	}
+	return writer.Commit()
+}
+
+// isProgressive diz se a URL é de um arquivo de vídeo inteiro (MP4, WebM...)
+func isProgressive(target string) bool {
+	parsed, err := url.Parse(target)
+	return err == nil && progressiveExts[strings.ToLower(path.Ext(parsed.Path))]
+}
+
+// chunk é um pedaço alinhado do arquivo
+type chunk struct {
+	data        []byte
+	contentType string
+	total       int64 // Tamanho do arquivo inteiro
+}
+
+func chunkKey(target string, index int64) string {
+	start := index * rangeChunkSize
+	return segmentcache.Key(target, fmt.Sprintf("bytes=%d-%d", start, start+rangeChunkSize-1))
+}
+
+// loadChunk lê o pedaço do cache ou baixa da origem
+func (h *StreamHandler) loadChunk(ctx context.Context, session *Session, index int64) (*chunk, error) {
+	key := chunkKey(session.URL, index)
+	h.prefetch.wait(ctx, key)
+	if data, meta, ok := h.Cache.Get(key); ok {
+		defer data.Close()
+		raw, err := io.ReadAll(data)
+		total, ok := contentRangeTotal(meta.ContentRange)
+		if err == nil && ok {
+			return &chunk{data: raw, contentType: meta.ContentType, total: total}, nil
+		}
+	}
+	return h.fetchChunk(ctx, session, index)
+}
+
+// fetchChunk baixa um pedaço com Range e o guarda no cache
+func (h *StreamHandler) fetchChunk(ctx context.Context, session *Session, index int64) (*chunk, error) {
+	start := index * rangeChunkSize
+	byteRange := fmt.Sprintf("bytes=%d-%d", start, start+rangeChunkSize-1)
+	resp, err := h.fetch(ctx, session, session.URL, byteRange)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil, errNoRanges
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("servidor retornou %s", resp.Status)
+	}
+	total, ok := contentRangeTotal(resp.Header.Get("Content-Range"))
+	if !ok || resp.Header.Get("Content-Encoding") != "" {
+		return nil, errNoRanges
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, rangeChunkSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if want := min(rangeChunkSize, total-start); int64(len(raw)) != want {
+		return nil, fmt.Errorf("pedaço incompleto: %d de %d bytes", len(raw), want)
+	}
+
+	c := &chunk{data: raw, contentType: resp.Header.Get("Content-Type"), total: total}
+	meta := segmentcache.Meta{ContentType: c.contentType, ContentRange: resp.Header.Get("Content-Range")}
+	if err := h.Cache.Put(chunkKey(session.URL, index), meta, raw); err != nil {
+		fmt.Printf("[VideoCache] Pedaço não cacheado: %v\n", err)
+	}
+	return c, nil
+}
+
+// serveChunked responde o arquivo principal da sessão em pedaços cacheados, baixando à frente
+// enquanto o player lê. Retorna false se o Range não for suportado aqui ou se a origem não
+// aceitar Range; nesse caso quem chama repassa a requisição direto.
+func (h *StreamHandler) serveChunked(w http.ResponseWriter, r *http.Request, session *Session) bool {
+	start, end, ok := parseByteRange(r.Header.Get("Range"))
+	if !ok {
+		return false
+	}
+
+	index := start / rangeChunkSize
+	current, err := h.loadChunk(r.Context(), session, index)
+	if errors.Is(err, errNoRanges) {
+		return false
+	}
+	if err != nil {
+		fmt.Printf("[VideoCache] Erro ao buscar %s: %v\n", session.URL, err)
+		http.Error(w, "Erro ao acessar vídeo", http.StatusBadGateway)
+		return true
+	}
+
+	total := current.total
+	if start >= total {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", total))
+		http.Error(w, "Range inválido", http.StatusRequestedRangeNotSatisfiable)
+		return true
+	}
+	if end < 0 || end >= total {
+		end = total - 1
+	}
+
+	w.Header().Set("Content-Type", current.contentType)
+	if parsed, err := url.Parse(session.URL); err == nil && strings.EqualFold(path.Ext(parsed.Path), ".mp4") {
+		w.Header().Set("Content-Type", "video/mp4")
+	}
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Content-Length", strconv.FormatInt(end-start+1, 10))
+	if r.Header.Get("Range") != "" {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, total))
+		w.WriteHeader(http.StatusPartialContent)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	last := end / rangeChunkSize
+	for ; index <= last; index++ {
+		if current == nil {
+			if current, err = h.loadChunk(r.Context(), session, index); err != nil {
+				fmt.Printf("[VideoCache] Erro no pedaço %d de %s: %v\n", index, session.URL, err)
+				return true
+			}
+		}
+		h.readAheadChunks(session, index, total)
+
+		chunkStart := index * rangeChunkSize
+		from := max(start-chunkStart, 0)
+		to := min(end-chunkStart+1, int64(len(current.data)))
+		if _, err := w.Write(current.data[from:to]); err != nil {
+			return true // Player fechou a conexão (seek)
+		}
+		current = nil
+	}
+	return true
+}
+
+// readAheadChunks baixa os próximos pedaços do arquivo para o cache
+func (h *StreamHandler) readAheadChunks(session *Session, index, total int64) {
+	lastChunk := (total - 1) / rangeChunkSize
+	for next := index + 1; next <= min(index+int64(h.readAhead()), lastChunk); next++ {
+		if h.Cache.Contains(chunkKey(session.URL, next)) {
+			continue
+		}
+		h.prefetch.start(chunkKey(session.URL, next), func(ctx context.Context) error {
+			_, err := h.fetchChunk(ctx, session, next)
+			return err
+		})
+	}
+}
+
+// parseByteRange lê "bytes=início-[fim]" (sem Range = arquivo inteiro; fim -1 = até o final).
+// Ranges múltiplos ou a partir do fim ("bytes=-500") não são tratados pelo cache.
+func parseByteRange(header string) (start, end int64, ok bool) {
+	if header == "" {
+		return 0, -1, true
+	}
+	spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+	first, last, _ := strings.Cut(spec, "-")
+	start, err := strconv.ParseInt(strings.TrimSpace(first), 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false
+	}
+	if strings.TrimSpace(last) == "" {
+		return start, -1, true
+	}
+	end, err = strconv.ParseInt(strings.TrimSpace(last), 10, 64)
+	if err != nil || end < start {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// contentRangeTotal lê o tamanho total de "bytes início-fim/total"
+func contentRangeTotal(header string) (int64, bool) {
+	_, total, found := strings.Cut(header, "/")
+	if !found {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(total), 10, 64)
+	return n, err == nil && n > 0
+}
@@ -0,0 +1,190 @@
+package proxy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxProxyBytes limita o corpo repassado por /proxy
+	DefaultMaxProxyBytes = 512 << 20
+	// DefaultMaxRedirects limita os redirects seguidos por /proxy
+	DefaultMaxRedirects = 5
+)
+
+// errBlockedAddress indica um destino em rede interna
+var errBlockedAddress = errors.New("endereço interno não permitido")
+
+// blockedPrefixes são as faixas que não são internet pública além das que netip já identifica
+// (loopback, privadas, link-local, multicast e não especificadas)
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "Esta rede"
+	netip.MustParsePrefix("100.64.0.0/10"), // CGNAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // Atribuições do IETF
+	netip.MustParsePrefix("198.18.0.0/15"), // Testes de benchmark
+	netip.MustParsePrefix("240.0.0.0/4"),   // Reservada e broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64 (embute IPv4 que pode ser interno)
+}
+
+// Guard protege o /proxy contra SSRF: só aceita URLs assinadas pelo próprio app (a chave muda
+// a cada execução), conecta apenas em endereços públicos, conferidos depois da resolução de DNS
+// e na hora da conexão (o que cobre DNS rebinding e redirects), e limita redirects e tamanho.
+// Sem isso, qualquer página aberta no navegador poderia usar o proxy para sondar a rede local.
+type Guard struct {
+	MaxBytes     int64 // Tamanho máximo do corpo repassado
+	MaxRedirects int
+
+	key []byte
+}
+
+// NewGuard cria um Guard com uma chave de assinatura aleatória
+func NewGuard() *Guard {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return &Guard{MaxBytes: DefaultMaxProxyBytes, MaxRedirects: DefaultMaxRedirects, key: key}
+}
+
+// Sign retorna a assinatura de uma URL de destino
+func (g *Guard) Sign(target string) string {
+	mac := hmac.New(sha256.New, g.key)
+	mac.Write([]byte(target))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify diz se sig é a assinatura de target
+func (g *Guard) Verify(target, sig string) bool {
+	return hmac.Equal([]byte(sig), []byte(g.Sign(target)))
+}
+
+// URL retorna o endereço assinado de target num endpoint do proxy ("http://127.0.0.1:porta/proxy")
+func (g *Guard) URL(endpoint, target string) string {
+	return endpoint + "?url=" + url.QueryEscape(target) + "&sig=" + g.Sign(target)
+}
+
+// Target extrai e valida o destino de uma URL assinada (?url=...&sig=...)
+func (g *Guard) Target(r *http.Request) (string, error) {
+	target, sig := r.URL.Query().Get("url"), r.URL.Query().Get("sig")
+	if target == "" {
+		return "", fmt.Errorf("URL não especificada")
+	}
+	if !g.Verify(target, sig) {
+		return target, fmt.Errorf("URL sem assinatura válida")
+	}
+	if err := checkURL(target); err != nil {
+		return target, err
+	}
+	return target, nil
+}
+
+// Reject responde 403 e registra a tentativa
+func (g *Guard) Reject(w http.ResponseWriter, r *http.Request, target string, err error) {
+	fmt.Printf("[ProxyGuard] Recusado %q de %s (Origin: %q): %v\n", target, r.RemoteAddr, r.Header.Get("Origin"), err)
+	http.Error(w, "Destino não permitido", http.StatusForbidden)
+}
+
+// Transport retorna uma cópia de base que só conecta em endereços públicos e ignora proxies do
+// ambiente (que fariam a conexão por conta própria, sem a checagem)
+func (g *Guard) Transport(base *http.Transport) *http.Transport {
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	t := base.Clone()
+	t.Proxy = nil
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			if blockedAddress(ip) {
+				return nil, fmt.Errorf("%s resolve para %s: %w", host, ip, errBlockedAddress)
+			}
+		}
+		var lastErr error
+		for _, ip := range ips {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		return nil, lastErr
+	}
+	return t
+}
+
+// CheckRedirect limita a quantidade de redirects e o esquema de cada um (para http.Client)
+func (g *Guard) CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= g.MaxRedirects {
+		return fmt.Errorf("mais de %d redirects", g.MaxRedirects)
+	}
+	return checkURL(req.URL.String())
+}
+
+// Body retorna o corpo da resposta limitado a MaxBytes; respostas que já declaram um tamanho
+// maior são recusadas antes de qualquer byte ser repassado
+func (g *Guard) Body(resp *http.Response) (io.Reader, error) {
+	if resp.ContentLength > g.MaxBytes {
+		return nil, fmt.Errorf("resposta de %d bytes passa do limite de %d", resp.ContentLength, g.MaxBytes)
+	}
+	return io.LimitReader(resp.Body, g.MaxBytes), nil
+}
+
+// IsBlocked diz se o erro de uma requisição veio de um destino recusado pelo Guard
+func IsBlocked(err error) bool {
+	return errors.Is(err, errBlockedAddress)
+}
+
+// checkURL aceita só http(s) com host; endereços IP literais já são conferidos aqui
+func checkURL(target string) error {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("URL inválida: %w", err)
+	}
+	if scheme := strings.ToLower(parsed.Scheme); scheme != "http" && scheme != "https" {
+		return fmt.Errorf("esquema %q não permitido", parsed.Scheme)
+	}
+	host := parsed.Hostname()
+	if host == "" {
+		return fmt.Errorf("URL sem host")
+	}
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return fmt.Errorf("%s: %w", host, errBlockedAddress)
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && blockedAddress(ip) {
+		return fmt.Errorf("%s: %w", host, errBlockedAddress)
+	}
+	return nil
+}
+
+// blockedAddress diz se o IP não é da internet pública
+func blockedAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
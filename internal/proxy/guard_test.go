@@ -0,0 +1,73 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestGuard_AcceptsOnlySignedPublicURLs(t *testing.T) {
+	guard := NewGuard()
+	target := "https://cdn.example.com/seg/1.ts?token=abc"
+
+	request := func(rawURL string) *http.Request {
+		return httptest.NewRequest("GET", rawURL, nil)
+	}
+	signed := guard.URL("http://127.0.0.1:1/proxy", target)
+	if got, err := guard.Target(request(signed)); err != nil || got != target {
+		t.Errorf("Target(signed) = %q, %v; want the target accepted", got, err)
+	}
+
+	rejected := map[string]string{
+		"unsigned":      "/proxy?url=" + url.QueryEscape(target),
+		"tampered":      strings.Replace(signed, "token%3Dabc", "token%3Dxyz", 1),
+		"other key":     NewGuard().URL("/proxy", target),
+		"file scheme":   guard.URL("/proxy", "file:///etc/passwd"),
+		"loopback":      guard.URL("/proxy", "http://127.0.0.1:8080/admin"),
+		"private":       guard.URL("/proxy", "http://192.168.0.1/"),
+		"link-local":    guard.URL("/proxy", "http://169.254.169.254/latest/meta-data/"),
+		"mapped ipv6":   guard.URL("/proxy", "http://[::ffff:10.0.0.1]/"),
+		"localhost":     guard.URL("/proxy", "http://localhost:3000/"),
+		"sub.localhost": guard.URL("/proxy", "http://app.localhost/"),
+	}
+	for name, rawURL := range rejected {
+		if _, err := guard.Target(request(rawURL)); err == nil {
+			t.Errorf("Target(%s) accepted %s", name, rawURL)
+		}
+	}
+}
+
+func TestGuard_TransportAndLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "segredo da rede local")
+	}))
+	defer server.Close()
+
+	// O host pode ser um nome que resolve para a rede local: a checagem é feita na conexão
+	guard := NewGuard()
+	client := &http.Client{Transport: guard.Transport(nil), CheckRedirect: guard.CheckRedirect}
+	target := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	if _, err := client.Get(target); !IsBlocked(err) {
+		t.Errorf("Get(%s) error = %v; want a blocked address", target, err)
+	}
+
+	via := make([]*http.Request, DefaultMaxRedirects)
+	if err := guard.CheckRedirect(httptest.NewRequest("GET", "https://cdn.example.com/", nil), via); err == nil {
+		t.Error("CheckRedirect() should stop after DefaultMaxRedirects hops")
+	}
+	if err := guard.CheckRedirect(httptest.NewRequest("GET", "http://10.0.0.1/", nil), via[:1]); !IsBlocked(err) {
+		t.Errorf("CheckRedirect() to a private address error = %v", err)
+	}
+
+	guard.MaxBytes = 10
+	if _, err := guard.Body(&http.Response{ContentLength: 11, Body: http.NoBody}); err == nil {
+		t.Error("Body() should reject a declared size above MaxBytes")
+	}
+	body, _ := guard.Body(&http.Response{ContentLength: -1, Body: io.NopCloser(strings.NewReader("0123456789abcdef"))})
+	if data, _ := io.ReadAll(body); string(data) != "0123456789" {
+		t.Errorf("Body() read %q; want it cut at MaxBytes", data)
+	}
+}
@@ -9,32 +9,63 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
-	"sync"
 	"time"
+
+	"GoAnimeGUI/pkg/hostrules"
+	"GoAnimeGUI/pkg/segmentcache"
 )
 
 // Server representa o servidor de proxy de vídeo
 type Server struct {
-	server       *http.Server
-	port         int
-	currentVideo string
-	mutex        sync.RWMutex
-	client       *http.Client
+	server   *http.Server
+	port     int
+	client   *http.Client
+	generic  *http.Client // Cliente de /proxy, restrito pelo guard
+	guard    *Guard
+	sessions *Sessions
+	streams  *StreamHandler
+	cancel   context.CancelFunc
 }
 
 // New cria um novo servidor de proxy
 func New() *Server {
-	return &Server{
+	transport := &http.Transport{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	guard := NewGuard()
+	s := &Server{
 		port: 0,
 		client: &http.Client{
-			Timeout: 30 * time.Second,
-			Transport: &http.Transport{
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 10,
-				IdleConnTimeout:     90 * time.Second,
-			},
+			Timeout:   30 * time.Second,
+			Transport: hostrules.Default().Transport(transport),
+		},
+		generic: &http.Client{
+			Timeout:       30 * time.Second,
+			Transport:     hostrules.Default().Transport(guard.Transport(transport)),
+			CheckRedirect: guard.CheckRedirect,
+		},
+		guard:    guard,
+		sessions: NewSessions(DefaultSessionTTL),
+	}
+	s.streams = &StreamHandler{
+		Sessions: s.sessions,
+		Client:   s.client,
+		ChildClient: &http.Client{
+			Timeout:       30 * time.Second,
+			Transport:     hostrules.Default().Transport(guard.Transport(transport)),
+			CheckRedirect: guard.CheckRedirect,
+		},
+		Guard:   guard,
+		BaseURL: func() string { return fmt.Sprintf("http://127.0.0.1:%d", s.port) },
+		Prepare: func(req *http.Request, session *Session) {
+			req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+			req.Header.Set("Referer", extractReferer(session.URL))
+			hostrules.Default().ApplyStream(req, session.URL)
 		},
 	}
+	return s
 }
 
 // Start inicia o servidor de proxy em uma porta disponível
@@ -48,8 +79,9 @@ func (s *Server) Start() error {
 	s.port = listener.Addr().(*net.TCPAddr).Port
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/video", s.handleVideo)
+	mux.Handle("/video/", s.streams)
 	mux.HandleFunc("/proxy", s.handleGenericProxy)
+	mux.Handle("/subtitle", &SubtitleHandler{Guard: s.guard, Client: s.generic})
 	mux.HandleFunc("/health", s.handleHealth)
 
 	s.server = &http.Server{
@@ -58,6 +90,10 @@ func (s *Server) Start() error {
 		WriteTimeout: 60 * time.Second,
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	go s.sessions.Run(ctx)
+
 	go func() {
 		fmt.Printf("[VideoProxy] Servidor iniciado na porta %d\n", s.port)
 		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
@@ -73,6 +109,7 @@ func (s *Server) Stop(ctx context.Context) error {
 	if s.server == nil {
 		return nil
 	}
+	s.cancel()
 	return s.server.Shutdown(ctx)
 }
 
@@ -81,44 +118,51 @@ func (s *Server) Port() int {
 	return s.port
 }
 
-// SetCurrentVideo define a URL do vídeo atual
-func (s *Server) SetCurrentVideo(videoURL string) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	s.currentVideo = videoURL
+// SetCache liga o cache em disco de segmentos e pedaços de MP4, baixando readAhead recursos à frente
+func (s *Server) SetCache(cache *segmentcache.Cache, readAhead int) {
+	s.streams.Cache = cache
+	s.streams.ReadAhead = func() int { return readAhead }
 }
 
-// GetCurrentVideo retorna a URL do vídeo atual
-func (s *Server) GetCurrentVideo() string {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-	return s.currentVideo
+// RegisterStream registra um stream com os headers exigidos pela fonte e retorna a URL
+// local /video/{token}. Cada stream tem o seu token, então vários podem tocar ao mesmo tempo.
+func (s *Server) RegisterStream(videoURL string, headers map[string]string) (string, error) {
+	if s.port == 0 {
+		return "", fmt.Errorf("proxy não iniciado")
+	}
+	session, err := s.sessions.Register(videoURL, headers)
+	if err != nil {
+		return "", fmt.Errorf("erro ao registrar stream: %w", err)
+	}
+	return s.streams.URL(session.Token), nil
 }
 
-// GetProxyURL retorna a URL do proxy para um vídeo
+// GetProxyURL retorna a URL assinada do proxy para um vídeo
 func (s *Server) GetProxyURL(videoURL string) string {
 	if s.port == 0 {
 		return videoURL
 	}
-	return fmt.Sprintf("http://127.0.0.1:%d/proxy?url=%s", s.port, url.QueryEscape(videoURL))
+	return s.guard.URL(fmt.Sprintf("http://127.0.0.1:%d/proxy", s.port), videoURL)
 }
 
-// handleVideo serve o vídeo atual via proxy
-func (s *Server) handleVideo(w http.ResponseWriter, r *http.Request) {
-	videoURL := s.GetCurrentVideo()
-	if videoURL == "" {
-		http.Error(w, "Nenhum vídeo configurado", http.StatusNotFound)
-		return
+// SubtitleURL retorna a URL assinada de /subtitle, que entrega a legenda convertida para WebVTT.
+// format pode ser vazio (detecta pela extensão ou pelo conteúdo).
+func (s *Server) SubtitleURL(subtitleURL, format string) string {
+	if s.port == 0 {
+		return subtitleURL
 	}
-
-	s.proxyRequest(w, r, videoURL)
+	endpoint := s.guard.URL(fmt.Sprintf("http://127.0.0.1:%d/subtitle", s.port), subtitleURL)
+	if format != "" {
+		endpoint += "&format=" + url.QueryEscape(format)
+	}
+	return endpoint
 }
 
-// handleGenericProxy faz proxy de qualquer URL passada como parâmetro
+// handleGenericProxy faz proxy das URLs assinadas por GetProxyURL
 func (s *Server) handleGenericProxy(w http.ResponseWriter, r *http.Request) {
-	targetURL := r.URL.Query().Get("url")
-	if targetURL == "" {
-		http.Error(w, "URL não especificada", http.StatusBadRequest)
+	targetURL, err := s.guard.Target(r)
+	if err != nil {
+		s.guard.Reject(w, r, targetURL, err)
 		return
 	}
 
@@ -150,31 +194,63 @@ func (s *Server) proxyRequest(w http.ResponseWriter, r *http.Request, targetURL
 	// Headers para parecer um navegador
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
 	req.Header.Set("Referer", extractReferer(targetURL))
+	hostrules.Default().Apply(req)
+
+	// Adiciona headers CORS
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Range")
+
+	// Segmentos já vistos saem do disco
+	cacheKey := segmentcache.Key(targetURL, r.Header.Get("Range"))
+	cacheable := r.Method == http.MethodGet
+	if cacheable && serveFromCache(w, s.streams.Cache, cacheKey) {
+		return
+	}
 
 	// Faz a requisição
-	resp, err := s.client.Do(req)
+	resp, err := s.generic.Do(req)
 	if err != nil {
+		if IsBlocked(err) {
+			s.guard.Reject(w, r, targetURL, err)
+			return
+		}
 		http.Error(w, fmt.Sprintf("Erro ao buscar vídeo: %v", err), http.StatusBadGateway)
 		return
 	}
 	defer resp.Body.Close()
 
-	// Copia headers da resposta
+	body, err := s.guard.Body(resp)
+	if err != nil {
+		fmt.Printf("[ProxyGuard] %s: %v\n", targetURL, err)
+		http.Error(w, "Resposta grande demais", http.StatusBadGateway)
+		return
+	}
+
+	// Copia headers da resposta (os de CORS já foram definidos acima)
 	for key, values := range resp.Header {
+		if strings.HasPrefix(key, "Access-Control-") {
+			continue
+		}
 		for _, value := range values {
 			w.Header().Add(key, value)
 		}
 	}
 
-	// Adiciona headers CORS
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Range")
+	var writer *segmentcache.Writer
+	if cacheable {
+		writer = newCacheWriter(s.streams.Cache, cacheKey, resp)
+	}
 
 	w.WriteHeader(resp.StatusCode)
 
-	// Stream do conteúdo
-	_, _ = io.Copy(w, resp.Body)
+	// Stream do conteúdo (e para o cache, se couber)
+	if writer == nil {
+		_, _ = io.Copy(w, body)
+		return
+	}
+	n, err := io.Copy(w, io.TeeReader(body, writer))
+	commitCacheWriter(writer, resp, n, err)
 }
 
 // extractReferer extrai o referer base da URL
@@ -0,0 +1,185 @@
+package proxy
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultSessionTTL é quanto um stream registrado fica válido sem nenhuma requisição
+const DefaultSessionTTL = time.Hour
+
+// Session é um stream registrado no proxy, acessado pelo token em /video/{token}
+type Session struct {
+	Token     string
+	URL       string
+	Headers   map[string]string // Headers exigidos pela fonte (Referer, Cookie...), aplicados a todo recurso do stream
+	ExpiresAt time.Time
+
+	segments map[string]segmentPos // Segmento -> posição na playlist de mídia (para read-ahead)
+}
+
+// segmentPos é a posição de um segmento na playlist de mídia em que apareceu
+type segmentPos struct {
+	list  []string
+	index int
+}
+
+// maxTrackedSegments limita os segmentos lembrados por sessão (playlists ao vivo não param de crescer)
+const maxTrackedSegments = 20000
+
+// Apply aplica os headers da sessão à requisição, por cima dos padrões
+func (s *Session) Apply(req *http.Request) {
+	for key, value := range s.Headers {
+		req.Header.Set(key, value)
+	}
+}
+
+// Sessions guarda os streams registrados. Cada acesso renova a validade;
+// sessões sem uso por mais que o TTL são apagadas.
+type Sessions struct {
+	sessions map[string]*Session
+	ttl      time.Duration
+	mutex    sync.Mutex
+	now      func() time.Time
+}
+
+// NewSessions cria o registro de streams (ttl <= 0 usa DefaultSessionTTL)
+func NewSessions(ttl time.Duration) *Sessions {
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	return &Sessions{
+		sessions: make(map[string]*Session),
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+// Register registra um stream e retorna a sessão com o token. Registrar de novo a mesma URL
+// com os mesmos headers reaproveita a sessão ativa.
+func (s *Sessions) Register(targetURL string, headers map[string]string) (*Session, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	for _, session := range s.sessions {
+		if session.URL == targetURL && maps.Equal(session.Headers, headers) && now.Before(session.ExpiresAt) {
+			session.ExpiresAt = now.Add(s.ttl)
+			return session.copy(), nil
+		}
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	session := &Session{
+		Token:     hex.EncodeToString(raw),
+		URL:       targetURL,
+		Headers:   maps.Clone(headers),
+		ExpiresAt: now.Add(s.ttl),
+	}
+	s.sessions[session.Token] = session
+	return session.copy(), nil
+}
+
+// Get retorna a sessão do token e renova a validade dela
+func (s *Sessions) Get(token string) (*Session, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session, ok := s.sessions[token]
+	if !ok {
+		return nil, false
+	}
+	now := s.now()
+	if !now.Before(session.ExpiresAt) {
+		delete(s.sessions, token)
+		return nil, false
+	}
+	session.ExpiresAt = now.Add(s.ttl)
+	return session.copy(), true
+}
+
+// SetSegments guarda a ordem dos segmentos de uma playlist de mídia da sessão
+func (s *Sessions) SetSegments(token string, uris []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session, ok := s.sessions[token]
+	if !ok || len(uris) == 0 {
+		return
+	}
+	if session.segments == nil || len(session.segments)+len(uris) > maxTrackedSegments {
+		session.segments = make(map[string]segmentPos, len(uris))
+	}
+	for i, uri := range uris {
+		session.segments[uri] = segmentPos{list: uris, index: i}
+	}
+}
+
+// NextSegments retorna até n segmentos depois de uri na playlist em que ele apareceu
+func (s *Sessions) NextSegments(token, uri string, n int) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session, ok := s.sessions[token]
+	if !ok {
+		return nil
+	}
+	pos, ok := session.segments[uri]
+	if !ok {
+		return nil
+	}
+	next := pos.list[pos.index+1:]
+	return next[:min(n, len(next))]
+}
+
+// Remove encerra uma sessão
+func (s *Sessions) Remove(token string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.sessions, token)
+}
+
+// Cleanup apaga as sessões expiradas e retorna quantas foram apagadas
+func (s *Sessions) Cleanup() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	removed := 0
+	for token, session := range s.sessions {
+		if !now.Before(session.ExpiresAt) {
+			delete(s.sessions, token)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Run apaga as sessões expiradas periodicamente até ctx ser cancelado
+func (s *Sessions) Run(ctx context.Context) {
+	ticker := time.NewTicker(min(s.ttl/4, 5*time.Minute))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Cleanup()
+		}
+	}
+}
+
+func (s *Session) copy() *Session {
+	c := *s
+	c.Headers = maps.Clone(s.Headers)
+	c.segments = nil
+	return &c
+}
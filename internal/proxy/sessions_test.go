@@ -0,0 +1,49 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessions_RegisterReuseAndExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sessions := NewSessions(time.Hour)
+	sessions.now = func() time.Time { return now }
+
+	headers := map[string]string{"Referer": "https://example.com/"}
+	first, err := sessions.Register("https://cdn.example.com/master.m3u8", headers)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	again, _ := sessions.Register("https://cdn.example.com/master.m3u8", headers)
+	if again.Token != first.Token {
+		t.Errorf("Register() token = %q; want the active session %q reused", again.Token, first.Token)
+	}
+	other, _ := sessions.Register("https://cdn.example.com/master.m3u8", nil)
+	if other.Token == first.Token {
+		t.Error("Register() with different headers should create a new session")
+	}
+
+	// Headers devolvidos são cópias
+	headers["Referer"] = "https://changed.example/"
+	first.Headers["Cookie"] = "x=1"
+	if got, _ := sessions.Get(first.Token); got.Headers["Referer"] != "https://example.com/" || got.Headers["Cookie"] != "" {
+		t.Errorf("Get() headers = %v; want the registered headers untouched", got.Headers)
+	}
+
+	// Cada acesso renova a validade
+	now = now.Add(50 * time.Minute)
+	if _, ok := sessions.Get(first.Token); !ok {
+		t.Fatal("Get() should renew the session on access")
+	}
+	now = now.Add(50 * time.Minute)
+	if _, ok := sessions.Get(first.Token); !ok {
+		t.Error("session accessed 50 minutes ago should still be valid")
+	}
+	if removed := sessions.Cleanup(); removed != 1 {
+		t.Errorf("Cleanup() = %d; want the idle session removed", removed)
+	}
+	if _, ok := sessions.Get(other.Token); ok {
+		t.Error("Get() should not return an expired session")
+	}
+}
@@ -0,0 +1,328 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"GoAnimeGUI/pkg/dash"
+	"GoAnimeGUI/pkg/hls"
+	"GoAnimeGUI/pkg/segmentcache"
+)
+
+// maxPlaylistSize limita o corpo de uma playlist lido para reescrita
+const maxPlaylistSize = 8 << 20
+
+// StreamHandler serve os streams registrados em Sessions: /video/{token} é o recurso principal e
+// /video/{token}/r?url=... os recursos filhos (segmentos, chaves, playlists de variantes...).
+// Playlists HLS e manifestos DASH são reescritos para que toda URI volte ao proxy com os headers
+// da sessão; as URLs do DASH usam /video/{token}/b/... (veja dashURL).
+// As URLs filhas são assinadas pelo Guard: quem tem o token não consegue pedir outros destinos.
+// Com Cache, segmentos e pedaços de MP4 ficam em disco e os próximos são baixados à frente.
+type StreamHandler struct {
+	Sessions    *Sessions
+	Client      *http.Client
+	ChildClient *http.Client // Recursos filhos de origens públicas (Guard.Transport); nil usa Client
+	Guard       *Guard
+	BaseURL     func() string                             // "http://127.0.0.1:porta"
+	Prepare     func(req *http.Request, session *Session) // Headers padrão (navegador, Referer); os da sessão vêm depois
+	Cache       *segmentcache.Cache                       // nil desativa o cache em disco
+	ReadAhead   func() int                                // Segmentos/pedaços baixados à frente (nil ou <= 0 desativa)
+
+	prefetch prefetcher
+}
+
+// URL retorna o endereço local do recurso principal de uma sessão
+func (h *StreamHandler) URL(token string) string {
+	return h.BaseURL() + "/video/" + token
+}
+
+// childURL retorna o endereço local de um recurso filho do stream
+func (h *StreamHandler) childURL(token, target string, kind hls.URIKind) string {
+	childURL := h.URL(token) + "/r?url=" + url.QueryEscape(target) + "&sig=" + h.Guard.Sign(target)
+	if kind == hls.URIPlaylist {
+		childURL += "&playlist=1"
+	}
+	return childURL
+}
+
+// dashURL retorna o endereço local de uma URL de manifesto DASH. Diferente de childURL, a origem
+// vai no caminho (/video/{token}/b/{assinatura}/{diretório em base64}/{resto}), para o player ainda
+// enxergar e preencher os identificadores de template ($Number$, $Time$...). Só o diretório é
+// assinado: o resto fica abaixo dele, no mesmo host.
+func (h *StreamHandler) dashURL(token, uri string) string {
+	cut := len(uri)
+	if i := strings.IndexAny(uri, "$?#"); i >= 0 {
+		cut = i
+	}
+	dir := uri[:strings.LastIndexByte(uri[:cut], '/')+1]
+	return h.URL(token) + "/b/" + h.Guard.Sign(dir) + "/" + base64.RawURLEncoding.EncodeToString([]byte(dir)) + "/" + uri[len(dir):]
+}
+
+// dashTarget desfaz dashURL: rest é o caminho depois de /b/ (ainda escapado) e rawQuery a query
+// pedida. Retorna "" se a assinatura do diretório não confere.
+func (h *StreamHandler) dashTarget(rest, rawQuery string) string {
+	sig, rest, _ := strings.Cut(rest, "/")
+	encoded, tail, _ := strings.Cut(rest, "/")
+	dir, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || !h.Guard.Verify(string(dir), sig) ||
+		!(strings.HasPrefix(string(dir), "http://") || strings.HasPrefix(string(dir), "https://")) {
+		return ""
+	}
+	target := string(dir) + tail
+	if rawQuery != "" {
+		target += "?" + rawQuery
+	}
+	return target
+}
+
+// ServeHTTP atende /video/{token}, /video/{token}/r?url=... e /video/{token}/b/...
+func (h *StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	token, sub, _ := strings.Cut(strings.TrimPrefix(r.URL.EscapedPath(), "/video/"), "/")
+	session, ok := h.Sessions.Get(token)
+	if !ok {
+		http.Error(w, "Stream não registrado ou expirado", http.StatusNotFound)
+		return
+	}
+
+	target, forcePlaylist := session.URL, false
+	switch {
+	case sub == "":
+	case sub == "r":
+		target = r.URL.Query().Get("url")
+		forcePlaylist = r.URL.Query().Get("playlist") == "1"
+		if target == "" {
+			http.Error(w, "URL não especificada", http.StatusBadRequest)
+			return
+		}
+		if !h.Guard.Verify(target, r.URL.Query().Get("sig")) {
+			h.Guard.Reject(w, r, target, fmt.Errorf("URL sem assinatura válida"))
+			return
+		}
+	case strings.HasPrefix(sub, "b/"):
+		if target = h.dashTarget(strings.TrimPrefix(sub, "b/"), r.URL.RawQuery); target == "" {
+			h.Guard.Reject(w, r, r.URL.Path, fmt.Errorf("URL sem assinatura válida"))
+			return
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if !forcePlaylist && h.Cache.Enabled() {
+		if sub == "" && isProgressive(target) && h.serveChunked(w, r, session) {
+			return
+		}
+		if sub != "" && h.serveCachedSegment(w, r, session, target) {
+			return
+		}
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, target, nil)
+	if err != nil {
+		http.Error(w, "Erro ao criar request", http.StatusBadRequest)
+		return
+	}
+	// Range para seek; Accept-Encoding fica de fora para a playlist chegar legível
+	for _, key := range []string{"Range", "Accept"} {
+		if value := r.Header.Get(key); value != "" {
+			req.Header.Set(key, value)
+		}
+	}
+	if h.Prepare != nil {
+		h.Prepare(req, session)
+	}
+	session.Apply(req)
+
+	resp, err := h.clientFor(session, target).Do(req)
+	if err != nil {
+		if IsBlocked(err) {
+			h.Guard.Reject(w, r, target, err)
+			return
+		}
+		fmt.Printf("[VideoProxy] Erro ao buscar %s: %v\n", target, err)
+		http.Error(w, "Erro ao acessar vídeo", http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		fmt.Printf("[VideoProxy] Servidor remoto retornou %s para %s\n", resp.Status, target)
+	}
+
+	body, isPlaylist := PeekPlaylist(resp, target)
+	if (isPlaylist || forcePlaylist) && resp.StatusCode < 300 {
+		h.writePlaylist(w, resp, body, session.Token)
+		return
+	}
+	if resp.StatusCode < 300 {
+		var isManifest bool
+		if body, isManifest = peekManifest(resp, body, target); isManifest {
+			h.writeManifest(w, resp, body, session.Token)
+			return
+		}
+	}
+
+	// Segmentos vão para o cache enquanto são repassados
+	var writer *segmentcache.Writer
+	if sub != "" {
+		writer = newCacheWriter(h.Cache, segmentcache.Key(target, r.Header.Get("Range")), resp)
+		h.readAheadSegments(session, target)
+	}
+
+	for key, values := range resp.Header {
+		if strings.HasPrefix(key, "Access-Control-") {
+			continue
+		}
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	if strings.EqualFold(path.Ext(req.URL.Path), ".mp4") {
+		w.Header().Set("Content-Type", "video/mp4")
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	if writer == nil {
+		if _, err := io.Copy(w, body); err != nil {
+			fmt.Printf("[VideoProxy] Erro no streaming: %v\n", err)
+		}
+		return
+	}
+	n, err := io.Copy(w, io.TeeReader(body, writer))
+	if err != nil {
+		fmt.Printf("[VideoProxy] Erro no streaming: %v\n", err)
+	}
+	commitCacheWriter(writer, resp, n, err)
+}
+
+// clientFor escolhe o cliente de um recurso. O principal foi registrado pelo próprio app e pode
+// estar em qualquer endereço (inclusive serviços locais); os filhos de uma origem pública vêm da
+// playlist ou do manifesto e só podem ir para endereços públicos.
+func (h *StreamHandler) clientFor(session *Session, target string) *http.Client {
+	if target == session.URL || h.ChildClient == nil || checkURL(session.URL) != nil {
+		return h.Client
+	}
+	return h.ChildClient
+}
+
+// writePlaylist reescreve a playlist e responde. URIs relativas são resolvidas contra a URL
+// final (depois de redirects); se o corpo não for uma playlist válida, vai como veio.
+func (h *StreamHandler) writePlaylist(w http.ResponseWriter, resp *http.Response, body io.Reader, token string) {
+	raw, err := io.ReadAll(io.LimitReader(body, maxPlaylistSize))
+	if err != nil {
+		http.Error(w, "Erro ao ler m3u8", http.StatusBadGateway)
+		return
+	}
+
+	var segments []string
+	content, err := hls.Rewrite(string(raw), resp.Request.URL, func(uri string, kind hls.URIKind) string {
+		if kind == hls.URIMedia {
+			segments = append(segments, uri)
+		}
+		return h.childURL(token, uri, kind)
+	})
+	if err != nil {
+		fmt.Printf("[VideoProxy] %v (%s)\n", err, resp.Request.URL)
+		content = string(raw)
+	}
+	if h.Cache.Enabled() && h.readAhead() > 0 {
+		h.Sessions.SetSegments(token, segments)
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(content))
+}
+
+// writeManifest reescreve o manifesto DASH e responde; se não for um MPD válido, vai como veio.
+// Com read-ahead, a ordem dos segmentos de cada representação fica registrada na sessão.
+func (h *StreamHandler) writeManifest(w http.ResponseWriter, resp *http.Response, body io.Reader, token string) {
+	raw, err := io.ReadAll(io.LimitReader(body, maxPlaylistSize))
+	if err != nil {
+		http.Error(w, "Erro ao ler mpd", http.StatusBadGateway)
+		return
+	}
+
+	base := resp.Request.URL
+	content, err := dash.Rewrite(raw, base, func(uri string) string {
+		return h.dashURL(token, uri)
+	})
+	if err != nil {
+		fmt.Printf("[VideoProxy] %v (%s)\n", err, base)
+		content = raw
+	}
+	if h.Cache.Enabled() && h.readAhead() > 0 {
+		if manifest, err := dash.Parse(raw, base); err == nil {
+			for _, rep := range manifest.Representations {
+				var segments []string
+				for _, seg := range rep.Segments {
+					if seg.Range.Length == 0 {
+						segments = append(segments, seg.URI)
+					}
+				}
+				h.Sessions.SetSegments(token, segments)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/dash+xml")
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
+
+// peekManifest diz se a resposta é um manifesto DASH, olhando o começo de body sem consumi-lo
+func peekManifest(resp *http.Response, body io.Reader, target string) (io.Reader, bool) {
+	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
+	if strings.HasPrefix(contentType, "video/") || strings.HasPrefix(contentType, "audio/") {
+		return body, false
+	}
+	reader, ok := body.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReader(body)
+	}
+	head, _ := reader.Peek(512)
+	return reader, dash.IsManifest(contentType, target, head)
+}
+
+// PeekPlaylist diz se a resposta é uma playlist m3u8 (pelo Content-Type, pela extensão ou
+// pelo começo do corpo) sem consumir o corpo. Use o reader retornado no lugar de resp.Body.
+func PeekPlaylist(resp *http.Response, target string) (io.Reader, bool) {
+	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
+	if strings.Contains(contentType, "mpegurl") {
+		return resp.Body, true
+	}
+	if parsed, err := url.Parse(target); err == nil && strings.EqualFold(path.Ext(parsed.Path), ".m3u8") {
+		return resp.Body, true
+	}
+
+	// CDNs às vezes servem a playlist como text/plain ou com extensão disfarçada
+	reader := bufio.NewReader(resp.Body)
+	head, _ := reader.Peek(16)
+	head = bytes.TrimPrefix(bytes.TrimSpace(head), []byte("\ufeff"))
+	return reader, bytes.HasPrefix(head, []byte("#EXTM3U"))
+}
+
+// setCORSHeaders libera o player da WebView a ler as respostas do proxy
+func setCORSHeaders(w http.ResponseWriter) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Range, Accept, Content-Type")
+	w.Header().Set("Access-Control-Expose-Headers", "Content-Length, Content-Range, Accept-Ranges")
+}
@@ -0,0 +1,91 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc responde as requisições sem rede
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func textResponse(req *http.Request, contentType, body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {contentType}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+func TestStreamHandler_SignsChildURLsAndGuardsPublicOrigins(t *testing.T) {
+	playlist := "#EXTM3U\n#EXT-X-TARGETDURATION:4\n#EXTINF:4,\nseg1.ts\n#EXTINF:4,\nhttp://10.0.0.1/seg2.ts\n#EXT-X-ENDLIST\n"
+	var mainHits, childHits []string
+	main := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mainHits = append(mainHits, req.URL.String())
+		return textResponse(req, "application/vnd.apple.mpegurl", playlist), nil
+	})}
+	child := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		childHits = append(childHits, req.URL.String())
+		if req.URL.Hostname() == "10.0.0.1" {
+			return nil, fmt.Errorf("10.0.0.1: %w", errBlockedAddress)
+		}
+		return textResponse(req, "video/mp2t", "segmento"), nil
+	})}
+
+	sessions := NewSessions(time.Hour)
+	session, _ := sessions.Register("https://cdn.example/ep1/index.m3u8", nil)
+	h := &StreamHandler{Sessions: sessions, Client: main, ChildClient: child, Guard: NewGuard(),
+		BaseURL: func() string { return "http://127.0.0.1:1" }}
+	get := func(rawURL string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", rawURL, nil))
+		return rec
+	}
+
+	rec := get("/video/" + session.Token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("playlist status = %d", rec.Code)
+	}
+	var children []string
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if strings.HasPrefix(line, "http://127.0.0.1:1/video/") {
+			children = append(children, strings.TrimPrefix(line, "http://127.0.0.1:1"))
+		}
+	}
+	if len(children) != 2 || !strings.Contains(children[0], "&sig=") {
+		t.Fatalf("rewritten children = %v; want 2 signed URLs", children)
+	}
+
+	if rec := get(children[0]); rec.Code != http.StatusOK || rec.Body.String() != "segmento" {
+		t.Errorf("signed child = %d %q", rec.Code, rec.Body)
+	}
+	if rec := get(children[1]); rec.Code != http.StatusForbidden {
+		t.Errorf("child on a private address = %d; want 403", rec.Code)
+	}
+	if len(mainHits) != 1 || len(childHits) != 2 {
+		t.Errorf("main client fetched %v, child client %v; want only the playlist on the main client", mainHits, childHits)
+	}
+
+	// Com o token, mas sem assinatura ou com ela trocada: nada é buscado
+	forged := "/video/" + session.Token + "/r?url=" + url.QueryEscape("http://192.168.0.1/admin")
+	tampered := strings.Replace(children[0], "seg1.ts", "seg9.ts", 1)
+	dash := "/video/" + session.Token + "/b/x/aHR0cDovLzEwLjAuMC4xLw/seg.m4s"
+	for _, rawURL := range []string{forged, forged + "&sig=abc", tampered, dash} {
+		if rec := get(rawURL); rec.Code != http.StatusForbidden {
+			t.Errorf("GET %s = %d; want 403", rawURL, rec.Code)
+		}
+	}
+	if len(childHits) != 2 {
+		t.Errorf("forged URLs reached the network: %v", childHits[2:])
+	}
+}
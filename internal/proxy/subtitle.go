@@ -0,0 +1,119 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"GoAnimeGUI/pkg/hostrules"
+	"GoAnimeGUI/pkg/subtitles"
+)
+
+// maxSubtitleSize limita o tamanho de uma legenda baixada
+const maxSubtitleSize = 10 << 20
+
+// SubtitleHandler serve /subtitle?url=...&sig=...: baixa a legenda (URL assinada pelo Guard),
+// converte SRT, ASS/SSA ou WebVTT para WebVTT e aplica o deslocamento pedido.
+// Parâmetros opcionais: format ("srt", "ass"...; sem ele vale a extensão da URL ou o conteúdo)
+// e offset em segundos (negativo adianta a legenda).
+type SubtitleHandler struct {
+	Guard  *Guard
+	Client *http.Client // Deve usar Guard.Transport e Guard.CheckRedirect
+}
+
+// ServeHTTP implementa http.Handler
+func (h *SubtitleHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	target, err := h.Guard.Target(r)
+	if err != nil {
+		h.Guard.Reject(w, r, target, err)
+		return
+	}
+	offset, err := parseOffset(r.URL.Query().Get("offset"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, target, nil)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao criar requisição: %v", err), http.StatusInternalServerError)
+		return
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	req.Header.Set("Referer", extractReferer(target))
+	hostrules.Default().Apply(req)
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		if IsBlocked(err) {
+			h.Guard.Reject(w, r, target, err)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Erro ao buscar legenda: %v", err), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		fmt.Printf("[Subtitle] HTTP %d em %s\n", resp.StatusCode, target)
+		http.Error(w, fmt.Sprintf("Legenda indisponível (HTTP %d)", resp.StatusCode), http.StatusBadGateway)
+		return
+	}
+	if resp.ContentLength > maxSubtitleSize {
+		http.Error(w, "Legenda grande demais", http.StatusBadGateway)
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSubtitleSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Erro ao ler legenda: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = subtitleExtension(target)
+	}
+	vtt, err := subtitles.ToWebVTT(data, format, offset)
+	if err != nil {
+		fmt.Printf("[Subtitle] Erro ao converter %s: %v\n", target, err)
+		http.Error(w, fmt.Sprintf("Erro ao converter legenda: %v", err), http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(vtt)
+}
+
+// parseOffset lê o deslocamento em segundos (aceita fração, "-1.5")
+func parseOffset(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return 0, fmt.Errorf("offset inválido: %s", value)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// subtitleExtension retorna a extensão do caminho da URL ("srt", "ass"...), ou "" se não houver
+func subtitleExtension(target string) string {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(path.Ext(parsed.Path), "."))
+}
@@ -0,0 +1,63 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSubtitleHandler_ConvertsSignedURLs(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ep1.srt":
+			io.WriteString(w, "1\r\n00:00:02,000 --> 00:00:04,000\r\nOl\xe1 <i>mundo</i>\r\n")
+		case "/login":
+			io.WriteString(w, "<html>Faça login</html>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer origin.Close()
+
+	// O destino precisa parecer público para o Guard; a conexão vai para o servidor de teste
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, origin.Listener.Addr().String())
+		},
+	}}
+	guard := NewGuard()
+	handler := &SubtitleHandler{Guard: guard, Client: client}
+
+	get := func(rawURL string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", rawURL, nil))
+		return rec
+	}
+
+	rec := get(guard.URL("/subtitle", "http://subs.example/ep1.srt") + "&offset=-1.5")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/vtt; charset=utf-8" {
+		t.Fatalf("status = %d, Content-Type = %q: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+	}
+	if want := "WEBVTT\n\n00:00:00.500 --> 00:00:02.500\nOlá <i>mundo</i>\n"; rec.Body.String() != want {
+		t.Errorf("body = %q; want %q", rec.Body, want)
+	}
+
+	for name, tc := range map[string]struct {
+		url  string
+		code int
+	}{
+		"unsigned":   {"/subtitle?url=http%3A%2F%2Fsubs.example%2Fep1.srt", http.StatusForbidden},
+		"private":    {guard.URL("/subtitle", "http://10.0.0.1/ep1.srt"), http.StatusForbidden},
+		"bad offset": {guard.URL("/subtitle", "http://subs.example/ep1.srt") + "&offset=abc", http.StatusBadRequest},
+		"not found":  {guard.URL("/subtitle", "http://subs.example/ep2.srt"), http.StatusBadGateway},
+		"not a sub":  {guard.URL("/subtitle", "http://subs.example/login"), http.StatusUnprocessableEntity},
+	} {
+		if rec := get(tc.url); rec.Code != tc.code {
+			t.Errorf("%s: status = %d; want %d (%s)", name, rec.Code, tc.code, strings.TrimSpace(rec.Body.String()))
+		}
+	}
+}
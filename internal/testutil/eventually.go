@@ -0,0 +1,19 @@
+// Package testutil tem helpers compartilhados pelos testes dos pacotes com filas em background.
+package testutil
+
+import "time"
+
+// Eventually chama cond a cada 20ms até ela retornar true ou timeout passar.
+// Retorna false no timeout; quem chama falha o teste com o estado que esperava.
+func Eventually(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if cond() {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
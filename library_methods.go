@@ -0,0 +1,135 @@
+// library_methods.go - Métodos da biblioteca para o frontend
+// Estados de visualização, notas pessoais e categorias dos favoritos
+package main
+
+import (
+	"fmt"
+
+	"GoAnimeGUI/pkg/store"
+)
+
+// ==============================
+// BIBLIOTECA
+// ==============================
+
+// GetLibrary retorna os favoritos com estado, nota e categorias.
+// Filtros vazios são ignorados; categoryId -1 retorna os sem categoria.
+func (a *App) GetLibrary(filter store.LibraryFilter) []store.LibraryItem {
+	db, err := store.Default()
+	if err != nil {
+		fmt.Printf("[Library] Banco indisponível: %v\n", err)
+		return []store.LibraryItem{}
+	}
+
+	items, err := db.QueryLibrary(filter)
+	if err != nil {
+		fmt.Printf("[Library] Erro ao consultar biblioteca: %v\n", err)
+		return []store.LibraryItem{}
+	}
+	return items
+}
+
+// GetWatchStatuses retorna os estados disponíveis na ordem da interface
+func (a *App) GetWatchStatuses() []store.WatchStatus {
+	return store.WatchStatuses
+}
+
+// SetLibraryStatus altera o estado de um anime (watching, completed, on_hold, dropped, plan_to_watch)
+func (a *App) SetLibraryStatus(animeURL string, status string) error {
+	db, err := store.Default()
+	if err != nil {
+		return err
+	}
+	if err := db.SetLibraryStatus(animeURL, store.WatchStatus(status)); err != nil {
+		return err
+	}
+	a.onLibraryChanged(animeURL)
+	return nil
+}
+
+// SetLibraryScore altera a nota pessoal (0-10, 0 = sem nota)
+func (a *App) SetLibraryScore(animeURL string, score float64) error {
+	db, err := store.Default()
+	if err != nil {
+		return err
+	}
+	if err := db.SetLibraryScore(animeURL, score); err != nil {
+		return err
+	}
+	a.onLibraryChanged(animeURL)
+	return nil
+}
+
+// SetLibraryNotes altera as anotações pessoais de um anime
+func (a *App) SetLibraryNotes(animeURL string, notes string) error {
+	db, err := store.Default()
+	if err != nil {
+		return err
+	}
+	return db.SetLibraryNotes(animeURL, notes)
+}
+
+// MoveLibraryEntries define as categorias dos animes indicados.
+// Uma lista vazia de categorias remove os animes de todas elas.
+func (a *App) MoveLibraryEntries(animeURLs []string, categoryIDs []int64) error {
+	db, err := store.Default()
+	if err != nil {
+		return err
+	}
+	return db.SetEntryCategories(animeURLs, categoryIDs)
+}
+
+// ==============================
+// CATEGORIAS
+// ==============================
+
+// GetCategories retorna as categorias do usuário na ordem definida
+func (a *App) GetCategories() []store.Category {
+	db, err := store.Default()
+	if err != nil {
+		return []store.Category{}
+	}
+
+	categories, err := db.Categories()
+	if err != nil {
+		fmt.Printf("[Library] Erro ao ler categorias: %v\n", err)
+		return []store.Category{}
+	}
+	return categories
+}
+
+// CreateCategory cria uma nova categoria no fim da lista
+func (a *App) CreateCategory(name string) (store.Category, error) {
+	db, err := store.Default()
+	if err != nil {
+		return store.Category{}, err
+	}
+	return db.CreateCategory(name)
+}
+
+// RenameCategory renomeia uma categoria
+func (a *App) RenameCategory(id int64, name string) error {
+	db, err := store.Default()
+	if err != nil {
+		return err
+	}
+	return db.RenameCategory(id, name)
+}
+
+// DeleteCategory apaga uma categoria (os animes continuam na biblioteca)
+func (a *App) DeleteCategory(id int64) error {
+	db, err := store.Default()
+	if err != nil {
+		return err
+	}
+	return db.DeleteCategory(id)
+}
+
+// ReorderCategories salva a nova ordem das categorias (lista de IDs)
+func (a *App) ReorderCategories(ids []int64) error {
+	db, err := store.Default()
+	if err != nil {
+		return err
+	}
+	return db.ReorderCategories(ids)
+}
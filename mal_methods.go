@@ -0,0 +1,341 @@
+// mal_methods.go - Métodos de tracking do MyAnimeList para o frontend
+// Login OAuth2 (PKCE), vínculo de animes da biblioteca e sincronização da lista
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"GoAnimeGUI/pkg/mal"
+	"GoAnimeGUI/pkg/retry"
+	"GoAnimeGUI/pkg/store"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// MALStatus é o estado da integração para o frontend
+type MALStatus struct {
+	Configured  bool      `json:"configured"`
+	LoggedIn    bool      `json:"loggedIn"`
+	User        *mal.User `json:"user"`
+	RedirectURI string    `json:"redirectUri"`
+}
+
+// ==============================
+// LOGIN
+// ==============================
+
+// GetMALStatus retorna se o MyAnimeList está configurado e conectado
+func (a *App) GetMALStatus() MALStatus {
+	auth := mal.GetAuth()
+	return MALStatus{
+		Configured:  auth.IsConfigured(),
+		LoggedIn:    auth.IsLoggedIn(),
+		User:        auth.CurrentUser(),
+		RedirectURI: auth.RedirectURI(),
+	}
+}
+
+// SaveMALConfig salva o Client ID (e o secret, se o app tiver) registrado no MAL
+func (a *App) SaveMALConfig(clientID, clientSecret string) error {
+	return mal.GetAuth().Configure(clientID, clientSecret)
+}
+
+// StartMALLogin abre o navegador para autorizar o GoAnime no MyAnimeList.
+// O resultado chega pelos eventos "mal:connected" ou "mal:error".
+func (a *App) StartMALLogin() error {
+	auth := mal.GetAuth()
+	if !auth.IsConfigured() {
+		return fmt.Errorf("client ID do MyAnimeList não configurado")
+	}
+
+	authURL, err := auth.AuthURL()
+	if err != nil {
+		return err
+	}
+	runtime.BrowserOpenURL(a.ctx, authURL)
+	fmt.Println("[MAL OAuth] Aguardando autorização do usuário...")
+
+	go func() {
+		code, err := auth.WaitForCallback()
+		if err != nil {
+			fmt.Printf("[MAL OAuth] Erro: %v\n", err)
+			runtime.EventsEmit(a.ctx, "mal:error", err.Error())
+			return
+		}
+
+		user, err := auth.ExchangeCode(code)
+		if err != nil {
+			fmt.Printf("[MAL OAuth] Erro ao completar OAuth: %v\n", err)
+			runtime.EventsEmit(a.ctx, "mal:error", err.Error())
+			return
+		}
+
+		fmt.Printf("[MAL OAuth] Usuário conectado: %s\n", user.Name)
+		runtime.EventsEmit(a.ctx, "mal:connected", user)
+
+		if _, err := a.SyncMAL(); err != nil {
+			fmt.Printf("[MAL] Erro na sincronização inicial: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// LogoutMAL desconecta a conta do MyAnimeList
+func (a *App) LogoutMAL() error {
+	return mal.GetAuth().Logout()
+}
+
+// ==============================
+// VÍNCULO COM A BIBLIOTECA
+// ==============================
+
+// LinkMAL vincula um anime da biblioteca a um ID do MyAnimeList (0 desfaz o vínculo)
+func (a *App) LinkMAL(animeURL string, malID int) error {
+	db, err := store.Default()
+	if err != nil {
+		return err
+	}
+	if err := db.LinkMAL(animeURL, malID); err != nil {
+		return err
+	}
+	if malID > 0 {
+		a.onLibraryChanged(animeURL)
+	}
+	return nil
+}
+
+// resolveMALID retorna o ID vinculado ou tenta vincular pelo resolver de identidade,
+// e por fim pelo título exato na busca do MAL (animes sem ID do MAL na AniList)
+func (a *App) resolveMALID(db *store.DB, item *store.LibraryItem) int {
+	if item.MALID == 0 {
+		linkLibraryIdentity(db, item)
+	}
+	if item.MALID > 0 {
+		return item.MALID
+	}
+
+	results, err := mal.GetAuth().SearchAnime(item.Title, 5)
+	if err != nil {
+		return 0
+	}
+	for _, anime := range results {
+		if titleMatchesAny(item.Title, anime.Titles()...) {
+			if err := db.LinkMAL(item.URL, anime.ID); err == nil {
+				fmt.Printf("[MAL] %s vinculado ao ID %d\n", item.Title, anime.ID)
+				item.MALID = anime.ID
+			}
+			return anime.ID
+		}
+	}
+	fmt.Printf("[MAL] Nenhum resultado exato para %q, vincule manualmente\n", item.Title)
+	return 0
+}
+
+// ==============================
+// ENVIO DE PROGRESSO
+// ==============================
+
+// pushMALEntry envia estado, nota e progresso de uma entrada para o MAL.
+// Se o MAL já tiver mais episódios assistidos, o progresso remoto é mantido
+// e trazido para a biblioteca, e o frontend recebe "mal:conflict".
+// Chamado pela fila de sincronização: um erro agenda uma nova tentativa.
+func (a *App) pushMALEntry(animeURL string) error {
+	auth := mal.GetAuth()
+	if !auth.IsLoggedIn() {
+		return nil
+	}
+
+	db, err := store.Default()
+	if err != nil {
+		return err
+	}
+	item, err := db.GetLibraryItem(animeURL)
+	if err != nil || item == nil {
+		return err
+	}
+	malID := a.resolveMALID(db, item)
+	if malID == 0 {
+		return nil
+	}
+
+	anime, err := auth.GetAnime(malID)
+	if errors.Is(err, mal.ErrNotLoggedIn) {
+		return retry.Permanent(err)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao ler %s: %w", item.Title, err)
+	}
+
+	update := malUpdateFor(item)
+	if remote := anime.MyListStatus; remote != nil && remote.NumEpisodesWatched > item.Progress {
+		a.resolveMALConflict(db, item, remote.NumEpisodesWatched)
+		update.NumEpisodesWatched = -1
+	}
+	if anime.NumEpisodes > 0 && item.Progress >= anime.NumEpisodes && item.Status == store.StatusWatching {
+		if err := db.SetLibraryStatus(animeURL, store.StatusCompleted); err == nil {
+			update.Status = string(store.StatusCompleted)
+		}
+	}
+
+	status, err := auth.UpdateListStatus(malID, update)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar %s: %w", item.Title, err)
+	}
+	fmt.Printf("[MAL] %s atualizado: %s, episódio %d\n", item.Title, status.Status, status.NumEpisodesWatched)
+	return nil
+}
+
+// resolveMALConflict adota o progresso do MAL quando ele está à frente do histórico local
+func (a *App) resolveMALConflict(db *store.DB, item *store.LibraryItem, remoteProgress int) TrackerConflict {
+	conflict := TrackerConflict{
+		AnimeURL:       item.URL,
+		Title:          item.Title,
+		LocalProgress:  item.Progress,
+		RemoteProgress: remoteProgress,
+	}
+	if err := db.SetLibraryProgress(item.URL, remoteProgress); err == nil {
+		item.Progress = remoteProgress
+	}
+	fmt.Printf("[MAL] %s: MAL no episódio %d, histórico local no %d; mantendo o do MAL\n",
+		conflict.Title, conflict.RemoteProgress, conflict.LocalProgress)
+	runtime.EventsEmit(a.ctx, "mal:conflict", conflict)
+	return conflict
+}
+
+func malUpdateFor(item *store.LibraryItem) mal.ListUpdate {
+	return mal.ListUpdate{
+		Status:             string(item.Status),
+		Score:              int(math.Round(item.Score)), // O MAL só aceita notas inteiras
+		NumEpisodesWatched: item.Progress,
+	}
+}
+
+// ==============================
+// SINCRONIZAÇÃO
+// ==============================
+
+// SyncMAL baixa a lista do MAL e reconcilia com a biblioteca local.
+// Estado e nota seguem o lado alterado mais recentemente; o progresso fica com o maior valor.
+func (a *App) SyncMAL() (TrackerSyncResult, error) {
+	result := newTrackerSyncResult()
+
+	auth := mal.GetAuth()
+	remote, err := auth.GetAnimeList()
+	if err != nil {
+		return result, err
+	}
+	db, err := store.Default()
+	if err != nil {
+		return result, err
+	}
+	items, err := db.QueryLibrary(store.LibraryFilter{})
+	if err != nil {
+		return result, err
+	}
+
+	byID := make(map[int]*mal.ListEntry, len(remote))
+	for i := range remote {
+		byID[remote[i].Anime.ID] = &remote[i]
+	}
+	matched := make(map[int]bool)
+
+	for i := range items {
+		item := &items[i]
+
+		// Entradas sem vínculo: tenta casar pelo título com a lista remota
+		if item.MALID == 0 {
+			for _, entry := range remote {
+				if titleMatchesAny(item.Title, entry.Anime.Titles()...) {
+					if err := db.LinkMAL(item.URL, entry.Anime.ID); err == nil {
+						item.MALID = entry.Anime.ID
+						result.Linked++
+					}
+					break
+				}
+			}
+			if item.MALID == 0 {
+				continue
+			}
+		}
+		matched[item.MALID] = true
+
+		entry := byID[item.MALID]
+		if entry == nil {
+			if _, err := auth.UpdateListStatus(item.MALID, malUpdateFor(item)); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", item.Title, err))
+			} else {
+				result.Pushed++
+			}
+			continue
+		}
+
+		if entry.ListStatus.NumEpisodesWatched > item.Progress {
+			result.Conflicts = append(result.Conflicts, a.resolveMALConflict(db, item, entry.ListStatus.NumEpisodesWatched))
+		}
+
+		pulled, pushed, err := a.reconcileMALEntry(db, item, entry)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", item.Title, err))
+		}
+		if pulled {
+			result.Pulled++
+		}
+		if pushed {
+			result.Pushed++
+		}
+	}
+
+	for _, entry := range remote {
+		if matched[entry.Anime.ID] {
+			continue
+		}
+		result.RemoteOnly = append(result.RemoteOnly, TrackerRemoteEntry{
+			ID:       entry.Anime.ID,
+			Title:    entry.Anime.Title,
+			Image:    entry.Anime.MainPicture.Large,
+			Status:   entry.ListStatus.Status,
+			Progress: entry.ListStatus.NumEpisodesWatched,
+			Episodes: entry.Anime.NumEpisodes,
+		})
+	}
+
+	result.log("MAL")
+	runtime.EventsEmit(a.ctx, "mal:synced", result)
+	return result, nil
+}
+
+// reconcileMALEntry aplica a regra de reconciliação em uma entrada vinculada
+// (o progresso remoto maior já foi adotado por resolveMALConflict)
+func (a *App) reconcileMALEntry(db *store.DB, item *store.LibraryItem, entry *mal.ListEntry) (pulled, pushed bool, err error) {
+	remote := entry.ListStatus
+	status, score := item.Status, item.Score
+
+	localUpdated, _ := time.Parse(time.RFC3339, item.UpdatedAt)
+	remoteUpdated, _ := time.Parse(time.RFC3339, remote.UpdatedAt)
+	if remoteUpdated.After(localUpdated) && store.WatchStatus(remote.Status).IsValid() {
+		status, score = store.WatchStatus(remote.Status), float64(remote.Score)
+	}
+
+	if status != item.Status || int(math.Round(score)) != int(math.Round(item.Score)) {
+		if err := db.SetLibraryStatus(item.URL, status); err != nil {
+			return false, false, err
+		}
+		if err := db.SetLibraryScore(item.URL, score); err != nil {
+			return false, false, err
+		}
+		item.Status, item.Score = status, score
+		pulled = true
+	}
+
+	if string(status) != remote.Status || int(math.Round(score)) != remote.Score || item.Progress != remote.NumEpisodesWatched {
+		if _, err := mal.GetAuth().UpdateListStatus(item.MALID, malUpdateFor(item)); err != nil {
+			return pulled, false, err
+		}
+		pushed = true
+	}
+	return pulled, pushed, nil
+}
@@ -0,0 +1,158 @@
+// manga_download_methods.go - Download de capítulos de mangá em CBZ
+// Capítulos, intervalos ou séries inteiras de qualquer fonte do mangascraper;
+// o frontend acompanha pelos eventos manga-download:*
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"GoAnimeGUI/pkg/mangadownloader"
+	"GoAnimeGUI/pkg/mangascraper"
+	"GoAnimeGUI/pkg/store"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+var (
+	// mangaDownloadManager é nil se o banco não estiver disponível
+	mangaDownloadManager *mangadownloader.Manager
+	mangaScraper         *mangascraper.Scraper
+)
+
+// initMangaDownloads cria o gerenciador e inicia a fila
+func (a *App) initMangaDownloads() {
+	db, err := store.Default()
+	if err != nil {
+		fmt.Printf("[MangaDownloads] Banco indisponível, downloads de mangá desativados: %v\n", err)
+		return
+	}
+
+	mangaScraper = mangascraper.New()
+	mangaDownloadManager = mangadownloader.New(db, mangaScraper.GetSource, a.mangaDownloadDir)
+	mangaDownloadManager.OnProgress(func(p mangadownloader.Progress) {
+		runtime.EventsEmit(a.ctx, "manga-download:progress", p)
+	})
+	mangaDownloadManager.OnChange(func(d store.MangaDownload) {
+		runtime.EventsEmit(a.ctx, "manga-download:changed", d)
+	})
+	go mangaDownloadManager.Run(a.ctx)
+}
+
+// mangaDownloadDir é a subpasta de mangás dentro da pasta de downloads
+func (a *App) mangaDownloadDir() string {
+	return filepath.Join(a.downloadDir(), "Mangás")
+}
+
+// enqueueManga busca os capítulos da série e coloca na fila os aceitos por keep
+func (a *App) enqueueManga(mangaURL string, keep func(mangascraper.Chapter) bool) ([]store.MangaDownload, error) {
+	if mangaDownloadManager == nil {
+		return nil, fmt.Errorf("downloads indisponíveis")
+	}
+	source, ok := mangaScraper.GetSource(mangaScraper.DetectSourceFromURL(mangaURL))
+	if !ok {
+		return nil, fmt.Errorf("fonte não encontrada para %s", mangaURL)
+	}
+
+	chapters, err := mangaScraper.GetChapters(mangaURL)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar capítulos: %w", err)
+	}
+	var selected []mangascraper.Chapter
+	for _, chapter := range chapters {
+		if keep(chapter) {
+			selected = append(selected, chapter)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("nenhum capítulo encontrado")
+	}
+
+	// Os metadados vão para o ComicInfo.xml; sem eles o CBZ ainda funciona
+	manga := mangascraper.Manga{URL: mangaURL, Title: selected[0].MangaName}
+	if details, err := mangaScraper.GetMangaDetails(mangaURL); err == nil && details != nil {
+		manga = *details
+		manga.URL = mangaURL
+	} else {
+		fmt.Printf("[MangaDownloads] Sem detalhes de %s: %v\n", mangaURL, err)
+	}
+
+	// Os sites listam do mais novo para o mais antigo; a fila segue a ordem de leitura
+	sort.SliceStable(selected, func(i, j int) bool { return selected[i].NumberFloat < selected[j].NumberFloat })
+	return mangaDownloadManager.Enqueue(source, manga, selected)
+}
+
+// DownloadMangaChapters coloca capítulos específicos de uma série na fila
+func (a *App) DownloadMangaChapters(mangaURL string, chapterURLs []string) ([]store.MangaDownload, error) {
+	wanted := make(map[string]bool, len(chapterURLs))
+	for _, u := range chapterURLs {
+		wanted[u] = true
+	}
+	return a.enqueueManga(mangaURL, func(c mangascraper.Chapter) bool { return wanted[c.URL] })
+}
+
+// DownloadMangaRange coloca na fila os capítulos de from até to (to <= 0 vai até o último)
+func (a *App) DownloadMangaRange(mangaURL string, from, to float64) ([]store.MangaDownload, error) {
+	return a.enqueueManga(mangaURL, func(c mangascraper.Chapter) bool {
+		return c.NumberFloat >= from && (to <= 0 || c.NumberFloat <= to)
+	})
+}
+
+// DownloadMangaSeries coloca todos os capítulos da série na fila
+func (a *App) DownloadMangaSeries(mangaURL string) ([]store.MangaDownload, error) {
+	return a.enqueueManga(mangaURL, func(mangascraper.Chapter) bool { return true })
+}
+
+// GetMangaDownloads retorna a fila e os capítulos concluídos
+func (a *App) GetMangaDownloads() ([]store.MangaDownload, error) {
+	db, err := store.Default()
+	if err != nil {
+		return nil, err
+	}
+	return db.MangaDownloads()
+}
+
+// PauseMangaDownload pausa um capítulo mantendo as páginas já baixadas
+func (a *App) PauseMangaDownload(id int64) error {
+	if mangaDownloadManager == nil {
+		return fmt.Errorf("downloads indisponíveis")
+	}
+	return mangaDownloadManager.Pause(id)
+}
+
+// ResumeMangaDownload retoma um capítulo pausado de onde parou
+func (a *App) ResumeMangaDownload(id int64) error {
+	if mangaDownloadManager == nil {
+		return fmt.Errorf("downloads indisponíveis")
+	}
+	return mangaDownloadManager.Resume(id)
+}
+
+// RetryMangaDownload tenta de novo um capítulo que falhou
+func (a *App) RetryMangaDownload(id int64) (*store.MangaDownload, error) {
+	if mangaDownloadManager == nil {
+		return nil, fmt.Errorf("downloads indisponíveis")
+	}
+	return mangaDownloadManager.Retry(id)
+}
+
+// CancelMangaDownload remove um capítulo da lista; deleteFile apaga também o CBZ ou as páginas parciais
+func (a *App) CancelMangaDownload(id int64, deleteFile bool) error {
+	if mangaDownloadManager == nil {
+		return fmt.Errorf("downloads indisponíveis")
+	}
+	return mangaDownloadManager.Cancel(id, deleteFile)
+}
+
+// SetMangaSourceLimit define quantos capítulos da fonte baixam ao mesmo tempo
+func (a *App) SetMangaSourceLimit(source string, limit int) error {
+	if mangaDownloadManager == nil {
+		return fmt.Errorf("downloads indisponíveis")
+	}
+	if _, ok := mangaScraper.GetSource(source); !ok {
+		return fmt.Errorf("fonte %q não encontrada", source)
+	}
+	mangaDownloadManager.SetSourceLimit(source, limit)
+	return nil
+}
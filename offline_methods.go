@@ -0,0 +1,294 @@
+// offline_methods.go - Biblioteca offline (episódios baixados)
+// Os arquivos são servidos pelo proxy local em /offline/, com suporte a Range para o player.
+// As URLs levam a assinatura do videoProxyGuard: sem ela, outra página no navegador não lê os arquivos.
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"GoAnimeGUI/pkg/offline"
+	"GoAnimeGUI/pkg/store"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// offlineLibrary é nil se o banco não estiver disponível
+var offlineLibrary *offline.Library
+
+// OfflineLibraryInfo é a biblioteca offline para o frontend
+type OfflineLibraryInfo struct {
+	Anime      []offline.Anime `json:"anime"`
+	TotalBytes int64           `json:"totalBytes"`
+	QuotaBytes int64           `json:"quotaBytes"` // 0 = sem limite
+}
+
+// OfflinePlayback são as URLs locais para tocar um episódio baixado
+type OfflinePlayback struct {
+	URL       string   `json:"url"`
+	Subtitles []string `json:"subtitles"`
+}
+
+// initOfflineLibrary cria o índice, indexa downloads antigos e agenda a limpeza
+func (a *App) initOfflineLibrary() {
+	db, err := store.Default()
+	if err != nil {
+		fmt.Printf("[Offline] Banco indisponível, biblioteca offline desativada: %v\n", err)
+		return
+	}
+
+	offlineLibrary = offline.New(db)
+	offlineLibrary.OnRemove(func(ep store.OfflineEpisode) {
+		// O download concluído não faz mais sentido sem o arquivo
+		if ep.DownloadID > 0 {
+			db.DeleteDownload(ep.DownloadID)
+			runtime.EventsEmit(a.ctx, "download:changed", store.Download{ID: ep.DownloadID})
+		}
+		runtime.EventsEmit(a.ctx, "offline:changed", ep.EpisodeURL)
+	})
+
+	go func() {
+		if downloads, err := db.Downloads(); err == nil {
+			for _, d := range downloads {
+				if d.State == store.DownloadCompleted {
+					offlineLibrary.Register(d)
+				}
+			}
+		}
+		a.cleanupOffline()
+
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-a.ctx.Done():
+				return
+			case <-ticker.C:
+				a.cleanupOffline()
+			}
+		}
+	}()
+}
+
+// addToOfflineLibrary indexa um download que acabou de terminar
+func (a *App) addToOfflineLibrary(d store.Download) {
+	if offlineLibrary == nil {
+		return
+	}
+	ep, err := offlineLibrary.Register(d)
+	if err != nil {
+		fmt.Printf("[Offline] Erro ao indexar download: %v\n", err)
+		return
+	}
+	runtime.EventsEmit(a.ctx, "offline:changed", ep.EpisodeURL)
+	a.cleanupOffline()
+}
+
+// offlineRules lê as regras de limpeza das configurações
+func (a *App) offlineRules() offline.Rules {
+	if a.User == nil {
+		return offline.Rules{}
+	}
+	return offline.Rules{
+		DeleteWatched: a.User.Settings.OfflineDeleteWatched,
+		QuotaBytes:    int64(a.User.Settings.OfflineQuotaGB) << 30,
+	}
+}
+
+func (a *App) cleanupOffline() {
+	if offlineLibrary == nil {
+		return
+	}
+	result, err := offlineLibrary.Cleanup(a.offlineRules())
+	if err != nil {
+		fmt.Printf("[Offline] Erro na limpeza: %v\n", err)
+		return
+	}
+	if result.Removed > 0 {
+		fmt.Printf("[Offline] Limpeza: %d episódios, %d MB liberados\n", result.Removed, result.FreedBytes>>20)
+	}
+}
+
+// lookupOffline retorna a cópia baixada do episódio (nil se não houver)
+func (a *App) lookupOffline(episodeURL string) *store.OfflineEpisode {
+	if offlineLibrary == nil {
+		return nil
+	}
+	return offlineLibrary.Lookup(episodeURL)
+}
+
+// offlinePlayback monta as URLs do proxy local para um episódio baixado
+func (a *App) offlinePlayback(ep *store.OfflineEpisode) (*OfflinePlayback, error) {
+	if err := a.startVideoProxy(); err != nil {
+		return nil, err
+	}
+	base := fmt.Sprintf("http://127.0.0.1:%d/offline/%d", a.proxyPort, ep.ID)
+	sig := "?sig=" + videoProxyGuard.Sign(offlineTarget(ep.ID))
+	playback := &OfflinePlayback{
+		// O nome do arquivo na URL deixa a extensão visível para o player
+		URL:       base + "/" + url.PathEscape(filepath.Base(ep.Path)) + sig,
+		Subtitles: []string{},
+	}
+	for i, sub := range ep.Subtitles {
+		playback.Subtitles = append(playback.Subtitles,
+			fmt.Sprintf("%s/sub/%d/%s%s", base, i, url.PathEscape(filepath.Base(sub)), sig))
+	}
+	return playback, nil
+}
+
+// offlineTarget é o que a assinatura das URLs de um episódio offline cobre
+func offlineTarget(id int64) string {
+	return fmt.Sprintf("offline:%d", id)
+}
+
+// offlineStreamURL é a URL local do episódio, se ele estiver baixado
+func (a *App) offlineStreamURL(episodeURL string) (string, bool) {
+	ep := a.lookupOffline(episodeURL)
+	if ep == nil {
+		return "", false
+	}
+	playback, err := a.offlinePlayback(ep)
+	if err != nil {
+		fmt.Printf("[Offline] Erro ao iniciar proxy: %v\n", err)
+		return "", false
+	}
+	return playback.URL, true
+}
+
+// playOffline toca um episódio baixado no MPV, com as legendas locais
+func (a *App) playOffline(ep *store.OfflineEpisode, start float64) error {
+	playback, err := a.offlinePlayback(ep)
+	if err != nil {
+		return err
+	}
+	var extra []string
+	for _, sub := range playback.Subtitles {
+		extra = append(extra, "--sub-file="+sub)
+	}
+	fmt.Printf("[Offline] Tocando do disco: %s\n", ep.Path)
+	return a.playAnimeFrom(playback.URL, start, extra...)
+}
+
+// handleOfflineMedia serve os arquivos da biblioteca offline:
+// /offline/{id}/{nome}?sig=... (vídeo) e /offline/{id}/sub/{n}/{nome}?sig=... (legenda).
+// Só arquivos do índice são servidos, nunca caminhos vindos da URL. Sem CORS: o <video> e o MPV
+// não precisam dele, e páginas de outras origens não devem ler a biblioteca.
+func (a *App) handleOfflineMedia(w http.ResponseWriter, r *http.Request) {
+	if offlineLibrary == nil {
+		http.Error(w, "Biblioteca offline indisponível", http.StatusServiceUnavailable)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/offline/"), "/")
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if !videoProxyGuard.Verify(offlineTarget(id), r.URL.Query().Get("sig")) {
+		videoProxyGuard.Reject(w, r, offlineTarget(id), fmt.Errorf("URL sem assinatura válida"))
+		return
+	}
+	ep, err := offlineLibrary.Get(id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	path := ep.Path
+	if len(parts) >= 3 && parts[1] == "sub" {
+		n, err := strconv.Atoi(parts[2])
+		if err != nil || n < 0 || n >= len(ep.Subtitles) {
+			http.NotFound(w, r)
+			return
+		}
+		path = ep.Subtitles[n]
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, "Erro ao ler arquivo", http.StatusInternalServerError)
+		return
+	}
+
+	if contentType := offlineContentType(path); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	// ServeContent trata Range, If-Range e HEAD
+	http.ServeContent(w, r, filepath.Base(path), info.ModTime(), file)
+}
+
+func offlineContentType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp4", ".m4v":
+		return "video/mp4"
+	case ".mkv":
+		return "video/x-matroska"
+	case ".webm":
+		return "video/webm"
+	case ".ts":
+		return "video/mp2t"
+	case ".vtt":
+		return "text/vtt; charset=utf-8"
+	case ".srt", ".ass", ".ssa":
+		return "text/plain; charset=utf-8"
+	}
+	return ""
+}
+
+// GetOfflineLibrary retorna os episódios baixados agrupados por anime
+func (a *App) GetOfflineLibrary() (*OfflineLibraryInfo, error) {
+	if offlineLibrary == nil {
+		return nil, fmt.Errorf("biblioteca offline indisponível")
+	}
+	animes, total, err := offlineLibrary.List()
+	if err != nil {
+		return nil, err
+	}
+	return &OfflineLibraryInfo{Anime: animes, TotalBytes: total, QuotaBytes: a.offlineRules().QuotaBytes}, nil
+}
+
+// GetOfflinePlayback retorna as URLs locais (vídeo e legendas) de um episódio baixado
+func (a *App) GetOfflinePlayback(id int64) (*OfflinePlayback, error) {
+	if offlineLibrary == nil {
+		return nil, fmt.Errorf("biblioteca offline indisponível")
+	}
+	ep, err := offlineLibrary.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return a.offlinePlayback(ep)
+}
+
+// IsEpisodeOffline indica se o episódio está baixado
+func (a *App) IsEpisodeOffline(episodeURL string) bool {
+	return a.lookupOffline(episodeURL) != nil
+}
+
+// DeleteOfflineEpisode apaga um episódio baixado do disco
+func (a *App) DeleteOfflineEpisode(id int64) error {
+	if offlineLibrary == nil {
+		return fmt.Errorf("biblioteca offline indisponível")
+	}
+	return offlineLibrary.Delete(id)
+}
+
+// CleanupOfflineLibrary aplica agora as regras de limpeza das configurações
+func (a *App) CleanupOfflineLibrary() (offline.CleanupResult, error) {
+	if offlineLibrary == nil {
+		return offline.CleanupResult{}, fmt.Errorf("biblioteca offline indisponível")
+	}
+	return offlineLibrary.Cleanup(a.offlineRules())
+}
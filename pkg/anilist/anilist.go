@@ -23,11 +23,12 @@ var (
 
 // AnimeMedia representa os dados do anime da AniList
 type AnimeMedia struct {
-	ID          int    `json:"id"`
-	MALID       int    `json:"idMal"`
-	Title       Title  `json:"title"`
-	Description string `json:"description"`
-	BannerImage string `json:"bannerImage"`
+	ID          int      `json:"id"`
+	MALID       int      `json:"idMal"`
+	Title       Title    `json:"title"`
+	Synonyms    []string `json:"synonyms"`
+	Description string   `json:"description"`
+	BannerImage string   `json:"bannerImage"`
 	CoverImage  struct {
 		ExtraLarge string `json:"extraLarge"`
 		Large      string `json:"large"`
@@ -48,8 +49,9 @@ type AnimeMedia struct {
 		} `json:"nodes"`
 	} `json:"studios"`
 	NextAiringEpisode *struct {
-		Episode         int `json:"episode"`
-		TimeUntilAiring int `json:"timeUntilAiring"`
+		Episode         int   `json:"episode"`
+		AiringAt        int64 `json:"airingAt"` // Unix timestamp
+		TimeUntilAiring int   `json:"timeUntilAiring"`
 	} `json:"nextAiringEpisode"`
 	Trailer *struct {
 		ID   string `json:"id"`
@@ -108,6 +110,7 @@ query ($search: String, $page: Int, $perPage: Int) {
         english
         native
       }
+      synonyms
       description(asHtml: false)
       bannerImage
       coverImage {
@@ -131,6 +134,7 @@ query ($search: String, $page: Int, $perPage: Int) {
       }
       nextAiringEpisode {
         episode
+        airingAt
         timeUntilAiring
       }
       trailer {
@@ -176,6 +180,7 @@ query ($page: Int, $perPage: Int) {
       }
       nextAiringEpisode {
         episode
+        airingAt
         timeUntilAiring
       }
       trailer {
@@ -221,6 +226,7 @@ query ($page: Int, $perPage: Int) {
       }
       nextAiringEpisode {
         episode
+        airingAt
         timeUntilAiring
       }
       trailer {
@@ -313,8 +319,16 @@ func GetPopular(limit int) ([]*AnimeMedia, error) {
 
 // GetAnimeByMALID busca anime pelo ID do MyAnimeList
 func GetAnimeByMALID(malID int) (*AnimeMedia, error) {
-	cacheKey := fmt.Sprintf("mal:%d", malID)
+	return getMedia("idMal", malID, fmt.Sprintf("mal:%d", malID))
+}
+
+// GetAnimeByID busca anime pelo ID da AniList
+func GetAnimeByID(id int) (*AnimeMedia, error) {
+	return getMedia("id", id, fmt.Sprintf("id:%d", id))
+}
 
+// getMedia busca um único anime pelo campo de ID informado ("id" ou "idMal")
+func getMedia(field string, id int, cacheKey string) (*AnimeMedia, error) {
 	cacheMutex.RLock()
 	if cached, ok := cache[cacheKey]; ok {
 		cacheMutex.RUnlock()
@@ -323,8 +337,8 @@ func GetAnimeByMALID(malID int) (*AnimeMedia, error) {
 	cacheMutex.RUnlock()
 
 	query := `
-	query ($malId: Int) {
-	  Media(idMal: $malId, type: ANIME) {
+	query ($id: Int) {
+	  Media(` + field + `: $id, type: ANIME) {
 		id
 		idMal
 		title {
@@ -332,6 +346,7 @@ func GetAnimeByMALID(malID int) (*AnimeMedia, error) {
 		  english
 		  native
 		}
+		synonyms
 		description(asHtml: false)
 		bannerImage
 		coverImage {
@@ -362,7 +377,7 @@ func GetAnimeByMALID(malID int) (*AnimeMedia, error) {
 	`
 
 	variables := map[string]interface{}{
-		"malId": malID,
+		"id": id,
 	}
 
 	body := map[string]interface{}{
@@ -0,0 +1,342 @@
+package anilist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"GoAnimeGUI/internal/oauth"
+)
+
+// Login OAuth2 (authorization code) e chamadas autenticadas da AniList.
+// O app precisa ser registrado em https://anilist.co/settings/developer
+// com a Redirect URL igual a DefaultRedirectURI.
+
+const (
+	authorizeURL = "https://anilist.co/api/v2/oauth/authorize"
+	tokenURL     = "https://anilist.co/api/v2/oauth/token"
+
+	// DefaultRedirectURI usa uma porta diferente do callback do Discord (9876)
+	DefaultRedirectURI = "http://localhost:9877/callback"
+)
+
+// ErrNotLoggedIn indica que não há conta AniList conectada
+var ErrNotLoggedIn = errors.New("conta AniList não conectada")
+
+// Viewer é o usuário AniList autenticado
+type Viewer struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Avatar string `json:"avatar"`
+}
+
+// authConfig é o conteúdo persistido em anilist.json
+type authConfig struct {
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret"`
+	AccessToken  string    `json:"access_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+	Viewer       *Viewer   `json:"viewer,omitempty"`
+}
+
+// Auth gerencia as credenciais e o token da AniList
+type Auth struct {
+	config      authConfig
+	redirectURI string
+	configPath  string
+	state       string // state OAuth do login em andamento (contra CSRF no callback)
+	mutex       sync.RWMutex
+}
+
+var (
+	authInstance *Auth
+	authOnce     sync.Once
+)
+
+// GetAuth retorna a instância singleton da autenticação AniList.
+// Credenciais vêm de anilist.json ou das variáveis ANILIST_CLIENT_ID/ANILIST_CLIENT_SECRET.
+func GetAuth() *Auth {
+	authOnce.Do(func() {
+		configDir, _ := os.UserConfigDir()
+		authInstance = &Auth{
+			redirectURI: DefaultRedirectURI,
+			configPath:  filepath.Join(configDir, "GoAnime", "anilist.json"),
+		}
+		authInstance.load()
+
+		if authInstance.config.ClientID == "" {
+			authInstance.config.ClientID = os.Getenv("ANILIST_CLIENT_ID")
+		}
+		if authInstance.config.ClientSecret == "" {
+			authInstance.config.ClientSecret = os.Getenv("ANILIST_CLIENT_SECRET")
+		}
+	})
+	return authInstance
+}
+
+// load carrega a configuração do disco
+func (a *Auth) load() {
+	data, err := os.ReadFile(a.configPath)
+	if err != nil {
+		return // Arquivo não existe, não configurado
+	}
+	if err := json.Unmarshal(data, &a.config); err != nil {
+		fmt.Printf("[AniList] Erro ao ler %s: %v\n", a.configPath, err)
+		return
+	}
+	if a.config.Viewer != nil && a.config.AccessToken != "" {
+		fmt.Printf("[AniList] Conta carregada: %s\n", a.config.Viewer.Name)
+	}
+}
+
+// save grava a configuração no disco (chamar com o lock de escrita)
+func (a *Auth) save() error {
+	if err := os.MkdirAll(filepath.Dir(a.configPath), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(a.config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(a.configPath, data, 0600)
+}
+
+// Configure salva as credenciais do aplicativo AniList
+func (a *Auth) Configure(clientID, clientSecret string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.config.ClientID = strings.TrimSpace(clientID)
+	a.config.ClientSecret = strings.TrimSpace(clientSecret)
+	return a.save()
+}
+
+// IsConfigured indica se as credenciais do aplicativo foram definidas
+func (a *Auth) IsConfigured() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.config.ClientID != "" && a.config.ClientSecret != ""
+}
+
+// RedirectURI retorna a URL de callback que deve estar registrada na AniList
+func (a *Auth) RedirectURI() string {
+	return a.redirectURI
+}
+
+// IsLoggedIn indica se há um token válido
+func (a *Auth) IsLoggedIn() bool {
+	return a.token() != ""
+}
+
+// CurrentViewer retorna o usuário conectado (nil se desconectado)
+func (a *Auth) CurrentViewer() *Viewer {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	if a.config.AccessToken == "" {
+		return nil
+	}
+	return a.config.Viewer
+}
+
+// token retorna o access token se ainda for válido
+func (a *Auth) token() string {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	if a.config.AccessToken == "" {
+		return ""
+	}
+	if !a.config.ExpiresAt.IsZero() && time.Now().After(a.config.ExpiresAt) {
+		return ""
+	}
+	return a.config.AccessToken
+}
+
+// AuthURL gera um novo state e retorna a URL de autorização para abrir no navegador
+func (a *Auth) AuthURL() (string, error) {
+	state, err := oauth.RandomString(16)
+	if err != nil {
+		return "", err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.state = state
+
+	params := url.Values{}
+	params.Set("client_id", a.config.ClientID)
+	params.Set("redirect_uri", a.redirectURI)
+	params.Set("response_type", "code")
+	params.Set("state", state)
+	return authorizeURL + "?" + params.Encode(), nil
+}
+
+// Logout remove o token salvo (as credenciais do aplicativo são mantidas)
+func (a *Auth) Logout() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.config.AccessToken = ""
+	a.config.ExpiresAt = time.Time{}
+	a.config.Viewer = nil
+	return a.save()
+}
+
+// WaitForCallback aguarda o código OAuth2 no servidor de callback local (internal/oauth)
+func (a *Auth) WaitForCallback() (string, error) {
+	a.mutex.RLock()
+	state := a.state
+	a.mutex.RUnlock()
+
+	code, err := oauth.WaitForCode(oauth.Callback{
+		Name:         "AniList OAuth",
+		RedirectURI:  a.redirectURI,
+		State:        state,
+		SuccessTitle: "AniList Conectada!",
+		RefusedText:  "A AniList recusou a autorização.",
+	})
+	if err != nil {
+		return "", err
+	}
+	a.mutex.Lock()
+	a.state = ""
+	a.mutex.Unlock()
+	return code, nil
+}
+
+// ExchangeCode troca o código de autorização por um access token e busca o usuário
+func (a *Auth) ExchangeCode(code string) (*Viewer, error) {
+	a.mutex.RLock()
+	body, _ := json.Marshal(map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     a.config.ClientID,
+		"client_secret": a.config.ClientSecret,
+		"redirect_uri":  a.redirectURI,
+		"code":          code,
+	})
+	a.mutex.RUnlock()
+
+	req, err := http.NewRequest("POST", tokenURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao trocar código: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("anilist retornou status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar token: %w", err)
+	}
+
+	viewer, err := fetchViewer(tokenResp.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.config.AccessToken = tokenResp.AccessToken
+	a.config.ExpiresAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	a.config.Viewer = viewer
+	if err := a.save(); err != nil {
+		return nil, fmt.Errorf("erro ao salvar token: %w", err)
+	}
+	return viewer, nil
+}
+
+const viewerQuery = `
+query {
+  Viewer {
+    id
+    name
+    avatar {
+      medium
+    }
+  }
+}
+`
+
+func fetchViewer(token string) (*Viewer, error) {
+	var result struct {
+		Viewer struct {
+			ID     int    `json:"id"`
+			Name   string `json:"name"`
+			Avatar struct {
+				Medium string `json:"medium"`
+			} `json:"avatar"`
+		} `json:"Viewer"`
+	}
+	if err := executeAuthQuery(token, viewerQuery, nil, &result); err != nil {
+		return nil, fmt.Errorf("erro ao buscar usuário: %w", err)
+	}
+	return &Viewer{ID: result.Viewer.ID, Name: result.Viewer.Name, Avatar: result.Viewer.Avatar.Medium}, nil
+}
+
+// executeAuthQuery executa uma query/mutation e decodifica "data" em out.
+// Sem token, a requisição vai sem autenticação (queries públicas).
+func executeAuthQuery(token, query string, variables map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", apiURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return ErrNotLoggedIn
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("limite de requisições da AniList atingido (tente em %ss)", resp.Header.Get("Retry-After"))
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("AniList error: %s", result.Errors[0].Message)
+	}
+	return json.Unmarshal(result.Data, out)
+}
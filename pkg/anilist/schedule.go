@@ -0,0 +1,109 @@
+package anilist
+
+import (
+	"fmt"
+	"time"
+)
+
+// AiringEpisode é um episódio com data de exibição (airingSchedule da AniList)
+type AiringEpisode struct {
+	ID       int   `json:"id"`
+	Episode  int   `json:"episode"`
+	AiringAt int64 `json:"airingAt"` // Unix timestamp
+	MediaID  int   `json:"mediaId"`
+	Media    struct {
+		ID         int    `json:"id"`
+		MALID      int    `json:"idMal"`
+		Title      Title  `json:"title"`
+		Episodes   int    `json:"episodes"`
+		Format     string `json:"format"`
+		IsAdult    bool   `json:"isAdult"`
+		Popularity int    `json:"popularity"`
+		CoverImage struct {
+			Large string `json:"large"`
+			Color string `json:"color"`
+		} `json:"coverImage"`
+	} `json:"media"`
+}
+
+// BestTitle retorna o título em inglês, ou o romaji se não houver
+func (e *AiringEpisode) BestTitle() string {
+	if e.Media.Title.English != "" {
+		return e.Media.Title.English
+	}
+	if e.Media.Title.Romaji != "" {
+		return e.Media.Title.Romaji
+	}
+	return e.Media.Title.Native
+}
+
+const airingScheduleQuery = `
+query ($page: Int, $from: Int, $to: Int, $mediaIds: [Int]) {
+  Page(page: $page, perPage: 50) {
+    pageInfo {
+      hasNextPage
+    }
+    airingSchedules(airingAt_greater: $from, airingAt_lesser: $to, mediaId_in: $mediaIds, sort: TIME) {
+      id
+      episode
+      airingAt
+      mediaId
+      media {
+        id
+        idMal
+        title {
+          romaji
+          english
+          native
+        }
+        episodes
+        format
+        isAdult
+        popularity
+        coverImage {
+          large
+          color
+        }
+      }
+    }
+  }
+}
+`
+
+// maxSchedulePages limita a paginação (uma semana da temporada cabe em poucas páginas)
+const maxSchedulePages = 10
+
+// GetAiringSchedule retorna os episódios que vão ao ar entre from e to, em ordem de horário.
+// Com mediaIDs, só os desses animes; sem, os de todos os animes em exibição.
+func GetAiringSchedule(from, to time.Time, mediaIDs []int) ([]AiringEpisode, error) {
+	variables := map[string]interface{}{
+		"from": from.Unix() - 1, // airingAt_greater é exclusivo
+		"to":   to.Unix(),
+	}
+	if len(mediaIDs) > 0 {
+		variables["mediaIds"] = mediaIDs
+	}
+
+	episodes := []AiringEpisode{}
+	for page := 1; page <= maxSchedulePages; page++ {
+		variables["page"] = page
+
+		var result struct {
+			Page struct {
+				PageInfo struct {
+					HasNextPage bool `json:"hasNextPage"`
+				} `json:"pageInfo"`
+				AiringSchedules []AiringEpisode `json:"airingSchedules"`
+			} `json:"Page"`
+		}
+		if err := executeAuthQuery("", airingScheduleQuery, variables, &result); err != nil {
+			return nil, fmt.Errorf("erro ao buscar calendário da AniList: %w", err)
+		}
+
+		episodes = append(episodes, result.Page.AiringSchedules...)
+		if !result.Page.PageInfo.HasNextPage {
+			break
+		}
+	}
+	return episodes, nil
+}
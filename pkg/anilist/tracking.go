@@ -0,0 +1,151 @@
+package anilist
+
+import (
+	"fmt"
+)
+
+// MediaListStatus é o estado de um anime na lista da AniList
+type MediaListStatus string
+
+const (
+	StatusCurrent   MediaListStatus = "CURRENT"
+	StatusPlanning  MediaListStatus = "PLANNING"
+	StatusCompleted MediaListStatus = "COMPLETED"
+	StatusDropped   MediaListStatus = "DROPPED"
+	StatusPaused    MediaListStatus = "PAUSED"
+	StatusRepeating MediaListStatus = "REPEATING"
+)
+
+// ListEntry é uma entrada da lista de animes do usuário
+type ListEntry struct {
+	ID        int             `json:"id"`
+	MediaID   int             `json:"mediaId"`
+	Status    MediaListStatus `json:"status"`
+	Score     float64         `json:"score"` // Escala 0-10
+	Progress  int             `json:"progress"`
+	UpdatedAt int64           `json:"updatedAt"` // Unix timestamp
+	Media     struct {
+		ID         int   `json:"id"`
+		MALID      int   `json:"idMal"`
+		Title      Title `json:"title"`
+		Episodes   int   `json:"episodes"`
+		CoverImage struct {
+			Large string `json:"large"`
+		} `json:"coverImage"`
+	} `json:"media"`
+}
+
+// ListEntryUpdate são os campos enviados para a AniList; campos zero não são alterados
+type ListEntryUpdate struct {
+	MediaID  int
+	Status   MediaListStatus
+	Score    float64 // 0-10; negativo limpa a nota
+	Progress int
+}
+
+const listEntryFields = `
+    id
+    mediaId
+    status
+    score(format: POINT_10_DECIMAL)
+    progress
+    updatedAt
+    media {
+      id
+      idMal
+      title {
+        romaji
+        english
+        native
+      }
+      episodes
+      coverImage {
+        large
+      }
+    }
+`
+
+const saveEntryMutation = `
+mutation ($mediaId: Int, $status: MediaListStatus, $scoreRaw: Int, $progress: Int) {
+  SaveMediaListEntry(mediaId: $mediaId, status: $status, scoreRaw: $scoreRaw, progress: $progress) {` + listEntryFields + `  }
+}
+`
+
+const animeListQuery = `
+query ($userId: Int) {
+  MediaListCollection(userId: $userId, type: ANIME) {
+    lists {
+      entries {` + listEntryFields + `      }
+    }
+  }
+}
+`
+
+// SaveListEntry cria ou atualiza uma entrada na lista do usuário (SaveMediaListEntry)
+func (a *Auth) SaveListEntry(update ListEntryUpdate) (*ListEntry, error) {
+	token := a.token()
+	if token == "" {
+		return nil, ErrNotLoggedIn
+	}
+	if update.MediaID <= 0 {
+		return nil, fmt.Errorf("media ID inválido: %d", update.MediaID)
+	}
+
+	variables := map[string]interface{}{
+		"mediaId": update.MediaID,
+	}
+	if update.Status != "" {
+		variables["status"] = update.Status
+	}
+	if update.Score > 0 {
+		variables["scoreRaw"] = int(update.Score * 10) // scoreRaw usa a escala 0-100
+	} else if update.Score < 0 {
+		variables["scoreRaw"] = 0
+	}
+	if update.Progress > 0 {
+		variables["progress"] = update.Progress
+	}
+
+	var result struct {
+		SaveMediaListEntry *ListEntry `json:"SaveMediaListEntry"`
+	}
+	if err := executeAuthQuery(token, saveEntryMutation, variables, &result); err != nil {
+		return nil, err
+	}
+	return result.SaveMediaListEntry, nil
+}
+
+// GetAnimeList retorna todas as entradas da lista de animes do usuário conectado
+func (a *Auth) GetAnimeList() ([]ListEntry, error) {
+	token := a.token()
+	viewer := a.CurrentViewer()
+	if token == "" || viewer == nil {
+		return nil, ErrNotLoggedIn
+	}
+
+	var result struct {
+		MediaListCollection struct {
+			Lists []struct {
+				Entries []ListEntry `json:"entries"`
+			} `json:"lists"`
+		} `json:"MediaListCollection"`
+	}
+	variables := map[string]interface{}{"userId": viewer.ID}
+	if err := executeAuthQuery(token, animeListQuery, variables, &result); err != nil {
+		return nil, err
+	}
+
+	// Um anime pode aparecer em listas personalizadas além da lista de status
+	seen := make(map[int]bool)
+	entries := []ListEntry{}
+	for _, list := range result.MediaListCollection.Lists {
+		for _, entry := range list.Entries {
+			if seen[entry.MediaID] {
+				continue
+			}
+			seen[entry.MediaID] = true
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
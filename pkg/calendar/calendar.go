@@ -0,0 +1,184 @@
+// Package calendar monta o calendário semanal de exibição a partir do airingSchedule da AniList,
+// agrupando os episódios por dia no fuso horário do usuário.
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	_ "time/tzdata" // Windows nem sempre tem a base de fusos horários
+
+	"GoAnimeGUI/pkg/anilist"
+)
+
+const (
+	// Days é o tamanho da janela do calendário
+	Days = 7
+
+	cacheTTL = 30 * time.Minute
+)
+
+// Entry é um episódio no calendário
+type Entry struct {
+	MediaID  int    `json:"mediaId"`
+	MALID    int    `json:"malId"`
+	Title    string `json:"title"`
+	Image    string `json:"image"`
+	Color    string `json:"color"`
+	Format   string `json:"format"`
+	Episode  int    `json:"episode"`
+	Episodes int    `json:"episodes"` // Total previsto (0 = desconhecido)
+	AiringAt int64  `json:"airingAt"` // Unix timestamp
+	Time     string `json:"time"`     // "HH:MM" no fuso do calendário
+
+	// Preenchidos quando o anime está na biblioteca
+	AnimeURL string `json:"animeUrl"`
+	Progress int    `json:"progress"`
+}
+
+// Day agrupa os episódios de um dia
+type Day struct {
+	Date    string  `json:"date"`    // "2006-01-02"
+	Weekday int     `json:"weekday"` // 0 = domingo
+	Entries []Entry `json:"entries"`
+}
+
+// Calendar é o calendário de uma janela de dias
+type Calendar struct {
+	TimeZone  string `json:"timeZone"`
+	Days      []Day  `json:"days"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// FetchFunc busca os episódios de uma janela (anilist.GetAiringSchedule; substituível em testes)
+type FetchFunc func(from, to time.Time, mediaIDs []int) ([]anilist.AiringEpisode, error)
+
+type cacheEntry struct {
+	episodes  []anilist.AiringEpisode
+	fetchedAt time.Time
+}
+
+// Service busca e guarda em cache as janelas do calendário
+type Service struct {
+	fetch FetchFunc
+	cache map[string]cacheEntry
+	mutex sync.Mutex
+}
+
+var (
+	defaultService *Service
+	defaultOnce    sync.Once
+)
+
+// New cria o serviço com a função de busca informada
+func New(fetch FetchFunc) *Service {
+	return &Service{fetch: fetch, cache: make(map[string]cacheEntry)}
+}
+
+// Default retorna o serviço do aplicativo, que consulta a AniList
+func Default() *Service {
+	defaultOnce.Do(func() {
+		defaultService = New(anilist.GetAiringSchedule)
+	})
+	return defaultService
+}
+
+// Week retorna o calendário de hoje até Days dias à frente, no fuso loc.
+// Com mediaIDs, só esses animes; sem, toda a temporada em exibição (sem conteúdo adulto).
+func (s *Service) Week(now time.Time, loc *time.Location, mediaIDs []int) (*Calendar, error) {
+	now = now.In(loc)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	to := from.AddDate(0, 0, Days)
+
+	episodes, err := s.episodes(from, to, mediaIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(mediaIDs) == 0 {
+		filtered := episodes[:0:0]
+		for _, ep := range episodes {
+			if !ep.Media.IsAdult {
+				filtered = append(filtered, ep)
+			}
+		}
+		episodes = filtered
+	}
+
+	return &Calendar{
+		TimeZone:  loc.String(),
+		Days:      Group(episodes, from, Days, loc),
+		UpdatedAt: time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// Invalidate descarta o cache (ex.: a biblioteca mudou)
+func (s *Service) Invalidate() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cache = make(map[string]cacheEntry)
+}
+
+func (s *Service) episodes(from, to time.Time, mediaIDs []int) ([]anilist.AiringEpisode, error) {
+	ids := append([]int(nil), mediaIDs...)
+	sort.Ints(ids)
+	key := fmt.Sprintf("%d:%d:%v", from.Unix(), to.Unix(), ids)
+
+	s.mutex.Lock()
+	cached, ok := s.cache[key]
+	s.mutex.Unlock()
+	if ok && time.Since(cached.fetchedAt) < cacheTTL {
+		return cached.episodes, nil
+	}
+
+	episodes, err := s.fetch(from, to, ids)
+	if err != nil {
+		// AniList fora do ar: melhor um calendário um pouco velho do que nenhum
+		if ok {
+			fmt.Printf("[Calendar] Usando cache expirado: %v\n", err)
+			return cached.episodes, nil
+		}
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	s.cache[key] = cacheEntry{episodes: episodes, fetchedAt: time.Now()}
+	s.mutex.Unlock()
+	return episodes, nil
+}
+
+// Group distribui os episódios em days dias a partir de from, pelo horário local em loc.
+// Dias sem episódios aparecem vazios; dentro do dia, a ordem é por horário.
+func Group(episodes []anilist.AiringEpisode, from time.Time, days int, loc *time.Location) []Day {
+	result := make([]Day, days)
+	index := make(map[string]int, days)
+	for i := range result {
+		d := from.In(loc).AddDate(0, 0, i)
+		result[i] = Day{Date: d.Format("2006-01-02"), Weekday: int(d.Weekday()), Entries: []Entry{}}
+		index[result[i].Date] = i
+	}
+
+	sorted := append([]anilist.AiringEpisode(nil), episodes...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].AiringAt < sorted[j].AiringAt })
+
+	for _, ep := range sorted {
+		at := time.Unix(ep.AiringAt, 0).In(loc)
+		i, ok := index[at.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		result[i].Entries = append(result[i].Entries, Entry{
+			MediaID:  ep.MediaID,
+			MALID:    ep.Media.MALID,
+			Title:    ep.BestTitle(),
+			Image:    ep.Media.CoverImage.Large,
+			Color:    ep.Media.CoverImage.Color,
+			Format:   ep.Media.Format,
+			Episode:  ep.Episode,
+			Episodes: ep.Media.Episodes,
+			AiringAt: ep.AiringAt,
+			Time:     at.Format("15:04"),
+		})
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package calendar
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"GoAnimeGUI/pkg/anilist"
+)
+
+func airing(mediaID, episode int, at time.Time, adult bool) anilist.AiringEpisode {
+	var ep anilist.AiringEpisode
+	ep.MediaID = mediaID
+	ep.Episode = episode
+	ep.AiringAt = at.Unix()
+	ep.Media.ID = mediaID
+	ep.Media.Title.Romaji = "Anime"
+	ep.Media.IsAdult = adult
+	return ep
+}
+
+func TestGroup_UsesTimeZone(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	saoPaulo, _ := time.LoadLocation("America/Sao_Paulo")
+
+	// 23:30 UTC de sábado = domingo de manhã em Tóquio, sábado à noite em São Paulo
+	at := time.Date(2026, 10, 17, 23, 30, 0, 0, time.UTC)
+	episodes := []anilist.AiringEpisode{airing(1, 5, at, false)}
+
+	days := Group(episodes, time.Date(2026, 10, 17, 0, 0, 0, 0, tokyo), 3, tokyo)
+	if len(days[0].Entries) != 0 || len(days[1].Entries) != 1 || days[1].Date != "2026-10-18" {
+		t.Errorf("Tokyo days = %+v; want episode on 2026-10-18", days)
+	}
+	if days[1].Entries[0].Time != "08:30" || days[1].Weekday != int(time.Sunday) {
+		t.Errorf("Tokyo entry = %+v (weekday %d); want 08:30 on Sunday", days[1].Entries[0], days[1].Weekday)
+	}
+
+	days = Group(episodes, time.Date(2026, 10, 17, 0, 0, 0, 0, saoPaulo), 3, saoPaulo)
+	if len(days[0].Entries) != 1 || days[0].Entries[0].Time != "20:30" {
+		t.Errorf("São Paulo days = %+v; want episode on 2026-10-17 at 20:30", days)
+	}
+}
+
+func TestService_CacheAndSeasonFilter(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	fetches := 0
+	offline := false
+	s := New(func(from, to time.Time, mediaIDs []int) ([]anilist.AiringEpisode, error) {
+		if offline {
+			return nil, errors.New("offline")
+		}
+		fetches++
+		if !from.Equal(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)) || to.Sub(from) != Days*24*time.Hour {
+			t.Errorf("fetch window = %v - %v", from, to)
+		}
+		return []anilist.AiringEpisode{
+			airing(1, 3, now.Add(2*time.Hour), false),
+			airing(2, 7, now.Add(26*time.Hour), true),
+		}, nil
+	})
+
+	cal, err := s.Week(now, time.UTC, nil)
+	if err != nil {
+		t.Fatalf("Week() error = %v", err)
+	}
+	if len(cal.Days) != Days || len(cal.Days[0].Entries) != 1 || len(cal.Days[1].Entries) != 0 {
+		t.Errorf("season calendar = %+v; want adult entry filtered out", cal.Days[:2])
+	}
+
+	if cal, _ := s.Week(now, time.UTC, []int{2, 1}); len(cal.Days[1].Entries) != 1 {
+		t.Errorf("library calendar = %+v; want both entries", cal.Days[:2])
+	}
+	s.Week(now, time.UTC, []int{1, 2})
+	if fetches != 2 {
+		t.Errorf("fetches = %d; want 2 (ID order must not matter)", fetches)
+	}
+
+	// Sem rede e com cache expirado, o cache antigo ainda é usado
+	for key, entry := range s.cache {
+		entry.fetchedAt = time.Now().Add(-time.Hour)
+		s.cache[key] = entry
+	}
+	offline = true
+	if _, err := s.Week(now, time.UTC, nil); err != nil {
+		t.Errorf("Week() offline with stale cache error = %v", err)
+	}
+}
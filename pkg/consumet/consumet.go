@@ -239,7 +239,11 @@ func GetBestStream(episodeID string, provider string) (string, bool, error) {
 	if err != nil {
 		return "", false, err
 	}
+	return bestSource(info)
+}
 
+// bestSource escolhe a fonte de maior qualidade
+func bestSource(info *StreamingInfo) (string, bool, error) {
 	if len(info.Sources) == 0 {
 		return "", false, fmt.Errorf("nenhuma fonte encontrada")
 	}
@@ -312,13 +316,23 @@ func GetTopAiring(provider string, page int) ([]AnimeInfo, error) {
 // FindAnimeAndGetStream busca um anime e retorna o stream do primeiro episódio
 // Útil como fallback quando o scraper principal falha
 func FindAnimeAndGetStream(title string, episodeNumber int) (string, bool, error) {
+	info, _, err := FindEpisodeSources(title, episodeNumber)
+	if err != nil {
+		return "", false, err
+	}
+	return bestSource(info)
+}
+
+// FindEpisodeSources busca um anime e retorna todas as fontes do episódio,
+// junto com o SubOrDub do anime ("sub" ou "dub") para identificar o idioma
+func FindEpisodeSources(title string, episodeNumber int) (*StreamingInfo, string, error) {
 	// Tenta com Gogoanime primeiro
 	results, err := Search(title, ProviderGogoanime)
 	if err != nil || len(results.Results) == 0 {
 		// Fallback para Zoro
 		results, err = Search(title, ProviderZoro)
 		if err != nil || len(results.Results) == 0 {
-			return "", false, fmt.Errorf("anime não encontrado em nenhum provedor")
+			return nil, "", fmt.Errorf("anime não encontrado em nenhum provedor")
 		}
 	}
 
@@ -333,7 +347,7 @@ func FindAnimeAndGetStream(title string, episodeNumber int) (string, bool, error
 	// Busca detalhes para pegar episódios
 	details, err := GetAnimeInfo(animeID, provider)
 	if err != nil {
-		return "", false, err
+		return nil, "", err
 	}
 
 	// Encontra o episódio desejado
@@ -350,9 +364,13 @@ func FindAnimeAndGetStream(title string, episodeNumber int) (string, bool, error
 		if provider == ProviderGogoanime {
 			episodeID = fmt.Sprintf("%s-episode-%d", animeID, episodeNumber)
 		} else {
-			return "", false, fmt.Errorf("episódio %d não encontrado", episodeNumber)
+			return nil, "", fmt.Errorf("episódio %d não encontrado", episodeNumber)
 		}
 	}
 
-	return GetBestStream(episodeID, provider)
+	info, err := GetEpisodeSources(episodeID, provider)
+	if err != nil {
+		return nil, "", err
+	}
+	return info, strings.ToLower(details.SubOrDub), nil
 }
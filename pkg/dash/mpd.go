@@ -0,0 +1,552 @@
+// Package dash lê manifestos MPEG-DASH (MPD): as representações de vídeo, áudio e legenda,
+// com os segmentos expandidos de SegmentTemplate (com ou sem SegmentTimeline), SegmentList
+// e SegmentBase, e as URLs resolvidas pela cadeia de BaseURL.
+package dash
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"GoAnimeGUI/pkg/hls"
+)
+
+// maxSegments limita a expansão de templates (manifesto malformado ou ao vivo sem fim)
+const maxSegments = 100000
+
+// Segment é um trecho de mídia de uma representação; Range vazio = recurso inteiro
+type Segment struct {
+	URI      string
+	Range    hls.ByteRange
+	Duration float64 // Segundos (0 se desconhecida)
+}
+
+// Representation é uma qualidade de uma faixa (vídeo, áudio ou legenda)
+type Representation struct {
+	ID        string
+	Kind      string // "video", "audio" ou "text"
+	MimeType  string
+	Codecs    string
+	Lang      string
+	Bandwidth int
+	Width     int
+	Height    int
+	Protected bool      // Tem ContentProtection (DRM): o player até toca, mas não dá para baixar
+	Init      *Segment  // Segmento de inicialização (fMP4); nil se não houver
+	Segments  []Segment // Em SegmentBase, um único segmento com o arquivo inteiro
+}
+
+// Manifest é um MPD com as representações de todos os períodos
+type Manifest struct {
+	Dynamic         bool    // type="dynamic": ao vivo, a lista de segmentos muda
+	Duration        float64 // Segundos (mediaPresentationDuration)
+	Representations []Representation
+}
+
+type mpdXML struct {
+	Type     string      `xml:"type,attr"`
+	Duration string      `xml:"mediaPresentationDuration,attr"`
+	BaseURL  []string    `xml:"BaseURL"`
+	Periods  []periodXML `xml:"Period"`
+}
+
+type segmentInfoXML struct {
+	Template *templateXML `xml:"SegmentTemplate"`
+	List     *listXML     `xml:"SegmentList"`
+	Base     *baseXML     `xml:"SegmentBase"`
+}
+
+type periodXML struct {
+	Duration string `xml:"duration,attr"`
+	segmentInfoXML
+	BaseURL        []string        `xml:"BaseURL"`
+	AdaptationSets []adaptationXML `xml:"AdaptationSet"`
+}
+
+type adaptationXML struct {
+	ContentType string `xml:"contentType,attr"`
+	MimeType    string `xml:"mimeType,attr"`
+	Codecs      string `xml:"codecs,attr"`
+	Lang        string `xml:"lang,attr"`
+	Width       int    `xml:"width,attr"`
+	Height      int    `xml:"height,attr"`
+	segmentInfoXML
+	BaseURL           []string            `xml:"BaseURL"`
+	ContentProtection []struct{}          `xml:"ContentProtection"`
+	Representations   []representationXML `xml:"Representation"`
+}
+
+type representationXML struct {
+	ID        string `xml:"id,attr"`
+	Bandwidth int    `xml:"bandwidth,attr"`
+	Width     int    `xml:"width,attr"`
+	Height    int    `xml:"height,attr"`
+	MimeType  string `xml:"mimeType,attr"`
+	Codecs    string `xml:"codecs,attr"`
+	segmentInfoXML
+	BaseURL           []string   `xml:"BaseURL"`
+	ContentProtection []struct{} `xml:"ContentProtection"`
+}
+
+// templateXML usa ponteiros para saber o que foi declarado e herdar o resto do nível de cima
+type templateXML struct {
+	Media          *string      `xml:"media,attr"`
+	Initialization *string      `xml:"initialization,attr"`
+	Timescale      *uint64      `xml:"timescale,attr"`
+	Duration       *uint64      `xml:"duration,attr"`
+	StartNumber    *int64       `xml:"startNumber,attr"`
+	Timeline       *timelineXML `xml:"SegmentTimeline"`
+}
+
+type timelineXML struct {
+	S []struct {
+		T *uint64 `xml:"t,attr"`
+		D uint64  `xml:"d,attr"`
+		R int64   `xml:"r,attr"`
+	} `xml:"S"`
+}
+
+type listXML struct {
+	Timescale      *uint64  `xml:"timescale,attr"`
+	Duration       *uint64  `xml:"duration,attr"`
+	Initialization *urlXML  `xml:"Initialization"`
+	SegmentURLs    []urlXML `xml:"SegmentURL"`
+}
+
+type baseXML struct {
+	Initialization *urlXML `xml:"Initialization"`
+}
+
+// urlXML serve para Initialization (sourceURL/range) e SegmentURL (media/mediaRange)
+type urlXML struct {
+	SourceURL  string `xml:"sourceURL,attr"`
+	Range      string `xml:"range,attr"`
+	Media      string `xml:"media,attr"`
+	MediaRange string `xml:"mediaRange,attr"`
+}
+
+// Parse lê um manifesto. URLs relativas são resolvidas contra base e as BaseURL do MPD.
+func Parse(body []byte, base *url.URL) (*Manifest, error) {
+	var doc mpdXML
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("manifesto DASH inválido: %w", err)
+	}
+	if len(doc.Periods) == 0 {
+		return nil, fmt.Errorf("manifesto DASH sem períodos")
+	}
+
+	m := &Manifest{Dynamic: doc.Type == "dynamic"}
+	m.Duration, _ = ParseDuration(doc.Duration)
+	mpdBase := withBaseURL(base, doc.BaseURL)
+
+	// Representações com o mesmo id em períodos seguidos continuam a mesma faixa
+	byID := make(map[string]int)
+	for i, period := range doc.Periods {
+		periodDuration, _ := ParseDuration(period.Duration)
+		if periodDuration == 0 && len(doc.Periods) == 1 {
+			periodDuration = m.Duration
+		}
+		periodBase := withBaseURL(mpdBase, period.BaseURL)
+
+		for _, set := range period.AdaptationSets {
+			setBase := withBaseURL(periodBase, set.BaseURL)
+			setInfo := period.segmentInfoXML.merge(set.segmentInfoXML)
+
+			for _, rx := range set.Representations {
+				rep := Representation{
+					ID:        rx.ID,
+					MimeType:  firstNonEmpty(rx.MimeType, set.MimeType),
+					Codecs:    firstNonEmpty(rx.Codecs, set.Codecs),
+					Lang:      set.Lang,
+					Bandwidth: rx.Bandwidth,
+					Width:     firstNonZero(rx.Width, set.Width),
+					Height:    firstNonZero(rx.Height, set.Height),
+					Protected: len(set.ContentProtection)+len(rx.ContentProtection) > 0,
+				}
+				rep.Kind = kindOf(set.ContentType, rep.MimeType, rep.Codecs)
+
+				repBase := withBaseURL(setBase, rx.BaseURL)
+				if err := rep.expand(setInfo.merge(rx.segmentInfoXML), repBase, periodDuration); err != nil {
+					return nil, fmt.Errorf("representação %s: %w", rx.ID, err)
+				}
+
+				if j, ok := byID[rep.ID]; ok && i > 0 && rep.ID != "" {
+					m.Representations[j].Segments = append(m.Representations[j].Segments, rep.Segments...)
+					continue
+				}
+				byID[rep.ID] = len(m.Representations)
+				m.Representations = append(m.Representations, rep)
+			}
+		}
+	}
+	return m, nil
+}
+
+// merge combina as informações de segmento de um nível com as do nível de baixo (que prevalecem)
+func (parent segmentInfoXML) merge(child segmentInfoXML) segmentInfoXML {
+	merged := child
+	if parent.Template != nil && child.Template != nil {
+		t := *parent.Template
+		if child.Template.Media != nil {
+			t.Media = child.Template.Media
+		}
+		if child.Template.Initialization != nil {
+			t.Initialization = child.Template.Initialization
+		}
+		if child.Template.Timescale != nil {
+			t.Timescale = child.Template.Timescale
+		}
+		if child.Template.Duration != nil {
+			t.Duration = child.Template.Duration
+		}
+		if child.Template.StartNumber != nil {
+			t.StartNumber = child.Template.StartNumber
+		}
+		if child.Template.Timeline != nil {
+			t.Timeline = child.Template.Timeline
+		}
+		merged.Template = &t
+	}
+	if child.Template == nil && child.List == nil && child.Base == nil {
+		merged = parent
+	}
+	return merged
+}
+
+// expand preenche Init e Segments a partir da forma de endereçamento declarada
+func (rep *Representation) expand(info segmentInfoXML, base *url.URL, periodDuration float64) error {
+	baseURL := ""
+	if base != nil {
+		baseURL = base.String()
+	}
+
+	switch {
+	case info.Template != nil:
+		return rep.expandTemplate(info.Template, base, periodDuration)
+
+	case info.List != nil:
+		timescale := max(uintOr(info.List.Timescale, 1), 1)
+		duration := float64(uintOr(info.List.Duration, 0)) / float64(timescale)
+		if init := info.List.Initialization; init != nil {
+			seg, err := segmentFor(base, baseURL, init.SourceURL, init.Range)
+			if err != nil {
+				return err
+			}
+			rep.Init = &seg
+		}
+		for _, su := range info.List.SegmentURLs {
+			seg, err := segmentFor(base, baseURL, su.Media, su.MediaRange)
+			if err != nil {
+				return err
+			}
+			seg.Duration = duration
+			rep.Segments = append(rep.Segments, seg)
+		}
+		return nil
+
+	default:
+		// SegmentBase ou só BaseURL: o arquivo inteiro é a mídia (o init está no começo dele)
+		if baseURL == "" {
+			return fmt.Errorf("sem BaseURL nem segmentos")
+		}
+		rep.Segments = []Segment{{URI: baseURL, Duration: periodDuration}}
+		return nil
+	}
+}
+
+// expandTemplate gera os segmentos de um SegmentTemplate
+func (rep *Representation) expandTemplate(t *templateXML, base *url.URL, periodDuration float64) error {
+	timescale := max(uintOr(t.Timescale, 1), 1)
+	number := intOr(t.StartNumber, 1)
+
+	if t.Initialization != nil && *t.Initialization != "" {
+		rep.Init = &Segment{URI: resolve(base, rep.fill(*t.Initialization, number, 0))}
+	}
+	if t.Media == nil || *t.Media == "" {
+		return fmt.Errorf("SegmentTemplate sem media")
+	}
+	media := *t.Media
+
+	add := func(start, duration uint64) error {
+		if len(rep.Segments) >= maxSegments {
+			return fmt.Errorf("mais de %d segmentos", maxSegments)
+		}
+		rep.Segments = append(rep.Segments, Segment{
+			URI:      resolve(base, rep.fill(media, number, start)),
+			Duration: float64(duration) / float64(timescale),
+		})
+		number++
+		return nil
+	}
+
+	if t.Timeline != nil {
+		var start uint64
+		end := uint64(periodDuration * float64(timescale))
+		for i, s := range t.Timeline.S {
+			if s.T != nil {
+				start = *s.T
+			}
+			if s.D == 0 {
+				continue
+			}
+			repeat := s.R
+			if repeat < 0 {
+				// r=-1: repete até o próximo S ou o fim do período
+				limit := end
+				if i+1 < len(t.Timeline.S) && t.Timeline.S[i+1].T != nil {
+					limit = *t.Timeline.S[i+1].T
+				}
+				repeat = 0
+				if limit > start {
+					repeat = int64((limit-start+s.D-1)/s.D) - 1
+				}
+			}
+			for r := int64(0); r <= repeat; r++ {
+				if err := add(start, s.D); err != nil {
+					return err
+				}
+				start += s.D
+			}
+		}
+		return nil
+	}
+
+	duration := uintOr(t.Duration, 0)
+	if duration == 0 {
+		return fmt.Errorf("SegmentTemplate sem duration nem SegmentTimeline")
+	}
+	if periodDuration <= 0 {
+		// Ao vivo ou duração desconhecida: não há como saber quantos segmentos existem
+		return nil
+	}
+	count := int(math.Ceil(periodDuration * float64(timescale) / float64(duration)))
+	for i := 0; i < count; i++ {
+		if err := add(uint64(i)*duration, duration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateVar casa os identificadores de template: $Number$, $Number%05d$, $Time$, $$...
+var templateVar = regexp.MustCompile(`\$(RepresentationID|Number|Bandwidth|Time)?(%0\d+d)?\$`)
+
+// fill substitui os identificadores do template para um segmento
+func (rep *Representation) fill(template string, number int64, time uint64) string {
+	return templateVar.ReplaceAllStringFunc(template, func(match string) string {
+		parts := templateVar.FindStringSubmatch(match)
+		format := "%d"
+		if parts[2] != "" {
+			format = parts[2]
+		}
+		switch parts[1] {
+		case "":
+			return "$"
+		case "RepresentationID":
+			return rep.ID
+		case "Number":
+			return fmt.Sprintf(format, number)
+		case "Bandwidth":
+			return fmt.Sprintf(format, rep.Bandwidth)
+		default:
+			return fmt.Sprintf(format, time)
+		}
+	})
+}
+
+// SelectVideo escolhe a representação de vídeo para a qualidade desejada, com as mesmas
+// regras de hls.Playlist.SelectVariant ("auto" = maior bitrate; senão a maior até a altura pedida)
+func (m *Manifest) SelectVideo(quality string) *Representation {
+	videos := m.byKind("video")
+	if len(videos) == 0 {
+		return nil
+	}
+	sort.SliceStable(videos, func(i, j int) bool {
+		if videos[i].Height != videos[j].Height {
+			return videos[i].Height > videos[j].Height
+		}
+		return videos[i].Bandwidth > videos[j].Bandwidth
+	})
+
+	target := hls.QualityHeight(quality)
+	if target == 0 {
+		best := videos[0]
+		for _, v := range videos[1:] {
+			if v.Bandwidth > best.Bandwidth {
+				best = v
+			}
+		}
+		return &best
+	}
+	for _, v := range videos {
+		if v.Height > 0 && v.Height <= target {
+			return &v
+		}
+	}
+	return &videos[len(videos)-1]
+}
+
+// SelectAudio escolhe a faixa de áudio separada de maior bitrate, preferindo o idioma lang
+// ("ja", "pt"...; vazio = qualquer). Retorna nil se o áudio vier junto com o vídeo.
+func (m *Manifest) SelectAudio(lang string) *Representation {
+	var best *Representation
+	for _, a := range m.byKind("audio") {
+		better := best == nil || a.Bandwidth > best.Bandwidth
+		if lang != "" && best != nil {
+			aLang, bestLang := matchesLang(a.Lang, lang), matchesLang(best.Lang, lang)
+			if aLang != bestLang {
+				better = aLang
+			}
+		}
+		if better {
+			best = &a
+		}
+	}
+	return best
+}
+
+func (m *Manifest) byKind(kind string) []Representation {
+	var found []Representation
+	for _, rep := range m.Representations {
+		if rep.Kind == kind && len(rep.Segments) > 0 {
+			found = append(found, rep)
+		}
+	}
+	return found
+}
+
+func matchesLang(lang, want string) bool {
+	lang, want = strings.ToLower(lang), strings.ToLower(want)
+	return lang == want || strings.HasPrefix(lang, want+"-")
+}
+
+// kindOf deduz o tipo da faixa por contentType, mimeType ou codecs
+func kindOf(contentType, mimeType, codecs string) string {
+	for _, kind := range []string{"video", "audio", "text"} {
+		if contentType == kind || strings.HasPrefix(mimeType, kind+"/") {
+			return kind
+		}
+	}
+	codecs = strings.ToLower(codecs)
+	switch {
+	case strings.Contains(mimeType, "ttml"), strings.Contains(mimeType, "vtt"),
+		strings.HasPrefix(codecs, "stpp"), strings.HasPrefix(codecs, "wvtt"):
+		return "text"
+	case strings.HasPrefix(codecs, "mp4a"), strings.HasPrefix(codecs, "opus"), strings.HasPrefix(codecs, "ac-3"), strings.HasPrefix(codecs, "ec-3"):
+		return "audio"
+	}
+	return "video"
+}
+
+// segmentFor monta um segmento de SegmentList/Initialization; URL vazia aponta para a BaseURL
+func segmentFor(base *url.URL, baseURL, ref, byteRange string) (Segment, error) {
+	seg := Segment{URI: baseURL}
+	if ref != "" {
+		seg.URI = resolve(base, ref)
+	}
+	if byteRange != "" {
+		r, err := ParseRange(byteRange)
+		if err != nil {
+			return seg, err
+		}
+		seg.Range = r
+	}
+	return seg, nil
+}
+
+// ParseRange lê um byte range do DASH ("início-fim", inclusivo)
+func ParseRange(value string) (hls.ByteRange, error) {
+	first, last, ok := strings.Cut(strings.TrimSpace(value), "-")
+	start, err1 := strconv.ParseInt(first, 10, 64)
+	end, err2 := strconv.ParseInt(last, 10, 64)
+	if !ok || err1 != nil || err2 != nil || end < start {
+		return hls.ByteRange{}, fmt.Errorf("byte range inválido: %s", value)
+	}
+	return hls.ByteRange{Offset: start, Length: end - start + 1}, nil
+}
+
+// durationPattern casa durações ISO 8601 (P1DT2H3M4.5S)
+var durationPattern = regexp.MustCompile(`^P(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// ParseDuration converte uma duração ISO 8601 em segundos (vazio = 0)
+func ParseDuration(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	parts := durationPattern.FindStringSubmatch(value)
+	if parts == nil {
+		return 0, fmt.Errorf("duração inválida: %s", value)
+	}
+	var seconds float64
+	for i, unit := range []float64{86400, 3600, 60, 1} {
+		if parts[i+1] != "" {
+			n, _ := strconv.ParseFloat(parts[i+1], 64)
+			seconds += n * unit
+		}
+	}
+	return seconds, nil
+}
+
+// withBaseURL aplica a primeira BaseURL do nível (as demais são espelhos) sobre a de cima
+func withBaseURL(parent *url.URL, baseURLs []string) *url.URL {
+	if len(baseURLs) == 0 || strings.TrimSpace(baseURLs[0]) == "" {
+		return parent
+	}
+	ref, err := url.Parse(strings.TrimSpace(baseURLs[0]))
+	if err != nil {
+		return parent
+	}
+	if parent == nil {
+		return ref
+	}
+	return parent.ResolveReference(ref)
+}
+
+func resolve(base *url.URL, ref string) string {
+	if base == nil || ref == "" {
+		return ref
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
+
+func uintOr(p *uint64, fallback uint64) uint64 {
+	if p == nil {
+		return fallback
+	}
+	return *p
+}
+
+func intOr(p *int64, fallback int64) int64 {
+	if p == nil {
+		return fallback
+	}
+	return *p
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func firstNonZero(values ...int) int {
+	for _, v := range values {
+		if v != 0 {
+			return v
+		}
+	}
+	return 0
+}
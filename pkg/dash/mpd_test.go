@@ -0,0 +1,158 @@
+package dash
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const templateMPD = `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" type="static" mediaPresentationDuration="PT0H0M9.5S">
+  <BaseURL>media/</BaseURL>
+  <Period>
+    <AdaptationSet mimeType="video/mp4" segmentAlignment="true">
+      <SegmentTemplate timescale="1000" initialization="$RepresentationID$/init.mp4" media="$RepresentationID$/seg-$Number%03d$.m4s?tok=a&amp;b=1" startNumber="0">
+        <SegmentTimeline>
+          <S t="0" d="4000" r="1"/>
+          <S d="1500"/>
+        </SegmentTimeline>
+      </SegmentTemplate>
+      <Representation id="v720" bandwidth="2500000" width="1280" height="720"/>
+      <Representation id="v1080" bandwidth="5000000" width="1920" height="1080"/>
+      <Representation id="v360" bandwidth="600000" width="640" height="360"/>
+    </AdaptationSet>
+    <AdaptationSet contentType="audio" mimeType="audio/mp4" lang="ja">
+      <Representation id="a1" bandwidth="128000">
+        <SegmentTemplate timescale="48000" duration="192000" initialization="https://audio.example/$Bandwidth$/init.mp4" media="https://audio.example/$Bandwidth$/$Time$.m4s"/>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`
+
+func TestParse_TemplateAndSelect(t *testing.T) {
+	base, _ := url.Parse("https://cdn.example/ep1/manifest.mpd?sig=x")
+	m, err := Parse([]byte(templateMPD), base)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if m.Dynamic || m.Duration != 9.5 || len(m.Representations) != 4 {
+		t.Fatalf("manifest = %+v; want static 9.5s with 4 representations", m)
+	}
+
+	v := m.SelectVideo("720p")
+	if v == nil || v.ID != "v720" {
+		t.Fatalf("SelectVideo(720p) = %+v", v)
+	}
+	if v.Init == nil || v.Init.URI != "https://cdn.example/ep1/media/v720/init.mp4" {
+		t.Errorf("init = %+v", v.Init)
+	}
+	want := []string{"seg-000.m4s", "seg-001.m4s", "seg-002.m4s"}
+	if len(v.Segments) != len(want) {
+		t.Fatalf("segments = %+v; want %d", v.Segments, len(want))
+	}
+	for i, name := range want {
+		if got := v.Segments[i].URI; got != "https://cdn.example/ep1/media/v720/"+name+"?tok=a&b=1" {
+			t.Errorf("segment[%d] = %q", i, got)
+		}
+	}
+	if v.Segments[2].Duration != 1.5 {
+		t.Errorf("last segment duration = %v; want 1.5", v.Segments[2].Duration)
+	}
+	for quality, want := range map[string]string{"auto": "v1080", "1080p": "v1080", "480p": "v360", "240p": "v360"} {
+		if got := m.SelectVideo(quality); got.ID != want {
+			t.Errorf("SelectVideo(%q) = %s; want %s", quality, got.ID, want)
+		}
+	}
+
+	// Sem SegmentTimeline: quantidade de segmentos pela duração do período (9,5s / 4s = 3)
+	a := m.SelectAudio("ja")
+	if a == nil || a.ID != "a1" || len(a.Segments) != 3 {
+		t.Fatalf("SelectAudio() = %+v", a)
+	}
+	if a.Init.URI != "https://audio.example/128000/init.mp4" || a.Segments[2].URI != "https://audio.example/128000/384000.m4s" {
+		t.Errorf("audio init = %q, segment[2] = %q", a.Init.URI, a.Segments[2].URI)
+	}
+}
+
+func TestParse_SegmentListAndBase(t *testing.T) {
+	base, _ := url.Parse("https://cdn.example/ep2/manifest.mpd")
+	m, err := Parse([]byte(`<MPD mediaPresentationDuration="PT24M">
+  <Period>
+    <AdaptationSet mimeType="video/mp4">
+      <Representation id="list" bandwidth="1000" height="480">
+        <BaseURL>https://other.example/files/video.mp4</BaseURL>
+        <SegmentList timescale="10" duration="40">
+          <Initialization range="0-799"/>
+          <SegmentURL mediaRange="800-1799"/>
+          <SegmentURL media="part2.mp4" mediaRange="0-99"/>
+        </SegmentList>
+      </Representation>
+      <Representation id="single" bandwidth="2000" height="720">
+        <BaseURL>single.mp4</BaseURL>
+        <SegmentBase indexRange="800-1200"><Initialization range="0-799"/></SegmentBase>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`), base)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	list := m.Representations[0]
+	if list.Init == nil || list.Init.URI != "https://other.example/files/video.mp4" || list.Init.Range.Length != 800 {
+		t.Errorf("list init = %+v", list.Init)
+	}
+	if len(list.Segments) != 2 || list.Segments[0].Range.Offset != 800 || list.Segments[0].Range.Length != 1000 ||
+		list.Segments[1].URI != "https://other.example/files/part2.mp4" || list.Segments[1].Duration != 4 {
+		t.Errorf("list segments = %+v", list.Segments)
+	}
+
+	single := m.Representations[1]
+	if single.Init != nil || len(single.Segments) != 1 || single.Segments[0].URI != "https://cdn.example/ep2/single.mp4" {
+		t.Errorf("single = %+v; want the whole file as one segment", single)
+	}
+	if m.SelectAudio("") != nil {
+		t.Error("SelectAudio() should be nil without a separate audio track")
+	}
+}
+
+func TestRewrite_ProxiesEveryURL(t *testing.T) {
+	base, _ := url.Parse("https://cdn.example/ep1/manifest.mpd?sig=x")
+	var seen []string
+	out, err := Rewrite([]byte(templateMPD), base, func(uri string) string {
+		seen = append(seen, uri)
+		return "http://127.0.0.1:9/p/" + uri
+	})
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	got := string(out)
+
+	for _, want := range []string{
+		`<BaseURL>http://127.0.0.1:9/p/https://cdn.example/ep1/media/</BaseURL>`,
+		`initialization="http://127.0.0.1:9/p/https://cdn.example/ep1/media/$RepresentationID$/init.mp4"`,
+		`media="http://127.0.0.1:9/p/https://cdn.example/ep1/media/$RepresentationID$/seg-$Number%03d$.m4s?tok=a&amp;b=1"`,
+		`media="http://127.0.0.1:9/p/https://audio.example/$Bandwidth$/$Time$.m4s"`,
+		`<S t="0" d="4000" r="1"/>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rewritten manifest missing %s\n%s", want, got)
+		}
+	}
+	if len(seen) != 5 {
+		t.Errorf("fn called for %d URLs; want 5: %v", len(seen), seen)
+	}
+
+	// O manifesto reescrito continua válido e aponta tudo para o proxy
+	m, err := Parse(out, nil)
+	if err != nil {
+		t.Fatalf("Parse(rewritten) error = %v", err)
+	}
+	for _, rep := range m.Representations {
+		for _, seg := range append(rep.Segments, *rep.Init) {
+			if !strings.HasPrefix(seg.URI, "http://127.0.0.1:9/p/") {
+				t.Errorf("%s: segment %q not proxied", rep.ID, seg.URI)
+			}
+		}
+	}
+}
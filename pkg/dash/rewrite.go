@@ -0,0 +1,175 @@
+package dash
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// uriAttrs são os atributos com URL de cada elemento do MPD
+var uriAttrs = map[string][]string{
+	"SegmentTemplate":     {"media", "initialization", "index"},
+	"SegmentURL":          {"media", "index"},
+	"Initialization":      {"sourceURL"},
+	"RepresentationIndex": {"sourceURL"},
+}
+
+// attrPatterns localizam cada atributo de uriAttrs no texto cru de uma tag
+var attrPatterns = func() map[string]*regexp.Regexp {
+	patterns := make(map[string]*regexp.Regexp)
+	for _, attrs := range uriAttrs {
+		for _, name := range attrs {
+			patterns[name] = regexp.MustCompile(`\s` + name + `\s*=\s*("[^"]*"|'[^']*')`)
+		}
+	}
+	return patterns
+}()
+
+// edit é um trecho do manifesto original a ser trocado
+type edit struct {
+	start, end int64
+	text       string
+}
+
+// Rewrite troca todas as URLs do manifesto pelo retorno de fn: o texto dos BaseURL e os atributos
+// media, initialization, index e sourceURL. As URLs chegam resolvidas contra a BaseURL em vigor
+// (ou base), com os identificadores de template ($Number$, $Time$...) intactos; fn deve preservá-los.
+// Esquemas que não são http(s) ficam como estão. O resto do XML é mantido byte a byte.
+func Rewrite(body []byte, base *url.URL, fn func(uri string) string) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+
+	type frame struct {
+		name string
+		base *url.URL
+	}
+	var (
+		stack     []frame
+		edits     []edit
+		foundMPD  bool
+		textStart int64 = -1 // Início do texto do BaseURL aberto
+		text      strings.Builder
+	)
+	current := func() *url.URL {
+		if len(stack) == 0 {
+			return base
+		}
+		return stack[len(stack)-1].base
+	}
+	replace := func(ref string, against *url.URL) (string, bool) {
+		uri := resolve(against, strings.TrimSpace(ref))
+		if !strings.HasPrefix(uri, "http://") && !strings.HasPrefix(uri, "https://") {
+			return "", false
+		}
+		return fn(uri), true
+	}
+
+	for {
+		start := decoder.InputOffset()
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("manifesto DASH inválido: %w", err)
+		}
+		end := decoder.InputOffset()
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			if name == "MPD" {
+				foundMPD = true
+			}
+			stack = append(stack, frame{name: name, base: current()})
+			if name == "BaseURL" {
+				textStart = end
+				text.Reset()
+			}
+			for _, attr := range uriAttrs[name] {
+				for _, a := range t.Attr {
+					if a.Name.Local != attr || a.Value == "" {
+						continue
+					}
+					if value, ok := replace(a.Value, current()); ok {
+						if e, ok := attributeEdit(body[start:end], start, attr, value); ok {
+							edits = append(edits, e)
+						}
+					}
+				}
+			}
+
+		case xml.CharData:
+			if textStart >= 0 {
+				text.Write(t)
+			}
+
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("manifesto DASH inválido: </%s> sem abertura", t.Name.Local)
+			}
+			closed := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if closed.name != "BaseURL" || textStart < 0 {
+				continue
+			}
+			// A BaseURL vale para o elemento pai e o que vem depois dele
+			ref := strings.TrimSpace(text.String())
+			if value, ok := replace(ref, current()); ok {
+				edits = append(edits, edit{start: textStart, end: start, text: escape(value)})
+				if len(stack) > 0 {
+					stack[len(stack)-1].base = withBaseURL(current(), []string{ref})
+				}
+			}
+			textStart = -1
+		}
+	}
+	if !foundMPD {
+		return nil, fmt.Errorf("manifesto DASH inválido: falta <MPD>")
+	}
+
+	// Os atributos de uma mesma tag entram na ordem de uriAttrs, não na do texto
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var out bytes.Buffer
+	var last int64
+	for _, e := range edits {
+		out.Write(body[last:e.start])
+		out.WriteString(e.text)
+		last = e.end
+	}
+	out.Write(body[last:])
+	return out.Bytes(), nil
+}
+
+// attributeEdit localiza o valor do atributo no texto cru da tag (que começa em offset)
+func attributeEdit(tag []byte, offset int64, name, value string) (edit, bool) {
+	loc := attrPatterns[name].FindSubmatchIndex(tag)
+	if loc == nil {
+		return edit{}, false
+	}
+	// Troca só o conteúdo entre as aspas
+	return edit{start: offset + int64(loc[2]) + 1, end: offset + int64(loc[3]) - 1, text: escape(value)}, true
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// IsManifest diz se o conteúdo parece um MPD, pelo Content-Type, pela extensão ou pelo começo do corpo
+func IsManifest(contentType, rawURL string, head []byte) bool {
+	if strings.Contains(strings.ToLower(contentType), "dash+xml") {
+		return true
+	}
+	if parsed, err := url.Parse(rawURL); err == nil && strings.HasSuffix(strings.ToLower(parsed.Path), ".mpd") {
+		return true
+	}
+	return bytes.Contains(head, []byte("<MPD"))
+}
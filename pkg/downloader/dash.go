@@ -0,0 +1,162 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"GoAnimeGUI/pkg/dash"
+	"GoAnimeGUI/pkg/hls"
+	"GoAnimeGUI/pkg/retry"
+	"GoAnimeGUI/pkg/store"
+)
+
+// fetchDASH baixa um stream DASH: escolhe a representação de vídeo pela qualidade configurada e
+// a melhor faixa de áudio separada, baixa os segmentos das duas em paralelo (guardados em
+// <arquivo>.dash para retomar) e junta tudo num MP4 com o ffmpeg.
+func (m *Manager) fetchDASH(ctx context.Context, d *store.Download, src *Source) error {
+	manifest, err := m.fetchManifest(ctx, src.URL, src.Header)
+	if err != nil {
+		return err
+	}
+	if manifest.Dynamic {
+		return retry.Permanentf("transmissão DASH ao vivo não suportada")
+	}
+	video := manifest.SelectVideo(m.quality())
+	if video == nil {
+		return retry.Permanentf("manifesto DASH sem vídeo")
+	}
+	audio := manifest.SelectAudio("")
+	if video.Protected || (audio != nil && audio.Protected) {
+		return retry.Permanentf("stream DASH com DRM não suportado")
+	}
+	fmt.Printf("[Downloads] DASH: representação %s %dp (%d bps) para %s\n", video.ID, video.Height, video.Bandwidth, d.Key)
+
+	// Arquivo único com o áudio junto (SegmentBase): é um download comum, com retomada
+	if audio == nil && video.Init == nil && len(video.Segments) == 1 && video.Segments[0].Range.Length == 0 {
+		return m.fetchFile(ctx, d, &Source{URL: video.Segments[0].URI, Header: src.Header})
+	}
+
+	// As faixas vão numa lista só (init e segmentos de cada uma, na ordem) para um progresso único
+	tracks := []*dash.Representation{video}
+	if audio != nil {
+		tracks = append(tracks, audio)
+	}
+	var segments []hls.Segment
+	counts := make([]int, len(tracks))
+	for i, track := range tracks {
+		parts := track.Segments
+		if track.Init != nil {
+			parts = append([]dash.Segment{*track.Init}, parts...)
+		}
+		for _, seg := range parts {
+			segments = append(segments, hls.Segment{URI: seg.URI, Range: seg.Range})
+		}
+		counts[i] = len(parts)
+	}
+
+	workDir := d.Path + ".dash"
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return retry.Permanentf("erro ao criar pasta temporária: %w", err)
+	}
+	if err := m.fetchSegments(ctx, d, segments, src.Header, workDir); err != nil {
+		return err
+	}
+	return m.assembleTracks(ctx, d, counts, true, workDir)
+}
+
+// assembleTracks junta os segmentos de cada faixa (DASH, ou HLS com áudio separado) e, havendo
+// áudio separado, une as faixas com o ffmpeg. Sem ffmpeg (ou se ele falhar), faixas em MP4
+// fragmentado ficam com o áudio num .m4a ao lado do vídeo; em MPEG-TS o download falha.
+func (m *Manager) assembleTracks(ctx context.Context, d *store.Download, counts []int, fragmented bool, workDir string) error {
+	var tracks []string
+	first := 0
+	for i, count := range counts {
+		track := filepath.Join(workDir, fmt.Sprintf("track-%d.mp4", i))
+		out, err := os.Create(track)
+		if err != nil {
+			return retry.Permanentf("erro ao juntar segmentos: %w", err)
+		}
+		for j := first; j < first+count; j++ {
+			if err := appendFile(out, segmentPath(workDir, j)); err != nil {
+				out.Close()
+				return retry.Permanentf("erro ao juntar segmentos: %w", err)
+			}
+		}
+		if err := out.Close(); err != nil {
+			return retry.Permanentf("erro ao juntar segmentos: %w", err)
+		}
+		tracks = append(tracks, track)
+		first += count
+	}
+
+	var err error
+	switch {
+	case len(tracks) == 1:
+		err = os.Rename(tracks[0], d.Path)
+	case m.ffmpegPath != "":
+		muxed := filepath.Join(workDir, "mux.mp4")
+		cmd := exec.CommandContext(ctx, m.ffmpegPath, "-y", "-i", tracks[0], "-i", tracks[1],
+			"-map", "0:v:0", "-map", "1:a:0", "-c", "copy", "-movflags", "+faststart", muxed)
+		if output, ffErr := cmd.CombinedOutput(); ffErr != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if !fragmented {
+				return retry.Permanentf("ffmpeg falhou ao unir o áudio: %v - %s", ffErr, lastLine(output))
+			}
+			fmt.Printf("[Downloads] ffmpeg falhou, salvando o áudio separado: %v - %s\n", ffErr, lastLine(output))
+			err = keepTracksApart(tracks, d.Path)
+		} else {
+			err = os.Rename(muxed, d.Path)
+		}
+	default:
+		fmt.Printf("[Downloads] ffmpeg não encontrado, salvando o áudio separado de %s\n", d.Key)
+		err = keepTracksApart(tracks, d.Path)
+	}
+	if err != nil {
+		return retry.Permanentf("erro ao finalizar arquivo: %w", err)
+	}
+	m.finishAssembled(d, d.Path, workDir)
+	return nil
+}
+
+// keepTracksApart salva o vídeo em path e o áudio em path com extensão .m4a
+func keepTracksApart(tracks []string, path string) error {
+	audio := strings.TrimSuffix(path, filepath.Ext(path)) + ".m4a"
+	if err := os.Rename(tracks[1], audio); err != nil {
+		return err
+	}
+	return os.Rename(tracks[0], path)
+}
+
+// fetchManifest baixa e interpreta um manifesto DASH; URLs relativas usam a URL final (após redirects)
+func (m *Manager) fetchManifest(ctx context.Context, manifestURL string, header http.Header) (*dash.Manifest, error) {
+	req, err := newRequest(ctx, manifestURL, header, hls.ByteRange{})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d ao baixar manifesto", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPlaylistSize))
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := dash.Parse(body, resp.Request.URL)
+	if err != nil {
+		return nil, retry.Permanentf("%w", err)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,266 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"GoAnimeGUI/pkg/dash"
+	"GoAnimeGUI/pkg/retry"
+	"GoAnimeGUI/pkg/store"
+)
+
+// videoExts são as extensões aceitas vindas da URL ou do nome do arquivo
+var videoExts = map[string]bool{".mp4": true, ".mkv": true, ".webm": true, ".avi": true, ".ts": true, ".m4v": true}
+
+// download baixa o arquivo de d, retomando o .part se existir
+func (m *Manager) download(ctx context.Context, d *store.Download) error {
+	src, err := m.resolve(ctx, *d)
+	if err != nil {
+		return fmt.Errorf("erro ao obter link do episódio: %w", err)
+	}
+	if src == nil || src.URL == "" {
+		return fmt.Errorf("link do episódio vazio")
+	}
+	// O caminho é fixado na primeira tentativa, para que mudar a pasta não perca o parcial
+	if d.Path == "" {
+		d.Path = m.targetPath(*d, src.URL)
+		if err := m.db.SetDownloadPath(d.ID, d.Path); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(d.Path), 0755); err != nil {
+		return retry.Permanentf("erro ao criar pasta de downloads: %w", err)
+	}
+	if isHLS(src.URL) {
+		return m.fetchHLS(ctx, d, src)
+	}
+	if isDASH(src.URL) {
+		return m.fetchDASH(ctx, d, src)
+	}
+	return m.fetchFile(ctx, d, src)
+}
+
+// fetchFile faz o GET (com Range se já houver parte baixada) e grava em d.Path+".part"
+func (m *Manager) fetchFile(ctx context.Context, d *store.Download, src *Source) error {
+	partPath := d.Path + ".part"
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	// Cancela a requisição se nenhum byte chegar por stallTimeout
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stall := time.AfterFunc(stallTimeout, cancel)
+	defer stall.Stop()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", src.URL, nil)
+	if err != nil {
+		return retry.Permanentf("URL de download inválida: %w", err)
+	}
+	for key, values := range src.Header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var total int64
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, size := parseContentRange(resp.Header.Get("Content-Range"))
+		if start != offset {
+			// Servidor devolveu outro trecho: recomeça do zero na próxima tentativa
+			os.Remove(partPath)
+			return fmt.Errorf("servidor retomou do byte %d em vez de %d", start, offset)
+		}
+		total = size
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// Playlist sem .m3u8 (ou manifesto sem .mpd) na URL: só dá para saber pelo Content-Type
+		if isPlaylistType(resp.Header.Get("Content-Type")) {
+			resp.Body.Close()
+			stall.Stop()
+			return m.fetchHLS(ctx, d, src)
+		}
+		if dash.IsManifest(resp.Header.Get("Content-Type"), "", nil) {
+			resp.Body.Close()
+			stall.Stop()
+			return m.fetchDASH(ctx, d, src)
+		}
+		// Servidor ignorou o Range (ou é o começo): sobrescreve o parcial
+		offset = 0
+		total = resp.ContentLength
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		_, size := parseContentRange(resp.Header.Get("Content-Range"))
+		if size > 0 && offset == size {
+			return m.finish(d, partPath, size)
+		}
+		os.Remove(partPath)
+		return fmt.Errorf("parcial inválido, reiniciando download")
+	default:
+		return fmt.Errorf("HTTP %d ao baixar episódio", resp.StatusCode)
+	}
+	if total < 0 {
+		total = 0
+	}
+
+	file, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return retry.Permanentf("erro ao abrir arquivo: %w", err)
+	}
+
+	written := offset
+	lastEmit, lastSave := time.Now(), time.Now()
+	speedBytes, speedStart := int64(0), time.Now()
+	var speed int64
+	buf := make([]byte, 256*1024)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			stall.Reset(stallTimeout)
+			if _, err := file.Write(buf[:n]); err != nil {
+				file.Close()
+				return retry.Permanentf("erro ao gravar arquivo: %w", err)
+			}
+			written += int64(n)
+			speedBytes += int64(n)
+		}
+
+		if now := time.Now(); now.Sub(lastEmit) >= progressInterval || readErr != nil {
+			if elapsed := now.Sub(speedStart); elapsed >= time.Second {
+				speed = int64(float64(speedBytes) / elapsed.Seconds())
+				speedBytes, speedStart = 0, now
+			}
+			m.emitProgress(d.ID, written, total, speed)
+			lastEmit = now
+			if now.Sub(lastSave) >= saveInterval {
+				m.db.SetDownloadProgress(d.ID, written, total)
+				lastSave = now
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			file.Close()
+			m.db.SetDownloadProgress(d.ID, written, total)
+			return readErr
+		}
+	}
+	if err := file.Close(); err != nil {
+		return retry.Permanentf("erro ao gravar arquivo: %w", err)
+	}
+
+	if total > 0 && written != total {
+		m.db.SetDownloadProgress(d.ID, written, total)
+		return fmt.Errorf("download incompleto: %d de %d bytes", written, total)
+	}
+	return m.finish(d, partPath, written)
+}
+
+// finish troca o .part pelo nome final
+func (m *Manager) finish(d *store.Download, partPath string, size int64) error {
+	if err := os.Rename(partPath, d.Path); err != nil {
+		return retry.Permanentf("erro ao finalizar arquivo: %w", err)
+	}
+	m.db.SetDownloadProgress(d.ID, size, size)
+	m.emitProgress(d.ID, size, size, 0)
+	return nil
+}
+
+func (m *Manager) emitProgress(id, downloaded, total, speed int64) {
+	if m.onProgress == nil {
+		return
+	}
+	p := Progress{ID: id, Downloaded: downloaded, Total: total, Speed: speed}
+	if total > 0 {
+		p.Percent = float64(downloaded) * 100 / float64(total)
+	}
+	m.onProgress(p)
+}
+
+// targetPath monta <pasta>/<anime>/<episódio>.<ext>
+func (m *Manager) targetPath(d store.Download, sourceURL string) string {
+	dir := m.dir()
+	if dir == "" {
+		dir = DefaultDir()
+	}
+
+	anime := CleanName(d.AnimeTitle)
+	if anime == "" {
+		anime = "Outros"
+	}
+
+	// TorBox já informa o nome do arquivo com extensão
+	if ext := strings.ToLower(filepath.Ext(d.EpisodeTitle)); videoExts[ext] {
+		return filepath.Join(dir, anime, CleanName(d.EpisodeTitle))
+	}
+
+	ext := ".mp4"
+	if u, err := url.Parse(sourceURL); err == nil {
+		if e := strings.ToLower(path.Ext(u.Path)); videoExts[e] {
+			ext = e
+		}
+	}
+
+	name := fmt.Sprintf("Episódio %02d", d.EpisodeNum)
+	if d.EpisodeNum <= 0 {
+		name = d.EpisodeTitle
+	}
+	if name = CleanName(name); name == "" {
+		name = fmt.Sprintf("download-%d", d.ID)
+	}
+	return filepath.Join(dir, anime, name+ext)
+}
+
+// CleanName remove caracteres que o Windows não aceita em nomes de arquivo
+func CleanName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 32 || strings.ContainsRune(`\/:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	// Windows também não aceita ponto ou espaço no fim
+	return strings.TrimRight(strings.TrimSpace(name), ". ")
+}
+
+// parseContentRange lê "bytes início-fim/total" (total -1 se "*")
+func parseContentRange(header string) (start, total int64) {
+	header = strings.TrimPrefix(strings.TrimSpace(header), "bytes ")
+	rangePart, totalPart, ok := strings.Cut(header, "/")
+	if !ok {
+		return -1, -1
+	}
+	total, err := strconv.ParseInt(totalPart, 10, 64)
+	if err != nil {
+		total = -1
+	}
+	if first, _, ok := strings.Cut(rangePart, "-"); ok {
+		if start, err = strconv.ParseInt(first, 10, 64); err == nil {
+			return start, total
+		}
+	}
+	return -1, total
+}
@@ -0,0 +1,515 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"GoAnimeGUI/pkg/hls"
+	"GoAnimeGUI/pkg/retry"
+	"GoAnimeGUI/pkg/store"
+)
+
+const (
+	hlsWorkers      = 4
+	segmentAttempts = 3
+	segmentTimeout  = 2 * time.Minute
+	maxPlaylistSize = 10 << 20
+	maxSegmentSize  = 200 << 20
+)
+
+// segmentRetryDelay é a espera entre tentativas de um mesmo segmento (zerada nos testes)
+var segmentRetryDelay = time.Second
+
+// isPlaylistType indica se o Content-Type é de uma playlist m3u8
+func isPlaylistType(contentType string) bool {
+	return strings.Contains(strings.ToLower(contentType), "mpegurl")
+}
+
+// fetchHLS baixa um stream HLS: escolhe a variante pela qualidade configurada, baixa os
+// segmentos em paralelo (guardados em <arquivo>.hls para retomar) e junta tudo num MP4.
+// Se a variante usa uma faixa de áudio separada (#EXT-X-MEDIA), ela é baixada junto (fetchHLSTracks).
+func (m *Manager) fetchHLS(ctx context.Context, d *store.Download, src *Source) error {
+	playlist, err := m.fetchPlaylist(ctx, src.URL, src.Header)
+	if err != nil {
+		return err
+	}
+	var audio *hls.Playlist
+	if playlist.Master {
+		variant := playlist.SelectVariant(m.quality())
+		if variant == nil {
+			return retry.Permanentf("playlist sem variantes")
+		}
+		fmt.Printf("[Downloads] HLS: variante %dp (%d bps) para %s\n", variant.Height, variant.Bandwidth, d.Key)
+		if rendition := playlist.AudioFor(variant); rendition != nil {
+			fmt.Printf("[Downloads] HLS: áudio separado %q (%s) para %s\n", rendition.Name, rendition.Language, d.Key)
+			if audio, err = m.fetchMediaPlaylist(ctx, rendition.URI, src.Header); err != nil {
+				return err
+			}
+		}
+		if playlist, err = m.fetchMediaPlaylist(ctx, variant.URI, src.Header); err != nil {
+			return err
+		}
+	}
+	initMap, err := mediaInit(playlist)
+	if err != nil {
+		return err
+	}
+	if audio != nil {
+		return m.fetchHLSTracks(ctx, d, src, playlist, initMap, audio)
+	}
+
+	workDir := d.Path + ".hls"
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return retry.Permanentf("erro ao criar pasta temporária: %w", err)
+	}
+
+	if initMap != nil {
+		initPath := filepath.Join(workDir, "init.mp4")
+		if _, err := os.Stat(initPath); err != nil {
+			data, err := m.fetchBytes(ctx, initMap.URI, src.Header, initMap.Range)
+			if err != nil {
+				return fmt.Errorf("erro ao baixar inicialização HLS: %w", err)
+			}
+			if err := writeFileAtomic(initPath, data); err != nil {
+				return retry.Permanentf("erro ao gravar segmento: %w", err)
+			}
+		}
+	}
+
+	if err := m.fetchSegments(ctx, d, playlist.Segments, src.Header, workDir); err != nil {
+		return err
+	}
+	return m.assembleHLS(ctx, d, len(playlist.Segments), initMap != nil, workDir)
+}
+
+// fetchHLSTracks baixa a variante e a faixa de áudio separada como duas faixas (guardadas em
+// <arquivo>.tracks) e as une como no DASH. Faixas em MPEG-TS só podem ser unidas pelo ffmpeg.
+func (m *Manager) fetchHLSTracks(ctx context.Context, d *store.Download, src *Source, video *hls.Playlist, videoInit *hls.Map, audio *hls.Playlist) error {
+	audioInit, err := mediaInit(audio)
+	if err != nil {
+		return err
+	}
+	fragmented := videoInit != nil && audioInit != nil
+	if !fragmented && m.ffmpegPath == "" {
+		return retry.Permanentf("stream HLS com áudio separado em MPEG-TS exige o ffmpeg")
+	}
+
+	// Como no DASH: init e segmentos de cada faixa numa lista só, para um progresso único
+	var segments []hls.Segment
+	counts := make([]int, 0, 2)
+	inits := []*hls.Map{videoInit, audioInit}
+	for i, track := range []*hls.Playlist{video, audio} {
+		parts := track.Segments
+		if inits[i] != nil {
+			parts = append([]hls.Segment{{URI: inits[i].URI, Range: inits[i].Range}}, parts...)
+		}
+		segments = append(segments, parts...)
+		counts = append(counts, len(parts))
+	}
+
+	workDir := d.Path + ".tracks"
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return retry.Permanentf("erro ao criar pasta temporária: %w", err)
+	}
+	if err := m.fetchSegments(ctx, d, segments, src.Header, workDir); err != nil {
+		return err
+	}
+	return m.assembleTracks(ctx, d, counts, fragmented, workDir)
+}
+
+// mediaInit confere se a media playlist é suportada e retorna o segmento de inicialização (fMP4) ou nil
+func mediaInit(playlist *hls.Playlist) (*hls.Map, error) {
+	if len(playlist.Segments) == 0 {
+		return nil, retry.Permanentf("playlist HLS sem segmentos")
+	}
+	var initMap *hls.Map
+	for _, seg := range playlist.Segments {
+		if seg.Key != nil && seg.Key.Method != "AES-128" {
+			return nil, retry.Permanentf("criptografia HLS %s não suportada", seg.Key.Method)
+		}
+		if seg.Map != nil {
+			if initMap != nil && *seg.Map != *initMap {
+				return nil, retry.Permanentf("playlist HLS com vários segmentos de inicialização não suportada")
+			}
+			initMap = seg.Map
+		}
+	}
+	return initMap, nil
+}
+
+// fetchSegments baixa os segmentos que ainda não estão em workDir, hlsWorkers por vez
+func (m *Manager) fetchSegments(ctx context.Context, d *store.Download, segments []hls.Segment, header http.Header, workDir string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		done, written atomic.Int64
+		keys          = newKeyCache(m, header)
+		firstErr      error
+		errOnce       sync.Once
+		pending       []int
+	)
+	for i := range segments {
+		if info, err := os.Stat(segmentPath(workDir, i)); err == nil {
+			done.Add(1)
+			written.Add(info.Size())
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	// Progresso estimado pela média dos segmentos já baixados
+	progressDone := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		last, lastAt := written.Load(), time.Now()
+		for {
+			select {
+			case <-progressDone:
+				return
+			case now := <-ticker.C:
+				bytesNow, segs := written.Load(), done.Load()
+				var total int64
+				if segs > 0 {
+					total = bytesNow * int64(len(segments)) / segs
+				}
+				speed := int64(float64(bytesNow-last) / now.Sub(lastAt).Seconds())
+				last, lastAt = bytesNow, now
+				m.emitProgress(d.ID, bytesNow, total, speed)
+			}
+		}
+	}()
+	defer close(progressDone)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < hlsWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				n, err := m.fetchSegment(ctx, segments[i], keys, segmentPath(workDir, i))
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("segmento %d: %w", i, err)
+						cancel()
+					})
+					continue
+				}
+				done.Add(1)
+				written.Add(n)
+			}
+		}()
+	}
+
+feed:
+	for _, i := range pending {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		m.db.SetDownloadProgress(d.ID, written.Load(), 0)
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// fetchSegment baixa (com novas tentativas) e decifra um segmento, gravando em path
+func (m *Manager) fetchSegment(ctx context.Context, seg hls.Segment, keys *keyCache, path string) (int64, error) {
+	var lastErr error
+	for attempt := 0; attempt < segmentAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			case <-time.After(segmentRetryDelay * time.Duration(attempt)):
+			}
+		}
+
+		segCtx, cancel := context.WithTimeout(ctx, segmentTimeout)
+		data, err := m.fetchBytes(segCtx, seg.URI, keys.header, seg.Range)
+		cancel()
+		if err == nil && seg.Key != nil {
+			data, err = keys.decrypt(ctx, seg, data)
+		}
+		if err == nil {
+			if err := writeFileAtomic(path, data); err != nil {
+				return 0, retry.Permanentf("erro ao gravar segmento: %w", err)
+			}
+			return int64(len(data)), nil
+		}
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		lastErr = err
+	}
+	return 0, lastErr
+}
+
+// assembleHLS junta os segmentos e, para MPEG-TS, remuxa para MP4 com o ffmpeg.
+// Sem ffmpeg (ou se ele falhar), o episódio fica como .ts, que os players abrem normalmente.
+func (m *Manager) assembleHLS(ctx context.Context, d *store.Download, count int, fragmented bool, workDir string) error {
+	joined := filepath.Join(workDir, "joined.ts")
+	if fragmented {
+		joined = filepath.Join(workDir, "joined.mp4")
+	}
+	out, err := os.Create(joined)
+	if err != nil {
+		return retry.Permanentf("erro ao juntar segmentos: %w", err)
+	}
+
+	parts := make([]string, 0, count+1)
+	if fragmented {
+		parts = append(parts, filepath.Join(workDir, "init.mp4"))
+	}
+	for i := 0; i < count; i++ {
+		parts = append(parts, segmentPath(workDir, i))
+	}
+	for _, part := range parts {
+		if err := appendFile(out, part); err != nil {
+			out.Close()
+			return retry.Permanentf("erro ao juntar segmentos: %w", err)
+		}
+	}
+	if err := out.Close(); err != nil {
+		return retry.Permanentf("erro ao juntar segmentos: %w", err)
+	}
+
+	final := d.Path
+	switch {
+	case fragmented:
+		err = os.Rename(joined, final)
+	case m.ffmpegPath != "":
+		remuxed := filepath.Join(workDir, "remux.mp4")
+		cmd := exec.CommandContext(ctx, m.ffmpegPath, "-y", "-i", joined, "-c", "copy",
+			"-bsf:a", "aac_adtstoasc", "-movflags", "+faststart", remuxed)
+		if output, ffErr := cmd.CombinedOutput(); ffErr != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			fmt.Printf("[Downloads] ffmpeg falhou, salvando como TS: %v - %s\n", ffErr, lastLine(output))
+			final = tsPath(d.Path)
+			err = os.Rename(joined, final)
+		} else {
+			err = os.Rename(remuxed, final)
+		}
+	default:
+		final = tsPath(d.Path)
+		err = os.Rename(joined, final)
+	}
+	if err != nil {
+		return retry.Permanentf("erro ao finalizar arquivo: %w", err)
+	}
+	m.finishAssembled(d, final, workDir)
+	return nil
+}
+
+// finishAssembled registra o arquivo final (que pode ter outra extensão), apaga os segmentos
+// e marca o progresso como completo
+func (m *Manager) finishAssembled(d *store.Download, final, workDir string) {
+	if final != d.Path {
+		d.Path = final
+		m.db.SetDownloadPath(d.ID, final)
+	}
+	os.RemoveAll(workDir)
+
+	if info, err := os.Stat(final); err == nil {
+		m.db.SetDownloadProgress(d.ID, info.Size(), info.Size())
+		m.emitProgress(d.ID, info.Size(), info.Size(), 0)
+	}
+}
+
+// fetchPlaylist baixa e interpreta uma playlist; URIs relativas usam a URL final (após redirects)
+func (m *Manager) fetchPlaylist(ctx context.Context, playlistURL string, header http.Header) (*hls.Playlist, error) {
+	req, err := newRequest(ctx, playlistURL, header, hls.ByteRange{})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d ao baixar playlist", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPlaylistSize))
+	if err != nil {
+		return nil, err
+	}
+	playlist, err := hls.Parse(string(body), resp.Request.URL)
+	if err != nil {
+		return nil, retry.Permanentf("%w", err)
+	}
+	return playlist, nil
+}
+
+// fetchMediaPlaylist baixa uma playlist de variante ou de faixa, que não pode ser outra master
+func (m *Manager) fetchMediaPlaylist(ctx context.Context, playlistURL string, header http.Header) (*hls.Playlist, error) {
+	playlist, err := m.fetchPlaylist(ctx, playlistURL, header)
+	if err != nil {
+		return nil, err
+	}
+	if playlist.Master {
+		return nil, retry.Permanentf("playlist HLS aninhada não suportada")
+	}
+	return playlist, nil
+}
+
+// fetchBytes baixa um recurso inteiro (ou o trecho r) para a memória
+func (m *Manager) fetchBytes(ctx context.Context, rawURL string, header http.Header, r hls.ByteRange) ([]byte, error) {
+	req, err := newRequest(ctx, rawURL, header, r)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSegmentSize))
+	if err != nil {
+		return nil, err
+	}
+	// Servidor ignorou o Range: recorta o trecho pedido
+	if r.Length > 0 && resp.StatusCode == http.StatusOK {
+		if r.Offset+r.Length > int64(len(data)) {
+			return nil, fmt.Errorf("segmento menor que o byte range")
+		}
+		data = data[r.Offset : r.Offset+r.Length]
+	}
+	return data, nil
+}
+
+func newRequest(ctx context.Context, rawURL string, header http.Header, r hls.ByteRange) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, retry.Permanentf("URL inválida: %w", err)
+	}
+	for key, values := range header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	if r.Length > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", r.Offset, r.Offset+r.Length-1))
+	}
+	return req, nil
+}
+
+// keyCache guarda as chaves AES já baixadas (a mesma chave serve a vários segmentos)
+type keyCache struct {
+	m      *Manager
+	header http.Header
+	keys   map[string][]byte
+	mutex  sync.Mutex
+}
+
+func newKeyCache(m *Manager, header http.Header) *keyCache {
+	return &keyCache{m: m, header: header, keys: make(map[string][]byte)}
+}
+
+func (c *keyCache) get(ctx context.Context, uri string) ([]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if key, ok := c.keys[uri]; ok {
+		return key, nil
+	}
+	key, err := c.m.fetchBytes(ctx, uri, c.header, hls.ByteRange{})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao baixar chave: %w", err)
+	}
+	if len(key) != 16 {
+		return nil, retry.Permanentf("chave AES-128 com %d bytes", len(key))
+	}
+	c.keys[uri] = key
+	return key, nil
+}
+
+// decrypt decifra um segmento AES-128-CBC. Sem IV na playlist, o IV é o número de sequência.
+func (c *keyCache) decrypt(ctx context.Context, seg hls.Segment, data []byte) ([]byte, error) {
+	key, err := c.get(ctx, seg.Key.URI)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("segmento cifrado com tamanho inválido (%d bytes)", len(data))
+	}
+
+	iv := seg.Key.IV
+	if iv == nil {
+		iv = make([]byte, aes.BlockSize)
+		binary.BigEndian.PutUint64(iv[8:], uint64(seg.Sequence))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, retry.Permanentf("%w", err)
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+
+	// Remove o padding PKCS#7
+	pad := int(plain[len(plain)-1])
+	if pad == 0 || pad > aes.BlockSize || !bytes.Equal(plain[len(plain)-pad:], bytes.Repeat([]byte{byte(pad)}, pad)) {
+		return nil, fmt.Errorf("padding inválido (chave errada?)")
+	}
+	return plain[:len(plain)-pad], nil
+}
+
+func segmentPath(workDir string, i int) string {
+	return filepath.Join(workDir, fmt.Sprintf("seg-%05d", i))
+}
+
+// writeFileAtomic grava via arquivo temporário, para um segmento pela metade nunca parecer completo
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func appendFile(out *os.File, path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	_, err = io.Copy(out, in)
+	return err
+}
+
+// tsPath troca a extensão do arquivo final por .ts
+func tsPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".ts"
+}
+
+func lastLine(output []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return lines[len(lines)-1]
+}
@@ -0,0 +1,419 @@
+// Package downloader baixa episódios para a biblioteca offline.
+// A fila fica no banco, então downloads pausados ou interrompidos continuam de onde pararam
+// (HTTP Range sobre o arquivo .part, ou segmentos já baixados no caso de HLS)
+// mesmo depois de fechar o app.
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"GoAnimeGUI/pkg/hostrules"
+	"GoAnimeGUI/pkg/retry"
+	"GoAnimeGUI/pkg/store"
+)
+
+const (
+	maxAttempts      = 5
+	retryBaseDelay   = 10 * time.Second
+	retryMaxDelay    = 5 * time.Minute
+	progressInterval = 500 * time.Millisecond
+	saveInterval     = 2 * time.Second
+	pollInterval     = 5 * time.Second
+)
+
+// stallTimeout aborta (e tenta de novo) um download que parou de receber dados
+var stallTimeout = time.Minute
+
+// Source é o arquivo a baixar, com os headers que o CDN exige
+type Source struct {
+	URL    string
+	Header http.Header
+}
+
+// ResolveFunc descobre a URL atual do download. É chamada a cada tentativa porque
+// os links das fontes e do TorBox expiram.
+type ResolveFunc func(ctx context.Context, d store.Download) (*Source, error)
+
+// Progress é o andamento de um download ativo
+type Progress struct {
+	ID         int64   `json:"id"`
+	Downloaded int64   `json:"downloaded"`
+	Total      int64   `json:"total"` // 0 = desconhecido
+	Percent    float64 `json:"percent"`
+	Speed      int64   `json:"speed"` // bytes/s
+}
+
+// job é um download em andamento
+type job struct {
+	cancel context.CancelFunc
+	reason string // Estado a gravar quando cancelado (pausado ou removido)
+	done   chan struct{}
+}
+
+// Manager controla a fila de downloads
+type Manager struct {
+	db         *store.DB
+	resolve    ResolveFunc
+	dir        func() string
+	limit      func() int
+	client     *http.Client
+	onProgress func(Progress)
+	onChange   func(store.Download)
+	quality    func() string // Qualidade preferida para streams HLS
+	ffmpegPath string        // Remux de HLS e junção das faixas DASH (vazio = salva como .ts / áudio separado)
+
+	active  map[int64]*job
+	retryAt map[int64]time.Time
+	mutex   sync.Mutex
+	wake    chan struct{}
+}
+
+// New cria o gerenciador. dir retorna a pasta da biblioteca e limit o número de downloads simultâneos.
+func New(db *store.DB, resolve ResolveFunc, dir func() string, limit func() int) *Manager {
+	return &Manager{
+		db:      db,
+		resolve: resolve,
+		dir:     dir,
+		limit:   limit,
+		client: &http.Client{
+			// Sem timeout total: episódios grandes demoram; travamentos são detectados por stallTimeout
+			Transport: hostrules.Default().Transport(&http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				ResponseHeaderTimeout: 30 * time.Second,
+				IdleConnTimeout:       90 * time.Second,
+			}),
+		},
+		quality: func() string { return "auto" },
+		active:  make(map[int64]*job),
+		retryAt: make(map[int64]time.Time),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// DefaultDir é a pasta usada quando o usuário não escolheu uma
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "GoAnime")
+	}
+	return filepath.Join(home, "Videos", "GoAnime")
+}
+
+// ConfigureHLS define a qualidade preferida ("auto", "1080p"...) e o ffmpeg usado no remux
+// do HLS e na junção das faixas do DASH
+func (m *Manager) ConfigureHLS(quality func() string, ffmpegPath string) {
+	if quality != nil {
+		m.quality = quality
+	}
+	m.ffmpegPath = ffmpegPath
+}
+
+// OnProgress registra um callback para o andamento dos downloads ativos
+func (m *Manager) OnProgress(fn func(Progress)) {
+	m.onProgress = fn
+}
+
+// OnChange registra um callback para mudanças de estado
+func (m *Manager) OnChange(fn func(store.Download)) {
+	m.onChange = fn
+}
+
+// Run processa a fila até o contexto ser cancelado
+func (m *Manager) Run(ctx context.Context) {
+	if err := m.db.RequeueInterruptedDownloads(); err != nil {
+		fmt.Printf("[Downloads] Erro ao retomar downloads interrompidos: %v\n", err)
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		m.schedule(ctx)
+		select {
+		case <-ctx.Done():
+			m.stopAll()
+			return
+		case <-ticker.C:
+		case <-m.wake:
+		}
+	}
+}
+
+// Kick faz a fila andar imediatamente
+func (m *Manager) Kick() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Enqueue adiciona um download à fila. Um episódio já na lista não é duplicado;
+// se tinha falhado, volta para a fila.
+func (m *Manager) Enqueue(d store.Download) (*store.Download, error) {
+	if d.Key == "" {
+		return nil, fmt.Errorf("download sem identificação")
+	}
+	saved, err := m.db.AddDownload(d)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao adicionar download: %w", err)
+	}
+	if saved.State == store.DownloadFailed {
+		return m.Retry(saved.ID)
+	}
+	m.changed(saved.ID)
+	m.Kick()
+	return saved, nil
+}
+
+// Pause pausa um download ativo ou na fila
+func (m *Manager) Pause(id int64) error {
+	if m.stop(id, store.DownloadPaused) {
+		return nil
+	}
+	d, err := m.get(id)
+	if err != nil {
+		return err
+	}
+	if d.State != store.DownloadQueued {
+		return nil
+	}
+	if err := m.db.SetDownloadState(id, store.DownloadPaused, ""); err != nil {
+		return err
+	}
+	m.changed(id)
+	return nil
+}
+
+// Resume devolve um download pausado à fila; ele continua de onde parou
+func (m *Manager) Resume(id int64) error {
+	d, err := m.get(id)
+	if err != nil {
+		return err
+	}
+	if d.State != store.DownloadPaused {
+		return nil
+	}
+	if err := m.db.SetDownloadState(id, store.DownloadQueued, ""); err != nil {
+		return err
+	}
+	m.changed(id)
+	m.Kick()
+	return nil
+}
+
+// Retry zera as tentativas de um download com falha e o devolve à fila
+func (m *Manager) Retry(id int64) (*store.Download, error) {
+	d, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if d.State != store.DownloadFailed && d.State != store.DownloadPaused {
+		return d, nil
+	}
+	if err := m.db.SetDownloadAttempts(id, 0); err != nil {
+		return nil, err
+	}
+	if err := m.db.SetDownloadState(id, store.DownloadQueued, ""); err != nil {
+		return nil, err
+	}
+	m.mutex.Lock()
+	delete(m.retryAt, id)
+	m.mutex.Unlock()
+
+	m.changed(id)
+	m.Kick()
+	return m.get(id)
+}
+
+// Cancel remove o download da lista. Com deleteFile, apaga também o arquivo
+// (parcial ou completo) do disco.
+func (m *Manager) Cancel(id int64, deleteFile bool) error {
+	m.stop(id, "")
+	d, err := m.get(id)
+	if err != nil {
+		return err
+	}
+	if deleteFile && d.Path != "" {
+		os.Remove(d.Path + ".part")
+		os.RemoveAll(d.Path + ".hls")
+		os.RemoveAll(d.Path + ".tracks")
+		if d.State == store.DownloadCompleted {
+			os.Remove(d.Path)
+		}
+	}
+	if err := m.db.DeleteDownload(id); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	delete(m.retryAt, id)
+	m.mutex.Unlock()
+	if m.onChange != nil {
+		m.onChange(store.Download{ID: id, Key: d.Key})
+	}
+	return nil
+}
+
+func (m *Manager) get(id int64) (*store.Download, error) {
+	d, err := m.db.GetDownload(id)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, fmt.Errorf("download %d não encontrado", id)
+	}
+	return d, nil
+}
+
+// stop cancela um download ativo e espera a goroutine terminar. Retorna false se não estava ativo.
+func (m *Manager) stop(id int64, reason string) bool {
+	m.mutex.Lock()
+	j, ok := m.active[id]
+	if ok {
+		j.reason = reason
+		j.cancel()
+	}
+	m.mutex.Unlock()
+	if ok {
+		<-j.done
+	}
+	return ok
+}
+
+// stopAll interrompe tudo no encerramento do app; os downloads voltam à fila na próxima abertura
+func (m *Manager) stopAll() {
+	m.mutex.Lock()
+	jobs := make([]*job, 0, len(m.active))
+	for _, j := range m.active {
+		j.reason = store.DownloadQueued
+		j.cancel()
+		jobs = append(jobs, j)
+	}
+	m.mutex.Unlock()
+	for _, j := range jobs {
+		<-j.done
+	}
+}
+
+// schedule inicia downloads da fila até o limite de simultâneos
+func (m *Manager) schedule(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+	queued, err := m.db.QueuedDownloads()
+	if err != nil {
+		fmt.Printf("[Downloads] Erro ao ler a fila: %v\n", err)
+		return
+	}
+
+	limit := m.limit()
+	if limit <= 0 {
+		limit = store.DefaultMaxDownloads
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	now := time.Now()
+	for _, d := range queued {
+		if len(m.active) >= limit {
+			return
+		}
+		if _, running := m.active[d.ID]; running {
+			continue
+		}
+		if at, waiting := m.retryAt[d.ID]; waiting && now.Before(at) {
+			continue
+		}
+		delete(m.retryAt, d.ID)
+
+		jobCtx, cancel := context.WithCancel(ctx)
+		j := &job{cancel: cancel, done: make(chan struct{})}
+		m.active[d.ID] = j
+		go m.run(jobCtx, j, d)
+	}
+}
+
+// run executa uma tentativa de download e grava o resultado
+func (m *Manager) run(ctx context.Context, j *job, d store.Download) {
+	defer close(j.done)
+	defer j.cancel()
+
+	m.db.SetDownloadState(d.ID, store.DownloadActive, "")
+	m.changed(d.ID)
+
+	err := m.download(ctx, &d)
+
+	m.mutex.Lock()
+	delete(m.active, d.ID)
+	reason := j.reason
+	m.mutex.Unlock()
+
+	switch {
+	case err == nil:
+		fmt.Printf("[Downloads] Concluído: %s\n", d.Path)
+		m.db.SetDownloadAttempts(d.ID, 0)
+		m.db.SetDownloadState(d.ID, store.DownloadCompleted, "")
+	case ctx.Err() != nil && reason != "":
+		// Pausado ou app fechando: o .part fica para retomar depois
+		m.db.SetDownloadState(d.ID, reason, "")
+	case ctx.Err() != nil && reason == "":
+		// Removido da lista por Cancel; nada a gravar
+		return
+	default:
+		m.fail(&d, err)
+	}
+	m.changed(d.ID)
+	m.Kick()
+}
+
+// fail agenda uma nova tentativa com backoff ou desiste de vez
+func (m *Manager) fail(d *store.Download, err error) {
+	attempts := d.Attempts + 1
+	m.db.SetDownloadAttempts(d.ID, attempts)
+
+	if retry.IsPermanent(err) || attempts >= maxAttempts {
+		fmt.Printf("[Downloads] Falhou (%d tentativas): %s: %v\n", attempts, d.Key, err)
+		m.db.SetDownloadState(d.ID, store.DownloadFailed, err.Error())
+		return
+	}
+
+	delay := retry.Backoff(attempts, retryBaseDelay, retryMaxDelay)
+	fmt.Printf("[Downloads] Erro em %s, nova tentativa em %v: %v\n", d.Key, delay, err)
+	m.mutex.Lock()
+	m.retryAt[d.ID] = time.Now().Add(delay)
+	m.mutex.Unlock()
+	m.db.SetDownloadState(d.ID, store.DownloadQueued, err.Error())
+}
+
+func (m *Manager) changed(id int64) {
+	if m.onChange == nil {
+		return
+	}
+	if d, err := m.db.GetDownload(id); err == nil && d != nil {
+		m.onChange(*d)
+	}
+}
+
+// isHLS indica se a URL é uma playlist m3u8
+func isHLS(rawURL string) bool {
+	path := strings.ToLower(rawURL)
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	return strings.HasSuffix(path, ".m3u8")
+}
+
+// isDASH indica se a URL é um manifesto MPD
+func isDASH(rawURL string) bool {
+	path := strings.ToLower(rawURL)
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	return strings.HasSuffix(path, ".mpd")
+}
@@ -0,0 +1,308 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"GoAnimeGUI/internal/testutil"
+	"GoAnimeGUI/pkg/store"
+)
+
+func newTestManager(t *testing.T, resolve ResolveFunc) (*Manager, *store.DB, string) {
+	t.Helper()
+	db := store.OpenTemp(t)
+
+	dir := t.TempDir()
+	m := New(db, resolve, func() string { return dir }, func() int { return 1 })
+	return m, db, dir
+}
+
+// waitState roda a fila até o download chegar ao estado esperado
+func waitState(t *testing.T, m *Manager, db *store.DB, id int64, state string) *store.Download {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var d *store.Download
+	if !testutil.Eventually(5*time.Second, func() bool {
+		m.schedule(ctx)
+		d, _ = db.GetDownload(id)
+		return d != nil && d.State == state
+	}) {
+		t.Fatalf("download %d = %+v; want state %s", id, d, state)
+	}
+	return d
+}
+
+func TestManager_ResumesPartialFile(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		if r.Header.Get("Referer") != "https://origem/" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		http.ServeContent(w, r, "ep.mp4", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	m, db, dir := newTestManager(t, func(ctx context.Context, d store.Download) (*Source, error) {
+		return &Source{URL: server.URL + "/video/ep.mp4?token=x", Header: http.Header{"Referer": {"https://origem/"}}}, nil
+	})
+
+	d, err := m.Enqueue(store.Download{Key: "ep1", AnimeTitle: "Frieren: Beyond", EpisodeNum: 3})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	// Simula um download pausado na metade
+	want := filepath.Join(dir, "Frieren_ Beyond", "Episódio 03.mp4")
+	os.MkdirAll(filepath.Dir(want), 0755)
+	os.WriteFile(want+".part", content[:40000], 0644)
+
+	d = waitState(t, m, db, d.ID, store.DownloadCompleted)
+	if d.Path != want {
+		t.Errorf("Path = %q; want %q", d.Path, want)
+	}
+	got, err := os.ReadFile(want)
+	if err != nil || !bytes.Equal(got, content) {
+		t.Errorf("file content mismatch (err = %v, %d bytes)", err, len(got))
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=40000-" {
+		t.Errorf("Range headers = %v; want [bytes=40000-]", ranges)
+	}
+	if d.DownloadedBytes != int64(len(content)) || d.TotalBytes != int64(len(content)) {
+		t.Errorf("progress = %d/%d; want %d", d.DownloadedBytes, d.TotalBytes, len(content))
+	}
+}
+
+func TestManager_FailuresAndRetry(t *testing.T) {
+	invalid := true
+	m, db, _ := newTestManager(t, func(ctx context.Context, d store.Download) (*Source, error) {
+		if invalid {
+			return &Source{URL: "http://cdn/\x7f"}, nil
+		}
+		return nil, context.DeadlineExceeded
+	})
+
+	d, _ := m.Enqueue(store.Download{Key: "ep1", AnimeTitle: "Anime", EpisodeNum: 1})
+
+	// Erro permanente: não entra no backoff
+	d = waitState(t, m, db, d.ID, store.DownloadFailed)
+	if d.Attempts != 1 || d.LastError == "" {
+		t.Errorf("download = %+v; want 1 attempt with error", d)
+	}
+
+	// Erro temporário: volta à fila com espera antes da próxima tentativa
+	invalid = false
+	if _, err := m.Retry(d.ID); err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	ctx := context.Background()
+	m.schedule(ctx)
+	time.Sleep(100 * time.Millisecond)
+	d, _ = db.GetDownload(d.ID)
+	if d.State != store.DownloadQueued || d.Attempts != 1 {
+		t.Errorf("after transient error = %+v; want queued with 1 attempt", d)
+	}
+	m.mutex.Lock()
+	_, waiting := m.retryAt[d.ID]
+	m.mutex.Unlock()
+	if !waiting {
+		t.Error("transient error should schedule a delayed retry")
+	}
+
+	if err := m.Cancel(d.ID, true); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if d, _ := db.GetDownload(d.ID); d != nil {
+		t.Errorf("download still listed after Cancel: %+v", d)
+	}
+}
+
+func TestManager_DownloadsEncryptedHLS(t *testing.T) {
+	segmentRetryDelay = 0
+	key := []byte("0123456789abcdef")
+	segments := [][]byte{bytes.Repeat([]byte("A"), 3000), bytes.Repeat([]byte("B"), 1234), []byte("C")}
+
+	// Cifra como um empacotador HLS: AES-128-CBC, PKCS#7, IV = número de sequência
+	encrypt := func(seq int, plain []byte) []byte {
+		pad := aes.BlockSize - len(plain)%aes.BlockSize
+		data := append(append([]byte(nil), plain...), bytes.Repeat([]byte{byte(pad)}, pad)...)
+		iv := make([]byte, aes.BlockSize)
+		binary.BigEndian.PutUint64(iv[8:], uint64(seq))
+		block, _ := aes.NewCipher(key)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(data, data)
+		return data
+	}
+
+	failures := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/play": // Master sem .m3u8 na URL: detectada pelo Content-Type
+			w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+			fmt.Fprint(w, "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=900000,RESOLUTION=854x480\nlow/index.m3u8\n"+
+				"#EXT-X-STREAM-INF:BANDWIDTH=3000000,RESOLUTION=1280x720\nhd/index.m3u8\n")
+		case "/hd/index.m3u8":
+			fmt.Fprint(w, "#EXTM3U\n#EXT-X-MEDIA-SEQUENCE:5\n#EXT-X-KEY:METHOD=AES-128,URI=\"/key\"\n"+
+				"#EXTINF:4,\ns0.ts\n#EXTINF:4,\ns1.ts\n#EXTINF:1,\ns2.ts\n#EXT-X-ENDLIST\n")
+		case "/key":
+			w.Write(key)
+		case "/hd/s1.ts":
+			if failures > 0 { // Falha temporária: o segmento é tentado de novo
+				failures--
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.Write(encrypt(6, segments[1]))
+		case "/hd/s0.ts":
+			w.Write(encrypt(5, segments[0]))
+		case "/hd/s2.ts":
+			w.Write(encrypt(7, segments[2]))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	m, db, dir := newTestManager(t, func(ctx context.Context, d store.Download) (*Source, error) {
+		return &Source{URL: server.URL + "/play"}, nil
+	})
+	m.ConfigureHLS(func() string { return "720p" }, "")
+
+	d, _ := m.Enqueue(store.Download{Key: "ep2", AnimeTitle: "Anime", EpisodeNum: 2})
+	d = waitState(t, m, db, d.ID, store.DownloadCompleted)
+
+	// Sem ffmpeg, o resultado fica como TS concatenado
+	want := filepath.Join(dir, "Anime", "Episódio 02.ts")
+	if d.Path != want {
+		t.Errorf("Path = %q; want %q", d.Path, want)
+	}
+	got, err := os.ReadFile(want)
+	if err != nil || !bytes.Equal(got, bytes.Join(segments, nil)) {
+		t.Errorf("file content mismatch (err = %v, %d bytes)", err, len(got))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Anime", "Episódio 02.mp4.hls")); !os.IsNotExist(err) {
+		t.Errorf("segment folder should be removed, stat err = %v", err)
+	}
+}
+
+func TestManager_DownloadsDASHWithSeparateAudio(t *testing.T) {
+	segmentRetryDelay = 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ep3.mpd":
+			fmt.Fprint(w, `<MPD type="static" mediaPresentationDuration="PT8S"><Period>
+  <AdaptationSet mimeType="video/mp4">
+    <SegmentTemplate timescale="1" duration="4" initialization="$RepresentationID$/init" media="$RepresentationID$/$Number$"/>
+    <Representation id="v480" bandwidth="900000" height="480"/>
+    <Representation id="v720" bandwidth="3000000" height="720"/>
+  </AdaptationSet>
+  <AdaptationSet mimeType="audio/mp4">
+    <Representation id="aac" bandwidth="128000">
+      <BaseURL>audio.mp4</BaseURL>
+      <SegmentList><Initialization range="0-3"/><SegmentURL mediaRange="4-9"/><SegmentURL mediaRange="10-11"/></SegmentList>
+    </Representation>
+  </AdaptationSet>
+</Period></MPD>`)
+		case "/v720/init", "/v720/1", "/v720/2":
+			fmt.Fprint(w, "["+r.URL.Path+"]")
+		case "/audio.mp4":
+			http.ServeContent(w, r, "audio.mp4", time.Time{}, bytes.NewReader([]byte("INITaaaaaabb")))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	m, db, dir := newTestManager(t, func(ctx context.Context, d store.Download) (*Source, error) {
+		return &Source{URL: server.URL + "/ep3.mpd"}, nil
+	})
+	m.ConfigureHLS(func() string { return "720p" }, "")
+
+	d, _ := m.Enqueue(store.Download{Key: "ep3", AnimeTitle: "Anime", EpisodeNum: 3})
+	d = waitState(t, m, db, d.ID, store.DownloadCompleted)
+
+	// Sem ffmpeg, o áudio fica num .m4a ao lado do vídeo
+	want := filepath.Join(dir, "Anime", "Episódio 03.mp4")
+	if d.Path != want {
+		t.Errorf("Path = %q; want %q", d.Path, want)
+	}
+	if got, err := os.ReadFile(want); err != nil || string(got) != "[/v720/init][/v720/1][/v720/2]" {
+		t.Errorf("video = %q (err = %v)", got, err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dir, "Anime", "Episódio 03.m4a")); err != nil || string(got) != "INITaaaaaabb" {
+		t.Errorf("audio = %q (err = %v)", got, err)
+	}
+	if _, err := os.Stat(want + ".dash"); !os.IsNotExist(err) {
+		t.Errorf("segment folder should be removed, stat err = %v", err)
+	}
+}
+
+func TestManager_DownloadsHLSWithSeparateAudio(t *testing.T) {
+	segmentRetryDelay = 0
+	tsAudio := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ep4.m3u8":
+			fmt.Fprint(w, "#EXTM3U\n"+
+				"#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID=\"aud\",NAME=\"Japonês\",LANGUAGE=\"ja\",DEFAULT=YES,URI=\"a/index.m3u8\"\n"+
+				"#EXT-X-STREAM-INF:BANDWIDTH=3000000,RESOLUTION=1280x720,AUDIO=\"aud\"\nv/index.m3u8\n")
+		case "/v/index.m3u8":
+			fmt.Fprint(w, "#EXTM3U\n#EXT-X-MAP:URI=\"init.mp4\"\n#EXTINF:4,\n1.m4s\n#EXTINF:4,\n2.m4s\n#EXT-X-ENDLIST\n")
+		case "/a/index.m3u8":
+			if tsAudio {
+				fmt.Fprint(w, "#EXTM3U\n#EXTINF:4,\n1.ts\n#EXT-X-ENDLIST\n")
+				return
+			}
+			fmt.Fprint(w, "#EXTM3U\n#EXT-X-MAP:URI=\"init.mp4\"\n#EXTINF:8,\n1.m4s\n#EXT-X-ENDLIST\n")
+		case "/v/init.mp4", "/v/1.m4s", "/v/2.m4s", "/a/init.mp4", "/a/1.m4s":
+			fmt.Fprint(w, "["+r.URL.Path+"]")
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	m, db, dir := newTestManager(t, func(ctx context.Context, d store.Download) (*Source, error) {
+		return &Source{URL: server.URL + "/ep4.m3u8"}, nil
+	})
+	m.ConfigureHLS(func() string { return "720p" }, "")
+
+	d, _ := m.Enqueue(store.Download{Key: "ep4", AnimeTitle: "Anime", EpisodeNum: 4})
+	d = waitState(t, m, db, d.ID, store.DownloadCompleted)
+
+	// Sem ffmpeg, faixas fMP4 ficam como no DASH: o áudio num .m4a ao lado do vídeo
+	want := filepath.Join(dir, "Anime", "Episódio 04.mp4")
+	if got, err := os.ReadFile(want); err != nil || string(got) != "[/v/init.mp4][/v/1.m4s][/v/2.m4s]" {
+		t.Errorf("video = %q (err = %v)", got, err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dir, "Anime", "Episódio 04.m4a")); err != nil || string(got) != "[/a/init.mp4][/a/1.m4s]" {
+		t.Errorf("audio = %q (err = %v)", got, err)
+	}
+	if _, err := os.Stat(want + ".tracks"); !os.IsNotExist(err) {
+		t.Errorf("segment folder should be removed, stat err = %v", err)
+	}
+
+	// Áudio em MPEG-TS não tem como ser unido sem o ffmpeg: falha em vez de baixar o vídeo mudo
+	tsAudio = true
+	d, _ = m.Enqueue(store.Download{Key: "ep5", AnimeTitle: "Anime", EpisodeNum: 5})
+	d = waitState(t, m, db, d.ID, store.DownloadFailed)
+	if !strings.Contains(d.LastError, "ffmpeg") {
+		t.Errorf("LastError = %q; want it to mention ffmpeg", d.LastError)
+	}
+}
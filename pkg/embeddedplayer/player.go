@@ -254,7 +254,17 @@ func (p *EmbeddedPlayer) setupEventObservers() {
 
 // eventHandler processa eventos do MPV
 func (p *EmbeddedPlayer) eventHandler() {
+	lastTimeUpdate := time.Now()
 	for p.eventLoop {
+		// Notifica a posição ~1x por segundo enquanto reproduz (os segmentos de skip são
+		// conferidos a cada frame em Run)
+		if time.Since(lastTimeUpdate) >= time.Second {
+			lastTimeUpdate = time.Now()
+			if p.OnTimeUpdate != nil && p.GetState() == StatePlaying {
+				p.OnTimeUpdate(p.GetPosition(), p.GetDuration())
+			}
+		}
+
 		event := p.mpv.WaitEvent(0.1) // 100ms timeout
 		if event == nil {
 			continue
@@ -327,6 +337,11 @@ func (p *EmbeddedPlayer) checkSkipSegments() {
 
 // LoadFile carrega um arquivo de vídeo
 func (p *EmbeddedPlayer) LoadFile(path string) error {
+	return p.LoadFileAt(path, 0)
+}
+
+// LoadFileAt carrega um arquivo começando na posição indicada (segundos)
+func (p *EmbeddedPlayer) LoadFileAt(path string, start float64) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -336,6 +351,13 @@ func (p *EmbeddedPlayer) LoadFile(path string) error {
 
 	p.setState(StateLoading)
 
+	// "start" vale para o próximo arquivo carregado
+	if start > 0 {
+		p.mpv.SetPropertyString("start", fmt.Sprintf("%.3f", start))
+	} else {
+		p.mpv.SetPropertyString("start", "none")
+	}
+
 	err := p.mpv.Command([]string{"loadfile", path, "replace"})
 	if err != nil {
 		p.setState(StateError)
@@ -350,6 +372,11 @@ func (p *EmbeddedPlayer) LoadURL(url string) error {
 	return p.LoadFile(url) // MPV trata URLs igual a arquivos
 }
 
+// LoadURLAt carrega uma URL de streaming retomando da posição indicada (segundos)
+func (p *EmbeddedPlayer) LoadURLAt(url string, start float64) error {
+	return p.LoadFileAt(url, start)
+}
+
 // Play inicia reprodução
 func (p *EmbeddedPlayer) Play() {
 	p.mu.Lock()
@@ -125,6 +125,11 @@ func (p *EmbeddedPlayer) LoadURL(url string) error {
 	return nil
 }
 
+func (p *EmbeddedPlayer) LoadURLAt(url string, start float64) error {
+	fmt.Printf("[EmbeddedPlayer STUB] LoadURLAt: %s @ %.1fs (não implementado)\n", url, start)
+	return nil
+}
+
 func (p *EmbeddedPlayer) Play() {
 	fmt.Println("[EmbeddedPlayer STUB] Play")
 }
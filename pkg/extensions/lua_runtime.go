@@ -2,12 +2,15 @@ package extensions
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
+	"GoAnimeGUI/pkg/hostrules"
+
 	"github.com/PuerkitoBio/goquery"
 	lua "github.com/yuin/gopher-lua"
 	luajson "layeh.com/gopher-json"
@@ -23,7 +26,7 @@ type LuaExtension struct {
 // Opções de configuração para o runtime Lua
 var (
 	luaHTTPTimeout = 15 * time.Second
-	luaHTTPClient  = &http.Client{Timeout: luaHTTPTimeout}
+	luaHTTPClient  = &http.Client{Timeout: luaHTTPTimeout, Transport: hostrules.Default().Transport(nil)}
 )
 
 // NewLuaExtension cria uma nova extension a partir de um script Lua
@@ -248,6 +251,17 @@ func (e *LuaExtension) extractInfo() error {
 	if e.info.ID == "" {
 		return fmt.Errorf("extension.id is required")
 	}
+
+	// Extension.hostRules = { { hosts = {"cdn.exemplo.com"}, headers = { Referer = "..." } } }
+	if rules, ok := tbl.RawGetString("hostRules").(*lua.LTable); ok {
+		raw, err := luajson.Encode(rules)
+		if err == nil {
+			err = json.Unmarshal(raw, &e.info.HostRules)
+		}
+		if err != nil {
+			return fmt.Errorf("extension.hostRules inválido: %w", err)
+		}
+	}
 	if e.info.Name == "" {
 		e.info.Name = e.info.ID
 	}
@@ -272,6 +286,7 @@ func luaHTTPGet(L *lua.LState) int {
 	}
 
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	hostrules.Default().Apply(req)
 
 	if headers != nil {
 		headers.ForEach(func(k, v lua.LValue) {
@@ -313,6 +328,7 @@ func luaHTTPPost(L *lua.LState) int {
 
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	hostrules.Default().Apply(req)
 
 	if headers != nil {
 		headers.ForEach(func(k, v lua.LValue) {
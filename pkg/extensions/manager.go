@@ -10,6 +10,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"GoAnimeGUI/pkg/hostrules"
 )
 
 // Manager gerencia todas as extensions instaladas e repositórios
@@ -209,6 +211,7 @@ func (m *Manager) UninstallExtension(id string) error {
 	// Remove do mapa
 	delete(m.extensions, id)
 	m.mu.Unlock()
+	hostrules.Default().SetExtension(id, nil)
 
 	// Remove arquivos
 	if ext.ScriptPath != "" {
@@ -231,6 +234,7 @@ func (m *Manager) EnableExtension(id string) error {
 	}
 
 	ext.State = ExtensionStateEnabled
+	hostrules.Default().SetExtension(id, ext.Info.HostRules)
 	return m.saveConfig()
 }
 
@@ -245,6 +249,7 @@ func (m *Manager) DisableExtension(id string) error {
 	}
 
 	ext.State = ExtensionStateDisabled
+	hostrules.Default().SetExtension(id, nil)
 	return m.saveConfig()
 }
 
@@ -430,6 +435,7 @@ func (m *Manager) loadExtensionFromFile(id, scriptPath string) error {
 		UpdatedAt:  time.Now(),
 	}
 	m.mu.Unlock()
+	hostrules.Default().SetExtension(info.ID, info.HostRules)
 
 	fmt.Printf("[Extensions] Carregado: %s v%s\n", info.Name, info.Version)
 	return nil
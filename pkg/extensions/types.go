@@ -5,6 +5,8 @@ package extensions
 import (
 	"context"
 	"time"
+
+	"GoAnimeGUI/pkg/hostrules"
 )
 
 // ExtensionInfo contém metadados de uma extension
@@ -22,6 +24,9 @@ type ExtensionInfo struct {
 	HasPopular    bool     `json:"hasPopular"`    // Suporta listagem de populares
 	HasSearch     bool     `json:"hasSearch"`     // Suporta busca
 	Filters       []Filter `json:"filters"`       // Filtros disponíveis (gênero, ano, etc)
+
+	// Headers/cookies/TLS exigidos pelos hosts da fonte; sobrescrevem as regras do app enquanto ela estiver ativa
+	HostRules []hostrules.Rule `json:"hostRules,omitempty"`
 }
 
 // Filter representa um filtro de busca disponível
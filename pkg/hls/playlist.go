@@ -0,0 +1,320 @@
+// Package hls lê playlists HLS (m3u8): master com variantes de qualidade e
+// media playlists com segmentos, chaves de criptografia e byte ranges.
+package hls
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Variant é uma qualidade da master playlist (#EXT-X-STREAM-INF)
+type Variant struct {
+	URI       string
+	Bandwidth int
+	Width     int
+	Height    int
+	Codecs    string
+	Audio     string // GROUP-ID das faixas de áudio (#EXT-X-MEDIA) usadas pela variante
+}
+
+// Media é uma faixa alternativa da master playlist (#EXT-X-MEDIA); sem URI, a faixa já vem na variante
+type Media struct {
+	Type     string // "AUDIO", "SUBTITLES"...
+	GroupID  string
+	Name     string
+	Language string
+	URI      string
+	Default  bool
+}
+
+// Key é a criptografia de um segmento (#EXT-X-KEY)
+type Key struct {
+	Method string // "AES-128", "SAMPLE-AES"
+	URI    string
+	IV     []byte // nil = usar o número de sequência do segmento
+}
+
+// ByteRange é um trecho de um recurso (#EXT-X-BYTERANGE); Length 0 = arquivo inteiro
+type ByteRange struct {
+	Offset int64
+	Length int64
+}
+
+// Map é o segmento de inicialização de streams fMP4 (#EXT-X-MAP)
+type Map struct {
+	URI   string
+	Range ByteRange
+}
+
+// Segment é um trecho de mídia da playlist
+type Segment struct {
+	URI      string
+	Duration float64
+	Sequence int64
+	Range    ByteRange
+	Key      *Key
+	Map      *Map
+}
+
+// Playlist é uma master playlist (Variants) ou uma media playlist (Segments)
+type Playlist struct {
+	Master         bool
+	Variants       []Variant
+	Media          []Media
+	Segments       []Segment
+	TargetDuration int
+	MediaSequence  int64
+	Ended          bool // #EXT-X-ENDLIST: VOD completo
+}
+
+// Parse lê uma playlist. URIs relativas são resolvidas contra base.
+func Parse(body string, base *url.URL) (*Playlist, error) {
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	if !scanner.Scan() || !strings.HasPrefix(strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "\ufeff"), "#EXTM3U") {
+		return nil, fmt.Errorf("playlist m3u8 inválida: falta #EXTM3U")
+	}
+
+	p := &Playlist{}
+	var (
+		pendingVariant *Variant
+		duration       float64
+		byteRange      *ByteRange
+		key            *Key
+		initMap        *Map
+		nextOffset     = map[string]int64{} // Fim do último byte range de cada URI
+		sequence       int64
+		sequenceSet    bool
+	)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "#") {
+			uri := resolve(base, line)
+			if pendingVariant != nil {
+				pendingVariant.URI = uri
+				p.Variants = append(p.Variants, *pendingVariant)
+				pendingVariant = nil
+				continue
+			}
+			if !sequenceSet {
+				sequence, sequenceSet = p.MediaSequence, true
+			}
+			seg := Segment{URI: uri, Duration: duration, Sequence: sequence, Key: key, Map: initMap}
+			if byteRange != nil {
+				seg.Range = *byteRange
+				if seg.Range.Offset < 0 {
+					seg.Range.Offset = nextOffset[uri]
+				}
+				nextOffset[uri] = seg.Range.Offset + seg.Range.Length
+			}
+			p.Segments = append(p.Segments, seg)
+			sequence++
+			duration, byteRange = 0, nil
+			continue
+		}
+
+		tag, value, _ := strings.Cut(line, ":")
+		switch tag {
+		case "#EXT-X-STREAM-INF":
+			p.Master = true
+			attrs := ParseAttributes(value)
+			v := Variant{Codecs: attrs["CODECS"], Audio: attrs["AUDIO"]}
+			v.Bandwidth, _ = strconv.Atoi(attrs["BANDWIDTH"])
+			if w, h, ok := strings.Cut(attrs["RESOLUTION"], "x"); ok {
+				v.Width, _ = strconv.Atoi(w)
+				v.Height, _ = strconv.Atoi(h)
+			}
+			pendingVariant = &v
+		case "#EXT-X-MEDIA":
+			attrs := ParseAttributes(value)
+			p.Media = append(p.Media, Media{
+				Type:     attrs["TYPE"],
+				GroupID:  attrs["GROUP-ID"],
+				Name:     attrs["NAME"],
+				Language: attrs["LANGUAGE"],
+				URI:      resolve(base, attrs["URI"]),
+				Default:  attrs["DEFAULT"] == "YES",
+			})
+		case "#EXT-X-TARGETDURATION":
+			p.TargetDuration, _ = strconv.Atoi(value)
+		case "#EXT-X-MEDIA-SEQUENCE":
+			p.MediaSequence, _ = strconv.ParseInt(value, 10, 64)
+		case "#EXT-X-ENDLIST":
+			p.Ended = true
+		case "#EXTINF":
+			d, _, _ := strings.Cut(value, ",")
+			duration, _ = strconv.ParseFloat(strings.TrimSpace(d), 64)
+		case "#EXT-X-BYTERANGE":
+			r, err := parseByteRange(value)
+			if err != nil {
+				return nil, err
+			}
+			byteRange = &r
+		case "#EXT-X-KEY":
+			attrs := ParseAttributes(value)
+			if attrs["METHOD"] == "" || attrs["METHOD"] == "NONE" {
+				key = nil
+				continue
+			}
+			key = &Key{Method: attrs["METHOD"], URI: resolve(base, attrs["URI"])}
+			if iv := attrs["IV"]; iv != "" {
+				raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(iv, "0x"), "0X"))
+				if err != nil || len(raw) != 16 {
+					return nil, fmt.Errorf("IV inválido na playlist: %s", iv)
+				}
+				key.IV = raw
+			}
+		case "#EXT-X-MAP":
+			attrs := ParseAttributes(value)
+			initMap = &Map{URI: resolve(base, attrs["URI"])}
+			if r := attrs["BYTERANGE"]; r != "" {
+				br, err := parseByteRange(r)
+				if err != nil {
+					return nil, err
+				}
+				if br.Offset < 0 {
+					br.Offset = 0
+				}
+				initMap.Range = br
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SelectVariant escolhe a variante para a qualidade desejada ("1080p", "720p", "auto"...).
+// "auto" ou vazio pega a de maior bitrate; senão, a maior que não passa da altura pedida
+// (ou a menor disponível, se todas passarem).
+func (p *Playlist) SelectVariant(quality string) *Variant {
+	if len(p.Variants) == 0 {
+		return nil
+	}
+	variants := append([]Variant(nil), p.Variants...)
+	sort.SliceStable(variants, func(i, j int) bool {
+		if variants[i].Height != variants[j].Height {
+			return variants[i].Height > variants[j].Height
+		}
+		return variants[i].Bandwidth > variants[j].Bandwidth
+	})
+
+	target := QualityHeight(quality)
+	if target == 0 {
+		best := variants[0]
+		for _, v := range variants[1:] {
+			if v.Bandwidth > best.Bandwidth {
+				best = v
+			}
+		}
+		return &best
+	}
+	for _, v := range variants {
+		if v.Height > 0 && v.Height <= target {
+			return &v
+		}
+	}
+	return &variants[len(variants)-1]
+}
+
+// AudioFor retorna a faixa de áudio separada da variante (a DEFAULT do grupo, ou a primeira),
+// ou nil se a variante não usa grupo de áudio ou se o áudio já vem nela (faixa sem URI)
+func (p *Playlist) AudioFor(v *Variant) *Media {
+	if v == nil || v.Audio == "" {
+		return nil
+	}
+	var found *Media
+	for i := range p.Media {
+		m := &p.Media[i]
+		if m.Type != "AUDIO" || m.GroupID != v.Audio {
+			continue
+		}
+		if m.URI == "" {
+			if m.Default {
+				return nil
+			}
+			continue
+		}
+		if found == nil || (m.Default && !found.Default) {
+			found = m
+		}
+	}
+	return found
+}
+
+// QualityHeight converte "720p" em 720 (0 para "auto" ou desconhecido)
+func QualityHeight(quality string) int {
+	q := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(quality)), "p")
+	h, err := strconv.Atoi(q)
+	if err != nil || h <= 0 {
+		return 0
+	}
+	return h
+}
+
+// ParseAttributes lê uma lista de atributos (CHAVE=valor,CHAVE="valor, com vírgula")
+func ParseAttributes(s string) map[string]string {
+	attrs := make(map[string]string)
+	for len(s) > 0 {
+		name, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		name = strings.TrimSpace(name)
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+			rest = strings.TrimPrefix(rest, ",")
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		attrs[strings.ToUpper(name)] = value
+		s = rest
+	}
+	return attrs
+}
+
+// parseByteRange lê "<tamanho>[@<início>]"; sem início, Offset fica -1 (continua do anterior)
+func parseByteRange(value string) (ByteRange, error) {
+	length, offset, hasOffset := strings.Cut(strings.TrimSpace(value), "@")
+	r := ByteRange{Offset: -1}
+	var err error
+	if r.Length, err = strconv.ParseInt(length, 10, 64); err != nil {
+		return r, fmt.Errorf("byte range inválido: %s", value)
+	}
+	if hasOffset {
+		if r.Offset, err = strconv.ParseInt(offset, 10, 64); err != nil {
+			return r, fmt.Errorf("byte range inválido: %s", value)
+		}
+	}
+	return r, nil
+}
+
+func resolve(base *url.URL, ref string) string {
+	if base == nil || ref == "" {
+		return ref
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
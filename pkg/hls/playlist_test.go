@@ -0,0 +1,170 @@
+package hls
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParse_MasterAndSelectVariant(t *testing.T) {
+	base, _ := url.Parse("https://cdn.example/anime/master.m3u8?token=abc")
+	p, err := Parse(`#EXTM3U
+#EXT-X-STREAM-INF:BANDWIDTH=800000,RESOLUTION=640x360,CODECS="avc1.4d401e,mp4a.40.2"
+360/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=5000000,RESOLUTION=1920x1080
+https://other.example/1080.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=2500000,RESOLUTION=1280x720
+/abs/720.m3u8
+`, base)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !p.Master || len(p.Variants) != 3 {
+		t.Fatalf("playlist = %+v; want master with 3 variants", p)
+	}
+	if v := p.Variants[0]; v.URI != "https://cdn.example/anime/360/index.m3u8" || v.Codecs != "avc1.4d401e,mp4a.40.2" {
+		t.Errorf("variant[0] = %+v", v)
+	}
+
+	tests := map[string]int{"auto": 1080, "": 1080, "1080p": 1080, "720p": 720, "480p": 360, "240p": 360}
+	for quality, want := range tests {
+		if v := p.SelectVariant(quality); v.Height != want {
+			t.Errorf("SelectVariant(%q) = %dp; want %dp", quality, v.Height, want)
+		}
+	}
+	if v := p.SelectVariant("720p"); v.URI != "https://cdn.example/abs/720.m3u8" {
+		t.Errorf("720p URI = %q", v.URI)
+	}
+	if a := p.AudioFor(p.SelectVariant("720p")); a != nil {
+		t.Errorf("AudioFor() = %+v; want nil without audio groups", a)
+	}
+}
+
+func TestParse_AudioRenditions(t *testing.T) {
+	base, _ := url.Parse("https://cdn.example/ep/master.m3u8")
+	p, err := Parse(`#EXTM3U
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",LANGUAGE="en",URI="audio/en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Japanese",LANGUAGE="ja",DEFAULT=YES,URI="audio/ja.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="muxed",NAME="Main",DEFAULT=YES
+#EXT-X-STREAM-INF:BANDWIDTH=3000000,RESOLUTION=1280x720,AUDIO="aac"
+720.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=900000,RESOLUTION=854x480,AUDIO="muxed"
+480.m3u8
+`, base)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(p.Media) != 3 || len(p.Variants) != 2 {
+		t.Fatalf("playlist = %+v; want 3 media and 2 variants", p)
+	}
+	audio := p.AudioFor(p.SelectVariant("720p"))
+	if audio == nil || audio.URI != "https://cdn.example/ep/audio/ja.m3u8" || audio.Language != "ja" {
+		t.Errorf("AudioFor(720p) = %+v; want the default Japanese rendition", audio)
+	}
+	if audio := p.AudioFor(p.SelectVariant("480p")); audio != nil {
+		t.Errorf("AudioFor(480p) = %+v; want nil for audio muxed into the variant", audio)
+	}
+}
+
+func TestParse_MediaPlaylist(t *testing.T) {
+	base, _ := url.Parse("https://cdn.example/ep/index.m3u8")
+	p, err := Parse(`#EXTM3U
+#EXT-X-TARGETDURATION:10
+#EXT-X-MEDIA-SEQUENCE:7
+#EXT-X-MAP:URI="init.mp4",BYTERANGE="720@0"
+#EXT-X-KEY:METHOD=AES-128,URI="key.bin"
+#EXTINF:9.5,
+seg0.ts
+#EXT-X-KEY:METHOD=AES-128,URI="https://keys.example/k2",IV=0x000102030405060708090A0B0C0D0E0F
+#EXTINF:10,
+#EXT-X-BYTERANGE:1000@0
+all.ts
+#EXTINF:10,
+#EXT-X-BYTERANGE:500
+all.ts
+#EXT-X-KEY:METHOD=NONE
+#EXTINF:4.2,
+seg3.ts
+#EXT-X-ENDLIST
+`, base)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if p.Master || !p.Ended || p.TargetDuration != 10 || len(p.Segments) != 4 {
+		t.Fatalf("playlist = %+v", p)
+	}
+
+	s := p.Segments
+	if s[0].URI != "https://cdn.example/ep/seg0.ts" || s[0].Sequence != 7 || s[0].Duration != 9.5 {
+		t.Errorf("segment 0 = %+v", s[0])
+	}
+	if s[0].Key == nil || s[0].Key.URI != "https://cdn.example/ep/key.bin" || s[0].Key.IV != nil {
+		t.Errorf("segment 0 key = %+v", s[0].Key)
+	}
+	if s[0].Map == nil || s[0].Map.URI != "https://cdn.example/ep/init.mp4" || s[0].Map.Range.Length != 720 {
+		t.Errorf("segment 0 map = %+v", s[0].Map)
+	}
+	if s[1].Key.IV == nil || s[1].Key.IV[15] != 0x0F || s[1].Range != (ByteRange{Offset: 0, Length: 1000}) {
+		t.Errorf("segment 1 = %+v key %+v", s[1], s[1].Key)
+	}
+	if s[2].Range != (ByteRange{Offset: 1000, Length: 500}) || s[2].Sequence != 9 {
+		t.Errorf("segment 2 range = %+v (seq %d); want continuation at 1000", s[2].Range, s[2].Sequence)
+	}
+	if s[3].Key != nil {
+		t.Errorf("segment 3 key = %+v; want nil after METHOD=NONE", s[3].Key)
+	}
+
+	if _, err := Parse("<html>", base); err == nil {
+		t.Error("Parse(html) should fail")
+	}
+}
+
+func TestRewrite_EveryURI(t *testing.T) {
+	base, _ := url.Parse("https://cdn.example/anime/master.m3u8?token=abc")
+	body := `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-SESSION-KEY:METHOD=SAMPLE-AES,URI="skd://drm",KEYFORMAT="com.apple.streamingkeydelivery"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aud",NAME="Português, BR",LANGUAGE="pt",URI="audio/pt.m3u8?x=1"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="PT",URI="/subs/pt.m3u8"
+#EXT-X-MEDIA:TYPE=CLOSED-CAPTIONS,GROUP-ID="cc",NAME="CC",INSTREAM-ID="CC1"
+#EXT-X-I-FRAME-STREAM-INF:BANDWIDTH=90000,URI="iframes.m3u8"
+#EXT-X-STREAM-INF:BANDWIDTH=800000,CODECS="avc1.4d401e,mp4a.40.2",AUDIO="aud"
+360/index.m3u8
+#EXT-X-KEY:METHOD=AES-128,URI="key.bin",IV=0x01
+#EXT-X-MAP:URI="init.mp4",BYTERANGE="720@0"
+# comentário com https://nao.reescrever/
+#EXTINF:9.5,
+seg0.ts?sig=1
+`
+	got, err := Rewrite(body, base, func(uri string, kind URIKind) string {
+		if kind == URIPlaylist {
+			return "P(" + uri + ")"
+		}
+		return "M(" + uri + ")"
+	})
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+
+	want := `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-SESSION-KEY:METHOD=SAMPLE-AES,URI="skd://drm",KEYFORMAT="com.apple.streamingkeydelivery"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aud",NAME="Português, BR",LANGUAGE="pt",URI="P(https://cdn.example/anime/audio/pt.m3u8?x=1)"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="PT",URI="P(https://cdn.example/subs/pt.m3u8)"
+#EXT-X-MEDIA:TYPE=CLOSED-CAPTIONS,GROUP-ID="cc",NAME="CC",INSTREAM-ID="CC1"
+#EXT-X-I-FRAME-STREAM-INF:BANDWIDTH=90000,URI="P(https://cdn.example/anime/iframes.m3u8)"
+#EXT-X-STREAM-INF:BANDWIDTH=800000,CODECS="avc1.4d401e,mp4a.40.2",AUDIO="aud"
+P(https://cdn.example/anime/360/index.m3u8)
+#EXT-X-KEY:METHOD=AES-128,URI="M(https://cdn.example/anime/key.bin)",IV=0x01
+#EXT-X-MAP:URI="M(https://cdn.example/anime/init.mp4)",BYTERANGE="720@0"
+# comentário com https://nao.reescrever/
+#EXTINF:9.5,
+M(https://cdn.example/anime/seg0.ts?sig=1)
+`
+	if got != want {
+		t.Errorf("Rewrite() =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := Rewrite("<html>", base, nil); err == nil {
+		t.Error("Rewrite() should reject a body without #EXTM3U")
+	}
+}
@@ -0,0 +1,119 @@
+package hls
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URIKind diz para onde aponta uma URI da playlist
+type URIKind int
+
+const (
+	URIMedia    URIKind = iota // Segmento, parte, chave, init (#EXT-X-MAP) ou dados de sessão
+	URIPlaylist                // Outra playlist: variante, rendição (#EXT-X-MEDIA) ou I-frames
+)
+
+// uriTags são as tags com atributo de URI e o que ele referencia (RFC 8216 e LL-HLS)
+var uriTags = map[string]struct {
+	attr string
+	kind URIKind
+}{
+	"#EXT-X-KEY":                {"URI", URIMedia},
+	"#EXT-X-SESSION-KEY":        {"URI", URIMedia},
+	"#EXT-X-MAP":                {"URI", URIMedia},
+	"#EXT-X-PART":               {"URI", URIMedia},
+	"#EXT-X-PRELOAD-HINT":       {"URI", URIMedia},
+	"#EXT-X-SESSION-DATA":       {"URI", URIMedia},
+	"#EXT-X-CONTENT-STEERING":   {"SERVER-URI", URIMedia},
+	"#EXT-X-MEDIA":              {"URI", URIPlaylist},
+	"#EXT-X-I-FRAME-STREAM-INF": {"URI", URIPlaylist},
+	"#EXT-X-RENDITION-REPORT":   {"URI", URIPlaylist},
+}
+
+// Rewrite troca todas as URIs da playlist pelo retorno de fn: linhas de segmento e de variante
+// e os atributos de URI das tags. As URIs chegam resolvidas contra base; esquemas que não são
+// http(s) (data:, skd:...) ficam como estão. Tags, atributos e a ordem das linhas são mantidos.
+func Rewrite(body string, base *url.URL, fn func(uri string, kind URIKind) string) (string, error) {
+	lines := strings.Split(body, "\n")
+	if len(lines) == 0 || !strings.HasPrefix(strings.TrimPrefix(strings.TrimSpace(lines[0]), "\ufeff"), "#EXTM3U") {
+		return "", fmt.Errorf("playlist m3u8 inválida: falta #EXTM3U")
+	}
+
+	replace := func(ref string, kind URIKind) string {
+		uri := resolve(base, ref)
+		if !strings.HasPrefix(uri, "http://") && !strings.HasPrefix(uri, "https://") {
+			return ref
+		}
+		return fn(uri, kind)
+	}
+
+	nextKind := URIMedia
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		lines[i] = line
+		switch {
+		case line == "":
+		case !strings.HasPrefix(line, "#"):
+			lines[i] = replace(line, nextKind)
+			nextKind = URIMedia
+		default:
+			tag, value, ok := strings.Cut(line, ":")
+			if tag == "#EXT-X-STREAM-INF" {
+				nextKind = URIPlaylist
+			}
+			if t, found := uriTags[tag]; found && ok {
+				lines[i] = tag + ":" + rewriteAttribute(value, t.attr, func(ref string) string {
+					return replace(ref, t.kind)
+				})
+			}
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// rewriteAttribute troca o valor (entre aspas) de um atributo da lista, mantendo o resto do texto
+func rewriteAttribute(list, name string, fn func(string) string) string {
+	var b strings.Builder
+	for len(list) > 0 {
+		key, rest, ok := strings.Cut(list, "=")
+		if !ok {
+			b.WriteString(list)
+			break
+		}
+		b.WriteString(key)
+		b.WriteByte('=')
+
+		var value string
+		quoted := strings.HasPrefix(rest, `"`)
+		if quoted {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else if i := strings.IndexByte(rest, ','); i >= 0 {
+			value, rest = rest[:i], rest[i:]
+		} else {
+			value, rest = rest, ""
+		}
+
+		if strings.EqualFold(strings.TrimSpace(key), name) && value != "" {
+			value = fn(value)
+		}
+		if quoted {
+			b.WriteString(`"` + value + `"`)
+		} else {
+			b.WriteString(value)
+		}
+
+		// Separador até o próximo atributo
+		if strings.HasPrefix(rest, ",") {
+			b.WriteByte(',')
+			rest = rest[1:]
+		}
+		list = rest
+	}
+	return b.String()
+}
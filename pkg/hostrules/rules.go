@@ -0,0 +1,331 @@
+// Package hostrules aplica headers, cookies e configurações de TLS por host nas requisições de saída.
+// As regras vêm de três camadas, nesta ordem (a seguinte sobrescreve a anterior):
+// as embutidas no app, o arquivo host_rules.json na pasta de dados e as declaradas pelas extensions.
+// Assim um CDN que passou a exigir outro Referer se conserta editando o arquivo, sem recompilar.
+package hostrules
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"GoAnimeGUI/pkg/store"
+)
+
+// FileName é o nome do arquivo de regras na pasta de dados
+const FileName = "host_rules.json"
+
+// Rule associa padrões de host a headers, cookies e TLS.
+// Um padrão sem curinga casa o domínio e os subdomínios ("lightspeedst.net" casa "cdn1.lightspeedst.net");
+// com curinga é comparado ao host inteiro ("*animefire*", "*.sharepoint.com").
+type Rule struct {
+	Hosts   []string          `json:"hosts"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Cookies map[string]string `json:"cookies,omitempty"`
+	TLS     *TLSSettings      `json:"tls,omitempty"`
+	Origin  string            `json:"origin,omitempty"` // Camada de onde veio: "builtin", "file" ou "extension:<id>"
+}
+
+// TLSSettings ajusta o TLS das conexões com o host
+type TLSSettings struct {
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"` // Certificado inválido/expirado
+	MinVersion         string `json:"minVersion,omitempty"`         // "1.0", "1.1", "1.2" ou "1.3"
+	ServerName         string `json:"serverName,omitempty"`         // SNI diferente do host
+}
+
+// fileFormat é o conteúdo de host_rules.json
+type fileFormat struct {
+	Rules []Rule `json:"rules"`
+}
+
+// builtinRules substituem os strings.Contains espalhados pelo app
+var builtinRules = []Rule{
+	{
+		Hosts: []string{"lightspeedst.net", "*animefire*"},
+		Headers: map[string]string{
+			"Referer": "https://animefire.plus/",
+			"Origin":  "https://animefire.plus",
+		},
+	},
+	{
+		Hosts: []string{"*sharepoint*", "*microsoft*"},
+		Headers: map[string]string{
+			"Referer": "https://myanime.sharepoint.com/",
+			"Origin":  "https://myanime.sharepoint.com",
+			"Accept":  "*/*",
+		},
+	},
+	{
+		Hosts: []string{"*allanime*", "*gogoanime*"},
+		Headers: map[string]string{
+			"Referer": "https://allanime.to/",
+			"Origin":  "https://allanime.to",
+		},
+	},
+}
+
+// Rules é o conjunto de regras em uso. Seguro para uso concorrente.
+type Rules struct {
+	path       string
+	builtin    []Rule
+	file       []Rule
+	extensions map[string][]Rule
+	mutex      sync.RWMutex
+}
+
+var (
+	defaultRules *Rules
+	defaultOnce  sync.Once
+)
+
+// DefaultPath retorna o caminho do arquivo de regras (~/.config/GoAnime/host_rules.json)
+func DefaultPath() string {
+	return filepath.Join(filepath.Dir(store.DefaultPath()), FileName)
+}
+
+// Default retorna as regras compartilhadas pelo app, com o arquivo de DefaultPath já carregado
+func Default() *Rules {
+	defaultOnce.Do(func() {
+		defaultRules = New(DefaultPath())
+		if err := defaultRules.Reload(); err != nil {
+			fmt.Printf("[HostRules] %v\n", err)
+		}
+	})
+	return defaultRules
+}
+
+// New cria as regras com as embutidas. O arquivo em path só é lido em Reload.
+func New(path string) *Rules {
+	r := &Rules{
+		path:       path,
+		extensions: make(map[string][]Rule),
+	}
+	for _, rule := range builtinRules {
+		rule.Origin = "builtin"
+		r.builtin = append(r.builtin, rule)
+	}
+	return r
+}
+
+// Reload relê o arquivo de regras. Arquivo ausente não é erro; arquivo inválido mantém as regras anteriores.
+func (r *Rules) Reload() error {
+	raw, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		r.mutex.Lock()
+		r.file = nil
+		r.mutex.Unlock()
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao ler %s: %w", r.path, err)
+	}
+
+	var parsed fileFormat
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return fmt.Errorf("regras inválidas em %s: %w", r.path, err)
+	}
+	for i := range parsed.Rules {
+		parsed.Rules[i].Origin = "file"
+	}
+
+	r.mutex.Lock()
+	r.file = parsed.Rules
+	r.mutex.Unlock()
+	fmt.Printf("[HostRules] %d regras carregadas de %s\n", len(parsed.Rules), r.path)
+	return nil
+}
+
+// SetExtension registra as regras declaradas por uma extension (nil remove).
+// Extensions não são confiáveis como o arquivo do usuário: TLS é descartado e só valem
+// padrões presos a um domínio ("cdn.site.com" ou "*.site.com"), nunca "*" ou "*cdn*".
+func (r *Rules) SetExtension(id string, rules []Rule) {
+	own := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.TLS != nil {
+			fmt.Printf("[HostRules] Extension %s: TLS ignorado em %v\n", id, rule.Hosts)
+			rule.TLS = nil
+		}
+		var hosts []string
+		for _, pattern := range rule.Hosts {
+			if scopedPattern(pattern) {
+				hosts = append(hosts, pattern)
+			} else {
+				fmt.Printf("[HostRules] Extension %s: padrão de host amplo demais ignorado: %q\n", id, pattern)
+			}
+		}
+		if len(hosts) == 0 || (len(rule.Headers) == 0 && len(rule.Cookies) == 0) {
+			continue
+		}
+		rule.Hosts = hosts
+		rule.Origin = "extension:" + id
+		own = append(own, rule)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if len(own) == 0 {
+		delete(r.extensions, id)
+		return
+	}
+	r.extensions[id] = own
+}
+
+// scopedPattern diz se o padrão está preso a um domínio: sem curinga ou com "*." só no início,
+// e com pelo menos um ponto no domínio ("com" ou "*.com" não valem)
+func scopedPattern(pattern string) bool {
+	domain := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(pattern)), "*.")
+	if strings.ContainsAny(domain, "*?[]") {
+		return false
+	}
+	dot := strings.Index(domain, ".")
+	return dot > 0 && dot < len(domain)-1
+}
+
+// List retorna todas as regras na ordem de aplicação
+func (r *Rules) List() []Rule {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	all := append(append([]Rule{}, r.builtin...), r.file...)
+	ids := make([]string, 0, len(r.extensions))
+	for id := range r.extensions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		all = append(all, r.extensions[id]...)
+	}
+	return all
+}
+
+// matching retorna as regras que valem para o host, na ordem de aplicação
+func (r *Rules) matching(host string) []Rule {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return nil
+	}
+	var found []Rule
+	for _, rule := range r.List() {
+		if rule.Matches(host) {
+			found = append(found, rule)
+		}
+	}
+	return found
+}
+
+// Matches diz se a regra vale para o host
+func (rule Rule) Matches(host string) bool {
+	host = strings.ToLower(host)
+	for _, pattern := range rule.Hosts {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if strings.ContainsAny(pattern, "*?[") {
+			if ok, _ := path.Match(pattern, host); ok {
+				return true
+			}
+			continue
+		}
+		if host == pattern || strings.HasSuffix(host, "."+pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply aplica as regras do host da requisição: os headers das regras sobrescrevem os padrões
+// já definidos e os cookies são somados aos que a requisição já tem (os dela prevalecem).
+// Headers próprios da fonte (VideoSource.Headers, tabela do http_get) devem ser aplicados depois.
+func (r *Rules) Apply(req *http.Request) {
+	r.apply(req, req.URL.Hostname())
+}
+
+// ApplyStream aplica as regras do host do stream e, se o recurso estiver em outro host
+// (segmentos num CDN diferente da playlist), as do host da requisição por cima
+func (r *Rules) ApplyStream(req *http.Request, streamURL string) {
+	if parsed, err := url.Parse(streamURL); err == nil && !strings.EqualFold(parsed.Hostname(), req.URL.Hostname()) {
+		r.apply(req, parsed.Hostname())
+	}
+	r.apply(req, req.URL.Hostname())
+}
+
+func (r *Rules) apply(req *http.Request, host string) {
+	for _, rule := range r.matching(host) {
+		for key, value := range rule.Headers {
+			req.Header.Set(key, value)
+		}
+		for name, value := range rule.Cookies {
+			if _, err := req.Cookie(name); err != nil {
+				req.AddCookie(&http.Cookie{Name: name, Value: value})
+			}
+		}
+	}
+}
+
+// TLSFor retorna a configuração de TLS do host (a última regra com TLS vence) ou nil
+func (r *Rules) TLSFor(host string) *TLSSettings {
+	var settings *TLSSettings
+	for _, rule := range r.matching(host) {
+		if rule.TLS != nil {
+			settings = rule.TLS
+		}
+	}
+	return settings
+}
+
+// Transport retorna um RoundTripper que usa base para os hosts sem regra de TLS
+// e uma cópia de base com o TLS da regra para os demais
+func (r *Rules) Transport(base *http.Transport) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	return &transport{rules: r, base: base, clones: make(map[TLSSettings]*http.Transport)}
+}
+
+type transport struct {
+	rules  *Rules
+	base   *http.Transport
+	clones map[TLSSettings]*http.Transport
+	mutex  sync.Mutex
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	settings := t.rules.TLSFor(req.URL.Hostname())
+	if settings == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	t.mutex.Lock()
+	clone, ok := t.clones[*settings]
+	if !ok {
+		clone = t.base.Clone()
+		if clone.TLSClientConfig == nil {
+			clone.TLSClientConfig = &tls.Config{}
+		}
+		clone.TLSClientConfig.InsecureSkipVerify = settings.InsecureSkipVerify
+		clone.TLSClientConfig.ServerName = settings.ServerName
+		if version, ok := tlsVersions[settings.MinVersion]; ok {
+			clone.TLSClientConfig.MinVersion = version
+		}
+		t.clones[*settings] = clone
+	}
+	t.mutex.Unlock()
+	return clone.RoundTrip(req)
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
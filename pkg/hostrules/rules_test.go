@@ -0,0 +1,81 @@
+package hostrules
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRules_LayersAndMatching(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	rules := New(path)
+
+	req, _ := http.NewRequest("GET", "https://cdn3.lightspeedst.net/v/720p.mp4", nil)
+	rules.Apply(req)
+	if got := req.Header.Get("Referer"); got != "https://animefire.plus/" {
+		t.Errorf("builtin Referer = %q; want animefire", got)
+	}
+
+	// O arquivo sobrescreve as embutidas e a extension sobrescreve o arquivo
+	os.WriteFile(path, []byte(`{"rules": [
+		{"hosts": ["lightspeedst.net"], "headers": {"Referer": "https://animefire.io/"}, "cookies": {"cf": "1"}},
+		{"hosts": ["*.example.com"], "tls": {"insecureSkipVerify": true}}
+	]}`), 0644)
+	if err := rules.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	rules.SetExtension("com.goanime.test", []Rule{{Hosts: []string{"cdn3.lightspeedst.net"}, Headers: map[string]string{"Origin": "https://ext.example"}}})
+
+	req, _ = http.NewRequest("GET", "https://cdn3.lightspeedst.net/v/720p.mp4", nil)
+	req.AddCookie(&http.Cookie{Name: "cf", Value: "own"})
+	rules.Apply(req)
+	if got := req.Header.Get("Referer"); got != "https://animefire.io/" {
+		t.Errorf("file Referer = %q; want the file rule", got)
+	}
+	if got := req.Header.Get("Origin"); got != "https://ext.example" {
+		t.Errorf("Origin = %q; want the extension rule", got)
+	}
+	if got := req.Header.Get("Cookie"); got != "cf=own" {
+		t.Errorf("Cookie = %q; want the request's own cookie kept", got)
+	}
+
+	// Segmento em outro CDN herda as regras do host do stream
+	req, _ = http.NewRequest("GET", "https://segments.other.net/1.ts", nil)
+	rules.ApplyStream(req, "https://allanime.day/master.m3u8")
+	if got := req.Header.Get("Referer"); got != "https://allanime.to/" {
+		t.Errorf("ApplyStream Referer = %q; want the stream host's rule", got)
+	}
+
+	if tls := rules.TLSFor("api.example.com"); tls == nil || !tls.InsecureSkipVerify {
+		t.Errorf("TLSFor() = %+v; want the file TLS settings", tls)
+	}
+	if rules.TLSFor("example.com") != nil || rules.TLSFor("notlightspeedst.net") != nil {
+		t.Error("patterns should not match unrelated hosts")
+	}
+
+	// Extensions não ligam TLS nem alcançam qualquer host
+	rules.SetExtension("com.goanime.wild", []Rule{
+		{Hosts: []string{"*", "*cdn*", "*.com", "com"}, Headers: map[string]string{"Authorization": "Bearer x"}},
+		{Hosts: []string{"*.wild.example", "*"}, Headers: map[string]string{"X-Ext": "1"}, TLS: &TLSSettings{InsecureSkipVerify: true}},
+	})
+	req, _ = http.NewRequest("GET", "https://bank.example.net/", nil)
+	rules.Apply(req)
+	if req.Header.Get("Authorization") != "" || req.Header.Get("X-Ext") != "" {
+		t.Errorf("wildcard extension rule applied to an unrelated host: %v", req.Header)
+	}
+	if rules.TLSFor("cdn.wild.example") != nil {
+		t.Error("extension rules should not change TLS")
+	}
+	req, _ = http.NewRequest("GET", "https://cdn.wild.example/", nil)
+	rules.Apply(req)
+	if got := req.Header.Get("X-Ext"); got != "1" {
+		t.Errorf("X-Ext = %q; want the scoped extension pattern kept", got)
+	}
+	rules.SetExtension("com.goanime.wild", nil)
+
+	rules.SetExtension("com.goanime.test", nil)
+	if n := len(rules.List()); n != len(builtinRules)+2 {
+		t.Errorf("List() has %d rules after removing the extension; want %d", n, len(builtinRules)+2)
+	}
+}
@@ -0,0 +1,147 @@
+package identity
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"GoAnimeGUI/pkg/anilist"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	// Tags de grupo/qualidade de torrents: [SubsPlease], [1080p], (BD 1080p)
+	bracketTag = regexp.MustCompile(`\[[^\]]*\]|\((?:[^)]*(?:\d{3,4}p|BD|WEB|HEVC|x26[45])[^)]*)\)`)
+	// Marcadores de idioma e de página das fontes brasileiras e inglesas
+	sourceSuffix = regexp.MustCompile(`(?i)\s*[(\[]?\b(?:dublado|legendado|dub|sub|todos os epis[oó]dios)\b[)\]]?`)
+	// Número de episódio no fim de nomes de arquivo: "Frieren - 05", "Ep 12", "Episódio 3"
+	episodeSuffix = regexp.MustCompile(`(?i)\s+(?:-\s*\d{1,4}(?:v\d)?|(?:ep|ep\.|episode|epis[oó]dio)\s*\d{1,4})\b.*$`)
+	// Qualidade e codec soltos no título
+	qualityTag = regexp.MustCompile(`(?i)\b(?:\d{3,4}p|4k|hevc|x26[45]|h\.?26[45]|bdrip|web-?dl|webrip|aac|flac)\b`)
+	yearTag    = regexp.MustCompile(`\(((?:19|20)\d{2})\)`)
+
+	// Temporadas escritas de formas diferentes viram só o número
+	seasonOrdinal = regexp.MustCompile(`\b(\d+)(?:st|nd|rd|th|a|ª)?\s+(?:season|temporada)\b`)
+	seasonPrefix  = regexp.MustCompile(`\b(?:season|temporada|s)\s*0*(\d+)\b`)
+	romanNumeral  = map[string]string{"ii": "2", "iii": "3", "iv": "4", "v": "5", "vi": "6"}
+)
+
+// ParseTitle limpa um título vindo de uma fonte ou de um nome de torrent e extrai o ano, se houver
+// Ex: "[SubsPlease] Sousou no Frieren (2023) - 05 (1080p)" -> "Sousou no Frieren", 2023
+func ParseTitle(raw string) (title string, year int) {
+	title = raw
+	if m := yearTag.FindStringSubmatch(title); m != nil {
+		year, _ = strconv.Atoi(m[1])
+		title = strings.Replace(title, m[0], " ", 1)
+	}
+	title = bracketTag.ReplaceAllString(title, " ")
+	title = episodeSuffix.ReplaceAllString(title, "")
+	title = sourceSuffix.ReplaceAllString(title, "")
+	title = qualityTag.ReplaceAllString(title, "")
+	title = strings.Trim(strings.Join(strings.Fields(title), " "), " -–:")
+	return title, year
+}
+
+// normalize reduz um título a tokens comparáveis: minúsculas, sem acentos
+// nem pontuação, e temporadas ("2nd Season", "Temporada 2", "II") como número
+func normalize(title string) []string {
+	title = norm.NFD.String(strings.ToLower(title))
+	title = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			return -1
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return r
+		default:
+			return ' '
+		}
+	}, title)
+	title = seasonOrdinal.ReplaceAllString(title, "$1")
+	title = seasonPrefix.ReplaceAllString(title, "$1")
+
+	tokens := strings.Fields(title)
+	for i, tok := range tokens {
+		if n, ok := romanNumeral[tok]; ok && i > 0 {
+			tokens[i] = n
+		}
+	}
+	return tokens
+}
+
+// titleSimilarity compara dois títulos pelo coeficiente de Dice dos tokens (0-1).
+// Um número presente em só um dos lados (temporada) pesa contra: "Frieren" != "Frieren 2".
+func titleSimilarity(a, b string) float64 {
+	ta, tb := normalize(a), normalize(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	if strings.Join(ta, " ") == strings.Join(tb, " ") {
+		return 1
+	}
+
+	count := make(map[string]int, len(tb))
+	for _, t := range tb {
+		count[t]++
+	}
+	common := 0
+	for _, t := range ta {
+		if count[t] > 0 {
+			count[t]--
+			common++
+		}
+	}
+	score := 2 * float64(common) / float64(len(ta)+len(tb))
+	if seasonNumber(ta) != seasonNumber(tb) {
+		score *= 0.7
+	}
+	return score
+}
+
+// seasonNumber retorna o último número do título (1 se não houver)
+func seasonNumber(tokens []string) int {
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if n, err := strconv.Atoi(tokens[i]); err == nil && n > 1 && n < 20 {
+			return n
+		}
+	}
+	return 1
+}
+
+// Score avalia o quanto um resultado da AniList corresponde à consulta (0-1).
+// O título decide; ano e número de episódios confirmam ou desempatam.
+func Score(q Query, media *anilist.AnimeMedia) float64 {
+	title, year := ParseTitle(q.Title)
+	if q.Year == 0 {
+		q.Year = year
+	}
+
+	best := 0.0
+	candidates := append([]string{media.Title.Romaji, media.Title.English, media.Title.Native}, media.Synonyms...)
+	for _, t := range candidates {
+		if s := titleSimilarity(title, t); s > best {
+			best = s
+		}
+	}
+
+	if q.Year > 0 && media.SeasonYear > 0 {
+		switch diff := q.Year - media.SeasonYear; {
+		case diff == 0:
+			best += 0.1
+		case diff < -1 || diff > 1:
+			best -= 0.2
+		}
+	}
+	if q.Episodes > 0 && media.Episodes > 0 {
+		// A fonte pode ter menos episódios (ainda em exibição), nunca muitos a mais
+		switch diff := float64(q.Episodes - media.Episodes); {
+		case diff == 0:
+			best += 0.1
+		case diff > math.Max(2, 0.2*float64(media.Episodes)):
+			best -= 0.1
+		}
+	}
+	return math.Max(0, math.Min(1, best))
+}
@@ -0,0 +1,205 @@
+// Package identity resolve entradas de qualquer fonte (AnimeFire, AllAnime, Anitube,
+// AnimesFlix, extensões, nomes de torrent) para os IDs canônicos da AniList e do MyAnimeList.
+// Os vínculos ficam gravados no SQLite e podem ser corrigidos manualmente pelo usuário.
+package identity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"GoAnimeGUI/pkg/anilist"
+	"GoAnimeGUI/pkg/store"
+)
+
+const (
+	// MinConfidence é a pontuação mínima para aceitar um resultado automaticamente
+	MinConfidence = 0.75
+
+	// Quanto tempo um "não encontrado" fica em cache antes de buscar de novo
+	negativeTTL = 7 * 24 * time.Hour
+	searchLimit = 8
+)
+
+// Query descreve uma entrada a ser identificada. Sem URL, a chave é o título normalizado
+// (nomes de torrent, buscas soltas); com URL, cada página de fonte tem seu próprio vínculo.
+type Query struct {
+	Title    string `json:"title"`
+	Source   string `json:"source"`
+	URL      string `json:"url"`
+	Year     int    `json:"year"`
+	Episodes int    `json:"episodes"`
+}
+
+// Key retorna a chave do vínculo no banco
+func (q Query) Key() string {
+	if q.URL != "" {
+		return "url:" + q.URL
+	}
+	return q.titleKey()
+}
+
+func (q Query) titleKey() string {
+	title, _ := ParseTitle(q.Title)
+	return "title:" + strings.Join(normalize(title), " ")
+}
+
+// Candidate é um resultado da AniList com a pontuação de correspondência
+type Candidate struct {
+	AniListID int     `json:"anilistId"`
+	MALID     int     `json:"malId"`
+	Title     string  `json:"title"`
+	Image     string  `json:"image"`
+	Year      int     `json:"year"`
+	Episodes  int     `json:"episodes"`
+	Score     float64 `json:"score"`
+}
+
+// SearchFunc busca animes na AniList (anilist.SearchAnime; substituível em testes)
+type SearchFunc func(title string, limit int) ([]*anilist.AnimeMedia, error)
+
+// Resolver mapeia entradas de fontes para IDs canônicos com cache persistente
+type Resolver struct {
+	db     *store.DB
+	search SearchFunc
+}
+
+var (
+	defaultResolver *Resolver
+	defaultErr      error
+	defaultOnce     sync.Once
+)
+
+// New cria um resolver sobre o banco e a função de busca informados
+func New(db *store.DB, search SearchFunc) *Resolver {
+	return &Resolver{db: db, search: search}
+}
+
+// Default retorna o resolver do aplicativo (banco padrão e busca da AniList)
+func Default() (*Resolver, error) {
+	defaultOnce.Do(func() {
+		db, err := store.Default()
+		if err != nil {
+			defaultErr = err
+			return
+		}
+		defaultResolver = New(db, anilist.SearchAnime)
+	})
+	return defaultResolver, defaultErr
+}
+
+// Resolve retorna o vínculo da entrada, buscando na AniList se ainda não houver um.
+// Se nenhum resultado for confiável, retorna um vínculo com AniListID zero.
+func (r *Resolver) Resolve(q Query) (*store.AnimeIdentity, error) {
+	key := q.Key()
+	cached, err := r.db.GetIdentity(key)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil && (cached.AniListID > 0 || cached.Manual || !expired(cached.UpdatedAt)) {
+		return cached, nil
+	}
+
+	// Uma página de fonte nova aproveita o vínculo já feito para o mesmo título
+	if q.URL != "" {
+		if byTitle, err := r.db.GetIdentity(q.titleKey()); err == nil && byTitle != nil && byTitle.AniListID > 0 {
+			return r.save(key, q.Title, byTitle.AniListID, byTitle.MALID, byTitle.Confidence, false)
+		}
+	}
+
+	candidates, err := r.Candidates(q)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(candidates) == 0 || candidates[0].Score < MinConfidence {
+		best := 0.0
+		if len(candidates) > 0 {
+			best = candidates[0].Score
+		}
+		fmt.Printf("[Identity] Nenhum resultado confiável para %q (melhor: %.2f)\n", q.Title, best)
+		return r.save(key, q.Title, 0, 0, best, false)
+	}
+
+	best := candidates[0]
+	fmt.Printf("[Identity] %q -> AniList %d / MAL %d (%.2f)\n", q.Title, best.AniListID, best.MALID, best.Score)
+	if q.URL != "" {
+		r.save(q.titleKey(), q.Title, best.AniListID, best.MALID, best.Score, false)
+	}
+	return r.save(key, q.Title, best.AniListID, best.MALID, best.Score, false)
+}
+
+// Candidates busca a entrada na AniList e retorna os resultados do mais ao menos provável
+func (r *Resolver) Candidates(q Query) ([]Candidate, error) {
+	title, _ := ParseTitle(q.Title)
+	if title == "" {
+		return []Candidate{}, nil
+	}
+
+	results, err := r.search(title, searchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar %q na AniList: %w", title, err)
+	}
+
+	candidates := make([]Candidate, 0, len(results))
+	for _, media := range results {
+		candidates = append(candidates, Candidate{
+			AniListID: media.ID,
+			MALID:     media.MALID,
+			Title:     media.GetBestTitle(),
+			Image:     media.GetBestImage(),
+			Year:      media.SeasonYear,
+			Episodes:  media.Episodes,
+			Score:     Score(q, media),
+		})
+	}
+	// Estável: em caso de empate vale a ordem da AniList (popularidade)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates, nil
+}
+
+// Override grava uma correção manual, que nunca é substituída pela resolução automática.
+// Vale para a página da fonte e também para o título, usado por imagens e AniSkip.
+func (r *Resolver) Override(q Query, anilistID, malID int) (*store.AnimeIdentity, error) {
+	if q.URL != "" {
+		if _, err := r.save(q.titleKey(), q.Title, anilistID, malID, 1, true); err != nil {
+			return nil, err
+		}
+	}
+	return r.save(q.Key(), q.Title, anilistID, malID, 1, true)
+}
+
+// Forget apaga o vínculo da entrada (inclusive correções manuais) para que seja resolvido de novo
+func (r *Resolver) Forget(q Query) error {
+	if q.URL != "" {
+		if err := r.db.DeleteIdentity(q.titleKey()); err != nil {
+			return err
+		}
+	}
+	return r.db.DeleteIdentity(q.Key())
+}
+
+func (r *Resolver) save(key, title string, anilistID, malID int, confidence float64, manual bool) (*store.AnimeIdentity, error) {
+	id := store.AnimeIdentity{
+		Key:        key,
+		Title:      title,
+		AniListID:  anilistID,
+		MALID:      malID,
+		Confidence: confidence,
+		Manual:     manual,
+	}
+	if err := r.db.SaveIdentity(id); err != nil {
+		return nil, fmt.Errorf("erro ao salvar vínculo de %q: %w", title, err)
+	}
+	// Relê: se já havia uma correção manual, ela prevalece
+	return r.db.GetIdentity(key)
+}
+
+func expired(updatedAt string) bool {
+	t, err := time.Parse(time.RFC3339, updatedAt)
+	return err != nil || time.Since(t) > negativeTTL
+}
@@ -0,0 +1,150 @@
+package identity
+
+import (
+	"testing"
+
+	"GoAnimeGUI/pkg/anilist"
+	"GoAnimeGUI/pkg/store"
+)
+
+func media(id, malID int, romaji, english string, year, episodes int, synonyms ...string) *anilist.AnimeMedia {
+	return &anilist.AnimeMedia{
+		ID:         id,
+		MALID:      malID,
+		Title:      anilist.Title{Romaji: romaji, English: english},
+		Synonyms:   synonyms,
+		SeasonYear: year,
+		Episodes:   episodes,
+	}
+}
+
+var catalog = []*anilist.AnimeMedia{
+	media(21, 21, "ONE PIECE", "ONE PIECE", 1999, 0),
+	media(154587, 52991, "Sousou no Frieren", "Frieren: Beyond Journey's End", 2023, 28, "Frieren at the Funeral"),
+	media(16498, 16498, "Shingeki no Kyojin", "Attack on Titan", 2013, 25),
+	media(20958, 25777, "Shingeki no Kyojin 2", "Attack on Titan Season 2", 2017, 12),
+}
+
+func TestParseTitle(t *testing.T) {
+	tests := []struct {
+		raw   string
+		title string
+		year  int
+	}{
+		{"[SubsPlease] Sousou no Frieren - 05 (1080p) [ABCD1234].mkv", "Sousou no Frieren", 0},
+		{"[EMBER] Frieren: Beyond Journey's End (2023) [BDRip 1080p HEVC]", "Frieren: Beyond Journey's End", 2023},
+		{"Shingeki no Kyojin 2 Dublado", "Shingeki no Kyojin 2", 0},
+		{"Sousou no Frieren (Legendado) - Todos os Episódios", "Sousou no Frieren", 0},
+		{"One Piece Episódio 1100", "One Piece", 0},
+		{"Re:Zero - 2nd Season", "Re:Zero - 2nd Season", 0},
+	}
+	for _, tt := range tests {
+		title, year := ParseTitle(tt.raw)
+		if title != tt.title || year != tt.year {
+			t.Errorf("ParseTitle(%q) = %q, %d; want %q, %d", tt.raw, title, year, tt.title, tt.year)
+		}
+	}
+}
+
+func TestScore_Seasons(t *testing.T) {
+	first, second := catalog[2], catalog[3]
+
+	tests := []struct {
+		title string
+		want  *anilist.AnimeMedia
+	}{
+		{"Shingeki no Kyojin", first},
+		{"Shingeki no Kyojin 2nd Season", second},
+		{"Attack on Titan Temporada 2", second},
+		{"Shingeki no Kyojin II", second},
+	}
+	for _, tt := range tests {
+		q := Query{Title: tt.title}
+		other := first
+		if tt.want == first {
+			other = second
+		}
+		if Score(q, tt.want) <= Score(q, other) {
+			t.Errorf("Score(%q): %d = %.2f, %d = %.2f; want %d ahead",
+				tt.title, tt.want.ID, Score(q, tt.want), other.ID, Score(q, other), tt.want.ID)
+		}
+	}
+
+	// Remakes com o mesmo título: ano e episódios desempatam
+	original := media(136, 136, "HUNTER×HUNTER", "Hunter x Hunter", 1999, 62)
+	remake := media(11061, 11061, "HUNTER×HUNTER (2011)", "Hunter x Hunter (2011)", 2011, 148)
+	q := Query{Title: "Hunter x Hunter (2011)", Episodes: 148}
+	if Score(q, remake) <= Score(q, original) {
+		t.Errorf("Score(%q): remake = %.2f, original = %.2f; want remake ahead", q.Title, Score(q, remake), Score(q, original))
+	}
+}
+
+func newTestResolver(t *testing.T) (*Resolver, *int) {
+	t.Helper()
+	db := store.OpenTemp(t)
+
+	searches := 0
+	return New(db, func(title string, limit int) ([]*anilist.AnimeMedia, error) {
+		searches++
+		return catalog, nil
+	}), &searches
+}
+
+func TestResolver_ResolveAndCache(t *testing.T) {
+	r, searches := newTestResolver(t)
+
+	q := Query{Title: "Frieren at the Funeral (Dublado)", Source: "AnimeFire", URL: "https://animefire/frieren-dublado"}
+	id, err := r.Resolve(q)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if id.AniListID != 154587 || id.MALID != 52991 {
+		t.Fatalf("Resolve() = %+v; want Frieren", id)
+	}
+
+	// Segunda chamada e torrent com o mesmo título vêm do cache
+	r.Resolve(q)
+	if id, _ := r.Resolve(Query{Title: "[SubsPlease] Frieren at the Funeral - 12 (1080p)"}); id.AniListID != 154587 {
+		t.Errorf("Resolve(torrent) = %+v; want cached Frieren", id)
+	}
+	if *searches != 1 {
+		t.Errorf("searches = %d; want 1", *searches)
+	}
+
+	// Nada parecido: resultado negativo também fica em cache
+	miss := Query{Title: "Anime Inexistente"}
+	if id, _ := r.Resolve(miss); id.AniListID != 0 {
+		t.Errorf("Resolve(miss) = %+v; want no match", id)
+	}
+	r.Resolve(miss)
+	if *searches != 2 {
+		t.Errorf("searches = %d; want 2", *searches)
+	}
+}
+
+func TestResolver_Override(t *testing.T) {
+	r, _ := newTestResolver(t)
+
+	q := Query{Title: "Shingeki no Kyojin", URL: "https://allanime/aot"}
+	if id, _ := r.Resolve(q); id.AniListID != 16498 {
+		t.Fatalf("Resolve() = %+v; want season 1", id)
+	}
+
+	if _, err := r.Override(q, 20958, 25777); err != nil {
+		t.Fatalf("Override() error = %v", err)
+	}
+	if id, _ := r.Resolve(q); id.AniListID != 20958 || !id.Manual {
+		t.Errorf("Resolve() after Override = %+v; want manual season 2", id)
+	}
+	// A correção vale também para buscas só pelo título
+	if id, _ := r.Resolve(Query{Title: "Shingeki no Kyojin"}); id.AniListID != 20958 {
+		t.Errorf("Resolve(title) after Override = %+v; want season 2", id)
+	}
+
+	if err := r.Forget(q); err != nil {
+		t.Fatalf("Forget() error = %v", err)
+	}
+	if id, _ := r.Resolve(q); id.AniListID != 16498 || id.Manual {
+		t.Errorf("Resolve() after Forget = %+v; want automatic season 1", id)
+	}
+}
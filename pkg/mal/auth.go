@@ -0,0 +1,387 @@
+// Package mal implementa o tracker do MyAnimeList (API v2 oficial, OAuth2 com PKCE)
+package mal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"GoAnimeGUI/internal/oauth"
+)
+
+// O app precisa ser registrado em https://myanimelist.net/apiconfig com a
+// App Redirect URL igual a DefaultRedirectURI. Apps do tipo "other" não têm secret.
+
+const (
+	authorizeURL = "https://myanimelist.net/v1/oauth2/authorize"
+	tokenURL     = "https://myanimelist.net/v1/oauth2/token"
+	apiURL       = "https://api.myanimelist.net/v2"
+
+	// DefaultRedirectURI usa uma porta diferente dos callbacks do Discord (9876) e AniList (9877)
+	DefaultRedirectURI = "http://localhost:9878/callback"
+)
+
+// ErrNotLoggedIn indica que não há conta MAL conectada
+var ErrNotLoggedIn = errors.New("conta MyAnimeList não conectada")
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// User é o usuário MAL autenticado
+type User struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Avatar string `json:"picture"`
+}
+
+// authConfig é o conteúdo persistido em mal.json
+type authConfig struct {
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret,omitempty"`
+	AccessToken  string    `json:"access_token,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+	User         *User     `json:"user,omitempty"`
+}
+
+// Auth gerencia as credenciais e tokens do MAL
+type Auth struct {
+	config      authConfig
+	redirectURI string
+	configPath  string
+	verifier    string // code_verifier do login em andamento
+	state       string
+	mutex       sync.RWMutex
+	refreshing  sync.Mutex // Uma renovação por vez: o MAL invalida o refresh token a cada uso
+}
+
+var (
+	authInstance *Auth
+	authOnce     sync.Once
+)
+
+// GetAuth retorna a instância singleton da autenticação MAL.
+// Credenciais vêm de mal.json ou das variáveis MAL_CLIENT_ID/MAL_CLIENT_SECRET.
+func GetAuth() *Auth {
+	authOnce.Do(func() {
+		configDir, _ := os.UserConfigDir()
+		authInstance = &Auth{
+			redirectURI: DefaultRedirectURI,
+			configPath:  filepath.Join(configDir, "GoAnime", "mal.json"),
+		}
+		authInstance.load()
+
+		if authInstance.config.ClientID == "" {
+			authInstance.config.ClientID = os.Getenv("MAL_CLIENT_ID")
+		}
+		if authInstance.config.ClientSecret == "" {
+			authInstance.config.ClientSecret = os.Getenv("MAL_CLIENT_SECRET")
+		}
+	})
+	return authInstance
+}
+
+// load carrega a configuração do disco
+func (a *Auth) load() {
+	data, err := os.ReadFile(a.configPath)
+	if err != nil {
+		return // Arquivo não existe, não configurado
+	}
+	if err := json.Unmarshal(data, &a.config); err != nil {
+		fmt.Printf("[MAL] Erro ao ler %s: %v\n", a.configPath, err)
+		return
+	}
+	if a.config.User != nil && a.config.RefreshToken != "" {
+		fmt.Printf("[MAL] Conta carregada: %s\n", a.config.User.Name)
+	}
+}
+
+// save grava a configuração no disco (chamar com o lock de escrita)
+func (a *Auth) save() error {
+	if err := os.MkdirAll(filepath.Dir(a.configPath), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(a.config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(a.configPath, data, 0600)
+}
+
+// Configure salva as credenciais do aplicativo MAL (o secret é opcional)
+func (a *Auth) Configure(clientID, clientSecret string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.config.ClientID = strings.TrimSpace(clientID)
+	a.config.ClientSecret = strings.TrimSpace(clientSecret)
+	return a.save()
+}
+
+// IsConfigured indica se o Client ID foi definido
+func (a *Auth) IsConfigured() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.config.ClientID != ""
+}
+
+// RedirectURI retorna a URL de callback que deve estar registrada no MAL
+func (a *Auth) RedirectURI() string {
+	return a.redirectURI
+}
+
+// IsLoggedIn indica se há tokens salvos (o access token é renovado quando expira)
+func (a *Auth) IsLoggedIn() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.config.AccessToken != "" && a.config.RefreshToken != ""
+}
+
+// CurrentUser retorna o usuário conectado (nil se desconectado)
+func (a *Auth) CurrentUser() *User {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	if a.config.AccessToken == "" {
+		return nil
+	}
+	return a.config.User
+}
+
+// AuthURL gera um novo par PKCE e retorna a URL de autorização.
+// O MAL só aceita code_challenge_method=plain, então o challenge é o próprio verifier.
+func (a *Auth) AuthURL() (string, error) {
+	verifier, err := oauth.RandomString(64)
+	if err != nil {
+		return "", err
+	}
+	state, err := oauth.RandomString(16)
+	if err != nil {
+		return "", err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.verifier = verifier
+	a.state = state
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", a.config.ClientID)
+	params.Set("redirect_uri", a.redirectURI)
+	params.Set("code_challenge", verifier)
+	params.Set("code_challenge_method", "plain")
+	params.Set("state", state)
+	return authorizeURL + "?" + params.Encode(), nil
+}
+
+// Logout remove os tokens salvos (as credenciais do aplicativo são mantidas)
+func (a *Auth) Logout() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.config.AccessToken = ""
+	a.config.RefreshToken = ""
+	a.config.ExpiresAt = time.Time{}
+	a.config.User = nil
+	return a.save()
+}
+
+// WaitForCallback aguarda o código OAuth2 no servidor de callback local (internal/oauth)
+func (a *Auth) WaitForCallback() (string, error) {
+	a.mutex.RLock()
+	state := a.state
+	a.mutex.RUnlock()
+
+	code, err := oauth.WaitForCode(oauth.Callback{
+		Name:         "MAL OAuth",
+		RedirectURI:  a.redirectURI,
+		State:        state,
+		SuccessTitle: "MyAnimeList Conectado!",
+		RefusedText:  "O MyAnimeList recusou a autorização.",
+	})
+	if err != nil {
+		return "", err
+	}
+	a.mutex.Lock()
+	a.state = ""
+	a.mutex.Unlock()
+	return code, nil
+}
+
+// ExchangeCode troca o código de autorização (com o code_verifier) por tokens e busca o usuário
+func (a *Auth) ExchangeCode(code string) (*User, error) {
+	a.mutex.RLock()
+	data := url.Values{}
+	data.Set("client_id", a.config.ClientID)
+	if a.config.ClientSecret != "" {
+		data.Set("client_secret", a.config.ClientSecret)
+	}
+	data.Set("grant_type", "authorization_code")
+	data.Set("code", code)
+	data.Set("code_verifier", a.verifier)
+	data.Set("redirect_uri", a.redirectURI)
+	a.mutex.RUnlock()
+
+	if err := a.requestToken(data); err != nil {
+		return nil, err
+	}
+
+	user, err := a.fetchUser()
+	if err != nil {
+		return nil, err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.config.User = user
+	a.verifier = ""
+	if err := a.save(); err != nil {
+		return nil, fmt.Errorf("erro ao salvar token: %w", err)
+	}
+	return user, nil
+}
+
+// refresh renova o access token usando o refresh token
+func (a *Auth) refresh() error {
+	a.mutex.RLock()
+	data := url.Values{}
+	data.Set("client_id", a.config.ClientID)
+	if a.config.ClientSecret != "" {
+		data.Set("client_secret", a.config.ClientSecret)
+	}
+	data.Set("grant_type", "refresh_token")
+	data.Set("refresh_token", a.config.RefreshToken)
+	a.mutex.RUnlock()
+
+	if err := a.requestToken(data); err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.save()
+}
+
+// requestToken chama o endpoint de token e guarda o resultado em memória
+func (a *Auth) requestToken(data url.Values) error {
+	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return fmt.Errorf("erro ao criar request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("erro ao obter token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("myanimelist retornou status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResp struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return fmt.Errorf("erro ao decodificar token: %w", err)
+	}
+
+	a.mutex.Lock()
+	a.config.AccessToken = tokenResp.AccessToken
+	a.config.RefreshToken = tokenResp.RefreshToken
+	a.config.ExpiresAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	a.mutex.Unlock()
+	return nil
+}
+
+// token retorna um access token válido, renovando se estiver para expirar
+func (a *Auth) token() (string, error) {
+	a.mutex.RLock()
+	token, refreshToken, expiresAt := a.config.AccessToken, a.config.RefreshToken, a.config.ExpiresAt
+	a.mutex.RUnlock()
+
+	if token == "" {
+		return "", ErrNotLoggedIn
+	}
+	if refreshToken == "" || time.Until(expiresAt) >= time.Minute {
+		return token, nil
+	}
+
+	// Quem esperou a renovação de outra chamada já encontra o token novo
+	a.refreshing.Lock()
+	defer a.refreshing.Unlock()
+	a.mutex.RLock()
+	expiresAt = a.config.ExpiresAt
+	a.mutex.RUnlock()
+	if time.Until(expiresAt) < time.Minute {
+		if err := a.refresh(); err != nil {
+			return "", fmt.Errorf("erro ao renovar token: %w", err)
+		}
+	}
+
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	if a.config.AccessToken == "" {
+		return "", ErrNotLoggedIn
+	}
+	return a.config.AccessToken, nil
+}
+
+func (a *Auth) fetchUser() (*User, error) {
+	var user User
+	if err := a.do("GET", "/users/@me?fields=picture", nil, &user); err != nil {
+		return nil, fmt.Errorf("erro ao buscar usuário: %w", err)
+	}
+	return &user, nil
+}
+
+// do executa uma chamada autenticada à API v2 e decodifica a resposta em out
+func (a *Auth) do(method, path string, form url.Values, out interface{}) error {
+	token, err := a.token()
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequest(method, apiURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return ErrNotLoggedIn
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("não encontrado no MyAnimeList")
+	case resp.StatusCode >= 400:
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("myanimelist retornou status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
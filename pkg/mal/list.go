@@ -0,0 +1,142 @@
+package mal
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ListStatus é o estado da entrada na lista do MAL.
+// Os valores são os mesmos usados pela biblioteca local.
+type ListStatus struct {
+	Status             string `json:"status"` // watching, completed, on_hold, dropped, plan_to_watch
+	Score              int    `json:"score"`  // 0-10 (0 = sem nota)
+	NumEpisodesWatched int    `json:"num_episodes_watched"`
+	IsRewatching       bool   `json:"is_rewatching"`
+	UpdatedAt          string `json:"updated_at"` // RFC3339
+}
+
+// Anime é o resumo de um anime retornado pela API
+type Anime struct {
+	ID               int    `json:"id"`
+	Title            string `json:"title"`
+	NumEpisodes      int    `json:"num_episodes"`
+	AlternativeTitle struct {
+		Synonyms []string `json:"synonyms"`
+		English  string   `json:"en"`
+		Japanese string   `json:"ja"`
+	} `json:"alternative_titles"`
+	MainPicture struct {
+		Medium string `json:"medium"`
+		Large  string `json:"large"`
+	} `json:"main_picture"`
+	MyListStatus *ListStatus `json:"my_list_status,omitempty"`
+}
+
+// Titles retorna o título principal e os alternativos
+func (a *Anime) Titles() []string {
+	titles := []string{a.Title, a.AlternativeTitle.English, a.AlternativeTitle.Japanese}
+	return append(titles, a.AlternativeTitle.Synonyms...)
+}
+
+// ListEntry é uma entrada da lista de animes do usuário
+type ListEntry struct {
+	Anime      Anime      `json:"node"`
+	ListStatus ListStatus `json:"list_status"`
+}
+
+// ListUpdate são os campos enviados ao MAL; campos vazios/negativos não são alterados
+type ListUpdate struct {
+	Status             string
+	Score              int // -1 = não altera
+	NumEpisodesWatched int // -1 = não altera
+}
+
+const animeFields = "num_episodes,alternative_titles,main_picture"
+
+// GetAnimeList retorna a lista completa de animes do usuário (todas as páginas)
+func (a *Auth) GetAnimeList() ([]ListEntry, error) {
+	entries := []ListEntry{}
+	path := "/users/@me/animelist?nsfw=true&limit=1000&fields=list_status," + animeFields
+
+	for path != "" {
+		var page struct {
+			Data   []ListEntry `json:"data"`
+			Paging struct {
+				Next string `json:"next"`
+			} `json:"paging"`
+		}
+		if err := a.do("GET", path, nil, &page); err != nil {
+			return nil, err
+		}
+		entries = append(entries, page.Data...)
+
+		path = ""
+		if page.Paging.Next != "" {
+			path = strings.TrimPrefix(page.Paging.Next, apiURL)
+		}
+	}
+	return entries, nil
+}
+
+// GetAnime retorna um anime com o estado na lista do usuário (MyListStatus nil se não estiver na lista)
+func (a *Auth) GetAnime(animeID int) (*Anime, error) {
+	var anime Anime
+	path := fmt.Sprintf("/anime/%d?fields=my_list_status,%s", animeID, animeFields)
+	if err := a.do("GET", path, nil, &anime); err != nil {
+		return nil, err
+	}
+	return &anime, nil
+}
+
+// UpdateListStatus cria ou atualiza a entrada de um anime na lista do usuário
+func (a *Auth) UpdateListStatus(animeID int, update ListUpdate) (*ListStatus, error) {
+	if animeID <= 0 {
+		return nil, fmt.Errorf("anime ID inválido: %d", animeID)
+	}
+
+	form := url.Values{}
+	if update.Status != "" {
+		form.Set("status", update.Status)
+	}
+	if update.Score >= 0 {
+		form.Set("score", strconv.Itoa(update.Score))
+	}
+	if update.NumEpisodesWatched >= 0 {
+		form.Set("num_watched_episodes", strconv.Itoa(update.NumEpisodesWatched))
+	}
+
+	var status ListStatus
+	if err := a.do("PATCH", fmt.Sprintf("/anime/%d/my_list_status", animeID), form, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// SearchAnime busca animes pelo título
+func (a *Auth) SearchAnime(query string, limit int) ([]Anime, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	// A API rejeita buscas muito longas
+	if runes := []rune(query); len(runes) > 64 {
+		query = string(runes[:64])
+	}
+
+	var result struct {
+		Data []struct {
+			Anime Anime `json:"node"`
+		} `json:"data"`
+	}
+	path := fmt.Sprintf("/anime?q=%s&limit=%d&nsfw=true&fields=%s", url.QueryEscape(query), limit, animeFields)
+	if err := a.do("GET", path, nil, &result); err != nil {
+		return nil, err
+	}
+
+	animes := make([]Anime, len(result.Data))
+	for i, d := range result.Data {
+		animes[i] = d.Anime
+	}
+	return animes, nil
+}
@@ -0,0 +1,118 @@
+package mangadownloader
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"GoAnimeGUI/pkg/store"
+)
+
+// comicInfo é o ComicInfo.xml (schema v2) lido por Komga, Kavita, Tachiyomi e afins.
+// A ordem dos campos segue a do schema.
+type comicInfo struct {
+	XMLName   xml.Name    `xml:"ComicInfo"`
+	XSI       string      `xml:"xmlns:xsi,attr"`
+	XSD       string      `xml:"xmlns:xsd,attr"`
+	Title     string      `xml:"Title,omitempty"`
+	Series    string      `xml:"Series"`
+	Number    string      `xml:"Number,omitempty"`
+	Summary   string      `xml:"Summary,omitempty"`
+	Writer    string      `xml:"Writer,omitempty"`
+	Genre     string      `xml:"Genre,omitempty"`
+	Web       string      `xml:"Web,omitempty"`
+	PageCount int         `xml:"PageCount"`
+	Manga     string      `xml:"Manga"`
+	Pages     []comicPage `xml:"Pages>Page"`
+}
+
+type comicPage struct {
+	Image     int    `xml:"Image,attr"`
+	Type      string `xml:"Type,attr,omitempty"`
+	ImageSize int64  `xml:"ImageSize,attr,omitempty"`
+}
+
+// writeCBZ empacota as páginas de dir com o ComicInfo.xml em d.Path.
+// O arquivo só aparece no destino quando está completo.
+func writeCBZ(d store.MangaDownload, dir string, pageCount int) error {
+	names := sortedPages(dir)
+	if len(names) != pageCount {
+		return fmt.Errorf("%d de %d páginas no disco", len(names), pageCount)
+	}
+
+	tmp := d.Path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = writeZip(file, d, dir, names)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	os.Remove(d.Path)
+	return os.Rename(tmp, d.Path)
+}
+
+func writeZip(w io.Writer, d store.MangaDownload, dir string, names []string) error {
+	archive := zip.NewWriter(w)
+
+	info := comicInfo{
+		XSI:       "http://www.w3.org/2001/XMLSchema-instance",
+		XSD:       "http://www.w3.org/2001/XMLSchema",
+		Title:     d.ChapterTitle,
+		Series:    d.MangaTitle,
+		Number:    d.ChapterNumber,
+		Summary:   d.Summary,
+		Writer:    d.Author,
+		Genre:     strings.Join(d.Genres, ", "),
+		Web:       d.ChapterURL,
+		PageCount: len(names),
+		Manga:     "Yes",
+	}
+	for i, name := range names {
+		page := comicPage{Image: i}
+		if i == 0 {
+			page.Type = "FrontCover"
+		}
+		if stat, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			page.ImageSize = stat.Size()
+		}
+		info.Pages = append(info.Pages, page)
+	}
+	entry, err := archive.Create("ComicInfo.xml")
+	if err != nil {
+		return err
+	}
+	io.WriteString(entry, xml.Header)
+	encoder := xml.NewEncoder(entry)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(info); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		// Imagens já são comprimidas; Store deixa a leitura mais rápida nos leitores
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			return err
+		}
+		page, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(entry, page)
+		page.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,308 @@
+package mangadownloader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"GoAnimeGUI/pkg/downloader"
+	"GoAnimeGUI/pkg/mangascraper"
+	"GoAnimeGUI/pkg/retry"
+	"GoAnimeGUI/pkg/store"
+)
+
+// imageExts mapeia o Content-Type das páginas para a extensão salva no CBZ
+var imageExts = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/jpg":  ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+	"image/avif": ".avif",
+}
+
+// DefaultDir é a pasta usada quando o usuário não escolheu uma
+func DefaultDir() string {
+	return filepath.Join(downloader.DefaultDir(), "Mangás")
+}
+
+// download baixa as páginas que faltam de d e monta o CBZ
+func (m *Manager) download(ctx context.Context, d *store.MangaDownload) error {
+	source, ok := m.sources(d.Source)
+	if !ok {
+		return retry.Permanentf("fonte de mangá %q não disponível", d.Source)
+	}
+	// O caminho é fixado na primeira tentativa, para que mudar a pasta não perca as páginas baixadas
+	if d.Path == "" {
+		d.Path = m.targetPath(*d)
+		if err := m.db.SetMangaDownloadPath(d.ID, d.Path); err != nil {
+			return err
+		}
+	}
+
+	// A lista é pedida a cada tentativa porque as URLs das imagens podem expirar
+	pages, err := source.GetChapterPages(d.ChapterURL)
+	if err != nil {
+		return fmt.Errorf("erro ao listar páginas: %w", err)
+	}
+	if len(pages) == 0 {
+		return fmt.Errorf("capítulo sem páginas")
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	dir := pagesDir(d.Path)
+	if d.PagesTotal > 0 && d.PagesTotal != len(pages) {
+		// O capítulo mudou na fonte: as páginas antigas não batem mais com a lista
+		os.RemoveAll(dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return retry.Permanentf("erro ao criar pasta do capítulo: %w", err)
+	}
+
+	if err := m.fetchPages(ctx, d, pages, dir); err != nil {
+		return err
+	}
+	if err := writeCBZ(*d, dir, len(pages)); err != nil {
+		return fmt.Errorf("erro ao montar CBZ: %w", err)
+	}
+	os.RemoveAll(dir)
+	return nil
+}
+
+// fetchPages baixa em paralelo as páginas que ainda não estão em dir
+func (m *Manager) fetchPages(ctx context.Context, d *store.MangaDownload, pages []mangascraper.Page, dir string) error {
+	existing := savedPages(dir)
+	total := len(pages)
+	done := 0
+	var missing []int
+	for i := range pages {
+		if _, ok := existing[i+1]; ok {
+			done++
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	m.db.SetMangaDownloadProgress(d.ID, done, total)
+	m.emitProgress(d.ID, done, total)
+
+	referer := d.ChapterURL
+	if parsed, err := url.Parse(d.ChapterURL); err == nil && parsed.Host != "" {
+		referer = fmt.Sprintf("%s://%s/", parsed.Scheme, parsed.Host)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	work := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		firstErr error
+	)
+	for w := 0; w < pageWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				err := m.fetchPage(ctx, pages[i].URL, referer, dir, i+1)
+				mutex.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("página %d: %w", i+1, err)
+					}
+					cancel()
+				} else {
+					done++
+					m.db.SetMangaDownloadProgress(d.ID, done, total)
+					m.emitProgress(d.ID, done, total)
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+loop:
+	for _, i := range missing {
+		select {
+		case work <- i:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil && firstErr == nil {
+		return err
+	}
+	return firstErr
+}
+
+// fetchPage baixa uma página, com algumas tentativas, para dir/NNN.ext
+func (m *Manager) fetchPage(ctx context.Context, pageURL, referer, dir string, number int) error {
+	var err error
+	for attempt := 0; attempt < pageAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(pageRetryDelay):
+			}
+		}
+		if err = m.fetchPageOnce(ctx, pageURL, referer, dir, number); err == nil || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (m *Manager) fetchPageOnce(ctx context.Context, pageURL, referer, dir string, number int) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return retry.Permanentf("URL de página inválida: %w", err)
+	}
+	req.Header.Set("User-Agent", m.userAgent)
+	req.Header.Set("Accept", "image/avif,image/webp,image/apng,image/*,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "pt-BR,pt;q=0.9,en-US;q=0.8,en;q=0.7")
+	req.Header.Set("Referer", referer)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("servidor respondeu %s", resp.Status)
+	}
+	contentType := strings.ToLower(strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0]))
+	if strings.HasPrefix(contentType, "text/") {
+		// Páginas de bloqueio (hotlink, Cloudflare) chegam como HTML com status 200
+		return fmt.Errorf("servidor não retornou uma imagem (%s)", contentType)
+	}
+
+	name := fmt.Sprintf("%03d%s", number, imageExt(contentType, pageURL))
+	tmp := filepath.Join(dir, name+".tmp")
+	file, err := os.Create(tmp)
+	if err != nil {
+		return retry.Permanentf("erro ao criar arquivo: %w", err)
+	}
+	_, err = io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, name))
+}
+
+func (m *Manager) emitProgress(id int64, pages, total int) {
+	if m.onProgress == nil {
+		return
+	}
+	p := Progress{ID: id, Pages: pages, Total: total}
+	if total > 0 {
+		p.Percent = float64(pages) * 100 / float64(total)
+	}
+	m.onProgress(p)
+}
+
+// targetPath monta <pasta>/<série>/<série> - Cap. 012.cbz
+func (m *Manager) targetPath(d store.MangaDownload) string {
+	dir := m.dir()
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	series := downloader.CleanName(d.MangaTitle)
+	if series == "" {
+		series = "Outros"
+	}
+	name := downloader.CleanName(fmt.Sprintf("%s - Cap. %s", series, padNumber(d.ChapterNumber)))
+	return filepath.Join(dir, series, name+".cbz")
+}
+
+// pagesDir é a pasta das páginas de um capítulo ainda não empacotado
+func pagesDir(cbzPath string) string {
+	return cbzPath + ".pages"
+}
+
+// savedPages lista as páginas completas em dir, pelo número
+func savedPages(dir string) map[int]string {
+	pages := make(map[int]string)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return pages
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		stem := strings.TrimSuffix(name, filepath.Ext(name))
+		if n, err := strconv.Atoi(stem); err == nil {
+			pages[n] = name
+		}
+	}
+	return pages
+}
+
+// sortedPages retorna os nomes das páginas em ordem
+func sortedPages(dir string) []string {
+	saved := savedPages(dir)
+	numbers := make([]int, 0, len(saved))
+	for n := range saved {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	names := make([]string, len(numbers))
+	for i, n := range numbers {
+		names[i] = saved[n]
+	}
+	return names
+}
+
+// imageExt escolhe a extensão pelo Content-Type, depois pela URL (padrão .jpg)
+func imageExt(contentType, pageURL string) string {
+	if ext, ok := imageExts[contentType]; ok {
+		return ext
+	}
+	if parsed, err := url.Parse(pageURL); err == nil {
+		ext := strings.ToLower(path.Ext(parsed.Path))
+		for _, known := range imageExts {
+			if ext == known {
+				return ext
+			}
+		}
+		if ext == ".jpeg" {
+			return ".jpg"
+		}
+	}
+	return ".jpg"
+}
+
+// padNumber completa a parte inteira com zeros ("7" → "007", "12.5" → "012.5")
+// para os leitores ordenarem os arquivos corretamente
+func padNumber(number string) string {
+	number = strings.TrimSpace(number)
+	whole, fraction, hasFraction := strings.Cut(number, ".")
+	n, err := strconv.Atoi(whole)
+	if err != nil || n < 0 {
+		return number
+	}
+	if hasFraction {
+		return fmt.Sprintf("%03d.%s", n, fraction)
+	}
+	return fmt.Sprintf("%03d", n)
+}
@@ -0,0 +1,404 @@
+// Package mangadownloader baixa capítulos de qualquer mangascraper.Source como arquivos CBZ
+// (com ComicInfo.xml), para ler offline e em leitores externos.
+// A fila fica no banco e as páginas já baixadas ficam numa pasta ao lado do CBZ,
+// então capítulos pausados ou interrompidos continuam de onde pararam.
+package mangadownloader
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"GoAnimeGUI/pkg/mangascraper"
+	"GoAnimeGUI/pkg/retry"
+	"GoAnimeGUI/pkg/store"
+)
+
+const (
+	maxAttempts    = 5
+	retryBaseDelay = 10 * time.Second
+	retryMaxDelay  = 5 * time.Minute
+	pollInterval   = 5 * time.Second
+	pageWorkers    = 3 // Páginas simultâneas por capítulo
+	pageAttempts   = 3
+
+	// DefaultSourceLimit é o número de capítulos simultâneos por fonte
+	// (os sites bloqueiam quem baixa demais de uma vez)
+	DefaultSourceLimit = 1
+)
+
+// pageRetryDelay é a espera entre tentativas de uma mesma página
+var pageRetryDelay = 2 * time.Second
+
+// SourceFunc encontra a fonte pelo nome gravado no download
+type SourceFunc func(name string) (mangascraper.Source, bool)
+
+// Progress é o andamento de um capítulo ativo
+type Progress struct {
+	ID      int64   `json:"id"`
+	Pages   int     `json:"pages"`
+	Total   int     `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+// job é um capítulo em andamento
+type job struct {
+	source string
+	cancel context.CancelFunc
+	reason string // Estado a gravar quando cancelado (pausado ou removido)
+	done   chan struct{}
+}
+
+// Manager controla a fila de capítulos
+type Manager struct {
+	db         *store.DB
+	sources    SourceFunc
+	dir        func() string
+	client     *http.Client
+	userAgent  string
+	onProgress func(Progress)
+	onChange   func(store.MangaDownload)
+
+	limits  map[string]int // Capítulos simultâneos por fonte
+	active  map[int64]*job
+	retryAt map[int64]time.Time
+	mutex   sync.Mutex
+	wake    chan struct{}
+}
+
+// New cria o gerenciador. dir retorna a pasta onde ficam as séries baixadas.
+func New(db *store.DB, sources SourceFunc, dir func() string) *Manager {
+	return &Manager{
+		db:        db,
+		sources:   sources,
+		dir:       dir,
+		client:    &http.Client{Timeout: time.Minute},
+		userAgent: mangascraper.DefaultConfig().UserAgent,
+		limits:    make(map[string]int),
+		active:    make(map[int64]*job),
+		retryAt:   make(map[int64]time.Time),
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// SetSourceLimit define quantos capítulos da fonte podem baixar ao mesmo tempo
+func (m *Manager) SetSourceLimit(source string, limit int) {
+	m.mutex.Lock()
+	m.limits[source] = limit
+	m.mutex.Unlock()
+	m.Kick()
+}
+
+// OnProgress registra um callback para o andamento dos capítulos ativos
+func (m *Manager) OnProgress(fn func(Progress)) {
+	m.onProgress = fn
+}
+
+// OnChange registra um callback para mudanças de estado
+func (m *Manager) OnChange(fn func(store.MangaDownload)) {
+	m.onChange = fn
+}
+
+// Run processa a fila até o contexto ser cancelado
+func (m *Manager) Run(ctx context.Context) {
+	if err := m.db.RequeueInterruptedMangaDownloads(); err != nil {
+		fmt.Printf("[MangaDownloads] Erro ao retomar downloads interrompidos: %v\n", err)
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		m.schedule(ctx)
+		select {
+		case <-ctx.Done():
+			m.stopAll()
+			return
+		case <-ticker.C:
+		case <-m.wake:
+		}
+	}
+}
+
+// Kick faz a fila andar imediatamente
+func (m *Manager) Kick() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Enqueue coloca capítulos de um mangá na fila. Capítulos já na lista não são duplicados;
+// os que tinham falhado voltam para a fila.
+func (m *Manager) Enqueue(source mangascraper.Source, manga mangascraper.Manga, chapters []mangascraper.Chapter) ([]store.MangaDownload, error) {
+	if source == nil {
+		return nil, fmt.Errorf("fonte de mangá não informada")
+	}
+	saved := make([]store.MangaDownload, 0, len(chapters))
+	for _, chapter := range chapters {
+		if chapter.URL == "" {
+			continue
+		}
+		d, err := m.db.AddMangaDownload(store.MangaDownload{
+			ChapterURL:    chapter.URL,
+			Source:        source.Name(),
+			MangaURL:      manga.URL,
+			MangaTitle:    manga.Title,
+			Author:        manga.Author,
+			Summary:       manga.Description,
+			Genres:        manga.Genres,
+			ChapterNumber: chapter.Number,
+			ChapterTitle:  chapter.Title,
+		})
+		if err != nil {
+			return saved, fmt.Errorf("erro ao adicionar capítulo %s: %w", chapter.Number, err)
+		}
+		if d.State == store.DownloadFailed {
+			if d, err = m.Retry(d.ID); err != nil {
+				return saved, err
+			}
+		} else {
+			m.changed(d.ID)
+		}
+		saved = append(saved, *d)
+	}
+	m.Kick()
+	return saved, nil
+}
+
+// Pause pausa um capítulo ativo ou na fila
+func (m *Manager) Pause(id int64) error {
+	if m.stop(id, store.DownloadPaused) {
+		return nil
+	}
+	d, err := m.get(id)
+	if err != nil {
+		return err
+	}
+	if d.State != store.DownloadQueued {
+		return nil
+	}
+	if err := m.db.SetMangaDownloadState(id, store.DownloadPaused, ""); err != nil {
+		return err
+	}
+	m.changed(id)
+	return nil
+}
+
+// Resume devolve um capítulo pausado à fila; as páginas já baixadas são aproveitadas
+func (m *Manager) Resume(id int64) error {
+	d, err := m.get(id)
+	if err != nil {
+		return err
+	}
+	if d.State != store.DownloadPaused {
+		return nil
+	}
+	if err := m.db.SetMangaDownloadState(id, store.DownloadQueued, ""); err != nil {
+		return err
+	}
+	m.changed(id)
+	m.Kick()
+	return nil
+}
+
+// Retry zera as tentativas de um capítulo com falha e o devolve à fila
+func (m *Manager) Retry(id int64) (*store.MangaDownload, error) {
+	d, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if d.State != store.DownloadFailed && d.State != store.DownloadPaused {
+		return d, nil
+	}
+	if err := m.db.SetMangaDownloadAttempts(id, 0); err != nil {
+		return nil, err
+	}
+	if err := m.db.SetMangaDownloadState(id, store.DownloadQueued, ""); err != nil {
+		return nil, err
+	}
+	m.mutex.Lock()
+	delete(m.retryAt, id)
+	m.mutex.Unlock()
+
+	m.changed(id)
+	m.Kick()
+	return m.get(id)
+}
+
+// Cancel remove o capítulo da lista. Com deleteFile, apaga também as páginas
+// parciais ou o CBZ pronto.
+func (m *Manager) Cancel(id int64, deleteFile bool) error {
+	m.stop(id, "")
+	d, err := m.get(id)
+	if err != nil {
+		return err
+	}
+	if deleteFile && d.Path != "" {
+		os.RemoveAll(pagesDir(d.Path))
+		if d.State == store.DownloadCompleted {
+			os.Remove(d.Path)
+		}
+	}
+	if err := m.db.DeleteMangaDownload(id); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	delete(m.retryAt, id)
+	m.mutex.Unlock()
+	if m.onChange != nil {
+		m.onChange(store.MangaDownload{ID: id, ChapterURL: d.ChapterURL})
+	}
+	return nil
+}
+
+func (m *Manager) get(id int64) (*store.MangaDownload, error) {
+	d, err := m.db.GetMangaDownload(id)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, fmt.Errorf("download de capítulo %d não encontrado", id)
+	}
+	return d, nil
+}
+
+// stop cancela um capítulo ativo e espera a goroutine terminar. Retorna false se não estava ativo.
+func (m *Manager) stop(id int64, reason string) bool {
+	m.mutex.Lock()
+	j, ok := m.active[id]
+	if ok {
+		j.reason = reason
+		j.cancel()
+	}
+	m.mutex.Unlock()
+	if ok {
+		<-j.done
+	}
+	return ok
+}
+
+// stopAll interrompe tudo no encerramento do app; os capítulos voltam à fila na próxima abertura
+func (m *Manager) stopAll() {
+	m.mutex.Lock()
+	jobs := make([]*job, 0, len(m.active))
+	for _, j := range m.active {
+		j.reason = store.DownloadQueued
+		j.cancel()
+		jobs = append(jobs, j)
+	}
+	m.mutex.Unlock()
+	for _, j := range jobs {
+		<-j.done
+	}
+}
+
+// schedule inicia capítulos da fila respeitando o limite de cada fonte
+func (m *Manager) schedule(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+	queued, err := m.db.QueuedMangaDownloads()
+	if err != nil {
+		fmt.Printf("[MangaDownloads] Erro ao ler a fila: %v\n", err)
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	running := make(map[string]int)
+	for _, j := range m.active {
+		running[j.source]++
+	}
+	now := time.Now()
+	for _, d := range queued {
+		if _, ok := m.active[d.ID]; ok {
+			continue
+		}
+		if running[d.Source] >= m.sourceLimit(d.Source) {
+			continue
+		}
+		if at, waiting := m.retryAt[d.ID]; waiting && now.Before(at) {
+			continue
+		}
+		delete(m.retryAt, d.ID)
+
+		jobCtx, cancel := context.WithCancel(ctx)
+		j := &job{source: d.Source, cancel: cancel, done: make(chan struct{})}
+		m.active[d.ID] = j
+		running[d.Source]++
+		go m.run(jobCtx, j, d)
+	}
+}
+
+// sourceLimit deve ser chamado com o mutex travado
+func (m *Manager) sourceLimit(source string) int {
+	if limit, ok := m.limits[source]; ok && limit > 0 {
+		return limit
+	}
+	return DefaultSourceLimit
+}
+
+// run executa uma tentativa de download e grava o resultado
+func (m *Manager) run(ctx context.Context, j *job, d store.MangaDownload) {
+	defer close(j.done)
+	defer j.cancel()
+
+	m.db.SetMangaDownloadState(d.ID, store.DownloadActive, "")
+	m.changed(d.ID)
+
+	err := m.download(ctx, &d)
+
+	m.mutex.Lock()
+	delete(m.active, d.ID)
+	reason := j.reason
+	m.mutex.Unlock()
+
+	switch {
+	case err == nil:
+		fmt.Printf("[MangaDownloads] Concluído: %s\n", d.Path)
+		m.db.SetMangaDownloadAttempts(d.ID, 0)
+		m.db.SetMangaDownloadState(d.ID, store.DownloadCompleted, "")
+	case ctx.Err() != nil && reason != "":
+		// Pausado ou app fechando: as páginas baixadas ficam para retomar depois
+		m.db.SetMangaDownloadState(d.ID, reason, "")
+	case ctx.Err() != nil && reason == "":
+		// Removido da lista por Cancel; nada a gravar
+		return
+	default:
+		m.fail(&d, err)
+	}
+	m.changed(d.ID)
+	m.Kick()
+}
+
+// fail agenda uma nova tentativa com backoff ou desiste de vez
+func (m *Manager) fail(d *store.MangaDownload, err error) {
+	attempts := d.Attempts + 1
+	m.db.SetMangaDownloadAttempts(d.ID, attempts)
+
+	if retry.IsPermanent(err) || attempts >= maxAttempts {
+		fmt.Printf("[MangaDownloads] Falhou (%d tentativas): %s: %v\n", attempts, d.ChapterURL, err)
+		m.db.SetMangaDownloadState(d.ID, store.DownloadFailed, err.Error())
+		return
+	}
+
+	delay := retry.Backoff(attempts, retryBaseDelay, retryMaxDelay)
+	fmt.Printf("[MangaDownloads] Erro em %s, nova tentativa em %v: %v\n", d.ChapterURL, delay, err)
+	m.mutex.Lock()
+	m.retryAt[d.ID] = time.Now().Add(delay)
+	m.mutex.Unlock()
+	m.db.SetMangaDownloadState(d.ID, store.DownloadQueued, err.Error())
+}
+
+func (m *Manager) changed(id int64) {
+	if m.onChange == nil {
+		return
+	}
+	if d, err := m.db.GetMangaDownload(id); err == nil && d != nil {
+		m.onChange(*d)
+	}
+}
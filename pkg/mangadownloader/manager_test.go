@@ -0,0 +1,200 @@
+package mangadownloader
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"GoAnimeGUI/internal/testutil"
+	"GoAnimeGUI/pkg/mangascraper"
+	"GoAnimeGUI/pkg/store"
+)
+
+// fakeSource implementa só o que o downloader usa
+type fakeSource struct {
+	mangascraper.Source
+	name  string
+	pages []mangascraper.Page
+}
+
+func (s *fakeSource) Name() string { return s.name }
+
+func (s *fakeSource) GetChapterPages(chapterURL string) ([]mangascraper.Page, error) {
+	return s.pages, nil
+}
+
+func newTestManager(t *testing.T, sources ...*fakeSource) (*Manager, *store.DB, string) {
+	t.Helper()
+	db := store.OpenTemp(t)
+
+	dir := t.TempDir()
+	m := New(db, func(name string) (mangascraper.Source, bool) {
+		for _, s := range sources {
+			if s.name == name {
+				return s, true
+			}
+		}
+		return nil, false
+	}, func() string { return dir })
+	return m, db, dir
+}
+
+// waitState roda a fila até o capítulo chegar ao estado esperado
+func waitState(t *testing.T, m *Manager, db *store.DB, id int64, state string) *store.MangaDownload {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var d *store.MangaDownload
+	if !testutil.Eventually(5*time.Second, func() bool {
+		m.schedule(ctx)
+		d, _ = db.GetMangaDownload(id)
+		return d != nil && d.State == state
+	}) {
+		t.Fatalf("download %d = %+v; want state %s", id, d, state)
+	}
+	return d
+}
+
+func TestManager_DownloadsChapterAsCBZ(t *testing.T) {
+	var mutex sync.Mutex
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requested = append(requested, r.URL.Path)
+		mutex.Unlock()
+		if !strings.HasPrefix(r.Header.Get("Referer"), "https://manga.example/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "image/webp")
+		fmt.Fprintf(w, "image %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	source := &fakeSource{name: "fake"}
+	for i := 1; i <= 4; i++ {
+		source.pages = append(source.pages, mangascraper.Page{Number: i, URL: fmt.Sprintf("%s/p%d", server.URL, i)})
+	}
+	m, db, dir := newTestManager(t, source)
+
+	manga := mangascraper.Manga{Title: "One Piece", URL: "https://manga.example/one-piece",
+		Genres: []string{"Ação", "Aventura"}, Author: "Eiichiro Oda"}
+	saved, err := m.Enqueue(source, manga, []mangascraper.Chapter{
+		{Number: "7", Title: "O Amigo", URL: "https://manga.example/one-piece/7"},
+	})
+	if err != nil || len(saved) != 1 {
+		t.Fatalf("Enqueue() = %v, %v; want 1 chapter", saved, err)
+	}
+
+	// Simula um capítulo interrompido com a primeira página já no disco
+	want := filepath.Join(dir, "One Piece", "One Piece - Cap. 007.cbz")
+	os.MkdirAll(pagesDir(want), 0755)
+	os.WriteFile(filepath.Join(pagesDir(want), "001.webp"), []byte("image /p1"), 0644)
+
+	d := waitState(t, m, db, saved[0].ID, store.DownloadCompleted)
+	if d.Path != want || d.PagesDone != 4 || d.PagesTotal != 4 {
+		t.Errorf("download = %+v; want %s with 4/4 pages", d, want)
+	}
+	if len(requested) != 3 {
+		t.Errorf("requested %v; want only the 3 missing pages", requested)
+	}
+	if _, err := os.Stat(pagesDir(want)); !os.IsNotExist(err) {
+		t.Error("pages folder should be removed after packing")
+	}
+
+	archive, err := zip.OpenReader(want)
+	if err != nil {
+		t.Fatalf("zip.OpenReader() error = %v", err)
+	}
+	defer archive.Close()
+	var names []string
+	var info string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+		if f.Name == "ComicInfo.xml" {
+			r, _ := f.Open()
+			data, _ := io.ReadAll(r)
+			r.Close()
+			info = string(data)
+		}
+	}
+	if got := strings.Join(names, ","); got != "ComicInfo.xml,001.webp,002.webp,003.webp,004.webp" {
+		t.Errorf("entries = %s", got)
+	}
+	for _, field := range []string{
+		"<Series>One Piece</Series>", "<Number>7</Number>", "<Genre>Ação, Aventura</Genre>",
+		"<Web>https://manga.example/one-piece/7</Web>", "<PageCount>4</PageCount>", "<Writer>Eiichiro Oda</Writer>",
+	} {
+		if !strings.Contains(info, field) {
+			t.Errorf("ComicInfo.xml missing %s:\n%s", field, info)
+		}
+	}
+}
+
+func TestManager_SourceLimit(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write([]byte("img"))
+	}))
+	defer server.Close()
+
+	pages := []mangascraper.Page{{Number: 1, URL: server.URL + "/p1.jpg"}}
+	a := &fakeSource{name: "a", pages: pages}
+	b := &fakeSource{name: "b", pages: pages}
+	m, _, _ := newTestManager(t, a, b)
+
+	m.Enqueue(a, mangascraper.Manga{Title: "A"}, []mangascraper.Chapter{
+		{Number: "1", URL: "https://a/1"}, {Number: "2", URL: "https://a/2"},
+	})
+	m.Enqueue(b, mangascraper.Manga{Title: "B"}, []mangascraper.Chapter{{Number: "1", URL: "https://b/1"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	running := func() map[string]int {
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		count := make(map[string]int)
+		for _, j := range m.active {
+			count[j.source]++
+		}
+		return count
+	}
+
+	m.schedule(ctx)
+	if got := running(); got["a"] != 1 || got["b"] != 1 {
+		t.Errorf("running = %v; want one chapter per source", got)
+	}
+	m.mutex.Lock()
+	m.limits["a"] = 2
+	m.mutex.Unlock()
+	m.schedule(ctx)
+	if got := running(); got["a"] != 2 {
+		t.Errorf("running = %v; want 2 chapters of source a after raising its limit", got)
+	}
+
+	close(release)
+	cancel()
+	m.stopAll()
+}
+
+func TestPadNumber(t *testing.T) {
+	tests := map[string]string{"7": "007", "12.5": "012.5", "1024": "1024", "Extra": "Extra"}
+	for in, want := range tests {
+		if got := padNumber(in); got != want {
+			t.Errorf("padNumber(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Package offline mantém o índice dos episódios baixados: o que existe no disco,
+// onde estão as legendas e quais arquivos podem ser apagados pelas regras de limpeza.
+package offline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"GoAnimeGUI/pkg/store"
+)
+
+// subtitleExts são as legendas procuradas ao lado do vídeo
+var subtitleExts = map[string]bool{".srt": true, ".ass": true, ".ssa": true, ".vtt": true}
+
+// Rules são as regras de limpeza automática
+type Rules struct {
+	DeleteWatched bool  // Apaga episódios assistidos até o fim
+	QuotaBytes    int64 // Tamanho máximo da biblioteca (0 = sem limite)
+}
+
+// CleanupResult resume uma limpeza
+type CleanupResult struct {
+	Removed    int   `json:"removed"`
+	FreedBytes int64 `json:"freedBytes"`
+}
+
+// Anime agrupa os episódios offline de um anime
+type Anime struct {
+	AnimeURL string                 `json:"animeUrl"`
+	Title    string                 `json:"title"`
+	Size     int64                  `json:"size"`
+	Episodes []store.OfflineEpisode `json:"episodes"`
+}
+
+// Library é o índice da biblioteca offline
+type Library struct {
+	db       *store.DB
+	onRemove func(store.OfflineEpisode)
+}
+
+// New cria o índice sobre o banco
+func New(db *store.DB) *Library {
+	return &Library{db: db}
+}
+
+// OnRemove registra um callback para episódios apagados pela biblioteca (Delete ou limpeza)
+func (l *Library) OnRemove(fn func(store.OfflineEpisode)) {
+	l.onRemove = fn
+}
+
+// Register indexa um download concluído. Chamadas repetidas só atualizam a entrada.
+func (l *Library) Register(d store.Download) (*store.OfflineEpisode, error) {
+	if d.State != store.DownloadCompleted || d.Path == "" {
+		return nil, fmt.Errorf("download %d não está concluído", d.ID)
+	}
+	info, err := os.Stat(d.Path)
+	if err != nil {
+		return nil, fmt.Errorf("arquivo do download não encontrado: %w", err)
+	}
+	return l.db.SaveOfflineEpisode(store.OfflineEpisode{
+		Path:         d.Path,
+		DownloadID:   d.ID,
+		AnimeURL:     d.AnimeURL,
+		AnimeTitle:   d.AnimeTitle,
+		Season:       Season(d.AnimeTitle),
+		EpisodeURL:   d.EpisodeURL,
+		EpisodeNum:   d.EpisodeNum,
+		EpisodeTitle: d.EpisodeTitle,
+		Size:         info.Size(),
+		Subtitles:    FindSubtitles(d.Path),
+	})
+}
+
+// Lookup retorna a cópia offline de um episódio, ou nil se não houver.
+// Entradas cujo arquivo sumiu do disco são removidas do índice.
+func (l *Library) Lookup(episodeURL string) *store.OfflineEpisode {
+	ep, err := l.db.OfflineEpisodeByURL(episodeURL)
+	if err != nil || ep == nil {
+		return nil
+	}
+	if !l.exists(ep) {
+		return nil
+	}
+	return ep
+}
+
+// Get retorna um episódio offline pelo ID, verificando se o arquivo ainda existe
+func (l *Library) Get(id int64) (*store.OfflineEpisode, error) {
+	ep, err := l.db.GetOfflineEpisode(id)
+	if err != nil {
+		return nil, err
+	}
+	if ep == nil || !l.exists(ep) {
+		return nil, fmt.Errorf("episódio offline %d não encontrado", id)
+	}
+	return ep, nil
+}
+
+// List retorna a biblioteca agrupada por anime
+func (l *Library) List() ([]Anime, int64, error) {
+	episodes, err := l.db.OfflineEpisodes()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	animes := []Anime{}
+	index := make(map[string]int)
+	for _, ep := range episodes {
+		key := ep.AnimeURL
+		if key == "" {
+			key = "title:" + ep.AnimeTitle
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(animes)
+			index[key] = i
+			animes = append(animes, Anime{AnimeURL: ep.AnimeURL, Title: ep.AnimeTitle, Episodes: []store.OfflineEpisode{}})
+		}
+		animes[i].Episodes = append(animes[i].Episodes, ep)
+		animes[i].Size += ep.Size
+		total += ep.Size
+	}
+	return animes, total, nil
+}
+
+// Delete apaga o episódio do disco (vídeo e legendas) e do índice
+func (l *Library) Delete(id int64) error {
+	ep, err := l.db.GetOfflineEpisode(id)
+	if err != nil {
+		return err
+	}
+	if ep == nil {
+		return nil
+	}
+	return l.remove(ep)
+}
+
+// Cleanup aplica as regras: apaga os assistidos (se ativado) e, acima da cota,
+// os mais antigos — primeiro os já assistidos, depois os outros, poupando o último baixado.
+func (l *Library) Cleanup(rules Rules) (CleanupResult, error) {
+	var result CleanupResult
+	episodes, err := l.db.OfflineEpisodes()
+	if err != nil {
+		return result, err
+	}
+
+	var kept []store.OfflineEpisode
+	var total int64
+	for i := range episodes {
+		ep := &episodes[i]
+		if _, err := os.Stat(ep.Path); os.IsNotExist(err) {
+			// Apagado por fora: a limpeza tira a entrada e o download (OnRemove)
+			l.remove(ep)
+			continue
+		}
+		if !l.exists(ep) {
+			continue
+		}
+		if rules.DeleteWatched && ep.WatchedAt != "" {
+			if l.remove(ep) == nil {
+				result.Removed++
+				result.FreedBytes += ep.Size
+			}
+			continue
+		}
+		kept = append(kept, *ep)
+		total += ep.Size
+	}
+
+	if rules.QuotaBytes <= 0 || total <= rules.QuotaBytes {
+		return result, nil
+	}
+
+	// O episódio mais recente nunca sai pela cota, senão um download maior que ela seria apagado ao terminar
+	var newest int64
+	for _, ep := range kept {
+		if ep.ID > newest {
+			newest = ep.ID
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		wi, wj := kept[i].WatchedAt != "", kept[j].WatchedAt != ""
+		if wi != wj {
+			return wi
+		}
+		if wi {
+			return kept[i].WatchedAt < kept[j].WatchedAt
+		}
+		return kept[i].AddedAt < kept[j].AddedAt
+	})
+	for i := range kept {
+		if total <= rules.QuotaBytes {
+			break
+		}
+		if kept[i].ID == newest {
+			continue
+		}
+		if l.remove(&kept[i]) == nil {
+			result.Removed++
+			result.FreedBytes += kept[i].Size
+			total -= kept[i].Size
+		}
+	}
+	return result, nil
+}
+
+// exists confere o arquivo no disco e tira do índice só se ele sumiu de fato.
+// Outras falhas (disco externo desconectado, permissão) mantêm a entrada para a próxima consulta.
+// O download não é apagado daqui: uma consulta não deve ter efeito fora do índice.
+func (l *Library) exists(ep *store.OfflineEpisode) bool {
+	_, err := os.Stat(ep.Path)
+	switch {
+	case err == nil:
+		return true
+	case os.IsNotExist(err):
+		fmt.Printf("[Offline] Arquivo sumiu, removendo do índice: %s\n", ep.Path)
+		l.db.DeleteOfflineEpisode(ep.ID)
+	default:
+		fmt.Printf("[Offline] Arquivo indisponível: %v\n", err)
+	}
+	return false
+}
+
+// remove apaga os arquivos e a entrada. Arquivo em uso (ex.: aberto no player no Windows)
+// fica para a próxima limpeza.
+func (l *Library) remove(ep *store.OfflineEpisode) error {
+	if err := os.Remove(ep.Path); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("[Offline] Não foi possível apagar %s: %v\n", ep.Path, err)
+		return err
+	}
+	for _, sub := range ep.Subtitles {
+		os.Remove(sub)
+	}
+	// Pasta do anime vazia não serve para nada
+	os.Remove(filepath.Dir(ep.Path))
+
+	if err := l.db.DeleteOfflineEpisode(ep.ID); err != nil {
+		return err
+	}
+	fmt.Printf("[Offline] Removido: %s\n", ep.Path)
+	if l.onRemove != nil {
+		l.onRemove(*ep)
+	}
+	return nil
+}
+
+// FindSubtitles procura legendas com o mesmo nome do vídeo (ex.: "Ep 01.srt", "Ep 01.pt-BR.ass")
+func FindSubtitles(videoPath string) []string {
+	base := strings.TrimSuffix(filepath.Base(videoPath), filepath.Ext(videoPath))
+	entries, err := os.ReadDir(filepath.Dir(videoPath))
+	if err != nil {
+		return []string{}
+	}
+
+	subtitles := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !subtitleExts[strings.ToLower(filepath.Ext(name))] {
+			continue
+		}
+		if stem := strings.TrimSuffix(name, filepath.Ext(name)); stem == base || strings.HasPrefix(stem, base+".") {
+			subtitles = append(subtitles, filepath.Join(filepath.Dir(videoPath), name))
+		}
+	}
+	return subtitles
+}
+
+var seasonPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(?:season|temporada)\s*(\d{1,2})\b`),
+	regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th|ª|a)?\s*(?:season|temporada)\b`),
+	regexp.MustCompile(`(?i)\bS(\d{1,2})\b`),
+}
+
+// Season extrai o número da temporada do título (1 se não houver)
+func Season(title string) int {
+	for _, re := range seasonPatterns {
+		if m := re.FindStringSubmatch(title); m != nil {
+			if n, err := strconv.Atoi(m[1]); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+	return 1
+}
@@ -0,0 +1,133 @@
+package offline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"GoAnimeGUI/pkg/store"
+)
+
+func newTestLibrary(t *testing.T) (*Library, *store.DB, string) {
+	t.Helper()
+	db := store.OpenTemp(t)
+	return New(db), db, t.TempDir()
+}
+
+// addEpisode grava um arquivo de size bytes e o registra como download concluído
+func addEpisode(t *testing.T, l *Library, dir string, num int, size int) *store.OfflineEpisode {
+	t.Helper()
+	path := filepath.Join(dir, "Frieren", fmt.Sprintf("Episódio %d.mp4", num))
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ep, err := l.Register(store.Download{
+		ID: int64(num), State: store.DownloadCompleted, Path: path,
+		AnimeURL: "https://a/frieren", AnimeTitle: "Frieren 2nd Season",
+		EpisodeURL: fmt.Sprintf("https://a/frieren/%d", num), EpisodeNum: num,
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	return ep
+}
+
+func TestLibrary_RegisterAndLookup(t *testing.T) {
+	l, db, dir := newTestLibrary(t)
+	ep := addEpisode(t, l, dir, 1, 100)
+	os.WriteFile(filepath.Join(dir, "Frieren", "Episódio 1.pt-BR.srt"), []byte("1"), 0644)
+	os.WriteFile(filepath.Join(dir, "Frieren", "Episódio 10.srt"), []byte("1"), 0644)
+
+	// Registrar de novo atualiza a entrada (e encontra a legenda nova)
+	d := store.Download{ID: 1, State: store.DownloadCompleted, Path: ep.Path, AnimeURL: ep.AnimeURL,
+		AnimeTitle: ep.AnimeTitle, EpisodeURL: ep.EpisodeURL, EpisodeNum: 1}
+	again, err := l.Register(d)
+	if err != nil || again.ID != ep.ID {
+		t.Fatalf("Register() again = %+v, %v; want same entry", again, err)
+	}
+	if again.Season != 2 || again.Size != 100 || len(again.Subtitles) != 1 {
+		t.Errorf("entry = %+v; want season 2, 100 bytes and 1 subtitle", again)
+	}
+
+	if got := l.Lookup("https://a/frieren/1"); got == nil || got.Path != ep.Path {
+		t.Errorf("Lookup() = %+v; want %s", got, ep.Path)
+	}
+
+	// Arquivo apagado por fora: some do índice, sem disparar OnRemove (que apaga o download)
+	removed := 0
+	l.OnRemove(func(store.OfflineEpisode) { removed++ })
+	os.Remove(ep.Path)
+	if got := l.Lookup("https://a/frieren/1"); got != nil || removed != 0 {
+		t.Errorf("Lookup() after file removal = %+v (OnRemove called %d times); want nil and no callback", got, removed)
+	}
+	if got, _ := db.GetOfflineEpisode(ep.ID); got != nil {
+		t.Errorf("entry %d still indexed after its file was deleted", ep.ID)
+	}
+
+	// Falha que não é "arquivo não existe" (aqui ENOTDIR) mantém a entrada
+	blocker := filepath.Join(dir, "not-a-dir")
+	os.WriteFile(blocker, []byte("x"), 0644)
+	unreachable, err := db.SaveOfflineEpisode(store.OfflineEpisode{Path: filepath.Join(blocker, "Episódio 2.mp4"),
+		AnimeTitle: "Frieren", EpisodeURL: "https://a/frieren/2", EpisodeNum: 2})
+	if err != nil {
+		t.Fatalf("SaveOfflineEpisode() error = %v", err)
+	}
+	if got := l.Lookup("https://a/frieren/2"); got != nil {
+		t.Errorf("Lookup() of an unreachable file = %+v; want nil", got)
+	}
+	if got, _ := db.GetOfflineEpisode(unreachable.ID); got == nil {
+		t.Error("entry removed after a stat error other than not-exist")
+	}
+}
+
+func TestLibrary_CleanupRules(t *testing.T) {
+	l, db, dir := newTestLibrary(t)
+	ep1 := addEpisode(t, l, dir, 1, 400)
+	ep2 := addEpisode(t, l, dir, 2, 400)
+	ep3 := addEpisode(t, l, dir, 3, 400)
+	db.MarkOfflineWatched(ep2.EpisodeURL)
+
+	// Cota de 500 bytes: sai primeiro o assistido, depois o mais antigo; o último baixado fica
+	result, err := l.Cleanup(Rules{QuotaBytes: 500})
+	if err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if result.Removed != 2 || result.FreedBytes != 800 {
+		t.Errorf("Cleanup() = %+v; want 2 removed, 800 bytes", result)
+	}
+	for _, ep := range []*store.OfflineEpisode{ep1, ep2} {
+		if _, err := os.Stat(ep.Path); !os.IsNotExist(err) {
+			t.Errorf("%s should be deleted", ep.Path)
+		}
+	}
+	if l.Lookup(ep3.EpisodeURL) == nil {
+		t.Error("newest episode should be kept even above quota")
+	}
+
+	db.MarkOfflineWatched(ep3.EpisodeURL)
+	if result, _ := l.Cleanup(Rules{DeleteWatched: true}); result.Removed != 1 {
+		t.Errorf("Cleanup(DeleteWatched) = %+v; want 1 removed", result)
+	}
+	if animes, total, _ := l.List(); len(animes) != 0 || total != 0 {
+		t.Errorf("List() = %+v, %d; want empty", animes, total)
+	}
+}
+
+func TestSeason(t *testing.T) {
+	tests := map[string]int{
+		"Frieren":                        1,
+		"Shingeki no Kyojin Season 3":    3,
+		"Mushoku Tensei 2nd Season":      2,
+		"Re:Zero 3ª Temporada":           3,
+		"Dr. Stone S2 (Dublado)":         2,
+		"Kaguya-sama: Love is War 2":     1,
+		"Overlord IV temporada completa": 1,
+	}
+	for title, want := range tests {
+		if got := Season(title); got != want {
+			t.Errorf("Season(%q) = %d; want %d", title, got, want)
+		}
+	}
+}
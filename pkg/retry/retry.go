@@ -0,0 +1,50 @@
+// Package retry reúne o que as filas persistidas (downloads, capítulos de mangá e sincronização)
+// compartilham ao tentar de novo: erros definitivos e o backoff exponencial.
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// permanentError é um erro que não adianta tentar de novo
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marca err como definitivo: a fila desiste do item sem novas tentativas
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Permanentf é Permanent(fmt.Errorf(format, args...))
+func Permanentf(format string, args ...interface{}) error {
+	return &permanentError{err: fmt.Errorf(format, args...)}
+}
+
+// IsPermanent indica se algum erro da cadeia foi marcado com Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Backoff retorna a espera antes da tentativa seguinte a n falhas:
+// base, 2*base, 4*base... limitado a max
+func Backoff(failures int, base, max time.Duration) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	d := base
+	for i := 1; i < failures; i++ {
+		d *= 2
+		if d >= max {
+			return max
+		}
+	}
+	return d
+}
@@ -0,0 +1,52 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestIsPermanent(t *testing.T) {
+	base := errors.New("401")
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"comum", base, false},
+		{"permanente", Permanent(base), true},
+		{"formatado", Permanentf("playlist sem segmentos"), true},
+		{"embrulhado", fmt.Errorf("mal: %w", Permanent(base)), true},
+	}
+	for _, tt := range tests {
+		if got := IsPermanent(tt.err); got != tt.want {
+			t.Errorf("%s: IsPermanent(%v) = %v; want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) != nil")
+	}
+	if !errors.Is(Permanentf("falhou: %w", base), base) {
+		t.Error("Permanentf não preserva o erro embrulhado")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, 10 * time.Second},
+		{3, 40 * time.Second},
+		{6, 5 * time.Minute},
+		{100, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.failures, 10*time.Second, 5*time.Minute); got != tt.want {
+			t.Errorf("Backoff(%d) = %v; want %v", tt.failures, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+// Package segmentcache é um cache LRU em disco para os recursos que passam pelo proxy de vídeo:
+// segmentos HLS e pedaços (byte ranges) de MP4. Voltar no vídeo ou rever um episódio lê do disco
+// em vez de baixar de novo da CDN.
+package segmentcache
+
+import (
+	"container/list"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBytes é o tamanho padrão do cache (1 GB)
+const DefaultMaxBytes = 1 << 30
+
+var (
+	defaultCache *Cache
+	defaultOnce  sync.Once
+)
+
+// DefaultDir retorna a pasta padrão do cache (~/.cache/GoAnime/video)
+func DefaultDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "GoAnime", "video")
+	}
+	return filepath.Join(cacheDir, "GoAnime", "video")
+}
+
+// Default retorna o cache compartilhado pelo app, em DefaultDir.
+// Retorna nil se a pasta não puder ser usada.
+func Default() *Cache {
+	defaultOnce.Do(func() {
+		cache, err := Open(DefaultDir(), DefaultMaxBytes)
+		if err != nil {
+			fmt.Printf("[SegmentCache] Cache de vídeo desativado: %v\n", err)
+			return
+		}
+		defaultCache = cache
+	})
+	return defaultCache
+}
+
+// Meta descreve uma entrada do cache (gravada ao lado dos dados, em <hash>.json)
+type Meta struct {
+	Key          string `json:"key"`
+	ContentType  string `json:"contentType,omitempty"`
+	ContentRange string `json:"contentRange,omitempty"` // Vazio = resposta 200 completa
+	Size         int64  `json:"size"`
+}
+
+// Stats são as métricas do cache
+type Stats struct {
+	Entries   int     `json:"entries"`
+	Bytes     int64   `json:"bytes"`
+	MaxBytes  int64   `json:"maxBytes"`
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	HitBytes  int64   `json:"hitBytes"` // Bytes servidos do disco
+	Evictions int64   `json:"evictions"`
+	HitRate   float64 `json:"hitRate"` // 0-1
+}
+
+// Cache é um cache LRU limitado a maxBytes. Seguro para uso concorrente.
+type Cache struct {
+	dir      string
+	maxBytes int64
+	entries  map[string]*list.Element // Key -> elemento com *Meta
+	lru      *list.List               // Frente = usado mais recentemente
+	size     int64
+	stats    Stats
+	mutex    sync.Mutex
+}
+
+// Open abre (ou cria) o cache em dir, reconstruindo o índice a partir dos arquivos
+// (a ordem LRU vem da data de modificação, atualizada a cada acerto)
+func Open(dir string, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório do cache: %w", err)
+	}
+	c := &Cache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	c.evict()
+	c.mutex.Unlock()
+	return c, nil
+}
+
+func (c *Cache) load() error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("erro ao ler diretório do cache: %w", err)
+	}
+
+	type loaded struct {
+		meta   *Meta
+		usedAt time.Time
+	}
+	var found []loaded
+	known := make(map[string]bool)
+	for _, file := range files {
+		name := file.Name()
+		switch {
+		case strings.HasSuffix(name, ".tmp"):
+			os.Remove(filepath.Join(c.dir, name)) // Escrita interrompida
+		case strings.HasSuffix(name, ".json"):
+			hash := strings.TrimSuffix(name, ".json")
+			raw, err := os.ReadFile(filepath.Join(c.dir, name))
+			var meta Meta
+			if err == nil {
+				err = json.Unmarshal(raw, &meta)
+			}
+			info, statErr := os.Stat(c.dataPath(hash))
+			if err != nil || statErr != nil || info.Size() != meta.Size || hashKey(meta.Key) != hash {
+				c.removeFiles(hash)
+				continue
+			}
+			known[hash] = true
+			found = append(found, loaded{meta: &meta, usedAt: info.ModTime()})
+		}
+	}
+	// Dados sem metadados (remoção que falhou no Windows, por exemplo)
+	for _, file := range files {
+		if hash, ok := strings.CutSuffix(file.Name(), ".bin"); ok && !known[hash] {
+			os.Remove(filepath.Join(c.dir, file.Name()))
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].usedAt.After(found[j].usedAt) })
+	for _, entry := range found {
+		c.entries[entry.meta.Key] = c.lru.PushBack(entry.meta)
+		c.size += entry.meta.Size
+	}
+	return nil
+}
+
+// Key normaliza URL e Range numa chave: esquema e host em minúsculas, sem porta padrão,
+// sem fragmento e com os parâmetros da query ordenados
+func Key(rawURL, byteRange string) string {
+	key := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil {
+		parsed.Scheme = strings.ToLower(parsed.Scheme)
+		host := strings.ToLower(parsed.Hostname())
+		if port := parsed.Port(); port != "" && !(parsed.Scheme == "http" && port == "80") && !(parsed.Scheme == "https" && port == "443") {
+			host += ":" + port
+		}
+		parsed.Host = host
+		parsed.Fragment = ""
+		parsed.RawFragment = ""
+		parsed.RawQuery = parsed.Query().Encode()
+		key = parsed.String()
+	}
+
+	byteRange = strings.ToLower(strings.ReplaceAll(byteRange, " ", ""))
+	if byteRange != "" {
+		key += "#" + byteRange
+	}
+	return key
+}
+
+// Get abre a entrada da chave e conta acerto ou falha. Quem chama fecha o arquivo.
+func (c *Cache) Get(key string) (io.ReadCloser, Meta, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, Meta{}, false
+	}
+	meta := elem.Value.(*Meta)
+	hash := hashKey(key)
+	file, err := os.Open(c.dataPath(hash))
+	if err != nil {
+		c.remove(elem)
+		c.stats.Misses++
+		return nil, Meta{}, false
+	}
+
+	c.lru.MoveToFront(elem)
+	now := time.Now()
+	os.Chtimes(c.dataPath(hash), now, now)
+	c.stats.Hits++
+	c.stats.HitBytes += meta.Size
+	return file, *meta, true
+}
+
+// Enabled diz se o cache aceita entradas (limite maior que zero). Seguro com c nil.
+func (c *Cache) Enabled() bool {
+	if c == nil {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.maxBytes > 0
+}
+
+// Contains diz se a chave está no cache, sem contar nas métricas
+func (c *Cache) Contains(key string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	_, ok := c.entries[key]
+	return ok
+}
+
+// Create começa a gravar uma entrada. Os dados só entram no cache com Commit.
+func (c *Cache) Create(key string, meta Meta) (*Writer, error) {
+	file, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar arquivo no cache: %w", err)
+	}
+	meta.Key = key
+	meta.Size = 0
+
+	c.mutex.Lock()
+	limit := c.maxBytes / 4 // Uma entrada não pode expulsar o cache inteiro
+	c.mutex.Unlock()
+	return &Writer{cache: c, file: file, meta: meta, limit: limit}, nil
+}
+
+// Put grava uma entrada completa
+func (c *Cache) Put(key string, meta Meta, data []byte) error {
+	w, err := c.Create(key, meta)
+	if err != nil {
+		return err
+	}
+	w.Write(data)
+	return w.Commit()
+}
+
+// SetMaxBytes muda o limite do cache, expulsando o que passar dele
+func (c *Cache) SetMaxBytes(maxBytes int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.maxBytes = maxBytes
+	c.evict()
+}
+
+// Stats retorna as métricas do cache
+func (c *Cache) Stats() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	stats.Bytes = c.size
+	stats.MaxBytes = c.maxBytes
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// Clear apaga todas as entradas e zera as métricas
+func (c *Cache) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for c.lru.Len() > 0 {
+		c.remove(c.lru.Back())
+	}
+	c.stats = Stats{}
+}
+
+// evict remove as entradas menos usadas até caber no limite. Chamar com o lock.
+func (c *Cache) evict() {
+	for c.size > c.maxBytes && c.lru.Len() > 0 {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// remove tira a entrada do índice e apaga os arquivos. Chamar com o lock.
+func (c *Cache) remove(elem *list.Element) {
+	meta := elem.Value.(*Meta)
+	c.lru.Remove(elem)
+	delete(c.entries, meta.Key)
+	c.size -= meta.Size
+	c.removeFiles(hashKey(meta.Key))
+}
+
+// removeFiles apaga os metadados antes dos dados: se os dados estiverem abertos (Windows),
+// o arquivo órfão é apagado na próxima abertura do cache
+func (c *Cache) removeFiles(hash string) {
+	os.Remove(filepath.Join(c.dir, hash+".json"))
+	os.Remove(c.dataPath(hash))
+}
+
+func (c *Cache) dataPath(hash string) string {
+	return filepath.Join(c.dir, hash+".bin")
+}
+
+func hashKey(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Writer grava uma entrada enquanto a resposta é repassada ao player (io.TeeReader).
+// Write nunca falha, para não interromper o stream: erros de disco ou entradas maiores
+// que o permitido só fazem Commit descartar a entrada.
+type Writer struct {
+	cache *Cache
+	file  *os.File
+	meta  Meta
+	limit int64
+	err   error
+}
+
+// Write grava no arquivo temporário
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return len(p), nil
+	}
+	if w.meta.Size+int64(len(p)) > w.limit {
+		w.err = fmt.Errorf("entrada maior que o limite do cache")
+		return len(p), nil
+	}
+	n, err := w.file.Write(p)
+	w.meta.Size += int64(n)
+	if err != nil {
+		w.err = err
+	}
+	return len(p), nil
+}
+
+// Commit coloca a entrada no cache, substituindo a anterior da mesma chave
+func (w *Writer) Commit() error {
+	if w.err != nil {
+		w.Abort()
+		return w.err
+	}
+	if err := w.file.Close(); err != nil {
+		os.Remove(w.file.Name())
+		return err
+	}
+
+	c := w.cache
+	hash := hashKey(w.meta.Key)
+	raw, _ := json.Marshal(w.meta)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.entries[w.meta.Key]; ok {
+		c.remove(elem)
+	}
+	if err := os.Rename(w.file.Name(), c.dataPath(hash)); err != nil {
+		os.Remove(w.file.Name())
+		return fmt.Errorf("erro ao gravar entrada do cache: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, hash+".json"), raw, 0644); err != nil {
+		os.Remove(c.dataPath(hash))
+		return fmt.Errorf("erro ao gravar entrada do cache: %w", err)
+	}
+
+	meta := w.meta
+	c.entries[meta.Key] = c.lru.PushFront(&meta)
+	c.size += meta.Size
+	c.evict()
+	return nil
+}
+
+// Abort descarta a entrada
+func (w *Writer) Abort() {
+	w.file.Close()
+	os.Remove(w.file.Name())
+}
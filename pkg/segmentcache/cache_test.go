@@ -0,0 +1,76 @@
+package segmentcache
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestKey_Normalizes(t *testing.T) {
+	a := Key("HTTPS://CDN.Example.com:443/seg/1.ts?b=2&a=1#frag", "Bytes=0- 99")
+	b := Key("https://cdn.example.com/seg/1.ts?a=1&b=2", "bytes=0-99")
+	if a != b {
+		t.Errorf("Key() = %q and %q; want equal keys", a, b)
+	}
+	if Key("https://cdn.example.com/seg/1.ts", "") == Key("https://cdn.example.com/seg/1.ts", "bytes=0-99") {
+		t.Error("Key() should differ per range")
+	}
+}
+
+func TestCache_LRUEvictionAndReopen(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := Open(dir, 100)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	segment := bytes.Repeat([]byte("x"), 20)
+	for _, key := range []string{"a", "b", "c", "d"} {
+		if err := cache.Put(key, Meta{ContentType: "video/mp2t"}, segment); err != nil {
+			t.Fatalf("Put(%q) error = %v", key, err)
+		}
+	}
+
+	// "a" vira o mais recente; "e" enche o cache e "f" expulsa "b"
+	data, meta, ok := cache.Get("a")
+	if !ok || meta.ContentType != "video/mp2t" || meta.Size != 20 {
+		t.Fatalf("Get(a) = %+v, %v; want the stored entry", meta, ok)
+	}
+	got, _ := io.ReadAll(data)
+	data.Close()
+	if !bytes.Equal(got, segment) {
+		t.Errorf("Get(a) data = %q; want %q", got, segment)
+	}
+	cache.Put("e", Meta{}, segment)
+	cache.Put("f", Meta{}, segment)
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true, "e": true, "f": true} {
+		if cache.Contains(key) != want {
+			t.Errorf("Contains(%q) = %v; want %v", key, !want, want)
+		}
+	}
+
+	// Entradas maiores que 1/4 do limite não entram
+	if err := cache.Put("big", Meta{}, bytes.Repeat([]byte("x"), 30)); err == nil || cache.Contains("big") {
+		t.Error("Put() should reject an entry larger than a quarter of the cache")
+	}
+
+	cache.Get("missing")
+	stats := cache.Stats()
+	if stats.Entries != 5 || stats.Bytes != 100 || stats.Hits != 1 || stats.Misses != 1 || stats.Evictions != 1 || stats.HitRate != 0.5 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	// Nova execução: o índice vem dos arquivos e o limite menor expulsa o excedente
+	reopened, err := Open(dir, 50)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if stats := reopened.Stats(); stats.Entries != 2 || stats.Bytes != 40 {
+		t.Errorf("Stats() after reopen = %+v; want 2 entries within the new limit", stats)
+	}
+
+	reopened.Clear()
+	if stats := reopened.Stats(); stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("Stats() after Clear = %+v; want empty", stats)
+	}
+}
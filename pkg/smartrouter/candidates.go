@@ -0,0 +1,223 @@
+package smartrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"GoAnimeGUI/pkg/hls"
+)
+
+// Idiomas e formatos dos candidatos
+const (
+	LanguageDub = "dub"
+	LanguageSub = "sub"
+	FormatHLS   = "hls"
+	FormatMP4   = "mp4"
+	FormatDASH  = "dash"
+)
+
+// StreamRequest descreve o episódio buscado e as preferências usadas para ordenar os candidatos
+type StreamRequest struct {
+	AnimeTitle    string
+	EpisodeNumber int
+	Language      string // LanguageDub, LanguageSub ou vazio (sem preferência)
+	Quality       string // "1080p", "720p"... ou "auto"/vazio (adaptativo e maiores primeiro)
+}
+
+// Candidate é uma opção de stream; o player tenta a próxima se uma falhar
+type Candidate struct {
+	URL      string            `json:"url"`
+	Source   string            `json:"source"`
+	Quality  string            `json:"quality"`  // "1080p", "auto"...
+	Language string            `json:"language"` // LanguageDub, LanguageSub ou vazio (desconhecido)
+	Format   string            `json:"format"`   // FormatHLS, FormatMP4 ou FormatDASH (o player escolhe dash.js por ele)
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+// ResolveFunc lista os candidatos de uma fonte com os metadados que ela conhece
+type ResolveFunc func(ctx context.Context, req StreamRequest) ([]Candidate, error)
+
+// GetStreamCandidates busca em todas as fontes liberadas ao mesmo tempo e junta os candidatos,
+// ordenados por idioma, pontuação da fonte e qualidade. Cancelar ctx interrompe todas as buscas.
+func (r *SmartRouter) GetStreamCandidates(ctx context.Context, req StreamRequest) ([]Candidate, error) {
+	ranked := r.rankedSources()
+	order := make(map[string]int, len(ranked))
+
+	var (
+		wg         sync.WaitGroup
+		mutex      sync.Mutex
+		candidates []Candidate
+		lastError  error
+	)
+	for i, source := range ranked {
+		order[source.Name] = i
+		if !r.allow(source.Name) {
+			continue
+		}
+		wg.Add(1)
+		go func(src StreamSource) {
+			defer wg.Done()
+			found, duration, err := r.fetchSource(ctx, src, req)
+			if ctx.Err() != nil {
+				r.release(src.Name)
+				return
+			}
+			if err != nil {
+				r.recordFailure(src.Name, duration, err)
+				mutex.Lock()
+				lastError = err
+				mutex.Unlock()
+				return
+			}
+			r.recordSuccess(src.Name, duration)
+			mutex.Lock()
+			candidates = append(candidates, found...)
+			mutex.Unlock()
+		}(source)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("busca cancelada: %w", err)
+	}
+	if len(candidates) == 0 {
+		if lastError == nil {
+			lastError = errors.New("nenhuma fonte disponível")
+		}
+		return nil, fmt.Errorf("todas as fontes falharam: %w", lastError)
+	}
+	rankCandidates(candidates, req, order)
+	return candidates, nil
+}
+
+// fetchSource busca os candidatos de uma fonte respeitando o timeout dela e o cancelamento de ctx.
+// O Fetcher pode ignorar o context; o select garante que o timeout vale mesmo assim.
+func (r *SmartRouter) fetchSource(ctx context.Context, src StreamSource, req StreamRequest) ([]Candidate, time.Duration, error) {
+	srcCtx, cancel := context.WithTimeout(ctx, src.Timeout)
+	defer cancel()
+
+	type outcome struct {
+		candidates []Candidate
+		err        error
+	}
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		candidates, err := resolveSource(srcCtx, src, req)
+		done <- outcome{candidates, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.candidates, time.Since(start), o.err
+	case <-srcCtx.Done():
+		if ctx.Err() != nil {
+			return nil, time.Since(start), ctx.Err()
+		}
+		return nil, src.Timeout, fmt.Errorf("timeout após %v", src.Timeout)
+	}
+}
+
+// resolveSource chama Resolve (ou Fetcher, para fontes que só retornam uma URL) e completa os metadados
+func resolveSource(ctx context.Context, src StreamSource, req StreamRequest) ([]Candidate, error) {
+	var found []Candidate
+	switch {
+	case src.Resolve != nil:
+		var err error
+		if found, err = src.Resolve(ctx, req); err != nil {
+			return nil, err
+		}
+	case src.Fetcher != nil:
+		url, err := src.Fetcher(ctx, req.AnimeTitle, req.EpisodeNumber)
+		if err != nil {
+			return nil, err
+		}
+		found = []Candidate{{URL: url}}
+	default:
+		return nil, fmt.Errorf("fonte %s sem Fetcher", src.Name)
+	}
+
+	candidates := make([]Candidate, 0, len(found))
+	for _, c := range found {
+		if c.URL == "" {
+			continue
+		}
+		c.Source = src.Name
+		if c.Format == "" {
+			c.Format = guessFormat(c.URL)
+		}
+		if c.Quality == "" {
+			c.Quality = "auto"
+		}
+		candidates = append(candidates, c)
+	}
+	if len(candidates) == 0 {
+		return nil, errors.New("nenhum stream retornado")
+	}
+	return candidates, nil
+}
+
+// rankCandidates ordena: idioma preferido, depois a ordem das fontes, depois a qualidade
+func rankCandidates(candidates []Candidate, req StreamRequest, sourceOrder map[string]int) {
+	target := hls.QualityHeight(req.Quality)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if la, lb := languageRank(a.Language, req.Language), languageRank(b.Language, req.Language); la != lb {
+			return la < lb
+		}
+		if sa, sb := sourceOrder[a.Source], sourceOrder[b.Source]; sa != sb {
+			return sa < sb
+		}
+		return qualityRank(a.Quality, target) < qualityRank(b.Quality, target)
+	})
+}
+
+// languageRank: o idioma pedido primeiro, desconhecido depois, o outro por último
+func languageRank(language, wanted string) int {
+	switch {
+	case wanted == "" || language == wanted:
+		return 0
+	case language == "":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// qualityRank: sem alvo, o stream adaptativo e depois as maiores; com alvo, a exata,
+// depois as menores mais próximas e por último as maiores
+func qualityRank(quality string, target int) int {
+	height := hls.QualityHeight(quality)
+	switch {
+	case target == 0 && height == 0:
+		return 0
+	case target == 0:
+		return 10000 - height
+	case height == 0:
+		return 5000
+	case height <= target:
+		return target - height
+	default:
+		return 10000 + height - target
+	}
+}
+
+// guessFormat deduz o formato pela extensão da URL (HLS quando não dá para saber)
+func guessFormat(rawURL string) string {
+	path := strings.ToLower(rawURL)
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	if strings.HasSuffix(path, ".mp4") || strings.HasSuffix(path, ".m4v") {
+		return FormatMP4
+	}
+	if strings.HasSuffix(path, ".mpd") {
+		return FormatDASH
+	}
+	return FormatHLS
+}
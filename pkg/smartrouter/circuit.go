@@ -0,0 +1,185 @@
+package smartrouter
+
+import (
+	"fmt"
+	"time"
+)
+
+// CircuitState é o estado do circuit breaker de uma fonte
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // Tráfego normal
+	CircuitOpen     CircuitState = "open"      // Fonte ignorada até o intervalo passar
+	CircuitHalfOpen CircuitState = "half-open" // Só algumas requisições de sondagem passam
+)
+
+// maxCircuitEvents é quantas mudanças de estado cada fonte guarda para diagnóstico
+const maxCircuitEvents = 20
+
+// CircuitEvent é uma mudança de estado do circuit breaker
+type CircuitEvent struct {
+	Source string       `json:"source"`
+	From   CircuitState `json:"from"`
+	To     CircuitState `json:"to"`
+	At     time.Time    `json:"at"`
+	Reason string       `json:"reason"`
+}
+
+// OnCircuitChange registra um callback para mudanças de estado dos circuits
+func (r *SmartRouter) OnCircuitChange(fn func(CircuitEvent)) {
+	r.statsMutex.Lock()
+	r.onCircuitChange = fn
+	r.statsMutex.Unlock()
+}
+
+// allow diz se uma requisição pode ir para a fonte. No meio-aberto reserva uma
+// das vagas de sondagem, que é devolvida ao registrar o resultado ou por release.
+func (r *SmartRouter) allow(sourceName string) bool {
+	r.statsMutex.Lock()
+	stats, ok := r.stats[sourceName]
+	if !ok {
+		r.statsMutex.Unlock()
+		return true
+	}
+
+	var events []CircuitEvent
+	now := r.now()
+	if stats.CircuitState == CircuitOpen {
+		if now.Before(stats.CircuitOpenAt.Add(stats.OpenInterval)) {
+			r.statsMutex.Unlock()
+			return false
+		}
+		events = append(events, r.transition(sourceName, stats, CircuitHalfOpen, "intervalo aberto expirou"))
+	}
+
+	allowed := true
+	if stats.CircuitState == CircuitHalfOpen {
+		// Uma sondagem que nunca registrou resultado não segura a vaga para sempre
+		if stats.probes > 0 && now.Sub(stats.probeAt) > r.probeTimeout(sourceName) {
+			stats.probes = 0
+		}
+		if stats.probes >= r.halfOpenProbes {
+			allowed = false
+		} else {
+			stats.probes++
+			stats.probeAt = now
+		}
+	}
+	r.statsMutex.Unlock()
+
+	r.emitCircuitEvents(events)
+	return allowed
+}
+
+// release devolve a vaga de sondagem de uma requisição abandonada sem resultado
+func (r *SmartRouter) release(sourceName string) {
+	r.statsMutex.Lock()
+	defer r.statsMutex.Unlock()
+	if stats, ok := r.stats[sourceName]; ok && stats.CircuitState == CircuitHalfOpen && stats.probes > 0 {
+		stats.probes--
+	}
+}
+
+// circuitSuccess aplica um sucesso ao circuit. Deve ser chamado com o mutex travado.
+func (r *SmartRouter) circuitSuccess(sourceName string, stats *SourceStats) []CircuitEvent {
+	stats.ConsecutiveFailures = 0
+	if stats.CircuitState != CircuitHalfOpen {
+		return nil
+	}
+	if stats.probes > 0 {
+		stats.probes--
+	}
+	stats.probeSuccesses++
+	if stats.probeSuccesses < r.halfOpenProbes {
+		return nil
+	}
+	stats.trips = 0
+	stats.OpenInterval = 0
+	return []CircuitEvent{r.transition(sourceName, stats, CircuitClosed, "sondagens bem-sucedidas")}
+}
+
+// circuitFailure aplica uma falha ao circuit. Deve ser chamado com o mutex travado.
+func (r *SmartRouter) circuitFailure(sourceName string, stats *SourceStats) []CircuitEvent {
+	stats.ConsecutiveFailures++
+	switch stats.CircuitState {
+	case CircuitHalfOpen:
+		// A fonte continua quebrada: volta a abrir, por mais tempo
+		return []CircuitEvent{r.open(sourceName, stats, "falha na sondagem")}
+	case CircuitClosed:
+		if stats.ConsecutiveFailures >= r.circuitThreshold {
+			return []CircuitEvent{r.open(sourceName, stats,
+				fmt.Sprintf("%d falhas seguidas", stats.ConsecutiveFailures))}
+		}
+	}
+	// Aberto: resultados atrasados de requisições antigas não mudam nada
+	return nil
+}
+
+// open abre o circuit com backoff exponencial: cada reabertura seguida dobra o intervalo
+func (r *SmartRouter) open(sourceName string, stats *SourceStats, reason string) CircuitEvent {
+	stats.trips++
+	interval := r.circuitResetTime
+	for i := 1; i < stats.trips && interval < r.circuitMaxResetTime; i++ {
+		interval *= 2
+	}
+	if interval > r.circuitMaxResetTime {
+		interval = r.circuitMaxResetTime
+	}
+	stats.OpenInterval = interval
+	stats.CircuitOpenAt = r.now()
+	return r.transition(sourceName, stats, CircuitOpen, fmt.Sprintf("%s, aberto por %v", reason, interval))
+}
+
+// closeCircuit fecha o circuit e zera o backoff. Deve ser chamado com o mutex travado.
+func (r *SmartRouter) closeCircuit(sourceName string, stats *SourceStats, reason string) []CircuitEvent {
+	stats.ConsecutiveFailures = 0
+	stats.trips = 0
+	stats.OpenInterval = 0
+	if stats.CircuitState == CircuitClosed {
+		return nil
+	}
+	return []CircuitEvent{r.transition(sourceName, stats, CircuitClosed, reason)}
+}
+
+// transition muda o estado e guarda o evento. Deve ser chamado com o mutex travado.
+func (r *SmartRouter) transition(sourceName string, stats *SourceStats, to CircuitState, reason string) CircuitEvent {
+	event := CircuitEvent{Source: sourceName, From: stats.CircuitState, To: to, At: r.now(), Reason: reason}
+	stats.CircuitState = to
+	stats.IsCircuitOpen = to != CircuitClosed
+	stats.probes = 0
+	stats.probeSuccesses = 0
+
+	stats.CircuitEvents = append(stats.CircuitEvents, event)
+	if len(stats.CircuitEvents) > maxCircuitEvents {
+		stats.CircuitEvents = stats.CircuitEvents[len(stats.CircuitEvents)-maxCircuitEvents:]
+	}
+	fmt.Printf("[SmartRouter] Circuit %s: %s → %s (%s)\n", sourceName, event.From, to, reason)
+	return event
+}
+
+// emitCircuitEvents chama o callback fora do mutex
+func (r *SmartRouter) emitCircuitEvents(events []CircuitEvent) {
+	if len(events) == 0 {
+		return
+	}
+	r.statsMutex.RLock()
+	fn := r.onCircuitChange
+	r.statsMutex.RUnlock()
+	if fn == nil {
+		return
+	}
+	for _, event := range events {
+		fn(event)
+	}
+}
+
+// probeTimeout é quanto uma sondagem pode ficar sem resultado antes de liberar a vaga
+func (r *SmartRouter) probeTimeout(sourceName string) time.Duration {
+	for _, source := range r.sources {
+		if source.Name == sourceName {
+			return 2 * source.Timeout
+		}
+	}
+	return 2 * r.defaultTimeout
+}
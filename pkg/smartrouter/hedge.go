@@ -0,0 +1,147 @@
+package smartrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	maxLatencySamples = 50 // Latências guardadas por fonte para o p90
+	minHedgeSamples   = 5  // Abaixo disso o p90 não é confiável e vale o HedgeDelay
+)
+
+// addLatencySample guarda a latência de um sucesso. Deve ser chamado com o mutex travado.
+func addLatencySample(stats *SourceStats, latency time.Duration) {
+	stats.latencies = append(stats.latencies, latency)
+	if len(stats.latencies) > maxLatencySamples {
+		stats.latencies = stats.latencies[len(stats.latencies)-maxLatencySamples:]
+	}
+}
+
+// p90 retorna o percentil 90 das latências de sucesso (0 se houver poucas amostras)
+func p90(samples []time.Duration) time.Duration {
+	if len(samples) < minHedgeSamples {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[(len(sorted)*9+9)/10-1]
+}
+
+// hedgeDelay é quanto esperar pela fonte antes de disparar a próxima:
+// o p90 observado, ou HedgeDelay se ainda não há histórico
+func (r *SmartRouter) hedgeDelay(source StreamSource) time.Duration {
+	r.statsMutex.RLock()
+	delay := time.Duration(0)
+	if stats, ok := r.stats[source.Name]; ok {
+		delay = p90(stats.latencies)
+	}
+	r.statsMutex.RUnlock()
+
+	if delay <= 0 {
+		delay = r.hedgeDelayDefault
+	}
+	if delay > source.Timeout {
+		delay = source.Timeout
+	}
+	return delay
+}
+
+// getStreamHedged dispara a melhor fonte e só acrescenta a próxima se a resposta
+// demorar mais que o p90 da fonte (ou se ela falhar), até HedgeMaxFanOut em voo.
+// A primeira resposta válida cancela as demais.
+func (r *SmartRouter) getStreamHedged(ctx context.Context, req StreamRequest) *StreamResult {
+	startTime := time.Now()
+	ranked := r.rankedSources()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type sourceResult struct {
+		source     string
+		candidates []Candidate
+		duration   time.Duration
+		err        error
+	}
+	resultChan := make(chan sourceResult, len(ranked))
+	next, inFlight := 0, 0
+	hedge := time.NewTimer(time.Hour)
+	hedge.Stop()
+	defer hedge.Stop()
+
+	// launch inicia a próxima fonte liberada pelo circuit breaker e arma o timer do hedge
+	launch := func() bool {
+		for next < len(ranked) {
+			src := ranked[next]
+			next++
+			if !r.allow(src.Name) {
+				continue
+			}
+			inFlight++
+			delay := r.hedgeDelay(src)
+			fmt.Printf("[SmartRouter] Hedge: tentando %s (próxima em %v)\n", src.Name, delay)
+			go func() {
+				candidates, duration, err := r.fetchSource(ctx, src, req)
+				if ctx.Err() != nil {
+					// Outra fonte já venceu ou quem chamou desistiu
+					r.release(src.Name)
+					return
+				}
+				resultChan <- sourceResult{src.Name, candidates, duration, err}
+			}()
+			hedge.Reset(delay)
+			return true
+		}
+		return false
+	}
+
+	if !launch() {
+		return &StreamResult{
+			Error:    errors.New("nenhuma fonte disponível"),
+			Duration: time.Since(startTime),
+		}
+	}
+
+	var lastError error
+	for inFlight > 0 {
+		select {
+		case result := <-resultChan:
+			inFlight--
+			if result.err == nil {
+				cancel() // Cancela as fontes que ainda estão buscando
+				r.recordSuccess(result.source, result.duration)
+				stream := newStreamResult(result.candidates, req, time.Since(startTime))
+				fmt.Printf("[SmartRouter] ✓ Hedge: sucesso com %s em %v\n", stream.Source, stream.Duration)
+				return stream
+			}
+			r.recordFailure(result.source, result.duration, result.err)
+			lastError = result.err
+			// Falhou: não adianta esperar o timer para tentar a próxima
+			if inFlight < r.hedgeMaxFanOut {
+				launch()
+			}
+
+		case <-hedge.C:
+			if inFlight < r.hedgeMaxFanOut {
+				launch()
+			}
+
+		case <-ctx.Done():
+			return &StreamResult{
+				Error:    fmt.Errorf("busca cancelada: %w", ctx.Err()),
+				Duration: time.Since(startTime),
+			}
+		}
+	}
+
+	if lastError == nil {
+		lastError = errors.New("sem resultado")
+	}
+	return &StreamResult{
+		Error:    fmt.Errorf("todas as fontes falharam: %w", lastError),
+		Duration: time.Since(startTime),
+	}
+}
@@ -0,0 +1,112 @@
+package smartrouter
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// latencyWeight é a fração da pontuação perdida por uma fonte que demora o DefaultTimeout inteiro
+const latencyWeight = 0.5
+
+// Ranking retorna os nomes das fontes na ordem em que serão tentadas agora
+func (r *SmartRouter) Ranking() []string {
+	ranked := r.rankedSources()
+	names := make([]string, len(ranked))
+	for i, source := range ranked {
+		names[i] = source.Name
+	}
+	return names
+}
+
+// rankedSources ordena as fontes pela pontuação atual. Empates mantêm a ordem de Priority.
+func (r *SmartRouter) rankedSources() []StreamSource {
+	priors := r.healthScores()
+	r.statsMutex.RLock()
+	defer r.statsMutex.RUnlock()
+
+	now := r.now()
+	ranked := make([]StreamSource, len(r.sources))
+	copy(ranked, r.sources)
+	scores := make(map[string]float64, len(ranked))
+	for _, source := range ranked {
+		scores[source.Name] = r.score(source, r.stats[source.Name], priors.of(source.Name), now)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i].Name] > scores[ranked[j].Name]
+	})
+	return ranked
+}
+
+// score combina a taxa de sucesso e a latência (médias móveis) com a prioridade estática.
+// Uma fonte sem falhas e instantânea vale 1 menos o peso da sua Priority.
+func (r *SmartRouter) score(source StreamSource, stats *SourceStats, prior float64, now time.Time) float64 {
+	success, latency := r.decayed(stats, prior, now)
+	penalty := math.Min(latency/float64(r.defaultTimeout.Milliseconds()), 1) * latencyWeight
+	return success*(1-penalty) - r.priorityWeight*float64(source.Priority)
+}
+
+// observe atualiza as médias móveis com uma medição. Deve ser chamado com o mutex travado.
+func (r *SmartRouter) observe(stats *SourceStats, prior float64, success bool, latency time.Duration) {
+	now := r.now()
+	rate, avg := r.decayed(stats, prior, now)
+	sample := 0.0
+	if success {
+		sample = 1
+	}
+	stats.SuccessRate = rate + r.ewmaAlpha*(sample-rate)
+	stats.AvgLatency = avg + r.ewmaAlpha*(float64(latency.Milliseconds())-avg)
+	stats.updatedAt = now
+}
+
+// decayed retorna as médias aproximadas do prior conforme o tempo sem medições:
+// uma fonte rebaixada que ninguém tenta volta a ser tentada depois de algumas meias-vidas.
+// priorSuccess é a pontuação salva em Health (ver healthScores), então uma fonte que falhava
+// na execução anterior começa rebaixada.
+func (r *SmartRouter) decayed(stats *SourceStats, priorSuccess float64, now time.Time) (success, latency float64) {
+	prior := r.priorLatency()
+	if stats.updatedAt.IsZero() {
+		return priorSuccess, prior
+	}
+	weight := math.Exp2(-float64(now.Sub(stats.updatedAt)) / float64(r.scoreHalfLife))
+	return priorSuccess + (stats.SuccessRate-priorSuccess)*weight, prior + (stats.AvgLatency-prior)*weight
+}
+
+// healthPriors são as pontuações de Health por fonte
+type healthPriors map[string]float64
+
+// of retorna o prior de sucesso da fonte (1 sem Health)
+func (p healthPriors) of(name string) float64 {
+	if score, ok := p[name]; ok {
+		return score
+	}
+	return 1
+}
+
+// healthScores lê as pontuações de Health das fontes (todas, se names vier vazio) antes de
+// travar o statsMutex: o sourcehealth.Tracker grava no SQLite sob o próprio mutex, e consultá-lo
+// com o statsMutex travado faria toda a ordenação esperar por essas gravações.
+func (r *SmartRouter) healthScores(names ...string) healthPriors {
+	r.statsMutex.RLock()
+	health := r.health
+	if len(names) == 0 {
+		for _, source := range r.sources {
+			names = append(names, source.Name)
+		}
+	}
+	r.statsMutex.RUnlock()
+
+	if health == nil {
+		return nil
+	}
+	scores := make(healthPriors, len(names))
+	for _, name := range names {
+		scores[name] = health.Score(name)
+	}
+	return scores
+}
+
+// priorLatency é a latência presumida de uma fonte sem histórico (em ms)
+func (r *SmartRouter) priorLatency() float64 {
+	return float64(r.defaultTimeout.Milliseconds()) / 4
+}
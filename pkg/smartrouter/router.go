@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,12 +12,13 @@ import (
 
 // StreamResult representa o resultado de uma busca de stream
 type StreamResult struct {
-	URL      string
-	Source   string
-	Referer  string
-	Headers  map[string]string
-	Error    error
-	Duration time.Duration
+	URL        string
+	Source     string
+	Referer    string
+	Headers    map[string]string
+	Error      error
+	Duration   time.Duration
+	Candidates []Candidate // Todos os streams da fonte vencedora, do melhor para o pior
 }
 
 // SourceStats mantém estatísticas de cada fonte
@@ -27,8 +29,24 @@ type SourceStats struct {
 	TotalLatency  int64 // em milissegundos
 	LastSuccess   time.Time
 	LastFailure   time.Time
-	IsCircuitOpen bool
+	IsCircuitOpen bool // Verdadeiro enquanto o circuit não está fechado (aberto ou meio-aberto)
 	CircuitOpenAt time.Time
+	SuccessRate   float64 // Média móvel exponencial de sucesso (0 a 1)
+	AvgLatency    float64 // Média móvel exponencial da latência em ms
+	Score         float64 // Pontuação da fonte na ordem de tentativas (maior = antes)
+	updatedAt     time.Time
+
+	CircuitState        CircuitState
+	OpenInterval        time.Duration // Tempo aberto antes das sondagens (dobra a cada reabertura)
+	ConsecutiveFailures int
+	CircuitEvents       []CircuitEvent // Últimas mudanças de estado, da mais antiga à mais recente
+	trips               int            // Aberturas seguidas sem fechar, para o backoff
+	probes              int            // Sondagens em andamento no meio-aberto
+	probeAt             time.Time
+	probeSuccesses      int
+
+	P90Latency time.Duration   // Percentil 90 das latências de sucesso recentes (0 = pouco histórico)
+	latencies  []time.Duration // Últimas latências de sucesso
 }
 
 // StreamSource define uma fonte de streaming
@@ -37,31 +55,70 @@ type StreamSource struct {
 	Priority int // Menor = maior prioridade
 	Timeout  time.Duration
 	Fetcher  func(ctx context.Context, animeTitle string, episodeNumber int) (string, error)
+	Resolve  ResolveFunc // Opcional: lista candidatos com qualidade, idioma e headers (tem preferência sobre Fetcher)
+}
+
+// Health recebe o resultado de cada busca e guarda a saúde das fontes entre execuções
+// (ver sourcehealth.Tracker). Score é a taxa de sucesso de longo prazo, usada como prior.
+type Health interface {
+	RecordSuccess(source string)
+	RecordFailure(source, reason string)
+	Score(source string) float64
 }
 
 // SmartRouter gerencia múltiplas fontes de streaming com circuit breaker
 type SmartRouter struct {
-	sources          []StreamSource
-	stats            map[string]*SourceStats
-	statsMutex       sync.RWMutex
-	circuitThreshold int           // Número de falhas para abrir o circuit
-	circuitResetTime time.Duration // Tempo para tentar resetar o circuit
-	defaultTimeout   time.Duration
+	sources             []StreamSource
+	stats               map[string]*SourceStats
+	statsMutex          sync.RWMutex  // Nunca chama health com ele travado (ver healthScores)
+	circuitThreshold    int           // Número de falhas seguidas para abrir o circuit
+	circuitResetTime    time.Duration // Tempo aberto na primeira abertura
+	circuitMaxResetTime time.Duration // Teto do backoff do tempo aberto
+	halfOpenProbes      int           // Sondagens simultâneas (e sucessos para fechar) no meio-aberto
+	onCircuitChange     func(CircuitEvent)
+	defaultTimeout      time.Duration
+	hedging             bool
+	hedgeDelayDefault   time.Duration
+	hedgeMaxFanOut      int
+	ewmaAlpha           float64
+	scoreHalfLife       time.Duration
+	priorityWeight      float64
+	health              Health
+	now                 func() time.Time
 }
 
 // Config para o SmartRouter
 type Config struct {
-	CircuitThreshold int           // Padrão: 3 falhas
-	CircuitResetTime time.Duration // Padrão: 30 segundos
-	DefaultTimeout   time.Duration // Padrão: 5 segundos
+	CircuitThreshold      int           // Padrão: 3 falhas seguidas
+	CircuitResetTime      time.Duration // Padrão: 30 segundos (dobra a cada reabertura seguida)
+	CircuitMaxResetTime   time.Duration // Padrão: 10 minutos
+	CircuitHalfOpenProbes int           // Padrão: 1 sondagem
+	DefaultTimeout        time.Duration // Padrão: 5 segundos
+	EWMAAlpha             float64       // Peso de cada nova medição nas médias (padrão: 0.3)
+	ScoreHalfLife         time.Duration // Meia-vida para as médias voltarem ao prior (padrão: 10 minutos)
+	PriorityWeight        float64       // Quanto a Priority estática pesa na pontuação (padrão: 0.05 por nível)
+
+	// Hedging: GetStreamParallel começa pela melhor fonte e só dispara a próxima
+	// se a resposta passar do p90 de latência da fonte. Sem ele, dispara todas de uma vez.
+	Hedging        bool
+	HedgeDelay     time.Duration // Espera antes da próxima fonte quando ainda não há p90 (padrão: 1 segundo)
+	HedgeMaxFanOut int           // Máximo de fontes buscando ao mesmo tempo (padrão: 2)
 }
 
 // DefaultConfig retorna configuração padrão
 func DefaultConfig() Config {
 	return Config{
-		CircuitThreshold: 3,
-		CircuitResetTime: 30 * time.Second,
-		DefaultTimeout:   5 * time.Second,
+		CircuitThreshold:      3,
+		CircuitResetTime:      30 * time.Second,
+		CircuitMaxResetTime:   10 * time.Minute,
+		CircuitHalfOpenProbes: 1,
+		DefaultTimeout:        5 * time.Second,
+		EWMAAlpha:             0.3,
+		ScoreHalfLife:         10 * time.Minute,
+		PriorityWeight:        0.05,
+		Hedging:               true,
+		HedgeDelay:            time.Second,
+		HedgeMaxFanOut:        2,
 	}
 }
 
@@ -73,16 +130,49 @@ func New(config Config) *SmartRouter {
 	if config.CircuitResetTime <= 0 {
 		config.CircuitResetTime = 30 * time.Second
 	}
+	if config.CircuitMaxResetTime < config.CircuitResetTime {
+		config.CircuitMaxResetTime = 10 * time.Minute
+		if config.CircuitMaxResetTime < config.CircuitResetTime {
+			config.CircuitMaxResetTime = config.CircuitResetTime
+		}
+	}
+	if config.CircuitHalfOpenProbes <= 0 {
+		config.CircuitHalfOpenProbes = 1
+	}
 	if config.DefaultTimeout <= 0 {
 		config.DefaultTimeout = 5 * time.Second
 	}
+	if config.EWMAAlpha <= 0 || config.EWMAAlpha > 1 {
+		config.EWMAAlpha = 0.3
+	}
+	if config.ScoreHalfLife <= 0 {
+		config.ScoreHalfLife = 10 * time.Minute
+	}
+	if config.PriorityWeight <= 0 {
+		config.PriorityWeight = 0.05
+	}
+	if config.HedgeDelay <= 0 {
+		config.HedgeDelay = time.Second
+	}
+	if config.HedgeMaxFanOut <= 0 {
+		config.HedgeMaxFanOut = 2
+	}
 
 	return &SmartRouter{
-		sources:          make([]StreamSource, 0),
-		stats:            make(map[string]*SourceStats),
-		circuitThreshold: config.CircuitThreshold,
-		circuitResetTime: config.CircuitResetTime,
-		defaultTimeout:   config.DefaultTimeout,
+		sources:             make([]StreamSource, 0),
+		stats:               make(map[string]*SourceStats),
+		circuitThreshold:    config.CircuitThreshold,
+		circuitResetTime:    config.CircuitResetTime,
+		circuitMaxResetTime: config.CircuitMaxResetTime,
+		halfOpenProbes:      config.CircuitHalfOpenProbes,
+		defaultTimeout:      config.DefaultTimeout,
+		hedging:             config.Hedging,
+		hedgeDelayDefault:   config.HedgeDelay,
+		hedgeMaxFanOut:      config.HedgeMaxFanOut,
+		ewmaAlpha:           config.EWMAAlpha,
+		scoreHalfLife:       config.ScoreHalfLife,
+		priorityWeight:      config.PriorityWeight,
+		now:                 time.Now,
 	}
 }
 
@@ -96,47 +186,29 @@ func (r *SmartRouter) AddSource(source StreamSource) {
 	}
 
 	r.sources = append(r.sources, source)
-	r.stats[source.Name] = &SourceStats{}
+	r.stats[source.Name] = &SourceStats{SuccessRate: 1, AvgLatency: r.priorLatency(), CircuitState: CircuitClosed}
 
-	// Ordena por prioridade
-	for i := 0; i < len(r.sources)-1; i++ {
-		for j := i + 1; j < len(r.sources); j++ {
-			if r.sources[i].Priority > r.sources[j].Priority {
-				r.sources[i], r.sources[j] = r.sources[j], r.sources[i]
-			}
-		}
-	}
+	// A prioridade desempata fontes com a mesma pontuação (ver rankedSources)
+	sort.SliceStable(r.sources, func(i, j int) bool {
+		return r.sources[i].Priority < r.sources[j].Priority
+	})
 }
 
-// isCircuitOpen verifica se o circuit breaker está aberto para uma fonte
-func (r *SmartRouter) isCircuitOpen(sourceName string) bool {
-	r.statsMutex.RLock()
-	defer r.statsMutex.RUnlock()
-
-	stats, ok := r.stats[sourceName]
-	if !ok {
-		return false
-	}
-
-	if !stats.IsCircuitOpen {
-		return false
-	}
-
-	// Verifica se é hora de tentar resetar
-	if time.Since(stats.CircuitOpenAt) > r.circuitResetTime {
-		return false // Permite uma tentativa
-	}
-
-	return true
+// SetHealth liga o router ao registro de saúde compartilhado: os resultados são
+// reportados a ele e as médias de cada fonte voltam para a pontuação dele, não para 1
+func (r *SmartRouter) SetHealth(health Health) {
+	r.statsMutex.Lock()
+	r.health = health
+	r.statsMutex.Unlock()
 }
 
 // recordSuccess registra um sucesso
 func (r *SmartRouter) recordSuccess(sourceName string, latency time.Duration) {
+	priors := r.healthScores(sourceName)
 	r.statsMutex.Lock()
-	defer r.statsMutex.Unlock()
-
 	stats, ok := r.stats[sourceName]
 	if !ok {
+		r.statsMutex.Unlock()
 		return
 	}
 
@@ -144,91 +216,86 @@ func (r *SmartRouter) recordSuccess(sourceName string, latency time.Duration) {
 	atomic.AddInt64(&stats.SuccessCount, 1)
 	atomic.AddInt64(&stats.TotalLatency, latency.Milliseconds())
 	stats.LastSuccess = time.Now()
-
-	// Fecha o circuit se estava aberto
-	if stats.IsCircuitOpen {
-		stats.IsCircuitOpen = false
-		fmt.Printf("[SmartRouter] Circuit fechado para %s após sucesso\n", sourceName)
+	r.observe(stats, priors.of(sourceName), true, latency)
+	addLatencySample(stats, latency)
+	events := r.circuitSuccess(sourceName, stats)
+	health := r.health
+	r.statsMutex.Unlock()
+
+	r.emitCircuitEvents(events)
+	if health != nil {
+		health.RecordSuccess(sourceName)
 	}
 }
 
-// recordFailure registra uma falha
-func (r *SmartRouter) recordFailure(sourceName string) {
+// recordFailure registra uma falha; latency é o tempo perdido com a fonte
+func (r *SmartRouter) recordFailure(sourceName string, latency time.Duration, err error) {
+	priors := r.healthScores(sourceName)
 	r.statsMutex.Lock()
-	defer r.statsMutex.Unlock()
-
 	stats, ok := r.stats[sourceName]
 	if !ok {
+		r.statsMutex.Unlock()
 		return
 	}
 
 	atomic.AddInt64(&stats.TotalRequests, 1)
 	atomic.AddInt64(&stats.FailureCount, 1)
 	stats.LastFailure = time.Now()
-
-	// Abre o circuit se atingiu o threshold
-	recentFailures := stats.FailureCount - stats.SuccessCount
-	if recentFailures >= int64(r.circuitThreshold) && !stats.IsCircuitOpen {
-		stats.IsCircuitOpen = true
-		stats.CircuitOpenAt = time.Now()
-		fmt.Printf("[SmartRouter] Circuit ABERTO para %s após %d falhas\n", sourceName, recentFailures)
+	r.observe(stats, priors.of(sourceName), false, latency)
+	events := r.circuitFailure(sourceName, stats)
+	health := r.health
+	r.statsMutex.Unlock()
+
+	r.emitCircuitEvents(events)
+	if health != nil {
+		health.RecordFailure(sourceName, err.Error())
 	}
 }
 
-// GetStream busca o stream usando a lógica de prioridade com fallback
+// GetStream busca o stream tentando as fontes da melhor para a pior pontuação, com fallback
 func (r *SmartRouter) GetStream(animeTitle string, episodeNumber int) *StreamResult {
+	return r.GetStreamContext(context.Background(), StreamRequest{AnimeTitle: animeTitle, EpisodeNumber: episodeNumber})
+}
+
+// GetStreamContext é o GetStream que para assim que ctx é cancelado
+func (r *SmartRouter) GetStreamContext(ctx context.Context, req StreamRequest) *StreamResult {
 	startTime := time.Now()
 
-	for _, source := range r.sources {
-		// Verifica circuit breaker
-		if r.isCircuitOpen(source.Name) {
-			fmt.Printf("[SmartRouter] Pulando %s (circuit aberto)\n", source.Name)
+	for _, source := range r.rankedSources() {
+		if ctx.Err() != nil {
+			break
+		}
+		// Verifica circuit breaker (no meio-aberto só passam as sondagens)
+		if !r.allow(source.Name) {
+			fmt.Printf("[SmartRouter] Pulando %s (circuit %s)\n", source.Name, r.circuitState(source.Name))
 			continue
 		}
 
 		fmt.Printf("[SmartRouter] Tentando %s (timeout: %v)\n", source.Name, source.Timeout)
+		candidates, duration, err := r.fetchSource(ctx, source, req)
+		if ctx.Err() != nil {
+			// Quem chamou desistiu; não é culpa da fonte
+			r.release(source.Name)
+			break
+		}
+		if err != nil {
+			r.recordFailure(source.Name, duration, err)
+			fmt.Printf("[SmartRouter] ✗ Falha em %s: %v\n", source.Name, err)
+			continue
+		}
 
-		// Cria context com timeout
-		ctx, cancel := context.WithTimeout(context.Background(), source.Timeout)
-
-		// Canal para receber resultado
-		resultChan := make(chan StreamResult, 1)
-
-		go func(src StreamSource) {
-			srcStart := time.Now()
-			url, err := src.Fetcher(ctx, animeTitle, episodeNumber)
-			duration := time.Since(srcStart)
-
-			resultChan <- StreamResult{
-				URL:      url,
-				Source:   src.Name,
-				Error:    err,
-				Duration: duration,
-			}
-		}(source)
-
-		// Espera resultado ou timeout
-		select {
-		case result := <-resultChan:
-			cancel()
-
-			if result.Error == nil && result.URL != "" {
-				r.recordSuccess(source.Name, result.Duration)
-				result.Duration = time.Since(startTime)
-				fmt.Printf("[SmartRouter] ✓ Sucesso com %s em %v\n", source.Name, result.Duration)
-				return &result
-			}
-
-			r.recordFailure(source.Name)
-			fmt.Printf("[SmartRouter] ✗ Falha em %s: %v\n", source.Name, result.Error)
+		r.recordSuccess(source.Name, duration)
+		result := newStreamResult(candidates, req, time.Since(startTime))
+		fmt.Printf("[SmartRouter] ✓ Sucesso com %s em %v\n", source.Name, result.Duration)
+		return result
+	}
 
-		case <-ctx.Done():
-			cancel()
-			r.recordFailure(source.Name)
-			fmt.Printf("[SmartRouter] ⏱ Timeout em %s após %v\n", source.Name, source.Timeout)
+	if err := ctx.Err(); err != nil {
+		return &StreamResult{
+			Error:    fmt.Errorf("busca cancelada: %w", err),
+			Duration: time.Since(startTime),
 		}
 	}
-
 	// Todas as fontes falharam
 	return &StreamResult{
 		Error:    errors.New("todas as fontes falharam"),
@@ -236,42 +303,45 @@ func (r *SmartRouter) GetStream(animeTitle string, episodeNumber int) *StreamRes
 	}
 }
 
-// GetStreamParallel busca em todas as fontes simultaneamente e retorna a primeira resposta
+// GetStreamParallel busca em várias fontes ao mesmo tempo e retorna a primeira resposta.
+// Com Config.Hedging as fontes entram aos poucos (ver getStreamHedged); sem ele, todas de uma vez.
 func (r *SmartRouter) GetStreamParallel(animeTitle string, episodeNumber int) *StreamResult {
+	return r.GetStreamParallelContext(context.Background(), StreamRequest{AnimeTitle: animeTitle, EpisodeNumber: episodeNumber})
+}
+
+// GetStreamParallelContext é o GetStreamParallel que para assim que ctx é cancelado
+func (r *SmartRouter) GetStreamParallelContext(ctx context.Context, req StreamRequest) *StreamResult {
+	if r.hedging {
+		return r.getStreamHedged(ctx, req)
+	}
 	startTime := time.Now()
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	resultChan := make(chan StreamResult, len(r.sources))
+	type sourceResult struct {
+		source     string
+		candidates []Candidate
+		duration   time.Duration
+		err        error
+	}
+	resultChan := make(chan sourceResult, len(r.sources))
 	activeSources := 0
 
-	for _, source := range r.sources {
-		if r.isCircuitOpen(source.Name) {
+	for _, source := range r.rankedSources() {
+		if !r.allow(source.Name) {
 			continue
 		}
 
 		activeSources++
 		go func(src StreamSource) {
-			srcCtx, srcCancel := context.WithTimeout(ctx, src.Timeout)
-			defer srcCancel()
-
-			srcStart := time.Now()
-			url, err := src.Fetcher(srcCtx, animeTitle, episodeNumber)
-			duration := time.Since(srcStart)
-
-			select {
-			case <-ctx.Done():
-				// Context cancelado, outra fonte já respondeu
+			candidates, duration, err := r.fetchSource(ctx, src, req)
+			if ctx.Err() != nil {
+				// Context cancelado: outra fonte já respondeu ou quem chamou desistiu
+				r.release(src.Name)
 				return
-			default:
-				resultChan <- StreamResult{
-					URL:      url,
-					Source:   src.Name,
-					Error:    err,
-					Duration: duration,
-				}
 			}
+			resultChan <- sourceResult{src.Name, candidates, duration, err}
 		}(source)
 	}
 
@@ -285,18 +355,26 @@ func (r *SmartRouter) GetStreamParallel(animeTitle string, episodeNumber int) *S
 	// Espera resultados
 	var lastError error
 	for i := 0; i < activeSources; i++ {
-		result := <-resultChan
+		var result sourceResult
+		select {
+		case result = <-resultChan:
+		case <-ctx.Done():
+			return &StreamResult{
+				Error:    fmt.Errorf("busca cancelada: %w", ctx.Err()),
+				Duration: time.Since(startTime),
+			}
+		}
 
-		if result.Error == nil && result.URL != "" {
+		if result.err == nil {
 			cancel() // Cancela outras requisições
-			r.recordSuccess(result.Source, result.Duration)
-			result.Duration = time.Since(startTime)
-			fmt.Printf("[SmartRouter] ✓ Primeiro sucesso: %s em %v\n", result.Source, result.Duration)
-			return &result
+			r.recordSuccess(result.source, result.duration)
+			stream := newStreamResult(result.candidates, req, time.Since(startTime))
+			fmt.Printf("[SmartRouter] ✓ Primeiro sucesso: %s em %v\n", stream.Source, stream.Duration)
+			return stream
 		}
 
-		r.recordFailure(result.Source)
-		lastError = result.Error
+		r.recordFailure(result.source, result.duration, result.err)
+		lastError = result.err
 	}
 
 	return &StreamResult{
@@ -305,22 +383,40 @@ func (r *SmartRouter) GetStreamParallel(animeTitle string, episodeNumber int) *S
 	}
 }
 
+// newStreamResult monta o resultado com o melhor candidato da fonte vencedora
+func newStreamResult(candidates []Candidate, req StreamRequest, duration time.Duration) *StreamResult {
+	rankCandidates(candidates, req, nil)
+	best := candidates[0]
+	return &StreamResult{
+		URL:        best.URL,
+		Source:     best.Source,
+		Referer:    best.Headers["Referer"],
+		Headers:    best.Headers,
+		Duration:   duration,
+		Candidates: candidates,
+	}
+}
+
+// circuitState retorna o estado atual do circuit de uma fonte
+func (r *SmartRouter) circuitState(sourceName string) CircuitState {
+	r.statsMutex.RLock()
+	defer r.statsMutex.RUnlock()
+	if stats, ok := r.stats[sourceName]; ok {
+		return stats.CircuitState
+	}
+	return CircuitClosed
+}
+
 // GetStats retorna estatísticas de uma fonte
 func (r *SmartRouter) GetStats(sourceName string) *SourceStats {
+	priors := r.healthScores(sourceName)
 	r.statsMutex.RLock()
 	defer r.statsMutex.RUnlock()
 
-	if stats, ok := r.stats[sourceName]; ok {
-		// Retorna uma cópia
-		return &SourceStats{
-			TotalRequests: atomic.LoadInt64(&stats.TotalRequests),
-			SuccessCount:  atomic.LoadInt64(&stats.SuccessCount),
-			FailureCount:  atomic.LoadInt64(&stats.FailureCount),
-			TotalLatency:  atomic.LoadInt64(&stats.TotalLatency),
-			LastSuccess:   stats.LastSuccess,
-			LastFailure:   stats.LastFailure,
-			IsCircuitOpen: stats.IsCircuitOpen,
-			CircuitOpenAt: stats.CircuitOpenAt,
+	now := r.now()
+	for _, source := range r.sources {
+		if source.Name == sourceName {
+			return r.snapshot(source, r.stats[source.Name], priors.of(source.Name), now)
 		}
 	}
 	return nil
@@ -328,45 +424,67 @@ func (r *SmartRouter) GetStats(sourceName string) *SourceStats {
 
 // GetAllStats retorna estatísticas de todas as fontes
 func (r *SmartRouter) GetAllStats() map[string]*SourceStats {
+	priors := r.healthScores()
 	r.statsMutex.RLock()
 	defer r.statsMutex.RUnlock()
 
+	now := r.now()
 	result := make(map[string]*SourceStats)
-	for name, stats := range r.stats {
-		result[name] = &SourceStats{
-			TotalRequests: atomic.LoadInt64(&stats.TotalRequests),
-			SuccessCount:  atomic.LoadInt64(&stats.SuccessCount),
-			FailureCount:  atomic.LoadInt64(&stats.FailureCount),
-			TotalLatency:  atomic.LoadInt64(&stats.TotalLatency),
-			LastSuccess:   stats.LastSuccess,
-			LastFailure:   stats.LastFailure,
-			IsCircuitOpen: stats.IsCircuitOpen,
-			CircuitOpenAt: stats.CircuitOpenAt,
-		}
+	for _, source := range r.sources {
+		result[source.Name] = r.snapshot(source, r.stats[source.Name], priors.of(source.Name), now)
 	}
 	return result
 }
 
+// snapshot retorna uma cópia das estatísticas com as médias atualizadas para now
+func (r *SmartRouter) snapshot(source StreamSource, stats *SourceStats, prior float64, now time.Time) *SourceStats {
+	success, latency := r.decayed(stats, prior, now)
+	return &SourceStats{
+		TotalRequests: atomic.LoadInt64(&stats.TotalRequests),
+		SuccessCount:  atomic.LoadInt64(&stats.SuccessCount),
+		FailureCount:  atomic.LoadInt64(&stats.FailureCount),
+		TotalLatency:  atomic.LoadInt64(&stats.TotalLatency),
+		LastSuccess:   stats.LastSuccess,
+		LastFailure:   stats.LastFailure,
+		IsCircuitOpen: stats.IsCircuitOpen,
+		CircuitOpenAt: stats.CircuitOpenAt,
+		SuccessRate:   success,
+		AvgLatency:    latency,
+		Score:         r.score(source, stats, prior, now),
+		updatedAt:     stats.updatedAt,
+
+		CircuitState:        stats.CircuitState,
+		OpenInterval:        stats.OpenInterval,
+		ConsecutiveFailures: stats.ConsecutiveFailures,
+		CircuitEvents:       append([]CircuitEvent(nil), stats.CircuitEvents...),
+		P90Latency:          p90(stats.latencies),
+	}
+}
+
 // ResetCircuit reseta o circuit breaker de uma fonte
 func (r *SmartRouter) ResetCircuit(sourceName string) {
 	r.statsMutex.Lock()
-	defer r.statsMutex.Unlock()
-
+	var events []CircuitEvent
 	if stats, ok := r.stats[sourceName]; ok {
-		stats.IsCircuitOpen = false
-		stats.FailureCount = 0
+		events = r.closeCircuit(sourceName, stats, "reset manual")
+		stats.updatedAt = time.Time{}
 		fmt.Printf("[SmartRouter] Circuit resetado manualmente para %s\n", sourceName)
 	}
+	r.statsMutex.Unlock()
+
+	r.emitCircuitEvents(events)
 }
 
 // ResetAllCircuits reseta todos os circuit breakers
 func (r *SmartRouter) ResetAllCircuits() {
 	r.statsMutex.Lock()
-	defer r.statsMutex.Unlock()
-
+	var events []CircuitEvent
 	for name, stats := range r.stats {
-		stats.IsCircuitOpen = false
-		stats.FailureCount = 0
+		events = append(events, r.closeCircuit(name, stats, "reset manual")...)
+		stats.updatedAt = time.Time{}
 		fmt.Printf("[SmartRouter] Circuit resetado para %s\n", name)
 	}
+	r.statsMutex.Unlock()
+
+	r.emitCircuitEvents(events)
 }
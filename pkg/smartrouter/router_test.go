@@ -0,0 +1,295 @@
+package smartrouter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestRouter cria um router com relógio controlado pelo teste
+func newTestRouter(sources ...StreamSource) (*SmartRouter, *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := New(DefaultConfig())
+	r.now = func() time.Time { return now }
+	for _, source := range sources {
+		r.AddSource(source)
+	}
+	return r, &now
+}
+
+func fetcher(url string, err error) func(context.Context, string, int) (string, error) {
+	return func(context.Context, string, int) (string, error) { return url, err }
+}
+
+func TestRanking_PriorityIsPrior(t *testing.T) {
+	r, _ := newTestRouter(
+		StreamSource{Name: "b", Priority: 2, Fetcher: fetcher("u", nil)},
+		StreamSource{Name: "a", Priority: 1, Fetcher: fetcher("u", nil)},
+	)
+	if got := r.Ranking(); got[0] != "a" || got[1] != "b" {
+		t.Errorf("Ranking() = %v; want [a b] before any request", got)
+	}
+}
+
+func TestRanking_DemotesFlakyAndSlowSources(t *testing.T) {
+	r, now := newTestRouter(
+		StreamSource{Name: "a", Priority: 1, Fetcher: fetcher("", errors.New("fora do ar"))},
+		StreamSource{Name: "b", Priority: 2, Fetcher: fetcher("https://b/ep.m3u8", nil)},
+	)
+
+	result := r.GetStream("Frieren", 1)
+	if result.Error != nil || result.Source != "b" {
+		t.Fatalf("GetStream() = %+v; want fallback to b", result)
+	}
+	if got := r.Ranking(); got[0] != "b" {
+		t.Errorf("Ranking() = %v; want b first after a failed", got)
+	}
+
+	// Sem novas medições, a fonte rebaixada volta a subir com o tempo
+	*now = now.Add(time.Hour)
+	if got := r.Ranking(); got[0] != "a" {
+		t.Errorf("Ranking() an hour later = %v; want a back on top", got)
+	}
+
+	// Latência alta também rebaixa, mesmo sem falhas
+	r.statsMutex.Lock()
+	for i := 0; i < 5; i++ {
+		r.observe(r.stats["a"], 1, true, 5*time.Second)
+		r.observe(r.stats["b"], 1, true, 200*time.Millisecond)
+	}
+	r.statsMutex.Unlock()
+	if got := r.Ranking(); got[0] != "b" {
+		t.Errorf("Ranking() = %v; want the faster source first", got)
+	}
+
+	stats := r.GetStats("a")
+	if stats.Score >= r.GetStats("b").Score || stats.AvgLatency < 3000 {
+		t.Errorf("stats a = %+v; want lower score and latency near 5s", stats)
+	}
+}
+
+func TestCircuit_HalfOpenProbesAndBackoff(t *testing.T) {
+	var err error = errors.New("fora do ar")
+	var calls int
+	r, now := newTestRouter(StreamSource{Name: "a", Priority: 1,
+		Fetcher: func(context.Context, string, int) (string, error) {
+			calls++
+			if err != nil {
+				return "", err
+			}
+			return "https://a/ep.m3u8", nil
+		}})
+	var events []CircuitEvent
+	r.OnCircuitChange(func(e CircuitEvent) { events = append(events, e) })
+
+	for i := 0; i < 3; i++ {
+		r.GetStream("Frieren", 1)
+	}
+	if stats := r.GetStats("a"); stats.CircuitState != CircuitOpen || stats.OpenInterval != 30*time.Second {
+		t.Fatalf("stats = %+v; want open for 30s after 3 failures", stats)
+	}
+	r.GetStream("Frieren", 1)
+	if calls != 3 {
+		t.Errorf("calls = %d; open circuit should block requests", calls)
+	}
+
+	// Intervalo passou: uma única sondagem por vez, e a falha reabre pelo dobro do tempo
+	*now = now.Add(31 * time.Second)
+	if !r.allow("a") || r.allow("a") {
+		t.Error("half-open should allow exactly one probe at a time")
+	}
+	r.release("a")
+	r.GetStream("Frieren", 1)
+	if stats := r.GetStats("a"); stats.CircuitState != CircuitOpen || stats.OpenInterval != time.Minute {
+		t.Errorf("stats = %+v; want reopened for 1m after failed probe", stats)
+	}
+
+	// Fonte recuperada: a sondagem fecha o circuit e zera o backoff
+	err = nil
+	*now = now.Add(61 * time.Second)
+	if result := r.GetStream("Frieren", 1); result.Error != nil {
+		t.Fatalf("GetStream() error = %v", result.Error)
+	}
+	stats := r.GetStats("a")
+	if stats.CircuitState != CircuitClosed || stats.OpenInterval != 0 || stats.IsCircuitOpen {
+		t.Errorf("stats = %+v; want closed after successful probe", stats)
+	}
+
+	var path []CircuitState
+	for _, e := range events {
+		path = append(path, e.To)
+	}
+	want := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(path) != len(want) || len(stats.CircuitEvents) != len(want) {
+		t.Fatalf("events = %v (%d stored); want %v", path, len(stats.CircuitEvents), want)
+	}
+	for i := range want {
+		if path[i] != want[i] {
+			t.Errorf("events = %v; want %v", path, want)
+			break
+		}
+	}
+}
+
+func TestGetStreamParallel_Hedging(t *testing.T) {
+	var mutex sync.Mutex
+	started := map[string]int{}
+	cancelled := make(chan struct{}, 1)
+	slow := true
+	fetch := func(name string, delay time.Duration) func(context.Context, string, int) (string, error) {
+		return func(ctx context.Context, _ string, _ int) (string, error) {
+			mutex.Lock()
+			started[name]++
+			wait := slow
+			mutex.Unlock()
+			if !wait {
+				delay = 0
+			}
+			select {
+			case <-time.After(delay):
+				return "https://" + name + "/ep.m3u8", nil
+			case <-ctx.Done():
+				cancelled <- struct{}{}
+				return "", ctx.Err()
+			}
+		}
+	}
+	r, _ := newTestRouter(
+		StreamSource{Name: "a", Priority: 1, Timeout: 5 * time.Second, Fetcher: fetch("a", 2*time.Second)},
+		StreamSource{Name: "b", Priority: 2, Timeout: 5 * time.Second, Fetcher: fetch("b", 10*time.Millisecond)},
+		StreamSource{Name: "c", Priority: 3, Timeout: 5 * time.Second, Fetcher: fetch("c", 0)},
+	)
+	// a costuma responder em 50ms: passou disso, o hedge dispara b
+	r.statsMutex.Lock()
+	for i := 0; i < minHedgeSamples; i++ {
+		addLatencySample(r.stats["a"], 50*time.Millisecond)
+	}
+	r.statsMutex.Unlock()
+
+	result := r.GetStreamParallel("Frieren", 1)
+	if result.Error != nil || result.Source != "b" {
+		t.Fatalf("GetStreamParallel() = %+v; want hedged answer from b", result)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("slow source should be cancelled after the hedge wins")
+	}
+	mutex.Lock()
+	if started["c"] != 0 {
+		t.Errorf("started = %v; c should not run while b answers in time", started)
+	}
+	slow = false
+	mutex.Unlock()
+
+	// Fonte rápida: nenhuma requisição extra
+	r.ResetAllCircuits()
+	if result := r.GetStreamParallel("Frieren", 1); result.Source != "a" {
+		t.Errorf("GetStreamParallel() = %+v; want a", result)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if started["b"] != 1 || started["c"] != 0 {
+		t.Errorf("started = %v; fast answer should not hedge", started)
+	}
+}
+
+func TestGetStreamCandidates_RanksAndHonorsCancel(t *testing.T) {
+	r, _ := newTestRouter(
+		StreamSource{Name: "a", Priority: 1, Fetcher: fetcher("https://a/ep.mp4?t=1", nil)},
+		StreamSource{Name: "b", Priority: 2, Resolve: func(context.Context, StreamRequest) ([]Candidate, error) {
+			return []Candidate{
+				{URL: "https://b/480.m3u8", Quality: "480p", Language: LanguageDub},
+				{URL: "https://b/1080.m3u8", Quality: "1080p", Language: LanguageDub},
+				{URL: "https://b/sub.m3u8", Quality: "1080p", Language: LanguageSub},
+				{URL: "https://b/manifest?id=1", Quality: "auto", Language: LanguageSub, Format: FormatDASH},
+			}, nil
+		}},
+	)
+
+	candidates, err := r.GetStreamCandidates(context.Background(), StreamRequest{AnimeTitle: "Frieren", EpisodeNumber: 1, Language: LanguageDub, Quality: "720p"})
+	if err != nil {
+		t.Fatalf("GetStreamCandidates() error = %v", err)
+	}
+	var got []string
+	for _, c := range candidates {
+		got = append(got, c.URL)
+	}
+	// Dublado primeiro (qualidade mais próxima abaixo de 720p, depois acima), idioma desconhecido, legendado
+	want := []string{"https://b/480.m3u8", "https://b/1080.m3u8", "https://a/ep.mp4?t=1", "https://b/manifest?id=1", "https://b/sub.m3u8"}
+	if len(got) != len(want) {
+		t.Fatalf("candidates = %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("candidates = %v; want %v", got, want)
+		}
+	}
+	if candidates[2].Format != FormatMP4 || candidates[2].Source != "a" || candidates[2].Quality != "auto" {
+		t.Errorf("fetcher candidate = %+v; want mp4 from a with auto quality", candidates[2])
+	}
+	if candidates[3].Format != FormatDASH {
+		t.Errorf("declared format = %q; want it kept for a URL without .mpd", candidates[3].Format)
+	}
+
+	// Cancelamento de quem chama para a busca sem contar falha para a fonte
+	block := StreamSource{Name: "lenta", Priority: 0, Timeout: 5 * time.Second,
+		Fetcher: func(ctx context.Context, _ string, _ int) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}}
+	slow, _ := newTestRouter(block)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	result := slow.GetStreamContext(ctx, StreamRequest{AnimeTitle: "Frieren", EpisodeNumber: 1})
+	if result.Error == nil || !errors.Is(result.Error, context.Canceled) || time.Since(start) > time.Second {
+		t.Errorf("GetStreamContext() = %+v after %v; want prompt cancellation", result, time.Since(start))
+	}
+	if stats := slow.GetStats("lenta"); stats.FailureCount != 0 {
+		t.Errorf("FailureCount = %d; caller cancellation should not count against the source", stats.FailureCount)
+	}
+}
+
+// fakeHealth guarda o que o router reportou e devolve pontuações fixas
+type fakeHealth struct {
+	scores   map[string]float64
+	failures []string
+}
+
+func (h *fakeHealth) RecordSuccess(string) {}
+
+func (h *fakeHealth) RecordFailure(source, reason string) {
+	h.failures = append(h.failures, source+": "+reason)
+}
+
+func (h *fakeHealth) Score(source string) float64 {
+	if score, ok := h.scores[source]; ok {
+		return score
+	}
+	return 1
+}
+
+func TestHealth_PersistedScoreIsPriorAndFailuresAreReported(t *testing.T) {
+	r, _ := newTestRouter(
+		StreamSource{Name: "a", Priority: 1, Fetcher: fetcher("", errors.New("fora do ar"))},
+		StreamSource{Name: "b", Priority: 2, Fetcher: fetcher("https://b/ep.m3u8", nil)},
+	)
+	health := &fakeHealth{scores: map[string]float64{"a": 0.2}}
+	r.SetHealth(health)
+
+	// a estava falhando na execução anterior: começa atrás de b sem nenhuma requisição
+	if got := r.Ranking(); got[0] != "b" {
+		t.Errorf("Ranking() = %v; want b first with a's low persisted score", got)
+	}
+
+	health.scores["a"] = 1
+	if result := r.GetStream("Frieren", 1); result.Source != "b" {
+		t.Fatalf("GetStream() = %+v; want fallback to b", result)
+	}
+	if len(health.failures) != 1 || health.failures[0] != "a: fora do ar" {
+		t.Errorf("failures = %v; want a's error reported", health.failures)
+	}
+}
@@ -45,7 +45,8 @@ type FriendSystem struct {
 	configPath    string
 	httpClient    *http.Client
 	heartbeatStop chan struct{}
-	isOnline      bool // Status de conexão com servidor
+	isOnline      bool   // Status de conexão com servidor
+	outbox        Outbox // Fila persistente para status/estatísticas (opcional)
 }
 
 // UserProfile perfil do usuário local
@@ -746,23 +747,16 @@ func (fs *FriendSystem) UpdateWatchingStatus(status *WatchingStatus) error {
 		return nil
 	}
 
-	// Envia para a API em background
-	go func() {
-		url := fs.apiBaseURL + "/status/update"
-
-		payload := map[string]interface{}{
-			"user_id":        profile.UserID,
-			"anime_title":    status.AnimeTitle,
-			"anime_image":    status.AnimeImage,
-			"episode_num":    status.EpisodeNum,
-			"total_episodes": status.TotalEpisodes,
-			"timestamp":      time.Now().Unix(),
-		}
-
-		fs.makeAuthenticatedRequest("POST", url, payload)
-	}()
-
-	return nil
+	// Só o status mais recente importa: um item por usuário na fila
+	payload := map[string]interface{}{
+		"user_id":        profile.UserID,
+		"anime_title":    status.AnimeTitle,
+		"anime_image":    status.AnimeImage,
+		"episode_num":    status.EpisodeNum,
+		"total_episodes": status.TotalEpisodes,
+		"timestamp":      time.Now().Unix(),
+	}
+	return fs.send(OutboxKindStatus, profile.UserID, "/status/update", payload)
 }
 
 // ClearWatchingStatus limpa o status de visualização
@@ -775,13 +769,13 @@ func (fs *FriendSystem) ClearWatchingStatus() {
 		return
 	}
 
-	go func() {
-		url := fs.apiBaseURL + "/status/clear"
-		payload := map[string]interface{}{
-			"user_id": profile.UserID,
-		}
-		fs.makeAuthenticatedRequest("POST", url, payload)
-	}()
+	// Substitui qualquer atualização de status ainda não enviada
+	payload := map[string]interface{}{
+		"user_id": profile.UserID,
+	}
+	if err := fs.send(OutboxKindStatus, profile.UserID, "/status/clear", payload); err != nil {
+		fmt.Printf("[Social] Erro ao limpar status: %v\n", err)
+	}
 }
 
 // ============================================
@@ -1144,22 +1138,14 @@ func (fs *FriendSystem) ReportWatching(animeID, title, image string, episode int
 	}
 	fs.mutex.RUnlock()
 
-	url := fs.apiBaseURL + "/stats/watching"
-
+	// Um item por anime: só o episódio mais recente é enviado
 	payload := map[string]interface{}{
 		"anime_id": animeID,
 		"title":    title,
 		"image":    image,
 		"episode":  episode,
 	}
-
-	resp, err := fs.makeAuthenticatedRequest("POST", url, payload)
-	if err != nil {
-		return nil // Silent fail
-	}
-	defer resp.Body.Close()
-
-	return nil
+	return fs.send(OutboxKindReport, animeID, "/stats/watching", payload)
 }
 
 // getLocalRecommendations retorna recomendações locais padrão quando offline
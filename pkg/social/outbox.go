@@ -0,0 +1,83 @@
+package social
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ============================================
+// FILA DE SAÍDA
+// ============================================
+
+// Tipos de item enviados pela fila de saída
+const (
+	OutboxKindStatus = "social_status" // Último status de visualização (um por usuário)
+	OutboxKindReport = "social_report" // Estatística de visualização (um por anime)
+)
+
+// ErrRejected indica que o servidor recusou a requisição (tentar de novo não adianta)
+var ErrRejected = errors.New("servidor social recusou a requisição")
+
+// Outbox guarda mutações para envio posterior, sobrevivendo a quedas do servidor.
+// Itens com o mesmo (kind, key) são substituídos pelo mais recente.
+type Outbox interface {
+	Enqueue(kind, key string, payload interface{}) error
+}
+
+// outboxItem é o payload gravado na fila
+type outboxItem struct {
+	Path    string                 `json:"path"`
+	Payload map[string]interface{} `json:"payload"`
+}
+
+// SetOutbox faz as atualizações de status e estatísticas passarem pela fila
+func (fs *FriendSystem) SetOutbox(outbox Outbox) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	fs.outbox = outbox
+}
+
+// send enfileira a requisição, ou envia em background se não houver fila
+func (fs *FriendSystem) send(kind, key, path string, payload map[string]interface{}) error {
+	fs.mutex.RLock()
+	outbox := fs.outbox
+	fs.mutex.RUnlock()
+
+	item := outboxItem{Path: path, Payload: payload}
+	if outbox != nil {
+		return outbox.Enqueue(kind, key, item)
+	}
+
+	go fs.post(item)
+	return nil
+}
+
+// SendQueued envia um item gravado pela fila de saída
+func (fs *FriendSystem) SendQueued(data []byte) error {
+	var item outboxItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return fmt.Errorf("%w: payload inválido: %v", ErrRejected, err)
+	}
+	return fs.post(item)
+}
+
+// post executa a requisição e classifica a resposta
+func (fs *FriendSystem) post(item outboxItem) error {
+	resp, err := fs.makeAuthenticatedRequest("POST", fs.apiBaseURL+item.Path, item.Payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("servidor social retornou status %d", resp.StatusCode)
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%w: status %d: %s", ErrRejected, resp.StatusCode, string(body))
+	}
+	return nil
+}
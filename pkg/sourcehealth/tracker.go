@@ -0,0 +1,384 @@
+// Package sourcehealth concentra a saúde das fontes de streaming: cooldown após falhas seguidas,
+// uma pontuação que volta ao normal com o tempo e o histórico por hora. Tudo é salvo no banco,
+// então uma fonte que estava fora do ar na última execução não volta como se fosse nova.
+package sourcehealth
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"GoAnimeGUI/pkg/store"
+)
+
+const (
+	HistoryHours = 48             // Horas de histórico guardadas por fonte
+	halfLife     = 12 * time.Hour // Meia-vida para a pontuação voltar a 1 sem novas medições
+	alpha        = 0.2            // Peso de cada resultado na pontuação
+)
+
+// cooldowns é o backoff após falhas seguidas: 30s, 1min, 2min, 5min, 10min
+var cooldowns = []time.Duration{
+	30 * time.Second,
+	1 * time.Minute,
+	2 * time.Minute,
+	5 * time.Minute,
+	10 * time.Minute,
+}
+
+// Status é o estado atual de uma fonte
+type Status struct {
+	Source        string    `json:"source"`
+	Score         float64   `json:"score"`     // Média móvel de sucesso com decaimento (1 = saudável)
+	FailCount     int       `json:"failCount"` // Falhas seguidas desde o último sucesso
+	InCooldown    bool      `json:"inCooldown"`
+	CooldownUntil time.Time `json:"cooldownUntil"`
+	LastError     string    `json:"lastError"`
+	LastFailureAt time.Time `json:"lastFailureAt"`
+	LastSuccessAt time.Time `json:"lastSuccessAt"`
+}
+
+// HourStats são os resultados de uma fonte em uma hora
+type HourStats struct {
+	Hour        time.Time `json:"hour"` // Início da hora (UTC)
+	Successes   int       `json:"successes"`
+	Failures    int       `json:"failures"`
+	SuccessRate float64   `json:"successRate"` // 0 a 1
+}
+
+// History é o estado de uma fonte com os resultados por hora, para a página de diagnóstico
+type History struct {
+	Status      Status      `json:"status"`
+	SuccessRate float64     `json:"successRate"` // No período todo (0 a 1)
+	Hours       []HourStats `json:"hours"`       // Só as horas com requisições, da mais antiga à mais recente
+}
+
+// Tracker registra os resultados das fontes e decide quais estão em cooldown
+type Tracker struct {
+	db      *store.DB // nil = só em memória
+	sources map[string]*source
+	mutex   sync.Mutex
+	now     func() time.Time
+}
+
+type source struct {
+	score         float64
+	scoreAt       time.Time
+	failCount     int
+	cooldownUntil time.Time
+	lastError     string
+	lastFailureAt time.Time
+	lastSuccessAt time.Time
+	hours         map[time.Time]*HourStats
+}
+
+var (
+	defaultTracker *Tracker
+	defaultOnce    sync.Once
+)
+
+// Default retorna o rastreador compartilhado pelo app, salvo no banco padrão.
+// Sem banco, funciona só em memória.
+func Default() *Tracker {
+	defaultOnce.Do(func() {
+		db, err := store.Default()
+		if err != nil {
+			fmt.Printf("[SourceHealth] Banco indisponível, saúde das fontes só em memória: %v\n", err)
+			db = nil
+		}
+		defaultTracker = New(db)
+	})
+	return defaultTracker
+}
+
+// New cria o rastreador e carrega o estado salvo (db nil = só em memória)
+func New(db *store.DB) *Tracker {
+	return newTracker(db, time.Now)
+}
+
+func newTracker(db *store.DB, now func() time.Time) *Tracker {
+	t := &Tracker{
+		db:      db,
+		sources: make(map[string]*source),
+		now:     now,
+	}
+	if db != nil {
+		if err := t.load(); err != nil {
+			fmt.Printf("[SourceHealth] Erro ao carregar histórico: %v\n", err)
+		}
+	}
+	return t
+}
+
+// load lê o estado e as últimas HistoryHours horas do banco e apaga as contagens mais antigas
+func (t *Tracker) load() error {
+	states, err := t.db.SourceHealthStates()
+	if err != nil {
+		return err
+	}
+	for _, h := range states {
+		s := t.source(h.Source)
+		s.score = h.Score
+		s.scoreAt = parseTime(h.ScoreAt)
+		s.failCount = h.FailCount
+		s.cooldownUntil = parseTime(h.CooldownUntil)
+		s.lastError = h.LastError
+		s.lastFailureAt = parseTime(h.LastFailureAt)
+		s.lastSuccessAt = parseTime(h.LastSuccessAt)
+	}
+
+	since := formatTime(historyStart(t.now()))
+	if err := t.db.PruneSourceHealthHours(since); err != nil {
+		return err
+	}
+	hours, err := t.db.SourceHealthHours(since)
+	if err != nil {
+		return err
+	}
+	for _, h := range hours {
+		hour := parseTime(h.Hour)
+		t.source(h.Source).hours[hour] = &HourStats{Hour: hour, Successes: h.Successes, Failures: h.Failures}
+	}
+	return nil
+}
+
+// RecordSuccess registra uma resposta válida da fonte e encerra o cooldown
+func (t *Tracker) RecordSuccess(name string) {
+	t.record(name, true, "")
+}
+
+// RecordFailure registra uma falha da fonte; falhas seguidas aumentam o cooldown
+func (t *Tracker) RecordFailure(name, reason string) {
+	t.record(name, false, reason)
+}
+
+func (t *Tracker) record(name string, success bool, reason string) {
+	if name == "" {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+	s := t.source(name)
+	score := s.decayedScore(now)
+	sample := 0.0
+	if success {
+		sample = 1
+	}
+	s.score = score + alpha*(sample-score)
+	s.scoreAt = now
+
+	if success {
+		if s.failCount > 0 {
+			fmt.Printf("[SourceHealth] Fonte %s recuperada após %d falhas\n", name, s.failCount)
+		}
+		s.failCount = 0
+		s.cooldownUntil = time.Time{}
+		s.lastSuccessAt = now
+	} else {
+		s.failCount++
+		cooldown := cooldowns[min(s.failCount, len(cooldowns))-1]
+		s.cooldownUntil = now.Add(cooldown)
+		s.lastError = reason
+		s.lastFailureAt = now
+		fmt.Printf("[SourceHealth] Falha %d para %s: %s (retry após %v)\n", s.failCount, name, reason, cooldown)
+	}
+
+	hour := now.UTC().Truncate(time.Hour)
+	counts, ok := s.hours[hour]
+	if !ok {
+		counts = &HourStats{Hour: hour}
+		s.hours[hour] = counts
+		for h := range s.hours {
+			if h.Before(historyStart(now)) {
+				delete(s.hours, h)
+			}
+		}
+	}
+	if success {
+		counts.Successes++
+	} else {
+		counts.Failures++
+	}
+
+	if t.db != nil {
+		if err := t.db.RecordSourceHealth(s.row(name), formatTime(hour), success); err != nil {
+			fmt.Printf("[SourceHealth] Erro ao salvar %s: %v\n", name, err)
+		}
+	}
+}
+
+// IsAvailable diz se a fonte está fora de cooldown
+func (t *Tracker) IsAvailable(name string) bool {
+	return t.CooldownRemaining(name) == 0
+}
+
+// CooldownRemaining retorna quanto falta para a fonte sair do cooldown
+func (t *Tracker) CooldownRemaining(name string) time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	s, ok := t.sources[name]
+	if !ok {
+		return 0
+	}
+	return max(s.cooldownUntil.Sub(t.now()), 0)
+}
+
+// Score retorna a pontuação atual da fonte (1 = saudável ou sem histórico, 0 = só falhas).
+// Sem novas medições, volta a 1 com meia-vida de 12 horas.
+func (t *Tracker) Score(name string) float64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	s, ok := t.sources[name]
+	if !ok {
+		return 1
+	}
+	return s.decayedScore(t.now())
+}
+
+// Status retorna o estado atual de uma fonte
+func (t *Tracker) Status(name string) Status {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	s, ok := t.sources[name]
+	if !ok {
+		return Status{Source: name, Score: 1}
+	}
+	return s.status(name, t.now())
+}
+
+// History retorna o estado e os resultados por hora das últimas horas (até HistoryHours) de cada fonte
+func (t *Tracker) History(hours int) []History {
+	if hours <= 0 || hours > HistoryHours {
+		hours = HistoryHours
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+	since := now.UTC().Truncate(time.Hour).Add(-time.Duration(hours-1) * time.Hour)
+	history := make([]History, 0, len(t.sources))
+	for name, s := range t.sources {
+		h := History{Status: s.status(name, now), Hours: []HourStats{}}
+		var successes, total int
+		for hour, counts := range s.hours {
+			if hour.Before(since) {
+				continue
+			}
+			stats := *counts
+			if n := stats.Successes + stats.Failures; n > 0 {
+				stats.SuccessRate = float64(stats.Successes) / float64(n)
+			}
+			successes += stats.Successes
+			total += stats.Successes + stats.Failures
+			h.Hours = append(h.Hours, stats)
+		}
+		sort.Slice(h.Hours, func(i, j int) bool { return h.Hours[i].Hour.Before(h.Hours[j].Hour) })
+		if total > 0 {
+			h.SuccessRate = float64(successes) / float64(total)
+		}
+		history = append(history, h)
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].Status.Source < history[j].Status.Source })
+	return history
+}
+
+// ResetSource zera falhas, cooldown e pontuação de uma fonte (o histórico por hora é mantido)
+func (t *Tracker) ResetSource(name string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if s, ok := t.sources[name]; ok {
+		t.reset(name, s)
+	}
+}
+
+// Reset zera falhas, cooldown e pontuação de todas as fontes
+func (t *Tracker) Reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for name, s := range t.sources {
+		t.reset(name, s)
+	}
+	fmt.Println("[SourceHealth] Todas as falhas foram resetadas")
+}
+
+// reset deve ser chamado com o mutex travado
+func (t *Tracker) reset(name string, s *source) {
+	s.score = 1
+	s.scoreAt = time.Time{}
+	s.failCount = 0
+	s.cooldownUntil = time.Time{}
+	if t.db != nil {
+		if err := t.db.SaveSourceHealth(s.row(name)); err != nil {
+			fmt.Printf("[SourceHealth] Erro ao salvar %s: %v\n", name, err)
+		}
+	}
+}
+
+// source retorna (criando se preciso) o estado de uma fonte. Deve ser chamado com o mutex travado.
+func (t *Tracker) source(name string) *source {
+	s, ok := t.sources[name]
+	if !ok {
+		s = &source{score: 1, hours: make(map[time.Time]*HourStats)}
+		t.sources[name] = s
+	}
+	return s
+}
+
+// decayedScore aproxima a pontuação de 1 conforme o tempo sem medições
+func (s *source) decayedScore(now time.Time) float64 {
+	if s.scoreAt.IsZero() {
+		return 1
+	}
+	weight := math.Exp2(-float64(now.Sub(s.scoreAt)) / float64(halfLife))
+	return 1 + (s.score-1)*min(weight, 1)
+}
+
+func (s *source) status(name string, now time.Time) Status {
+	return Status{
+		Source:        name,
+		Score:         s.decayedScore(now),
+		FailCount:     s.failCount,
+		InCooldown:    now.Before(s.cooldownUntil),
+		CooldownUntil: s.cooldownUntil,
+		LastError:     s.lastError,
+		LastFailureAt: s.lastFailureAt,
+		LastSuccessAt: s.lastSuccessAt,
+	}
+}
+
+func (s *source) row(name string) store.SourceHealth {
+	return store.SourceHealth{
+		Source:        name,
+		Score:         s.score,
+		ScoreAt:       formatTime(s.scoreAt),
+		FailCount:     s.failCount,
+		CooldownUntil: formatTime(s.cooldownUntil),
+		LastError:     s.lastError,
+		LastFailureAt: formatTime(s.lastFailureAt),
+		LastSuccessAt: formatTime(s.lastSuccessAt),
+	}
+}
+
+// historyStart é a hora mais antiga mantida no histórico
+func historyStart(now time.Time) time.Time {
+	return now.UTC().Truncate(time.Hour).Add(-(HistoryHours - 1) * time.Hour)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
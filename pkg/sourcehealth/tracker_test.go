@@ -0,0 +1,98 @@
+package sourcehealth
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"GoAnimeGUI/pkg/store"
+)
+
+func newTestTracker(t *testing.T, path string, now *time.Time) *Tracker {
+	t.Helper()
+	db, err := store.Open(path)
+	if err != nil {
+		t.Fatalf("store.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return newTracker(db, func() time.Time { return *now })
+}
+
+func TestTracker_CooldownBackoffAndRecovery(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(t, filepath.Join(t.TempDir(), "test.db"), &now)
+
+	tracker.RecordFailure("AllAnime", "timeout")
+	if tracker.IsAvailable("AllAnime") || tracker.CooldownRemaining("AllAnime") != 30*time.Second {
+		t.Fatalf("CooldownRemaining() = %v; want 30s after the first failure", tracker.CooldownRemaining("AllAnime"))
+	}
+	tracker.RecordFailure("AllAnime", "timeout")
+	if got := tracker.CooldownRemaining("AllAnime"); got != time.Minute {
+		t.Errorf("CooldownRemaining() = %v; want 1m after the second failure", got)
+	}
+	if !tracker.IsAvailable("AnimeFire") {
+		t.Error("unknown source should be available")
+	}
+
+	tracker.RecordSuccess("AllAnime")
+	status := tracker.Status("AllAnime")
+	if !tracker.IsAvailable("AllAnime") || status.FailCount != 0 || status.LastError != "timeout" {
+		t.Errorf("Status() = %+v; want available with the last error kept", status)
+	}
+}
+
+func TestTracker_PersistsAndDecays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	now := time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC)
+	tracker := newTestTracker(t, path, &now)
+
+	for i := 0; i < 5; i++ {
+		tracker.RecordFailure("Enime", "502 Bad Gateway")
+	}
+	now = now.Add(5 * time.Minute)
+	tracker.RecordSuccess("Consumet")
+	tracker.RecordFailure("Consumet", "sem resultado")
+	tracker.RecordSuccess("Consumet")
+
+	// Nova execução: estado, cooldown e histórico vêm do banco
+	reopened := newTestTracker(t, path, &now)
+	status := reopened.Status("Enime")
+	if status.FailCount != 5 || !status.InCooldown || status.LastError != "502 Bad Gateway" {
+		t.Fatalf("Status() after reopen = %+v; want 5 failures in cooldown", status)
+	}
+	dead := reopened.Score("Enime")
+	if dead > 0.5 {
+		t.Errorf("Score() = %v; want a failing source ranked low after restart", dead)
+	}
+
+	history := reopened.History(0)
+	if len(history) != 2 || history[0].Status.Source != "Consumet" || history[1].Status.Source != "Enime" {
+		t.Fatalf("History() = %+v; want Consumet and Enime", history)
+	}
+	consumet := history[0]
+	if len(consumet.Hours) != 1 || consumet.Hours[0].Successes != 2 || consumet.Hours[0].Failures != 1 {
+		t.Errorf("Consumet hours = %+v; want one hour with 2 successes and 1 failure", consumet.Hours)
+	}
+	if rate := consumet.Hours[0].SuccessRate; rate < 0.66 || rate > 0.67 {
+		t.Errorf("SuccessRate = %v; want 2/3", rate)
+	}
+
+	// Um dia depois, o cooldown acabou e a pontuação quase voltou ao normal
+	now = now.Add(24 * time.Hour)
+	if got := reopened.History(1); len(got[1].Hours) != 0 {
+		t.Errorf("History(1) Enime hours = %+v; want only the current hour", got[1].Hours)
+	}
+	if !reopened.IsAvailable("Enime") {
+		t.Error("cooldown should expire")
+	}
+	if score := reopened.Score("Enime"); score <= dead || score < 0.75 {
+		t.Errorf("Score() a day later = %v; want it to decay back towards 1", score)
+	}
+
+	// Histórico além de HistoryHours é apagado ao abrir
+	now = now.Add(HistoryHours * time.Hour)
+	if got := newTestTracker(t, path, &now).History(0); len(got[0].Hours) != 0 {
+		t.Errorf("History() = %+v; want old hours pruned", got[0].Hours)
+	}
+}
@@ -1,8 +1,7 @@
 package store
 
 import (
-	"encoding/json"
-	"os"
+	"fmt"
 	// ATENÇÃO: NÃO ADICIONE NENHUM OUTRO IMPORT AQUI
 )
 
@@ -52,18 +51,41 @@ type UserSettings struct {
 	SeedingOnlyWifi     bool   `json:"seeding_only_wifi"`     // Apenas em WiFi
 	SeedingSchedule     string `json:"seeding_schedule"`      // "always", "night", "idle"
 	SeedingContributed  int64  `json:"seeding_contributed"`   // Total contribuído (bytes)
+
+	// Biblioteca
+	UpdateCheckHours int `json:"update_check_hours"` // Intervalo da busca por novos episódios (-1 = desativada)
+
+	// Downloads
+	DownloadDir  string `json:"download_dir"`  // Pasta da biblioteca offline (vazio = Vídeos/GoAnime)
+	MaxDownloads int    `json:"max_downloads"` // Downloads simultâneos
+
+	// Biblioteca offline
+	OfflineDeleteWatched bool `json:"offline_delete_watched"` // Apagar episódios baixados depois de assistidos
+	OfflineQuotaGB       int  `json:"offline_quota_gb"`       // Espaço máximo em GB (0 = sem limite)
+
+	// Cache de vídeo do proxy
+	VideoCacheMB   int `json:"video_cache_mb"`   // Tamanho máximo do cache de segmentos em MB (-1 = desativado)
+	VideoReadAhead int `json:"video_read_ahead"` // Segmentos baixados à frente durante a reprodução (-1 = desativado)
 }
 
+// Padrões do cache de vídeo
+const (
+	DefaultVideoCacheMB   = 1024
+	DefaultVideoReadAhead = 3
+)
+
 // WatchedEpisode guarda informação de um episódio assistido
 type WatchedEpisode struct {
-	AnimeTitle   string `json:"anime_title"`
-	AnimeImage   string `json:"anime_image"`
-	AnimeURL     string `json:"anime_url"`
-	EpisodeTitle string `json:"episode_title"`
-	EpisodeURL   string `json:"episode_url"`
-	EpisodeNum   int    `json:"episode_num"`
-	WatchedAt    string `json:"watched_at"` // ISO 8601 timestamp
-	Progress     int    `json:"progress"`   // Percentagem assistida (0-100)
+	AnimeTitle   string  `json:"anime_title"`
+	AnimeImage   string  `json:"anime_image"`
+	AnimeURL     string  `json:"anime_url"`
+	EpisodeTitle string  `json:"episode_title"`
+	EpisodeURL   string  `json:"episode_url"`
+	EpisodeNum   int     `json:"episode_num"`
+	WatchedAt    string  `json:"watched_at"` // ISO 8601 timestamp
+	Progress     int     `json:"progress"`   // Percentagem assistida (0-100)
+	Position     float64 `json:"position"`   // Posição exata para retomar (segundos)
+	Duration     float64 `json:"duration"`   // Duração total do episódio (segundos)
 }
 
 type UserData struct {
@@ -77,29 +99,62 @@ type UserData struct {
 	DefaultQuality string           `json:"default_quality,omitempty"`
 }
 
-const dbFile = "goanime_user.json"
-
+// LoadUser carrega o usuário do banco SQLite (importando o JSON legado na primeira vez).
+// Retorna nil se ainda não existe usuário. Se o SQLite não puder ser aberto, usa o JSON.
 func LoadUser() *UserData {
-	data, err := os.ReadFile(dbFile)
+	db, err := Default()
 	if err != nil {
-		return nil
+		user, err := readLegacyJSON(legacyJSONFile)
+		if err != nil {
+			fmt.Printf("[Store] Erro ao ler %s: %v\n", legacyJSONFile, err)
+			return nil
+		}
+		return user
 	}
-	var user UserData
-	if err := json.Unmarshal(data, &user); err != nil {
+
+	user, err := db.LoadUser()
+	if err != nil {
+		fmt.Printf("[Store] Erro ao carregar usuário: %v\n", err)
 		return nil
 	}
+	return user
+}
 
-	// Inicializa settings padrão se não existir
-	if user.Settings.ContentLanguage == "" {
-		user.Settings.ContentLanguage = "all"
+// SaveUser persiste o usuário inteiro em uma transação
+func SaveUser(user *UserData) error {
+	db, err := Default()
+	if err != nil {
+		return writeLegacyJSON(legacyJSONFile, user)
 	}
+	return db.SaveUser(user)
+}
 
-	return &user
+// ExportUser retorna o usuário com o histórico completo, pronto para exportar
+func ExportUser(user *UserData) (*UserData, error) {
+	db, err := Default()
+	if err != nil {
+		return user, nil
+	}
+	return db.ExportUser(user)
 }
 
-func SaveUser(user *UserData) error {
-	data, _ := json.MarshalIndent(user, "", "  ")
-	return os.WriteFile(dbFile, data, 0600)
+// ReplaceUser substitui todos os dados do usuário, incluindo o histórico completo
+func ReplaceUser(user *UserData) error {
+	db, err := Default()
+	if err != nil {
+		return writeLegacyJSON(legacyJSONFile, user)
+	}
+	return db.ReplaceUser(user)
+}
+
+// SaveWatchedEpisode grava apenas um episódio do histórico (sem regravar o usuário).
+// user só é usado no fallback JSON, que precisa gravar o arquivo inteiro.
+func SaveWatchedEpisode(user *UserData, ep WatchedEpisode) error {
+	db, err := Default()
+	if err != nil {
+		return writeLegacyJSON(legacyJSONFile, user)
+	}
+	return db.SaveWatchedEpisode(ep)
 }
 
 // GetDefaultSettings retorna as configurações padrão
@@ -115,5 +170,9 @@ func GetDefaultSettings() UserSettings {
 		SeedingOnlyWifi:     true,
 		SeedingSchedule:     "idle",
 		SeedingContributed:  0,
+		UpdateCheckHours:    DefaultUpdateCheckHours,
+		MaxDownloads:        DefaultMaxDownloads,
+		VideoCacheMB:        DefaultVideoCacheMB,
+		VideoReadAhead:      DefaultVideoReadAhead,
 	}
 }
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Estados de um download
+const (
+	DownloadQueued      = "queued"      // Aguardando vaga
+	DownloadActive      = "downloading" // Baixando agora
+	DownloadPaused      = "paused"      // Pausado pelo usuário
+	DownloadCompleted   = "completed"   // Arquivo pronto em Path
+	DownloadFailed      = "failed"      // Desistiu após erro ou tentativas demais
+	DefaultMaxDownloads = 2             // Downloads simultâneos padrão
+)
+
+// Download é um episódio na fila de downloads.
+// SourceURL vazio significa resolver o stream pelo episódio a cada tentativa (links expiram).
+type Download struct {
+	ID              int64  `json:"id"`
+	Key             string `json:"key"` // Identifica o episódio/arquivo, evitando duplicatas
+	AnimeURL        string `json:"animeUrl"`
+	AnimeTitle      string `json:"animeTitle"`
+	EpisodeURL      string `json:"episodeUrl"`
+	EpisodeNum      int    `json:"episodeNum"`
+	EpisodeTitle    string `json:"episodeTitle"`
+	SourceURL       string `json:"sourceUrl"`
+	Path            string `json:"path"`
+	State           string `json:"state"`
+	TotalBytes      int64  `json:"totalBytes"`
+	DownloadedBytes int64  `json:"downloadedBytes"`
+	Attempts        int    `json:"attempts"`
+	LastError       string `json:"lastError"`
+	CreatedAt       string `json:"createdAt"`
+	UpdatedAt       string `json:"updatedAt"`
+}
+
+const downloadColumns = `id, key, anime_url, anime_title, episode_url, episode_num, episode_title, source_url,
+	path, state, total_bytes, downloaded_bytes, attempts, last_error, created_at, updated_at`
+
+// AddDownload coloca um download na fila. Se a chave já existe, retorna o existente sem alterá-lo.
+func (db *DB) AddDownload(d Download) (*Download, error) {
+	now := time.Now().Format(time.RFC3339)
+	if _, err := db.sql.Exec(`INSERT INTO downloads (key, anime_url, anime_title, episode_url, episode_num, episode_title,
+	source_url, path, state, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (key) DO NOTHING`,
+		d.Key, d.AnimeURL, d.AnimeTitle, d.EpisodeURL, d.EpisodeNum, d.EpisodeTitle,
+		d.SourceURL, d.Path, DownloadQueued, now, now); err != nil {
+		return nil, err
+	}
+	return db.queryDownload(`WHERE key = ?`, d.Key)
+}
+
+// GetDownload retorna um download pelo ID (nil se não existir)
+func (db *DB) GetDownload(id int64) (*Download, error) {
+	return db.queryDownload(`WHERE id = ?`, id)
+}
+
+// Downloads retorna todos os downloads, dos mais antigos aos mais novos
+func (db *DB) Downloads() ([]Download, error) {
+	return db.queryDownloads(`ORDER BY id`)
+}
+
+// QueuedDownloads retorna os downloads aguardando vaga, na ordem da fila
+func (db *DB) QueuedDownloads() ([]Download, error) {
+	return db.queryDownloads(`WHERE state = ? ORDER BY id`, DownloadQueued)
+}
+
+// SetDownloadState muda o estado de um download e registra o erro (vazio limpa)
+func (db *DB) SetDownloadState(id int64, state, lastError string) error {
+	_, err := db.sql.Exec(`UPDATE downloads SET state = ?, last_error = ?, updated_at = ? WHERE id = ?`,
+		state, lastError, time.Now().Format(time.RFC3339), id)
+	return err
+}
+
+// SetDownloadProgress grava o progresso (chamado periodicamente durante o download)
+func (db *DB) SetDownloadProgress(id int64, downloaded, total int64) error {
+	_, err := db.sql.Exec(`UPDATE downloads SET downloaded_bytes = ?, total_bytes = ?, updated_at = ? WHERE id = ?`,
+		downloaded, total, time.Now().Format(time.RFC3339), id)
+	return err
+}
+
+// SetDownloadPath grava onde o arquivo está sendo salvo
+func (db *DB) SetDownloadPath(id int64, path string) error {
+	_, err := db.sql.Exec(`UPDATE downloads SET path = ? WHERE id = ?`, path, id)
+	return err
+}
+
+// SetDownloadAttempts grava o número de falhas seguidas
+func (db *DB) SetDownloadAttempts(id int64, attempts int) error {
+	_, err := db.sql.Exec(`UPDATE downloads SET attempts = ? WHERE id = ?`, attempts, id)
+	return err
+}
+
+// RequeueInterruptedDownloads devolve à fila os downloads que estavam ativos quando o app fechou
+func (db *DB) RequeueInterruptedDownloads() error {
+	_, err := db.sql.Exec(`UPDATE downloads SET state = ? WHERE state = ?`, DownloadQueued, DownloadActive)
+	return err
+}
+
+// DeleteDownload remove um download da lista (o arquivo fica a cargo de quem chama)
+func (db *DB) DeleteDownload(id int64) error {
+	_, err := db.sql.Exec(`DELETE FROM downloads WHERE id = ?`, id)
+	return err
+}
+
+func (db *DB) queryDownload(where string, args ...interface{}) (*Download, error) {
+	var d Download
+	err := db.sql.QueryRow(`SELECT `+downloadColumns+` FROM downloads `+where, args...).Scan(
+		&d.ID, &d.Key, &d.AnimeURL, &d.AnimeTitle, &d.EpisodeURL, &d.EpisodeNum, &d.EpisodeTitle, &d.SourceURL,
+		&d.Path, &d.State, &d.TotalBytes, &d.DownloadedBytes, &d.Attempts, &d.LastError, &d.CreatedAt, &d.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (db *DB) queryDownloads(where string, args ...interface{}) ([]Download, error) {
+	rows, err := db.sql.Query(`SELECT `+downloadColumns+` FROM downloads `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	downloads := []Download{}
+	for rows.Next() {
+		var d Download
+		if err := rows.Scan(&d.ID, &d.Key, &d.AnimeURL, &d.AnimeTitle, &d.EpisodeURL, &d.EpisodeNum, &d.EpisodeTitle,
+			&d.SourceURL, &d.Path, &d.State, &d.TotalBytes, &d.DownloadedBytes, &d.Attempts, &d.LastError,
+			&d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, err
+		}
+		downloads = append(downloads, d)
+	}
+	return downloads, rows.Err()
+}
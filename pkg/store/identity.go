@@ -0,0 +1,77 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// AnimeIdentity liga uma entrada de fonte (ou um título solto) aos IDs canônicos.
+// AniListID zero com Manual falso é um resultado negativo guardado em cache.
+type AnimeIdentity struct {
+	Key        string  `json:"key"`
+	Title      string  `json:"title"`
+	AniListID  int     `json:"anilistId"`
+	MALID      int     `json:"malId"`
+	Confidence float64 `json:"confidence"` // 0-1; correções manuais valem 1
+	Manual     bool    `json:"manual"`
+	UpdatedAt  string  `json:"updatedAt"`
+}
+
+const identityColumns = `key, title, anilist_id, mal_id, confidence, manual, updated_at`
+
+// GetIdentity retorna o vínculo gravado para a chave (nil se não houver)
+func (db *DB) GetIdentity(key string) (*AnimeIdentity, error) {
+	var id AnimeIdentity
+	err := db.sql.QueryRow(`SELECT `+identityColumns+` FROM anime_identities WHERE key = ?`, key).
+		Scan(&id.Key, &id.Title, &id.AniListID, &id.MALID, &id.Confidence, &id.Manual, &id.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// SaveIdentity grava um vínculo. Um vínculo automático nunca sobrescreve uma correção manual.
+func (db *DB) SaveIdentity(id AnimeIdentity) error {
+	_, err := db.sql.Exec(`INSERT INTO anime_identities (`+identityColumns+`)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (key) DO UPDATE SET
+	title = excluded.title,
+	anilist_id = excluded.anilist_id,
+	mal_id = excluded.mal_id,
+	confidence = excluded.confidence,
+	manual = excluded.manual,
+	updated_at = excluded.updated_at
+WHERE excluded.manual = 1 OR anime_identities.manual = 0`,
+		id.Key, id.Title, id.AniListID, id.MALID, id.Confidence, id.Manual, time.Now().Format(time.RFC3339))
+	return err
+}
+
+// DeleteIdentity remove o vínculo da chave, forçando uma nova resolução
+func (db *DB) DeleteIdentity(key string) error {
+	_, err := db.sql.Exec(`DELETE FROM anime_identities WHERE key = ?`, key)
+	return err
+}
+
+// Identities retorna todos os vínculos resolvidos, os manuais primeiro
+func (db *DB) Identities() ([]AnimeIdentity, error) {
+	rows, err := db.sql.Query(`SELECT ` + identityColumns + ` FROM anime_identities
+WHERE anilist_id > 0 ORDER BY manual DESC, title`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []AnimeIdentity{}
+	for rows.Next() {
+		var id AnimeIdentity
+		if err := rows.Scan(&id.Key, &id.Title, &id.AniListID, &id.MALID, &id.Confidence, &id.Manual, &id.UpdatedAt); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
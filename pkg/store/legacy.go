@@ -0,0 +1,91 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// legacyJSONFile é o arquivo usado antes do SQLite (relativo ao diretório de trabalho)
+const legacyJSONFile = "goanime_user.json"
+
+// metaLegacyImported marca que o JSON legado já foi importado
+const metaLegacyImported = "legacy_json_imported"
+
+// ImportLegacyJSON importa goanime_user.json uma única vez.
+// Após importar o arquivo é renomeado para .migrated; se estiver corrompido
+// é renomeado para .corrupt-<timestamp> para que não se perca nem seja lido de novo.
+func (db *DB) ImportLegacyJSON(path string) error {
+	done, err := db.getMeta(metaLegacyImported)
+	if err != nil {
+		return err
+	}
+	if done != "" {
+		return nil
+	}
+
+	user, err := readLegacyJSON(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		corrupt := fmt.Sprintf("%s.corrupt-%d", path, time.Now().Unix())
+		if renameErr := os.Rename(path, corrupt); renameErr == nil {
+			fmt.Printf("[Store] %s corrompido, movido para %s\n", path, corrupt)
+		}
+		return fmt.Errorf("JSON legado inválido: %w", err)
+	}
+
+	// Não sobrescreve um usuário que já existe no banco
+	existing, err := db.LoadUser()
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		if err := db.ReplaceUser(user); err != nil {
+			return err
+		}
+		fmt.Printf("[Store] Importado %s: %d favoritos, %d episódios no histórico\n",
+			path, len(user.Favorites), len(user.WatchHistory))
+	}
+
+	if err := db.setMeta(metaLegacyImported, time.Now().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	if err := os.Rename(path, path+".migrated"); err != nil {
+		fmt.Printf("[Store] Não foi possível renomear %s: %v\n", path, err)
+	}
+	return nil
+}
+
+// readLegacyJSON lê e valida o arquivo JSON antigo
+func readLegacyJSON(path string) (*UserData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var user UserData
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, err
+	}
+	if user.Settings.ContentLanguage == "" {
+		user.Settings.ContentLanguage = "all"
+	}
+	return &user, nil
+}
+
+// writeLegacyJSON grava no formato antigo de forma atômica (arquivo temporário + rename).
+// Usado apenas quando o SQLite não está disponível.
+func writeLegacyJSON(path string, user *UserData) error {
+	data, err := json.MarshalIndent(user, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,383 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WatchStatus é o estado de um anime na biblioteca
+type WatchStatus string
+
+const (
+	StatusWatching    WatchStatus = "watching"
+	StatusCompleted   WatchStatus = "completed"
+	StatusOnHold      WatchStatus = "on_hold"
+	StatusDropped     WatchStatus = "dropped"
+	StatusPlanToWatch WatchStatus = "plan_to_watch"
+)
+
+// WatchStatuses lista os estados válidos, na ordem exibida na interface
+var WatchStatuses = []WatchStatus{StatusWatching, StatusCompleted, StatusOnHold, StatusDropped, StatusPlanToWatch}
+
+// IsValid verifica se o estado é conhecido
+func (s WatchStatus) IsValid() bool {
+	for _, v := range WatchStatuses {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxScore é a nota máxima pessoal (escala 0-10, 0 = sem nota)
+const MaxScore = 10
+
+// Category é uma categoria criada pelo usuário
+type Category struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+}
+
+// LibraryItem é um favorito com os dados de biblioteca
+type LibraryItem struct {
+	SavedAnime
+	Status     WatchStatus `json:"status"`
+	Score      float64     `json:"score"`
+	Notes      string      `json:"notes"`
+	Categories []int64     `json:"categories"`
+	Progress   int         `json:"progress"`  // Maior episódio concluído
+	AniListID  int         `json:"anilistId"` // 0 = não vinculado
+	MALID      int         `json:"malId"`     // 0 = não vinculado
+	AddedAt    string      `json:"addedAt"`
+	UpdatedAt  string      `json:"updatedAt"`
+}
+
+// LibraryFilter filtra a biblioteca; campos vazios não filtram
+type LibraryFilter struct {
+	Status     WatchStatus `json:"status"`
+	CategoryID int64       `json:"categoryId"` // -1 = sem categoria
+	Source     string      `json:"source"`
+}
+
+// UncategorizedID filtra entradas que não estão em nenhuma categoria
+const UncategorizedID = -1
+
+// ErrNotInLibrary indica que o anime não está nos favoritos
+var ErrNotInLibrary = errors.New("anime não está na biblioteca")
+
+// ensureLibraryEntries cria as entradas de biblioteca dos favoritos que ainda não têm uma
+func ensureLibraryEntries(e execer, favorites []SavedAnime) error {
+	now := time.Now().Format(time.RFC3339)
+	for _, fav := range favorites {
+		if fav.URL == "" {
+			continue
+		}
+		if _, err := e.Exec(`INSERT INTO library_entries (anime_url, status, added_at, updated_at)
+VALUES (?, ?, ?, ?) ON CONFLICT (anime_url) DO NOTHING`, fav.URL, StatusPlanToWatch, now, now); err != nil {
+			return fmt.Errorf("erro ao criar entrada na biblioteca: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteLibraryEntry remove a entrada (e suas categorias) da biblioteca
+func (db *DB) DeleteLibraryEntry(animeURL string) error {
+	_, err := db.sql.Exec(`DELETE FROM library_entries WHERE anime_url = ?`, animeURL)
+	return err
+}
+
+// updateLibraryEntry aplica um UPDATE em uma entrada existente
+func (db *DB) updateLibraryEntry(animeURL, set string, args ...interface{}) error {
+	args = append(args, time.Now().Format(time.RFC3339), animeURL)
+	res, err := db.sql.Exec(`UPDATE library_entries SET `+set+`, updated_at = ? WHERE anime_url = ?`, args...)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotInLibrary
+	}
+	return nil
+}
+
+// SetLibraryStatus altera o estado de uma entrada
+func (db *DB) SetLibraryStatus(animeURL string, status WatchStatus) error {
+	if !status.IsValid() {
+		return fmt.Errorf("estado inválido: %q", status)
+	}
+	return db.updateLibraryEntry(animeURL, `status = ?`, status)
+}
+
+// SetLibraryScore altera a nota pessoal (0-10)
+func (db *DB) SetLibraryScore(animeURL string, score float64) error {
+	if score < 0 || score > MaxScore {
+		return fmt.Errorf("nota deve estar entre 0 e %d", MaxScore)
+	}
+	return db.updateLibraryEntry(animeURL, `score = ?`, score)
+}
+
+// SetLibraryNotes altera as anotações pessoais
+func (db *DB) SetLibraryNotes(animeURL, notes string) error {
+	return db.updateLibraryEntry(animeURL, `notes = ?`, notes)
+}
+
+// MarkWatching passa de "planejo assistir" para "assistindo" ao começar um episódio
+func (db *DB) MarkWatching(animeURL string) error {
+	_, err := db.sql.Exec(`UPDATE library_entries SET status = ?, updated_at = ?
+WHERE anime_url = ? AND status = ?`, StatusWatching, time.Now().Format(time.RFC3339), animeURL, StatusPlanToWatch)
+	return err
+}
+
+// SetLibraryProgress registra o maior episódio concluído (nunca diminui o progresso)
+func (db *DB) SetLibraryProgress(animeURL string, progress int) error {
+	return db.updateLibraryEntry(animeURL, `progress = MAX(progress, ?)`, progress)
+}
+
+// LinkAniList vincula uma entrada a um anime da AniList (0 desfaz o vínculo)
+func (db *DB) LinkAniList(animeURL string, mediaID int) error {
+	return db.updateLibraryEntry(animeURL, `anilist_id = ?`, mediaID)
+}
+
+// LinkMAL vincula uma entrada a um anime do MyAnimeList (0 desfaz o vínculo)
+func (db *DB) LinkMAL(animeURL string, malID int) error {
+	return db.updateLibraryEntry(animeURL, `mal_id = ?`, malID)
+}
+
+// SetEntryCategories substitui as categorias das entradas indicadas
+func (db *DB) SetEntryCategories(animeURLs []string, categoryIDs []int64) error {
+	tx, err := db.sql.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, url := range animeURLs {
+		var exists int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM library_entries WHERE anime_url = ?`, url).Scan(&exists); err != nil {
+			return err
+		}
+		if exists == 0 {
+			return fmt.Errorf("%w: %s", ErrNotInLibrary, url)
+		}
+
+		if _, err := tx.Exec(`DELETE FROM library_entry_categories WHERE anime_url = ?`, url); err != nil {
+			return err
+		}
+		for _, id := range categoryIDs {
+			if _, err := tx.Exec(`INSERT INTO library_entry_categories (anime_url, category_id) VALUES (?, ?)`, url, id); err != nil {
+				return fmt.Errorf("categoria %d inválida: %w", id, err)
+			}
+		}
+		if _, err := tx.Exec(`UPDATE library_entries SET updated_at = ? WHERE anime_url = ?`,
+			time.Now().Format(time.RFC3339), url); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// libraryQuery seleciona os favoritos com os dados de biblioteca (na ordem dos favoritos)
+const libraryQuery = `SELECT s.title, s.image, s.url, s.source, s.sources,
+	COALESCE(l.status, ?), COALESCE(l.score, 0), COALESCE(l.notes, ''),
+	COALESCE(l.progress, 0), COALESCE(l.anilist_id, 0), COALESCE(l.mal_id, 0),
+	COALESCE(l.added_at, ''), COALESCE(l.updated_at, ''),
+	COALESCE((SELECT GROUP_CONCAT(c.category_id) FROM library_entry_categories c WHERE c.anime_url = s.url), '')
+FROM saved_anime s
+LEFT JOIN library_entries l ON l.anime_url = s.url
+WHERE s.list = ?`
+
+// QueryLibrary retorna os favoritos com dados de biblioteca, na ordem dos favoritos
+func (db *DB) QueryLibrary(filter LibraryFilter) ([]LibraryItem, error) {
+	query := libraryQuery
+	args := []interface{}{StatusPlanToWatch, listFavorites}
+
+	if filter.Status != "" {
+		query += ` AND COALESCE(l.status, ?) = ?`
+		args = append(args, StatusPlanToWatch, filter.Status)
+	}
+	switch {
+	case filter.CategoryID == UncategorizedID:
+		query += ` AND NOT EXISTS (SELECT 1 FROM library_entry_categories c WHERE c.anime_url = s.url)`
+	case filter.CategoryID > 0:
+		query += ` AND EXISTS (SELECT 1 FROM library_entry_categories c WHERE c.anime_url = s.url AND c.category_id = ?)`
+		args = append(args, filter.CategoryID)
+	}
+	query += ` ORDER BY s.position`
+
+	items, err := db.scanLibrary(query, args...)
+	if err != nil || filter.Source == "" {
+		return items, err
+	}
+
+	filtered := items[:0]
+	for _, item := range items {
+		if item.hasSource(filter.Source) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered, nil
+}
+
+// GetLibraryItem retorna a entrada de um favorito, ou nil se não estiver na biblioteca
+func (db *DB) GetLibraryItem(animeURL string) (*LibraryItem, error) {
+	items, err := db.scanLibrary(libraryQuery+` AND s.url = ? LIMIT 1`, StatusPlanToWatch, listFavorites, animeURL)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+func (db *DB) scanLibrary(query string, args ...interface{}) ([]LibraryItem, error) {
+	rows, err := db.sql.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []LibraryItem{}
+	for rows.Next() {
+		var item LibraryItem
+		var sources, categories string
+		if err := rows.Scan(&item.Title, &item.Image, &item.URL, &item.Source, &sources,
+			&item.Status, &item.Score, &item.Notes, &item.Progress, &item.AniListID, &item.MALID,
+			&item.AddedAt, &item.UpdatedAt, &categories); err != nil {
+			return nil, err
+		}
+		if sources != "" && sources != "null" {
+			json.Unmarshal([]byte(sources), &item.Sources)
+		}
+		item.Categories = parseIDList(categories)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// hasSource verifica se o anime está disponível na fonte (principal ou alternativa)
+func (item LibraryItem) hasSource(source string) bool {
+	if strings.EqualFold(item.Source, source) {
+		return true
+	}
+	for _, s := range item.Sources {
+		if strings.EqualFold(s.Name, source) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseIDList(s string) []int64 {
+	ids := []int64{}
+	for _, part := range strings.Split(s, ",") {
+		var id int64
+		if _, err := fmt.Sscan(part, &id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// === CATEGORIAS ===
+
+// Categories retorna as categorias na ordem definida pelo usuário
+func (db *DB) Categories() ([]Category, error) {
+	rows, err := db.sql.Query(`SELECT id, name, position FROM categories ORDER BY position, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []Category{}
+	for rows.Next() {
+		var c Category
+		if err := rows.Scan(&c.ID, &c.Name, &c.Position); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// CreateCategory cria uma categoria no fim da lista
+func (db *DB) CreateCategory(name string) (Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Category{}, errors.New("nome da categoria vazio")
+	}
+
+	var position int
+	if err := db.sql.QueryRow(`SELECT COALESCE(MAX(position), -1) + 1 FROM categories`).Scan(&position); err != nil {
+		return Category{}, err
+	}
+
+	res, err := db.sql.Exec(`INSERT INTO categories (name, position) VALUES (?, ?)`, name, position)
+	if err != nil {
+		return Category{}, fmt.Errorf("categoria %q já existe ou é inválida: %w", name, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Category{}, err
+	}
+	return Category{ID: id, Name: name, Position: position}, nil
+}
+
+// RenameCategory renomeia uma categoria
+func (db *DB) RenameCategory(id int64, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("nome da categoria vazio")
+	}
+	res, err := db.sql.Exec(`UPDATE categories SET name = ? WHERE id = ?`, name, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteCategory apaga a categoria; as entradas continuam na biblioteca
+func (db *DB) DeleteCategory(id int64) error {
+	_, err := db.sql.Exec(`DELETE FROM categories WHERE id = ?`, id)
+	return err
+}
+
+// ReorderCategories define a nova ordem a partir da lista de IDs
+func (db *DB) ReorderCategories(ids []int64) error {
+	tx, err := db.sql.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, id := range ids {
+		if _, err := tx.Exec(`UPDATE categories SET position = ? WHERE id = ?`, i, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// === ATALHOS PARA O BANCO PADRÃO ===
+
+func withDefault(fn func(db *DB) error) error {
+	db, err := Default()
+	if err != nil {
+		return err
+	}
+	return fn(db)
+}
+
+// DeleteLibraryEntry remove a entrada de biblioteca do banco padrão
+func DeleteLibraryEntry(animeURL string) error {
+	return withDefault(func(db *DB) error { return db.DeleteLibraryEntry(animeURL) })
+}
+
+// MarkWatching marca o anime como "assistindo" no banco padrão
+func MarkWatching(animeURL string) error {
+	return withDefault(func(db *DB) error { return db.MarkWatching(animeURL) })
+}
@@ -0,0 +1,145 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// MangaDownload é um capítulo na fila de downloads de mangá (usa os mesmos estados de Download)
+type MangaDownload struct {
+	ID            int64    `json:"id"`
+	ChapterURL    string   `json:"chapterUrl"`
+	Source        string   `json:"source"`
+	MangaURL      string   `json:"mangaUrl"`
+	MangaTitle    string   `json:"mangaTitle"`
+	Author        string   `json:"author"`
+	Summary       string   `json:"summary"`
+	Genres        []string `json:"genres"`
+	ChapterNumber string   `json:"chapterNumber"`
+	ChapterTitle  string   `json:"chapterTitle"`
+	Path          string   `json:"path"` // Arquivo .cbz
+	State         string   `json:"state"`
+	PagesTotal    int      `json:"pagesTotal"`
+	PagesDone     int      `json:"pagesDone"`
+	Attempts      int      `json:"attempts"`
+	LastError     string   `json:"lastError"`
+	CreatedAt     string   `json:"createdAt"`
+	UpdatedAt     string   `json:"updatedAt"`
+}
+
+const mangaDownloadColumns = `id, chapter_url, source, manga_url, manga_title, author, summary, genres,
+	chapter_number, chapter_title, path, state, pages_total, pages_done, attempts, last_error, created_at, updated_at`
+
+// AddMangaDownload coloca um capítulo na fila. Se já existe, retorna o existente sem alterá-lo.
+func (db *DB) AddMangaDownload(d MangaDownload) (*MangaDownload, error) {
+	if d.Genres == nil {
+		d.Genres = []string{}
+	}
+	genres, _ := json.Marshal(d.Genres)
+	now := time.Now().Format(time.RFC3339)
+	if _, err := db.sql.Exec(`INSERT INTO manga_downloads (chapter_url, source, manga_url, manga_title, author, summary,
+	genres, chapter_number, chapter_title, state, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (chapter_url) DO NOTHING`,
+		d.ChapterURL, d.Source, d.MangaURL, d.MangaTitle, d.Author, d.Summary, string(genres),
+		d.ChapterNumber, d.ChapterTitle, DownloadQueued, now, now); err != nil {
+		return nil, err
+	}
+	return db.queryMangaDownload(`WHERE chapter_url = ?`, d.ChapterURL)
+}
+
+// GetMangaDownload retorna um download de capítulo pelo ID (nil se não existir)
+func (db *DB) GetMangaDownload(id int64) (*MangaDownload, error) {
+	return db.queryMangaDownload(`WHERE id = ?`, id)
+}
+
+// MangaDownloads retorna todos os downloads de capítulos, na ordem da fila
+func (db *DB) MangaDownloads() ([]MangaDownload, error) {
+	return db.queryMangaDownloads(`ORDER BY id`)
+}
+
+// QueuedMangaDownloads retorna os capítulos aguardando vaga, na ordem da fila
+func (db *DB) QueuedMangaDownloads() ([]MangaDownload, error) {
+	return db.queryMangaDownloads(`WHERE state = ? ORDER BY id`, DownloadQueued)
+}
+
+// SetMangaDownloadState muda o estado de um capítulo e registra o erro (vazio limpa)
+func (db *DB) SetMangaDownloadState(id int64, state, lastError string) error {
+	_, err := db.sql.Exec(`UPDATE manga_downloads SET state = ?, last_error = ?, updated_at = ? WHERE id = ?`,
+		state, lastError, time.Now().Format(time.RFC3339), id)
+	return err
+}
+
+// SetMangaDownloadProgress grava quantas páginas já foram baixadas
+func (db *DB) SetMangaDownloadProgress(id int64, done, total int) error {
+	_, err := db.sql.Exec(`UPDATE manga_downloads SET pages_done = ?, pages_total = ?, updated_at = ? WHERE id = ?`,
+		done, total, time.Now().Format(time.RFC3339), id)
+	return err
+}
+
+// SetMangaDownloadPath grava onde o CBZ será salvo
+func (db *DB) SetMangaDownloadPath(id int64, path string) error {
+	_, err := db.sql.Exec(`UPDATE manga_downloads SET path = ? WHERE id = ?`, path, id)
+	return err
+}
+
+// SetMangaDownloadAttempts grava o número de falhas seguidas
+func (db *DB) SetMangaDownloadAttempts(id int64, attempts int) error {
+	_, err := db.sql.Exec(`UPDATE manga_downloads SET attempts = ? WHERE id = ?`, attempts, id)
+	return err
+}
+
+// RequeueInterruptedMangaDownloads devolve à fila os capítulos que estavam baixando quando o app fechou
+func (db *DB) RequeueInterruptedMangaDownloads() error {
+	_, err := db.sql.Exec(`UPDATE manga_downloads SET state = ? WHERE state = ?`, DownloadQueued, DownloadActive)
+	return err
+}
+
+// DeleteMangaDownload remove um capítulo da lista (o arquivo fica a cargo de quem chama)
+func (db *DB) DeleteMangaDownload(id int64) error {
+	_, err := db.sql.Exec(`DELETE FROM manga_downloads WHERE id = ?`, id)
+	return err
+}
+
+func (db *DB) queryMangaDownload(where string, args ...interface{}) (*MangaDownload, error) {
+	d, err := scanMangaDownload(db.sql.QueryRow(`SELECT `+mangaDownloadColumns+` FROM manga_downloads `+where, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return d, err
+}
+
+func (db *DB) queryMangaDownloads(where string, args ...interface{}) ([]MangaDownload, error) {
+	rows, err := db.sql.Query(`SELECT `+mangaDownloadColumns+` FROM manga_downloads `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	downloads := []MangaDownload{}
+	for rows.Next() {
+		d, err := scanMangaDownload(rows)
+		if err != nil {
+			return nil, err
+		}
+		downloads = append(downloads, *d)
+	}
+	return downloads, rows.Err()
+}
+
+func scanMangaDownload(row scanner) (*MangaDownload, error) {
+	var d MangaDownload
+	var genres string
+	if err := row.Scan(&d.ID, &d.ChapterURL, &d.Source, &d.MangaURL, &d.MangaTitle, &d.Author, &d.Summary, &genres,
+		&d.ChapterNumber, &d.ChapterTitle, &d.Path, &d.State, &d.PagesTotal, &d.PagesDone, &d.Attempts,
+		&d.LastError, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return nil, err
+	}
+	json.Unmarshal([]byte(genres), &d.Genres)
+	if d.Genres == nil {
+		d.Genres = []string{}
+	}
+	return &d, nil
+}
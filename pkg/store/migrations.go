@@ -0,0 +1,341 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migration representa uma alteração versionada do esquema SQLite.
+// Nunca edite uma migração já publicada: adicione uma nova com a próxima versão.
+type migration struct {
+	version int
+	name    string
+	stmts   string
+}
+
+// migrations lista todas as migrações em ordem crescente de versão
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "esquema inicial",
+		stmts: `
+CREATE TABLE meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+
+CREATE TABLE user (
+	id              INTEGER PRIMARY KEY CHECK (id = 1),
+	username        TEXT NOT NULL,
+	avatar          TEXT NOT NULL DEFAULT '',
+	mpv_path        TEXT NOT NULL DEFAULT '',
+	default_quality TEXT NOT NULL DEFAULT '',
+	settings        TEXT NOT NULL DEFAULT '{}'
+);
+
+CREATE TABLE saved_anime (
+	list     TEXT    NOT NULL,
+	position INTEGER NOT NULL,
+	title    TEXT    NOT NULL DEFAULT '',
+	image    TEXT    NOT NULL DEFAULT '',
+	url      TEXT    NOT NULL DEFAULT '',
+	source   TEXT    NOT NULL DEFAULT '',
+	sources  TEXT    NOT NULL DEFAULT '[]',
+	PRIMARY KEY (list, position)
+);
+
+CREATE TABLE watched_episodes (
+	episode_url   TEXT PRIMARY KEY,
+	anime_title   TEXT    NOT NULL DEFAULT '',
+	anime_image   TEXT    NOT NULL DEFAULT '',
+	anime_url     TEXT    NOT NULL DEFAULT '',
+	episode_title TEXT    NOT NULL DEFAULT '',
+	episode_num   INTEGER NOT NULL DEFAULT 0,
+	watched_at    TEXT    NOT NULL DEFAULT '',
+	progress      INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_watched_episodes_watched_at ON watched_episodes (watched_at DESC);
+CREATE INDEX idx_watched_episodes_anime_url ON watched_episodes (anime_url);
+`,
+	},
+	{
+		version: 2,
+		name:    "posição de retomada por episódio",
+		stmts: `
+ALTER TABLE watched_episodes ADD COLUMN position REAL NOT NULL DEFAULT 0;
+ALTER TABLE watched_episodes ADD COLUMN duration REAL NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		version: 3,
+		name:    "estados da biblioteca e categorias",
+		stmts: `
+CREATE TABLE library_entries (
+	anime_url  TEXT PRIMARY KEY,
+	status     TEXT NOT NULL DEFAULT 'plan_to_watch',
+	score      REAL NOT NULL DEFAULT 0,
+	notes      TEXT NOT NULL DEFAULT '',
+	added_at   TEXT NOT NULL DEFAULT '',
+	updated_at TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE categories (
+	id       INTEGER PRIMARY KEY AUTOINCREMENT,
+	name     TEXT    NOT NULL UNIQUE,
+	position INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE library_entry_categories (
+	anime_url   TEXT    NOT NULL REFERENCES library_entries (anime_url) ON DELETE CASCADE,
+	category_id INTEGER NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+	PRIMARY KEY (anime_url, category_id)
+);
+
+CREATE INDEX idx_library_entries_status ON library_entries (status);
+
+-- Favoritos existentes entram na biblioteca; quem já tem episódios assistidos fica "assistindo"
+INSERT INTO library_entries (anime_url, status, added_at, updated_at)
+SELECT s.url,
+	CASE WHEN EXISTS (SELECT 1 FROM watched_episodes w WHERE w.anime_url = s.url)
+		THEN 'watching' ELSE 'plan_to_watch' END,
+	strftime('%Y-%m-%dT%H:%M:%SZ', 'now'), strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+FROM saved_anime s
+WHERE s.list = 'favorites' AND s.url != ''
+GROUP BY s.url;
+`,
+	},
+	{
+		version: 4,
+		name:    "vínculo com a AniList e progresso da biblioteca",
+		stmts: `
+ALTER TABLE library_entries ADD COLUMN anilist_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE library_entries ADD COLUMN progress INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_library_entries_anilist_id ON library_entries (anilist_id);
+
+-- Progresso = maior episódio concluído de cada anime
+UPDATE library_entries SET progress = COALESCE((
+	SELECT MAX(w.episode_num) FROM watched_episodes w
+	WHERE w.anime_url = library_entries.anime_url AND w.progress >= 90
+), 0);
+`,
+	},
+	{
+		version: 5,
+		name:    "vínculo com o MyAnimeList",
+		stmts: `
+ALTER TABLE library_entries ADD COLUMN mal_id INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX idx_library_entries_mal_id ON library_entries (mal_id);
+`,
+	},
+	{
+		version: 6,
+		name:    "fila de sincronização",
+		stmts: `
+CREATE TABLE sync_queue (
+	id              INTEGER PRIMARY KEY AUTOINCREMENT,
+	kind            TEXT    NOT NULL,
+	key             TEXT    NOT NULL,
+	payload         TEXT    NOT NULL DEFAULT '',
+	revision        INTEGER NOT NULL DEFAULT 1,
+	attempts        INTEGER NOT NULL DEFAULT 0,
+	state           TEXT    NOT NULL DEFAULT 'pending',
+	last_error      TEXT    NOT NULL DEFAULT '',
+	next_attempt_at INTEGER NOT NULL DEFAULT 0,
+	created_at      TEXT    NOT NULL DEFAULT '',
+	updated_at      TEXT    NOT NULL DEFAULT '',
+	UNIQUE (kind, key)
+);
+
+CREATE INDEX idx_sync_queue_due ON sync_queue (state, next_attempt_at);
+`,
+	},
+	{
+		version: 7,
+		name:    "identidade canônica dos animes",
+		stmts: `
+CREATE TABLE anime_identities (
+	key        TEXT PRIMARY KEY,
+	title      TEXT    NOT NULL DEFAULT '',
+	anilist_id INTEGER NOT NULL DEFAULT 0,
+	mal_id     INTEGER NOT NULL DEFAULT 0,
+	confidence REAL    NOT NULL DEFAULT 0,
+	manual     INTEGER NOT NULL DEFAULT 0,
+	updated_at TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_anime_identities_anilist ON anime_identities (anilist_id);
+`,
+	},
+	{
+		version: 8,
+		name:    "verificação de novos episódios",
+		stmts: `
+ALTER TABLE library_entries ADD COLUMN known_episodes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE library_entries ADD COLUMN checked_at TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE library_updates (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	anime_url    TEXT    NOT NULL REFERENCES library_entries (anime_url) ON DELETE CASCADE,
+	episode_from INTEGER NOT NULL,
+	episode_to   INTEGER NOT NULL,
+	seen         INTEGER NOT NULL DEFAULT 0,
+	created_at   TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_library_updates_seen ON library_updates (seen, created_at DESC);
+`,
+	},
+	{
+		version: 9,
+		name:    "fila de downloads",
+		stmts: `
+CREATE TABLE downloads (
+	id               INTEGER PRIMARY KEY AUTOINCREMENT,
+	key              TEXT    NOT NULL UNIQUE,
+	anime_url        TEXT    NOT NULL DEFAULT '',
+	anime_title      TEXT    NOT NULL DEFAULT '',
+	episode_url      TEXT    NOT NULL DEFAULT '',
+	episode_num      INTEGER NOT NULL DEFAULT 0,
+	episode_title    TEXT    NOT NULL DEFAULT '',
+	source_url       TEXT    NOT NULL DEFAULT '',
+	path             TEXT    NOT NULL DEFAULT '',
+	state            TEXT    NOT NULL DEFAULT 'queued',
+	total_bytes      INTEGER NOT NULL DEFAULT 0,
+	downloaded_bytes INTEGER NOT NULL DEFAULT 0,
+	attempts         INTEGER NOT NULL DEFAULT 0,
+	last_error       TEXT    NOT NULL DEFAULT '',
+	created_at       TEXT    NOT NULL DEFAULT '',
+	updated_at       TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_downloads_state ON downloads (state, id);
+`,
+	},
+	{
+		version: 10,
+		name:    "biblioteca offline",
+		stmts: `
+CREATE TABLE offline_episodes (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	path          TEXT    NOT NULL UNIQUE,
+	download_id   INTEGER NOT NULL DEFAULT 0,
+	anime_url     TEXT    NOT NULL DEFAULT '',
+	anime_title   TEXT    NOT NULL DEFAULT '',
+	season        INTEGER NOT NULL DEFAULT 1,
+	episode_url   TEXT    NOT NULL DEFAULT '',
+	episode_num   INTEGER NOT NULL DEFAULT 0,
+	episode_title TEXT    NOT NULL DEFAULT '',
+	size          INTEGER NOT NULL DEFAULT 0,
+	subtitles     TEXT    NOT NULL DEFAULT '[]',
+	added_at      TEXT    NOT NULL DEFAULT '',
+	watched_at    TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_offline_episode_url ON offline_episodes (episode_url);
+CREATE INDEX idx_offline_anime ON offline_episodes (anime_url, season, episode_num);
+`,
+	},
+	{
+		version: 11,
+		name:    "downloads de mangá",
+		stmts: `
+CREATE TABLE manga_downloads (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	chapter_url    TEXT    NOT NULL UNIQUE,
+	source         TEXT    NOT NULL DEFAULT '',
+	manga_url      TEXT    NOT NULL DEFAULT '',
+	manga_title    TEXT    NOT NULL DEFAULT '',
+	author         TEXT    NOT NULL DEFAULT '',
+	summary        TEXT    NOT NULL DEFAULT '',
+	genres         TEXT    NOT NULL DEFAULT '[]',
+	chapter_number TEXT    NOT NULL DEFAULT '',
+	chapter_title  TEXT    NOT NULL DEFAULT '',
+	path           TEXT    NOT NULL DEFAULT '',
+	state          TEXT    NOT NULL DEFAULT 'queued',
+	pages_total    INTEGER NOT NULL DEFAULT 0,
+	pages_done     INTEGER NOT NULL DEFAULT 0,
+	attempts       INTEGER NOT NULL DEFAULT 0,
+	last_error     TEXT    NOT NULL DEFAULT '',
+	created_at     TEXT    NOT NULL DEFAULT '',
+	updated_at     TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_manga_downloads_state ON manga_downloads (state, id);
+`,
+	},
+	{
+		version: 12,
+		name:    "saúde das fontes",
+		stmts: `
+CREATE TABLE source_health (
+	source          TEXT    PRIMARY KEY,
+	score           REAL    NOT NULL DEFAULT 1,
+	score_at        TEXT    NOT NULL DEFAULT '',
+	fail_count      INTEGER NOT NULL DEFAULT 0,
+	cooldown_until  TEXT    NOT NULL DEFAULT '',
+	last_error      TEXT    NOT NULL DEFAULT '',
+	last_failure_at TEXT    NOT NULL DEFAULT '',
+	last_success_at TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE TABLE source_health_hours (
+	source    TEXT    NOT NULL,
+	hour      TEXT    NOT NULL,
+	successes INTEGER NOT NULL DEFAULT 0,
+	failures  INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (source, hour)
+);
+`,
+	},
+}
+
+// migrate aplica as migrações pendentes, cada uma em sua própria transação
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    INTEGER PRIMARY KEY,
+	name       TEXT NOT NULL,
+	applied_at TEXT NOT NULL
+)`); err != nil {
+		return fmt.Errorf("erro ao criar tabela de migrações: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("erro ao ler versão do esquema: %w", err)
+	}
+
+	latest := migrations[len(migrations)-1].version
+	if current > latest {
+		return fmt.Errorf("banco de dados na versão %d é mais novo que o aplicativo (versão %d)", current, latest)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(m.stmts); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migração %d (%s) falhou: %w", m.version, m.name, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+			m.version, m.name, time.Now().Format(time.RFC3339)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("erro ao registrar migração %d: %w", m.version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+
+		fmt.Printf("[Store] Migração %d aplicada: %s\n", m.version, m.name)
+	}
+
+	return nil
+}
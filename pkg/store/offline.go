@@ -0,0 +1,116 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// OfflineEpisode é um episódio baixado disponível para assistir sem internet
+type OfflineEpisode struct {
+	ID           int64    `json:"id"`
+	Path         string   `json:"path"`
+	DownloadID   int64    `json:"downloadId"` // 0 = arquivo não veio da fila de downloads
+	AnimeURL     string   `json:"animeUrl"`
+	AnimeTitle   string   `json:"animeTitle"`
+	Season       int      `json:"season"`
+	EpisodeURL   string   `json:"episodeUrl"`
+	EpisodeNum   int      `json:"episodeNum"`
+	EpisodeTitle string   `json:"episodeTitle"`
+	Size         int64    `json:"size"`
+	Subtitles    []string `json:"subtitles"` // Caminhos das legendas ao lado do vídeo
+	AddedAt      string   `json:"addedAt"`
+	WatchedAt    string   `json:"watchedAt"` // Vazio = ainda não assistido até o fim
+}
+
+const offlineColumns = `id, path, download_id, anime_url, anime_title, season, episode_url, episode_num,
+	episode_title, size, subtitles, added_at, watched_at`
+
+// SaveOfflineEpisode adiciona ou atualiza (pelo caminho do arquivo) um episódio offline
+func (db *DB) SaveOfflineEpisode(ep OfflineEpisode) (*OfflineEpisode, error) {
+	if ep.Subtitles == nil {
+		ep.Subtitles = []string{}
+	}
+	subtitles, _ := json.Marshal(ep.Subtitles)
+	if _, err := db.sql.Exec(`INSERT INTO offline_episodes (path, download_id, anime_url, anime_title, season,
+	episode_url, episode_num, episode_title, size, subtitles, added_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (path) DO UPDATE SET
+	download_id = excluded.download_id, anime_url = excluded.anime_url, anime_title = excluded.anime_title,
+	season = excluded.season, episode_url = excluded.episode_url, episode_num = excluded.episode_num,
+	episode_title = excluded.episode_title, size = excluded.size, subtitles = excluded.subtitles`,
+		ep.Path, ep.DownloadID, ep.AnimeURL, ep.AnimeTitle, ep.Season, ep.EpisodeURL, ep.EpisodeNum,
+		ep.EpisodeTitle, ep.Size, string(subtitles), time.Now().Format(time.RFC3339)); err != nil {
+		return nil, err
+	}
+	return db.queryOfflineEpisode(`WHERE path = ?`, ep.Path)
+}
+
+// GetOfflineEpisode retorna um episódio offline pelo ID (nil se não existir)
+func (db *DB) GetOfflineEpisode(id int64) (*OfflineEpisode, error) {
+	return db.queryOfflineEpisode(`WHERE id = ?`, id)
+}
+
+// OfflineEpisodeByURL retorna a cópia offline de um episódio (nil se não existir)
+func (db *DB) OfflineEpisodeByURL(episodeURL string) (*OfflineEpisode, error) {
+	if episodeURL == "" {
+		return nil, nil
+	}
+	return db.queryOfflineEpisode(`WHERE episode_url = ? ORDER BY id DESC LIMIT 1`, episodeURL)
+}
+
+// OfflineEpisodes retorna os episódios offline ordenados por anime, temporada e episódio
+func (db *DB) OfflineEpisodes() ([]OfflineEpisode, error) {
+	rows, err := db.sql.Query(`SELECT ` + offlineColumns + ` FROM offline_episodes
+ORDER BY anime_title COLLATE NOCASE, anime_url, season, episode_num, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	episodes := []OfflineEpisode{}
+	for rows.Next() {
+		ep, err := scanOfflineEpisode(rows)
+		if err != nil {
+			return nil, err
+		}
+		episodes = append(episodes, *ep)
+	}
+	return episodes, rows.Err()
+}
+
+// MarkOfflineWatched registra que a cópia offline do episódio foi assistida até o fim
+func (db *DB) MarkOfflineWatched(episodeURL string) error {
+	_, err := db.sql.Exec(`UPDATE offline_episodes SET watched_at = ? WHERE episode_url = ? AND watched_at = ''`,
+		time.Now().Format(time.RFC3339), episodeURL)
+	return err
+}
+
+// DeleteOfflineEpisode remove um episódio do índice (o arquivo fica a cargo de quem chama)
+func (db *DB) DeleteOfflineEpisode(id int64) error {
+	_, err := db.sql.Exec(`DELETE FROM offline_episodes WHERE id = ?`, id)
+	return err
+}
+
+func (db *DB) queryOfflineEpisode(where string, args ...interface{}) (*OfflineEpisode, error) {
+	ep, err := scanOfflineEpisode(db.sql.QueryRow(`SELECT `+offlineColumns+` FROM offline_episodes `+where, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return ep, err
+}
+
+func scanOfflineEpisode(row scanner) (*OfflineEpisode, error) {
+	var ep OfflineEpisode
+	var subtitles string
+	if err := row.Scan(&ep.ID, &ep.Path, &ep.DownloadID, &ep.AnimeURL, &ep.AnimeTitle, &ep.Season, &ep.EpisodeURL,
+		&ep.EpisodeNum, &ep.EpisodeTitle, &ep.Size, &subtitles, &ep.AddedAt, &ep.WatchedAt); err != nil {
+		return nil, err
+	}
+	json.Unmarshal([]byte(subtitles), &ep.Subtitles)
+	if ep.Subtitles == nil {
+		ep.Subtitles = []string{}
+	}
+	return &ep, nil
+}
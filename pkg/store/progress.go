@@ -0,0 +1,130 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	// CompletedProgress é a porcentagem a partir da qual o episódio conta como assistido
+	CompletedProgress = 90
+	// minResumePosition evita retomar episódios que mal começaram (segundos)
+	minResumePosition = 10
+)
+
+// IsCompleted indica se o episódio foi assistido até o fim (ou quase)
+func (ep WatchedEpisode) IsCompleted() bool {
+	return ep.Progress >= CompletedProgress
+}
+
+// ResumePosition retorna de onde o episódio deve continuar (0 = do início)
+func (ep WatchedEpisode) ResumePosition() float64 {
+	if ep.IsCompleted() || ep.Position < minResumePosition {
+		return 0
+	}
+	return ep.Position
+}
+
+// ProgressPercent converte posição/duração em porcentagem (0-100)
+func ProgressPercent(position, duration float64) int {
+	if duration <= 0 {
+		return 0
+	}
+	p := int(math.Round(position / duration * 100))
+	if p > 100 {
+		return 100
+	}
+	if p < 0 {
+		return 0
+	}
+	return p
+}
+
+// UpdateWatchPosition grava a posição atual de um episódio já registrado no histórico.
+// Retorna sql.ErrNoRows se o episódio ainda não existe.
+func (db *DB) UpdateWatchPosition(episodeURL string, position, duration float64) error {
+	res, err := db.sql.Exec(`UPDATE watched_episodes
+SET position = ?, duration = ?, progress = ?, watched_at = ?
+WHERE episode_url = ?`,
+		position, duration, ProgressPercent(position, duration), time.Now().Format(time.RFC3339), episodeURL)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetWatchedEpisode retorna o registro de um episódio, ou nil se nunca foi assistido
+func (db *DB) GetWatchedEpisode(episodeURL string) (*WatchedEpisode, error) {
+	row := db.sql.QueryRow(`SELECT `+watchedColumns+` FROM watched_episodes WHERE episode_url = ?`, episodeURL)
+	ep, err := scanWatchedEpisode(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ep, nil
+}
+
+// LastWatchedPerAnime retorna o episódio mais recente de cada anime, do mais recente ao mais antigo
+func (db *DB) LastWatchedPerAnime(limit int) ([]WatchedEpisode, error) {
+	query := `SELECT ` + watchedColumns + ` FROM (
+	SELECT *, ROW_NUMBER() OVER (PARTITION BY anime_url ORDER BY watched_at DESC, episode_num DESC) AS rn
+	FROM watched_episodes
+	WHERE anime_url != ''
+) WHERE rn = 1
+ORDER BY watched_at DESC`
+	args := []interface{}{}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := db.sql.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanWatchedEpisodes(rows)
+}
+
+// UpdateWatchPosition grava a posição atual de um episódio no banco padrão
+func UpdateWatchPosition(episodeURL string, position, duration float64) error {
+	db, err := Default()
+	if err != nil {
+		return err
+	}
+	return db.UpdateWatchPosition(episodeURL, position, duration)
+}
+
+// GetWatchedEpisode busca um episódio no banco padrão
+func GetWatchedEpisode(episodeURL string) (*WatchedEpisode, error) {
+	db, err := Default()
+	if err != nil {
+		return nil, err
+	}
+	return db.GetWatchedEpisode(episodeURL)
+}
+
+// RecentWatchedEpisodes retorna os episódios mais recentes do banco padrão
+func RecentWatchedEpisodes(limit int) ([]WatchedEpisode, error) {
+	db, err := Default()
+	if err != nil {
+		return nil, err
+	}
+	return db.RecentWatchedEpisodes(limit)
+}
+
+// LastWatchedPerAnime retorna o último episódio de cada anime no banco padrão
+func LastWatchedPerAnime(limit int) ([]WatchedEpisode, error) {
+	db, err := Default()
+	if err != nil {
+		return nil, err
+	}
+	return db.LastWatchedPerAnime(limit)
+}
@@ -0,0 +1,110 @@
+package store
+
+// SourceHealth é o estado persistido da saúde de uma fonte de streaming.
+// Os horários são RFC3339 em UTC (vazio = nunca).
+type SourceHealth struct {
+	Source        string
+	Score         float64 // Média móvel de sucesso (0 a 1) medida em ScoreAt
+	ScoreAt       string
+	FailCount     int // Falhas seguidas desde o último sucesso
+	CooldownUntil string
+	LastError     string
+	LastFailureAt string
+	LastSuccessAt string
+}
+
+// SourceHealthHour conta os resultados de uma fonte em uma hora
+type SourceHealthHour struct {
+	Source    string
+	Hour      string // Início da hora, RFC3339 em UTC
+	Successes int
+	Failures  int
+}
+
+const upsertSourceHealth = `INSERT INTO source_health (source, score, score_at, fail_count, cooldown_until,
+	last_error, last_failure_at, last_success_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (source) DO UPDATE SET
+	score = excluded.score, score_at = excluded.score_at, fail_count = excluded.fail_count,
+	cooldown_until = excluded.cooldown_until, last_error = excluded.last_error,
+	last_failure_at = excluded.last_failure_at, last_success_at = excluded.last_success_at`
+
+// SaveSourceHealth grava o estado de uma fonte
+func (db *DB) SaveSourceHealth(h SourceHealth) error {
+	_, err := db.sql.Exec(upsertSourceHealth, h.Source, h.Score, h.ScoreAt, h.FailCount, h.CooldownUntil,
+		h.LastError, h.LastFailureAt, h.LastSuccessAt)
+	return err
+}
+
+// RecordSourceHealth grava o estado de uma fonte e conta o resultado na hora indicada
+func (db *DB) RecordSourceHealth(h SourceHealth, hour string, success bool) error {
+	tx, err := db.sql.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(upsertSourceHealth, h.Source, h.Score, h.ScoreAt, h.FailCount, h.CooldownUntil,
+		h.LastError, h.LastFailureAt, h.LastSuccessAt); err != nil {
+		return err
+	}
+
+	successes, failures := 0, 1
+	if success {
+		successes, failures = 1, 0
+	}
+	if _, err := tx.Exec(`INSERT INTO source_health_hours (source, hour, successes, failures) VALUES (?, ?, ?, ?)
+ON CONFLICT (source, hour) DO UPDATE SET
+	successes = successes + excluded.successes, failures = failures + excluded.failures`,
+		h.Source, hour, successes, failures); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SourceHealthStates retorna o estado salvo de todas as fontes
+func (db *DB) SourceHealthStates() ([]SourceHealth, error) {
+	rows, err := db.sql.Query(`SELECT source, score, score_at, fail_count, cooldown_until, last_error,
+	last_failure_at, last_success_at FROM source_health ORDER BY source`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := []SourceHealth{}
+	for rows.Next() {
+		var h SourceHealth
+		if err := rows.Scan(&h.Source, &h.Score, &h.ScoreAt, &h.FailCount, &h.CooldownUntil, &h.LastError,
+			&h.LastFailureAt, &h.LastSuccessAt); err != nil {
+			return nil, err
+		}
+		states = append(states, h)
+	}
+	return states, rows.Err()
+}
+
+// SourceHealthHours retorna as contagens por hora a partir de since (RFC3339 em UTC), da mais antiga à mais recente
+func (db *DB) SourceHealthHours(since string) ([]SourceHealthHour, error) {
+	rows, err := db.sql.Query(`SELECT source, hour, successes, failures FROM source_health_hours
+WHERE hour >= ? ORDER BY hour, source`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := []SourceHealthHour{}
+	for rows.Next() {
+		var h SourceHealthHour
+		if err := rows.Scan(&h.Source, &h.Hour, &h.Successes, &h.Failures); err != nil {
+			return nil, err
+		}
+		hours = append(hours, h)
+	}
+	return hours, rows.Err()
+}
+
+// PruneSourceHealthHours apaga as contagens anteriores a before (RFC3339 em UTC)
+func (db *DB) PruneSourceHealthHours(before string) error {
+	_, err := db.sql.Exec(`DELETE FROM source_health_hours WHERE hour < ?`, before)
+	return err
+}
@@ -0,0 +1,378 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// dbFileName é o nome do banco SQLite dentro do diretório de dados
+const dbFileName = "goanime.db"
+
+// Nomes das listas persistidas na tabela saved_anime
+const (
+	listHistory   = "history"
+	listFavorites = "favorites"
+)
+
+// WatchHistoryLimit é a quantidade de episódios recentes mantidos em memória.
+// O banco guarda o histórico completo.
+const WatchHistoryLimit = 50
+
+// DB encapsula a conexão SQLite com os dados do usuário
+type DB struct {
+	sql  *sql.DB
+	path string
+}
+
+var (
+	defaultDB    *DB
+	defaultDBErr error
+	dbOnce       sync.Once
+)
+
+// DefaultPath retorna o caminho padrão do banco (~/.config/GoAnime/goanime.db)
+func DefaultPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return dbFileName
+	}
+	return filepath.Join(configDir, "GoAnime", dbFileName)
+}
+
+// Default retorna o banco singleton, abrindo-o e importando o JSON legado na primeira chamada
+func Default() (*DB, error) {
+	dbOnce.Do(func() {
+		defaultDB, defaultDBErr = Open(DefaultPath())
+		if defaultDBErr != nil {
+			fmt.Printf("[Store] Erro ao abrir banco SQLite: %v\n", defaultDBErr)
+			return
+		}
+		if err := defaultDB.ImportLegacyJSON(legacyJSONFile); err != nil {
+			fmt.Printf("[Store] Erro ao importar %s: %v\n", legacyJSONFile, err)
+		}
+	})
+	return defaultDB, defaultDBErr
+}
+
+// Open abre (ou cria) o banco no caminho indicado e aplica as migrações pendentes
+func Open(path string) (*DB, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("erro ao criar diretório de dados: %w", err)
+		}
+	}
+
+	conn, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000&_journal_mode=WAL&_foreign_keys=on")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite serializa escritas; uma conexão evita "database is locked"
+	conn.SetMaxOpenConns(1)
+
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := migrate(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &DB{sql: conn, path: path}, nil
+}
+
+// Close fecha a conexão com o banco
+func (db *DB) Close() error {
+	return db.sql.Close()
+}
+
+// Path retorna o caminho do arquivo do banco
+func (db *DB) Path() string {
+	return db.path
+}
+
+// LoadUser carrega o usuário salvo. Retorna (nil, nil) se ainda não existe usuário.
+func (db *DB) LoadUser() (*UserData, error) {
+	var user UserData
+	var settings string
+	err := db.sql.QueryRow(`SELECT username, avatar, mpv_path, default_quality, settings FROM user WHERE id = 1`).
+		Scan(&user.Username, &user.Avatar, &user.MPVPath, &user.DefaultQuality, &settings)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(settings), &user.Settings); err != nil {
+		return nil, fmt.Errorf("configurações corrompidas: %w", err)
+	}
+	if user.Settings.ContentLanguage == "" {
+		user.Settings.ContentLanguage = "all"
+	}
+	if user.Settings.UpdateCheckHours == 0 {
+		user.Settings.UpdateCheckHours = DefaultUpdateCheckHours
+	}
+	if user.Settings.MaxDownloads <= 0 {
+		user.Settings.MaxDownloads = DefaultMaxDownloads
+	}
+	if user.Settings.VideoCacheMB == 0 {
+		user.Settings.VideoCacheMB = DefaultVideoCacheMB
+	}
+	if user.Settings.VideoReadAhead == 0 {
+		user.Settings.VideoReadAhead = DefaultVideoReadAhead
+	}
+
+	if user.History, err = db.loadList(listHistory); err != nil {
+		return nil, err
+	}
+	if user.Favorites, err = db.loadList(listFavorites); err != nil {
+		return nil, err
+	}
+	if user.WatchHistory, err = db.RecentWatchedEpisodes(WatchHistoryLimit); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// SaveUser grava o usuário inteiro em uma única transação.
+// O histórico de episódios é apenas acrescentado/atualizado, nunca apagado.
+func (db *DB) SaveUser(user *UserData) error {
+	if user == nil {
+		return errors.New("usuário nulo")
+	}
+
+	tx, err := db.sql.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveUserTx(tx, user); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ExportUser retorna uma cópia de user com o histórico de episódios completo do banco
+// (LoadUser só carrega os WatchHistoryLimit mais recentes), para a exportação não perder nada
+func (db *DB) ExportUser(user *UserData) (*UserData, error) {
+	if user == nil {
+		return nil, errors.New("usuário nulo")
+	}
+	history, err := db.RecentWatchedEpisodes(0)
+	if err != nil {
+		return nil, err
+	}
+	export := *user
+	export.WatchHistory = history
+	return &export, nil
+}
+
+// ReplaceUser substitui todos os dados (usado na importação), incluindo o histórico completo
+func (db *DB) ReplaceUser(user *UserData) error {
+	if user == nil {
+		return errors.New("usuário nulo")
+	}
+
+	tx, err := db.sql.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM watched_episodes`); err != nil {
+		return err
+	}
+	if err := saveUserTx(tx, user); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func saveUserTx(tx *sql.Tx, user *UserData) error {
+	settings, err := json.Marshal(user.Settings)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`INSERT INTO user (id, username, avatar, mpv_path, default_quality, settings)
+VALUES (1, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+	username = excluded.username,
+	avatar = excluded.avatar,
+	mpv_path = excluded.mpv_path,
+	default_quality = excluded.default_quality,
+	settings = excluded.settings`,
+		user.Username, user.Avatar, user.MPVPath, user.DefaultQuality, string(settings)); err != nil {
+		return fmt.Errorf("erro ao salvar usuário: %w", err)
+	}
+
+	if err := saveListTx(tx, listHistory, user.History); err != nil {
+		return err
+	}
+	if err := saveListTx(tx, listFavorites, user.Favorites); err != nil {
+		return err
+	}
+	if err := ensureLibraryEntries(tx, user.Favorites); err != nil {
+		return err
+	}
+
+	for _, ep := range user.WatchHistory {
+		if err := upsertWatchedEpisode(tx, ep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func saveListTx(tx *sql.Tx, list string, animes []SavedAnime) error {
+	if _, err := tx.Exec(`DELETE FROM saved_anime WHERE list = ?`, list); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO saved_anime (list, position, title, image, url, source, sources) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, anime := range animes {
+		sources, err := json.Marshal(anime.Sources)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.Exec(list, i, anime.Title, anime.Image, anime.URL, anime.Source, string(sources)); err != nil {
+			return fmt.Errorf("erro ao salvar lista %s: %w", list, err)
+		}
+	}
+	return nil
+}
+
+func (db *DB) loadList(list string) ([]SavedAnime, error) {
+	rows, err := db.sql.Query(`SELECT title, image, url, source, sources FROM saved_anime WHERE list = ? ORDER BY position`, list)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	animes := []SavedAnime{}
+	for rows.Next() {
+		var anime SavedAnime
+		var sources string
+		if err := rows.Scan(&anime.Title, &anime.Image, &anime.URL, &anime.Source, &sources); err != nil {
+			return nil, err
+		}
+		if sources != "" && sources != "null" {
+			json.Unmarshal([]byte(sources), &anime.Sources)
+		}
+		animes = append(animes, anime)
+	}
+	return animes, rows.Err()
+}
+
+// execer é satisfeito por *sql.DB e *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func upsertWatchedEpisode(e execer, ep WatchedEpisode) error {
+	// Progresso/posição zerados não sobrescrevem os salvos: o frontend registra
+	// o episódio no histórico ao abrir, antes de saber onde parou
+	_, err := e.Exec(`INSERT INTO watched_episodes
+	(episode_url, anime_title, anime_image, anime_url, episode_title, episode_num, watched_at, progress, position, duration)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (episode_url) DO UPDATE SET
+	anime_title = excluded.anime_title,
+	anime_image = excluded.anime_image,
+	anime_url = excluded.anime_url,
+	episode_title = excluded.episode_title,
+	episode_num = excluded.episode_num,
+	watched_at = excluded.watched_at,
+	progress = CASE WHEN excluded.progress > 0 THEN excluded.progress ELSE watched_episodes.progress END,
+	position = CASE WHEN excluded.position > 0 THEN excluded.position ELSE watched_episodes.position END,
+	duration = CASE WHEN excluded.duration > 0 THEN excluded.duration ELSE watched_episodes.duration END`,
+		ep.EpisodeURL, ep.AnimeTitle, ep.AnimeImage, ep.AnimeURL, ep.EpisodeTitle, ep.EpisodeNum, ep.WatchedAt,
+		ep.Progress, ep.Position, ep.Duration)
+	if err != nil {
+		return fmt.Errorf("erro ao salvar episódio assistido: %w", err)
+	}
+	return nil
+}
+
+// SaveWatchedEpisode grava (ou atualiza) um único episódio do histórico
+func (db *DB) SaveWatchedEpisode(ep WatchedEpisode) error {
+	return upsertWatchedEpisode(db.sql, ep)
+}
+
+// RecentWatchedEpisodes retorna os episódios assistidos mais recentes primeiro.
+// limit <= 0 retorna o histórico completo.
+func (db *DB) RecentWatchedEpisodes(limit int) ([]WatchedEpisode, error) {
+	query := `SELECT ` + watchedColumns + ` FROM watched_episodes ORDER BY watched_at DESC`
+	args := []interface{}{}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := db.sql.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWatchedEpisodes(rows)
+}
+
+// watchedColumns são as colunas lidas por scanWatchedEpisode, na mesma ordem
+const watchedColumns = `episode_url, anime_title, anime_image, anime_url, episode_title, episode_num,
+	watched_at, progress, position, duration`
+
+// scanner é satisfeito por *sql.Row e *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWatchedEpisode(row scanner) (WatchedEpisode, error) {
+	var ep WatchedEpisode
+	err := row.Scan(&ep.EpisodeURL, &ep.AnimeTitle, &ep.AnimeImage, &ep.AnimeURL, &ep.EpisodeTitle,
+		&ep.EpisodeNum, &ep.WatchedAt, &ep.Progress, &ep.Position, &ep.Duration)
+	return ep, err
+}
+
+func scanWatchedEpisodes(rows *sql.Rows) ([]WatchedEpisode, error) {
+	episodes := []WatchedEpisode{}
+	for rows.Next() {
+		ep, err := scanWatchedEpisode(rows)
+		if err != nil {
+			return nil, err
+		}
+		episodes = append(episodes, ep)
+	}
+	return episodes, rows.Err()
+}
+
+// getMeta lê um valor da tabela meta ("" se não existir)
+func (db *DB) getMeta(key string) (string, error) {
+	var value string
+	err := db.sql.QueryRow(`SELECT value FROM meta WHERE key = ?`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return value, err
+}
+
+// setMeta grava um valor na tabela meta
+func (db *DB) setMeta(key, value string) error {
+	_, err := db.sql.Exec(`INSERT INTO meta (key, value) VALUES (?, ?)
+ON CONFLICT (key) DO UPDATE SET value = excluded.value`, key, value)
+	return err
+}
@@ -0,0 +1,332 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDB_SaveAndLoadUser(t *testing.T) {
+	db := OpenTemp(t)
+
+	if user, err := db.LoadUser(); err != nil || user != nil {
+		t.Fatalf("LoadUser() on empty db = %v, %v; want nil, nil", user, err)
+	}
+
+	user := &UserData{
+		Username: "thiago",
+		Avatar:   "avatar1.png",
+		Favorites: []SavedAnime{
+			{Title: "Frieren", URL: "https://a/frieren", Source: "AnimeFire",
+				Sources: []AnimeSource{{Name: "AnimeFire", Language: "pt-BR", URL: "https://a/frieren"}}},
+			{Title: "Dandadan", URL: "https://a/dandadan"},
+		},
+		WatchHistory: []WatchedEpisode{
+			{AnimeTitle: "Frieren", EpisodeURL: "https://a/frieren/1", EpisodeNum: 1, WatchedAt: "2026-01-01T10:00:00Z", Progress: 100},
+		},
+		Settings: GetDefaultSettings(),
+		MPVPath:  "/usr/bin/mpv",
+	}
+	if err := db.SaveUser(user); err != nil {
+		t.Fatalf("SaveUser() error = %v", err)
+	}
+
+	if err := db.SaveWatchedEpisode(WatchedEpisode{AnimeTitle: "Frieren", EpisodeURL: "https://a/frieren/2",
+		EpisodeNum: 2, WatchedAt: "2026-01-02T10:00:00Z", Progress: 40}); err != nil {
+		t.Fatalf("SaveWatchedEpisode() error = %v", err)
+	}
+
+	got, err := db.LoadUser()
+	if err != nil {
+		t.Fatalf("LoadUser() error = %v", err)
+	}
+	if got.Username != "thiago" || got.MPVPath != "/usr/bin/mpv" {
+		t.Errorf("LoadUser() user = %+v", got)
+	}
+	if len(got.Favorites) != 2 || got.Favorites[0].Title != "Frieren" || got.Favorites[1].Title != "Dandadan" {
+		t.Errorf("LoadUser() favorites = %+v; want order preserved", got.Favorites)
+	}
+	if len(got.Favorites[0].Sources) != 1 || got.Favorites[0].Sources[0].Language != "pt-BR" {
+		t.Errorf("LoadUser() sources = %+v", got.Favorites[0].Sources)
+	}
+	if len(got.WatchHistory) != 2 || got.WatchHistory[0].EpisodeNum != 2 {
+		t.Errorf("LoadUser() watch history = %+v; want most recent first", got.WatchHistory)
+	}
+	if got.Settings.SeedingMaxCPU != 30 {
+		t.Errorf("LoadUser() settings = %+v", got.Settings)
+	}
+}
+
+func TestDB_ExportImportKeepsFullHistory(t *testing.T) {
+	db := OpenTemp(t)
+	if err := db.SaveUser(&UserData{Username: "thiago", Settings: GetDefaultSettings()}); err != nil {
+		t.Fatalf("SaveUser() error = %v", err)
+	}
+	total := WatchHistoryLimit + 20
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	for i := 1; i <= total; i++ {
+		if err := db.SaveWatchedEpisode(WatchedEpisode{AnimeTitle: "One Piece", EpisodeURL: fmt.Sprintf("https://a/op/%d", i),
+			EpisodeNum: i, WatchedAt: start.Add(time.Duration(i) * time.Minute).Format(time.RFC3339), Position: float64(i * 10), Duration: 1440}); err != nil {
+			t.Fatalf("SaveWatchedEpisode(%d) error = %v", i, err)
+		}
+	}
+
+	// Mesmo fluxo de ExportUserData/ImportUserData: usuário carregado, JSON e substituição
+	user, err := db.LoadUser()
+	if err != nil || len(user.WatchHistory) != WatchHistoryLimit {
+		t.Fatalf("LoadUser() = %d episodes, %v; want %d", len(user.WatchHistory), err, WatchHistoryLimit)
+	}
+	export, err := db.ExportUser(user)
+	if err != nil {
+		t.Fatalf("ExportUser() error = %v", err)
+	}
+	data, _ := json.Marshal(export)
+	var imported UserData
+	if err := json.Unmarshal(data, &imported); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if err := db.ReplaceUser(&imported); err != nil {
+		t.Fatalf("ReplaceUser() error = %v", err)
+	}
+
+	history, err := db.RecentWatchedEpisodes(0)
+	if err != nil || len(history) != total {
+		t.Fatalf("history after import = %d episodes, %v; want %d", len(history), err, total)
+	}
+	oldest := history[len(history)-1]
+	if oldest.EpisodeNum != 1 || oldest.Position != 10 || oldest.Duration != 1440 {
+		t.Errorf("oldest episode after import = %+v; want its position kept", oldest)
+	}
+}
+
+func TestDB_MigrateIsIdempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	for i := 0; i < 2; i++ {
+		db, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open() #%d error = %v", i, err)
+		}
+		db.Close()
+	}
+}
+
+func TestDB_ImportLegacyJSON(t *testing.T) {
+	db := OpenTemp(t)
+	dir := t.TempDir()
+
+	legacy := filepath.Join(dir, "goanime_user.json")
+	os.WriteFile(legacy, []byte(`{
+  "username": "legacy",
+  "favorites": [{"Title": "One Piece", "URL": "https://a/op"}],
+  "watch_history": [{"anime_title": "One Piece", "episode_url": "https://a/op/1", "episode_num": 1}],
+  "settings": {"default_quality": "1080p"}
+}`), 0600)
+
+	if err := db.ImportLegacyJSON(legacy); err != nil {
+		t.Fatalf("ImportLegacyJSON() error = %v", err)
+	}
+
+	user, err := db.LoadUser()
+	if err != nil || user == nil {
+		t.Fatalf("LoadUser() = %v, %v", user, err)
+	}
+	if user.Username != "legacy" || len(user.Favorites) != 1 || len(user.WatchHistory) != 1 {
+		t.Errorf("imported user = %+v", user)
+	}
+	if user.Settings.ContentLanguage != "all" {
+		t.Errorf("ContentLanguage = %q; want default \"all\"", user.Settings.ContentLanguage)
+	}
+	if _, err := os.Stat(legacy + ".migrated"); err != nil {
+		t.Errorf("legacy file not renamed: %v", err)
+	}
+
+	// Um segundo arquivo não deve ser importado de novo
+	os.WriteFile(legacy, []byte(`{"username": "other"}`), 0600)
+	if err := db.ImportLegacyJSON(legacy); err != nil {
+		t.Fatalf("second ImportLegacyJSON() error = %v", err)
+	}
+	if user, _ := db.LoadUser(); user.Username != "legacy" {
+		t.Errorf("username after second import = %q; want legacy", user.Username)
+	}
+}
+
+func TestDB_ImportLegacyJSONCorrupt(t *testing.T) {
+	db := OpenTemp(t)
+	legacy := filepath.Join(t.TempDir(), "goanime_user.json")
+	os.WriteFile(legacy, []byte(`{"username": `), 0600)
+
+	if err := db.ImportLegacyJSON(legacy); err == nil {
+		t.Fatal("ImportLegacyJSON() on corrupt file: want error")
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("corrupt file should be moved aside, stat err = %v", err)
+	}
+	matches, _ := filepath.Glob(legacy + ".corrupt-*")
+	if len(matches) != 1 {
+		t.Errorf("corrupt backup files = %v", matches)
+	}
+}
+
+func TestDB_WatchPositionAndLastPerAnime(t *testing.T) {
+	db := OpenTemp(t)
+
+	eps := []WatchedEpisode{
+		{AnimeURL: "https://a/frieren", EpisodeURL: "https://a/frieren/1", EpisodeNum: 1, WatchedAt: "2026-01-01T10:00:00Z", Progress: 100},
+		{AnimeURL: "https://a/frieren", EpisodeURL: "https://a/frieren/2", EpisodeNum: 2, WatchedAt: "2026-01-02T10:00:00Z"},
+		{AnimeURL: "https://a/op", EpisodeURL: "https://a/op/7", EpisodeNum: 7, WatchedAt: "2026-01-01T12:00:00Z", Progress: 95},
+	}
+	for _, ep := range eps {
+		if err := db.SaveWatchedEpisode(ep); err != nil {
+			t.Fatalf("SaveWatchedEpisode() error = %v", err)
+		}
+	}
+
+	if err := db.UpdateWatchPosition("https://a/frieren/2", 600, 1440); err != nil {
+		t.Fatalf("UpdateWatchPosition() error = %v", err)
+	}
+	if err := db.UpdateWatchPosition("https://a/missing", 1, 2); err == nil {
+		t.Error("UpdateWatchPosition() on unknown episode: want error")
+	}
+
+	// Registrar o episódio de novo (sem posição) não pode zerar o progresso
+	eps[1].WatchedAt = "2026-01-03T10:00:00Z"
+	if err := db.SaveWatchedEpisode(eps[1]); err != nil {
+		t.Fatalf("SaveWatchedEpisode() error = %v", err)
+	}
+
+	ep, err := db.GetWatchedEpisode("https://a/frieren/2")
+	if err != nil || ep == nil {
+		t.Fatalf("GetWatchedEpisode() = %v, %v", ep, err)
+	}
+	if ep.Position != 600 || ep.Duration != 1440 || ep.Progress != 42 {
+		t.Errorf("episode = %+v; want position 600, duration 1440, progress 42", ep)
+	}
+	if got := ep.ResumePosition(); got != 600 {
+		t.Errorf("ResumePosition() = %v; want 600", got)
+	}
+
+	last, err := db.LastWatchedPerAnime(0)
+	if err != nil {
+		t.Fatalf("LastWatchedPerAnime() error = %v", err)
+	}
+	if len(last) != 2 {
+		t.Fatalf("LastWatchedPerAnime() = %d items; want 2", len(last))
+	}
+	if last[0].EpisodeURL != "https://a/frieren/2" || last[1].EpisodeURL != "https://a/op/7" {
+		t.Errorf("LastWatchedPerAnime() order = %s, %s", last[0].EpisodeURL, last[1].EpisodeURL)
+	}
+	if !last[1].IsCompleted() || last[1].ResumePosition() != 0 {
+		t.Errorf("completed episode should resume from start: %+v", last[1])
+	}
+}
+
+func TestDB_Library(t *testing.T) {
+	db := OpenTemp(t)
+
+	user := &UserData{
+		Username: "thiago",
+		Favorites: []SavedAnime{
+			{Title: "Frieren", URL: "https://a/frieren", Source: "AnimeFire"},
+			{Title: "Dandadan", URL: "https://b/dandadan", Source: "AllAnime",
+				Sources: []AnimeSource{{Name: "AnimeFire", URL: "https://a/dandadan"}}},
+			{Title: "Naruto", URL: "https://b/naruto", Source: "AllAnime"},
+		},
+		Settings: GetDefaultSettings(),
+	}
+	if err := db.SaveUser(user); err != nil {
+		t.Fatalf("SaveUser() error = %v", err)
+	}
+
+	if err := db.SetLibraryStatus("https://a/frieren", "assistindo"); err == nil {
+		t.Error("SetLibraryStatus() with unknown status: want error")
+	}
+	if err := db.SetLibraryStatus("https://a/missing", StatusDropped); !errors.Is(err, ErrNotInLibrary) {
+		t.Errorf("SetLibraryStatus() on missing entry = %v; want ErrNotInLibrary", err)
+	}
+	if err := db.SetLibraryScore("https://a/frieren", 11); err == nil {
+		t.Error("SetLibraryScore(11): want error")
+	}
+	if err := db.SetLibraryScore("https://a/frieren", 9.5); err != nil {
+		t.Fatalf("SetLibraryScore() error = %v", err)
+	}
+	if err := db.SetLibraryStatus("https://b/naruto", StatusCompleted); err != nil {
+		t.Fatalf("SetLibraryStatus() error = %v", err)
+	}
+	if err := db.MarkWatching("https://a/frieren"); err != nil {
+		t.Fatalf("MarkWatching() error = %v", err)
+	}
+	if err := db.MarkWatching("https://b/naruto"); err != nil {
+		t.Fatalf("MarkWatching() error = %v", err)
+	}
+
+	shonen, _ := db.CreateCategory("Shonen")
+	fav, _ := db.CreateCategory("Preferidos")
+	if _, err := db.CreateCategory("Shonen"); err == nil {
+		t.Error("CreateCategory() duplicated name: want error")
+	}
+	if err := db.ReorderCategories([]int64{fav.ID, shonen.ID}); err != nil {
+		t.Fatalf("ReorderCategories() error = %v", err)
+	}
+	if cats, _ := db.Categories(); len(cats) != 2 || cats[0].ID != fav.ID {
+		t.Errorf("Categories() = %+v; want Preferidos first", cats)
+	}
+
+	if err := db.SetEntryCategories([]string{"https://b/dandadan", "https://b/naruto"}, []int64{shonen.ID}); err != nil {
+		t.Fatalf("SetEntryCategories() error = %v", err)
+	}
+
+	check := func(name string, filter LibraryFilter, want ...string) {
+		t.Helper()
+		items, err := db.QueryLibrary(filter)
+		if err != nil {
+			t.Fatalf("%s: QueryLibrary() error = %v", name, err)
+		}
+		var got []string
+		for _, item := range items {
+			got = append(got, item.Title)
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: QueryLibrary() = %v; want %v", name, got, want)
+		}
+	}
+	check("all", LibraryFilter{}, "Frieren", "Dandadan", "Naruto")
+	check("watching", LibraryFilter{Status: StatusWatching}, "Frieren")
+	check("completed", LibraryFilter{Status: StatusCompleted}, "Naruto")
+	check("category", LibraryFilter{CategoryID: shonen.ID}, "Dandadan", "Naruto")
+	check("uncategorized", LibraryFilter{CategoryID: UncategorizedID}, "Frieren")
+	check("source", LibraryFilter{Source: "animefire"}, "Frieren", "Dandadan")
+
+	items, _ := db.QueryLibrary(LibraryFilter{Status: StatusWatching})
+	if len(items) == 1 && items[0].Score != 9.5 {
+		t.Errorf("score = %v; want 9.5", items[0].Score)
+	}
+
+	if err := db.SetLibraryProgress("https://a/frieren", 5); err != nil {
+		t.Fatalf("SetLibraryProgress() error = %v", err)
+	}
+	db.SetLibraryProgress("https://a/frieren", 3) // Não pode diminuir
+	if err := db.LinkAniList("https://a/frieren", 154587); err != nil {
+		t.Fatalf("LinkAniList() error = %v", err)
+	}
+	item, err := db.GetLibraryItem("https://a/frieren")
+	if err != nil || item == nil {
+		t.Fatalf("GetLibraryItem() = %v, %v", item, err)
+	}
+	if item.Progress != 5 || item.AniListID != 154587 || item.Status != StatusWatching {
+		t.Errorf("GetLibraryItem() = %+v; want progress 5, anilist 154587, watching", item)
+	}
+	if item, _ := db.GetLibraryItem("https://a/missing"); item != nil {
+		t.Errorf("GetLibraryItem() on missing entry = %+v; want nil", item)
+	}
+
+	// Apagar a categoria não remove os animes da biblioteca
+	if err := db.DeleteCategory(shonen.ID); err != nil {
+		t.Fatalf("DeleteCategory() error = %v", err)
+	}
+	check("after delete", LibraryFilter{CategoryID: UncategorizedID}, "Frieren", "Dandadan", "Naruto")
+}
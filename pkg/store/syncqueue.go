@@ -0,0 +1,128 @@
+package store
+
+import (
+	"time"
+)
+
+// Estados de um item da fila de sincronização
+const (
+	SyncPending = "pending" // Aguardando envio (ou nova tentativa)
+	SyncFailed  = "failed"  // Desistiu após erro permanente ou tentativas demais
+)
+
+// SyncJob é uma mutação pendente para um serviço externo (tracker, servidor social).
+// Só existe um item por (Kind, Key): enfileirar de novo substitui o payload.
+type SyncJob struct {
+	ID            int64  `json:"id"`
+	Kind          string `json:"kind"`
+	Key           string `json:"key"`
+	Payload       string `json:"payload"`
+	Revision      int    `json:"-"`
+	Attempts      int    `json:"attempts"`
+	State         string `json:"state"`
+	LastError     string `json:"lastError"`
+	NextAttemptAt int64  `json:"nextAttemptAt"` // Unix timestamp
+	CreatedAt     string `json:"createdAt"`
+	UpdatedAt     string `json:"updatedAt"`
+}
+
+const syncJobColumns = `id, kind, key, payload, revision, attempts, state, last_error, next_attempt_at, created_at, updated_at`
+
+// EnqueueSync adiciona uma mutação à fila. Se já houver uma para o mesmo
+// (kind, key), ela é substituída e volta a ser enviada imediatamente.
+func (db *DB) EnqueueSync(kind, key, payload string) error {
+	now := time.Now()
+	_, err := db.sql.Exec(`INSERT INTO sync_queue (kind, key, payload, next_attempt_at, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (kind, key) DO UPDATE SET
+	payload = excluded.payload,
+	revision = revision + 1,
+	attempts = 0,
+	state = 'pending',
+	last_error = '',
+	next_attempt_at = excluded.next_attempt_at,
+	updated_at = excluded.updated_at`,
+		kind, key, payload, now.Unix(), now.Format(time.RFC3339), now.Format(time.RFC3339))
+	return err
+}
+
+// DueSyncJobs retorna os itens pendentes cuja próxima tentativa já venceu
+func (db *DB) DueSyncJobs(now time.Time, limit int) ([]SyncJob, error) {
+	return db.querySyncJobs(`WHERE state = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?`,
+		SyncPending, now.Unix(), limit)
+}
+
+// NextSyncAttempt retorna quando vence o próximo item pendente (zero se a fila estiver vazia)
+func (db *DB) NextSyncAttempt() (time.Time, error) {
+	var next int64
+	err := db.sql.QueryRow(`SELECT COALESCE(MIN(next_attempt_at), 0) FROM sync_queue WHERE state = ?`, SyncPending).Scan(&next)
+	if err != nil || next == 0 {
+		return time.Time{}, err
+	}
+	return time.Unix(next, 0), nil
+}
+
+// SyncJobs retorna todos os itens da fila, do mais antigo ao mais novo
+func (db *DB) SyncJobs() ([]SyncJob, error) {
+	return db.querySyncJobs(`ORDER BY id`)
+}
+
+func (db *DB) querySyncJobs(where string, args ...interface{}) ([]SyncJob, error) {
+	rows, err := db.sql.Query(`SELECT `+syncJobColumns+` FROM sync_queue `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []SyncJob{}
+	for rows.Next() {
+		var j SyncJob
+		if err := rows.Scan(&j.ID, &j.Kind, &j.Key, &j.Payload, &j.Revision, &j.Attempts, &j.State,
+			&j.LastError, &j.NextAttemptAt, &j.CreatedAt, &j.UpdatedAt); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// CompleteSyncJob remove um item enviado com sucesso.
+// Se ele foi substituído durante o envio (revisão diferente), a versão nova continua na fila.
+func (db *DB) CompleteSyncJob(job SyncJob) error {
+	_, err := db.sql.Exec(`DELETE FROM sync_queue WHERE id = ? AND revision = ?`, job.ID, job.Revision)
+	return err
+}
+
+// FailSyncJob registra uma falha e agenda a próxima tentativa (ou marca como falho de vez)
+func (db *DB) FailSyncJob(job SyncJob, errMsg string, next time.Time, permanent bool) error {
+	state := SyncPending
+	if permanent {
+		state = SyncFailed
+	}
+	_, err := db.sql.Exec(`UPDATE sync_queue
+SET attempts = attempts + 1, state = ?, last_error = ?, next_attempt_at = ?, updated_at = ?
+WHERE id = ? AND revision = ?`,
+		state, errMsg, next.Unix(), time.Now().Format(time.RFC3339), job.ID, job.Revision)
+	return err
+}
+
+// RetrySyncJobs antecipa a próxima tentativa dos itens pendentes (kind vazio = todos).
+// Com includeFailed, os itens que já tinham desistido também voltam para a fila.
+func (db *DB) RetrySyncJobs(kind string, includeFailed bool) error {
+	query := `UPDATE sync_queue
+SET next_attempt_at = 0, attempts = CASE WHEN state = 'failed' THEN 0 ELSE attempts END, state = 'pending'
+WHERE (? = '' OR kind = ?) AND `
+	if includeFailed {
+		query += `(state = 'failed' OR attempts > 0)`
+	} else {
+		query += `state = 'pending' AND attempts > 0`
+	}
+	_, err := db.sql.Exec(query, kind, kind)
+	return err
+}
+
+// DeleteSyncJob descarta um item da fila
+func (db *DB) DeleteSyncJob(id int64) error {
+	_, err := db.sql.Exec(`DELETE FROM sync_queue WHERE id = ?`, id)
+	return err
+}
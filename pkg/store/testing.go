@@ -0,0 +1,18 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// OpenTemp abre um banco vazio numa pasta temporária do teste, fechado ao fim dele.
+// Usado pelos testes dos pacotes que gravam no store.
+func OpenTemp(t testing.TB) *DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("store.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
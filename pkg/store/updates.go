@@ -0,0 +1,124 @@
+package store
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultUpdateCheckHours é o intervalo padrão entre verificações de novos episódios
+const DefaultUpdateCheckHours = 6
+
+// LibraryUpdate é um aviso de episódios novos de um anime da biblioteca
+type LibraryUpdate struct {
+	ID          int64  `json:"id"`
+	AnimeURL    string `json:"animeUrl"`
+	Title       string `json:"title"`
+	Image       string `json:"image"`
+	Source      string `json:"source"`
+	EpisodeFrom int    `json:"episodeFrom"` // Primeiro episódio novo
+	EpisodeTo   int    `json:"episodeTo"`   // Último episódio disponível
+	Seen        bool   `json:"seen"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+// UpdateCheckItems retorna as entradas a verificar, das verificadas há mais tempo para as mais
+// recentes. Animes concluídos ou abandonados e os verificados depois de checkedBefore ficam de fora.
+func (db *DB) UpdateCheckItems(checkedBefore time.Time, limit int) ([]LibraryItem, error) {
+	return db.scanLibrary(libraryQuery+` AND l.status NOT IN (?, ?) AND l.checked_at < ?
+ORDER BY l.checked_at, s.position LIMIT ?`,
+		StatusPlanToWatch, listFavorites, StatusCompleted, StatusDropped, checkedBefore.UTC().Format(time.RFC3339), limit)
+}
+
+// RecordEpisodeCount grava quantos episódios a fonte tem agora. Se o número cresceu,
+// registra um aviso no feed e o retorna; a primeira contagem só define a referência.
+func (db *DB) RecordEpisodeCount(animeURL string, count int) (*LibraryUpdate, error) {
+	tx, err := db.sql.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var known int
+	if err := tx.QueryRow(`SELECT known_episodes FROM library_entries WHERE anime_url = ?`, animeURL).Scan(&known); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	// Contagem menor (fonte instável) não baixa a referência
+	if _, err := tx.Exec(`UPDATE library_entries SET known_episodes = MAX(known_episodes, ?), checked_at = ? WHERE anime_url = ?`,
+		count, now, animeURL); err != nil {
+		return nil, err
+	}
+
+	var update *LibraryUpdate
+	if known > 0 && count > known {
+		res, err := tx.Exec(`INSERT INTO library_updates (anime_url, episode_from, episode_to, created_at) VALUES (?, ?, ?, ?)`,
+			animeURL, known+1, count, now)
+		if err != nil {
+			return nil, err
+		}
+		update = &LibraryUpdate{AnimeURL: animeURL, EpisodeFrom: known + 1, EpisodeTo: count, CreatedAt: now}
+		update.ID, _ = res.LastInsertId()
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return update, nil
+}
+
+// LibraryUpdates retorna o feed de novos episódios, do mais recente ao mais antigo
+func (db *DB) LibraryUpdates(unseenOnly bool, limit int) ([]LibraryUpdate, error) {
+	query := `SELECT u.id, u.anime_url, COALESCE(s.title, ''), COALESCE(s.image, ''), COALESCE(s.source, ''),
+	u.episode_from, u.episode_to, u.seen, u.created_at
+FROM library_updates u
+LEFT JOIN saved_anime s ON s.url = u.anime_url AND s.list = ?`
+	if unseenOnly {
+		query += ` WHERE u.seen = 0`
+	}
+	query += ` ORDER BY u.created_at DESC, u.id DESC LIMIT ?`
+
+	rows, err := db.sql.Query(query, listFavorites, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	updates := []LibraryUpdate{}
+	for rows.Next() {
+		var u LibraryUpdate
+		if err := rows.Scan(&u.ID, &u.AnimeURL, &u.Title, &u.Image, &u.Source,
+			&u.EpisodeFrom, &u.EpisodeTo, &u.Seen, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		updates = append(updates, u)
+	}
+	return updates, rows.Err()
+}
+
+// UnseenUpdateCount retorna quantos animes têm avisos não vistos (para o badge)
+func (db *DB) UnseenUpdateCount() (int, error) {
+	var n int
+	err := db.sql.QueryRow(`SELECT COUNT(DISTINCT anime_url) FROM library_updates WHERE seen = 0`).Scan(&n)
+	return n, err
+}
+
+// MarkUpdatesSeen marca avisos como vistos (sem IDs, marca todos)
+func (db *DB) MarkUpdatesSeen(ids []int64) error {
+	if len(ids) == 0 {
+		_, err := db.sql.Exec(`UPDATE library_updates SET seen = 1 WHERE seen = 0`)
+		return err
+	}
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	_, err := db.sql.Exec(`UPDATE library_updates SET seen = 1 WHERE id IN (?`+strings.Repeat(", ?", len(ids)-1)+`)`, args...)
+	return err
+}
+
+// MarkAnimeUpdatesSeen marca como vistos os avisos de um anime (ao abrir a página dele)
+func (db *DB) MarkAnimeUpdatesSeen(animeURL string) error {
+	_, err := db.sql.Exec(`UPDATE library_updates SET seen = 1 WHERE anime_url = ? AND seen = 0`, animeURL)
+	return err
+}
@@ -0,0 +1,325 @@
+package subtitles
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	assPos     = regexp.MustCompile(`^pos\(\s*(-?[\d.]+)\s*,\s*(-?[\d.]+)\s*\)`)
+	assToggle  = regexp.MustCompile(`^([biu])(\d*)$`)
+	assNumeric = regexp.MustCompile(`^(an|a|p)(\d+)$`)
+)
+
+// assStyle é a parte de um estilo do ASS que tem equivalente no WebVTT
+type assStyle struct {
+	bold, italic, underline bool
+	alignment               int // Teclado numérico (1 a 9)
+}
+
+// assFormat é a formatação de um trecho do texto
+type assFormat struct {
+	bold, italic, underline bool
+}
+
+// assRun é um trecho do texto com a mesma formatação
+type assRun struct {
+	format assFormat
+	text   string
+}
+
+// ParseASS lê uma legenda Advanced SubStation Alpha (ASS) ou SubStation Alpha (SSA). Negrito,
+// itálico e sublinhado vêm do estilo e das tags {\b1}, {\i1} e {\u1}; o posicionamento vem do
+// alinhamento ({\an}, {\a} ou do estilo) e de {\pos(x,y)}, proporcional a PlayResX e PlayResY.
+// Desenhos ({\p1}) e linhas de comentário são ignorados.
+func ParseASS(text string) []Cue {
+	playResX, playResY := 384.0, 288.0
+	styles := make(map[string]assStyle)
+	var section string
+	var styleFormat, eventFormat []string
+	var cues []Cue
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(line)
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		legacy := section == "[v4 styles]"
+
+		switch {
+		case section == "[script info]":
+			switch strings.ToLower(key) {
+			case "playresx":
+				if v, err := strconv.ParseFloat(value, 64); err == nil && v > 0 {
+					playResX = v
+				}
+			case "playresy":
+				if v, err := strconv.ParseFloat(value, 64); err == nil && v > 0 {
+					playResY = v
+				}
+			}
+		case strings.HasPrefix(section, "[v4") && key == "Format":
+			styleFormat = splitFields(value, -1)
+		case strings.HasPrefix(section, "[v4") && key == "Style":
+			name, style := parseASSStyle(styleFormat, splitFields(value, len(styleFormat)), legacy)
+			styles[name] = style
+		case section == "[events]" && key == "Format":
+			eventFormat = splitFields(value, -1)
+		case section == "[events]" && key == "Dialogue":
+			if cue, ok := parseDialogue(eventFormat, splitFields(value, len(eventFormat)), styles, playResX, playResY); ok {
+				cues = append(cues, cue)
+			}
+		}
+	}
+	return cues
+}
+
+// splitFields separa os campos de uma linha Format/Style/Dialogue; com n > 0, o último campo
+// (o texto, no caso de Dialogue) fica inteiro mesmo contendo vírgulas
+func splitFields(value string, n int) []string {
+	fields := strings.SplitN(value, ",", n)
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
+
+// fieldIndex retorna a posição de name numa linha Format, ou -1
+func fieldIndex(format []string, name string) int {
+	for i, field := range format {
+		if strings.EqualFold(field, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func fieldValue(format, fields []string, name string) string {
+	if i := fieldIndex(format, name); i >= 0 && i < len(fields) {
+		return fields[i]
+	}
+	return ""
+}
+
+// parseASSStyle lê uma linha Style; no SSA (legacy) o alinhamento usa a numeração antiga
+func parseASSStyle(format, fields []string, legacy bool) (string, assStyle) {
+	style := assStyle{
+		bold:      assBool(fieldValue(format, fields, "Bold")),
+		italic:    assBool(fieldValue(format, fields, "Italic")),
+		underline: assBool(fieldValue(format, fields, "Underline")),
+		alignment: 2,
+	}
+	if an, err := strconv.Atoi(fieldValue(format, fields, "Alignment")); err == nil {
+		if legacy {
+			an = legacyAlignment(an)
+		}
+		if an >= 1 && an <= 9 {
+			style.alignment = an
+		}
+	}
+	return fieldValue(format, fields, "Name"), style
+}
+
+// assBool lê os booleanos do ASS (-1 verdadeiro, 0 falso; alguns arquivos usam 1)
+func assBool(value string) bool {
+	return value == "-1" || value == "1"
+}
+
+// legacyAlignment converte o alinhamento do SSA (1-3 embaixo, 5-7 em cima, 9-11 no meio)
+// para o do teclado numérico usado pelo ASS
+func legacyAlignment(a int) int {
+	switch {
+	case a >= 1 && a <= 3:
+		return a
+	case a >= 5 && a <= 7:
+		return a + 2
+	case a >= 9 && a <= 11:
+		return a - 5
+	}
+	return 2
+}
+
+// parseDialogue converte uma linha Dialogue numa fala
+func parseDialogue(format, fields []string, styles map[string]assStyle, playResX, playResY float64) (Cue, bool) {
+	if len(fields) != len(format) {
+		return Cue{}, false
+	}
+	start, err1 := parseTimestamp(fieldValue(format, fields, "Start"))
+	end, err2 := parseTimestamp(fieldValue(format, fields, "End"))
+	if err1 != nil || err2 != nil || end <= start {
+		return Cue{}, false
+	}
+
+	base, ok := styles[strings.TrimPrefix(fieldValue(format, fields, "Style"), "*")]
+	if !ok {
+		base = styles["Default"]
+		if base.alignment == 0 {
+			base.alignment = 2
+		}
+	}
+
+	runs, alignment, pos := parseOverrides(fieldValue(format, fields, "Text"), base, styles)
+	text := renderRuns(runs)
+	if text == "" {
+		return Cue{}, false
+	}
+
+	settings := alignmentSettings(alignment)
+	if pos != nil {
+		settings = positionSettings(alignment, pos[0]/playResX*100, pos[1]/playResY*100)
+	}
+	return Cue{Start: start, End: end, Text: text, Settings: settings}, true
+}
+
+// parseOverrides percorre o texto de uma fala aplicando as tags de override ({\...}). Retorna os
+// trechos formatados, o alinhamento final e a posição de \pos (nil se não houver).
+func parseOverrides(text string, base assStyle, styles map[string]assStyle) ([]assRun, int, []float64) {
+	current := assFormat{bold: base.bold, italic: base.italic, underline: base.underline}
+	alignment := base.alignment
+	var pos []float64
+	drawing := false
+	var runs []assRun
+
+	emit := func(s string) {
+		if drawing || s == "" {
+			return
+		}
+		s = strings.NewReplacer(`\N`, "\n", `\n`, " ", `\h`, "\u00a0").Replace(s)
+		if n := len(runs); n > 0 && runs[n-1].format == current {
+			runs[n-1].text += s
+			return
+		}
+		runs = append(runs, assRun{format: current, text: s})
+	}
+
+	for text != "" {
+		open := strings.Index(text, "{")
+		if open < 0 {
+			emit(text)
+			break
+		}
+		closing := strings.Index(text[open:], "}")
+		if closing < 0 {
+			emit(text)
+			break
+		}
+		emit(text[:open])
+		block := text[open+1 : open+closing]
+		text = text[open+closing+1:]
+		if !strings.HasPrefix(block, `\`) {
+			continue // Comentário entre chaves
+		}
+
+		for _, tag := range strings.Split(block, `\`)[1:] {
+			tag = strings.TrimSpace(tag)
+			if m := assPos.FindStringSubmatch(tag); m != nil {
+				x, _ := strconv.ParseFloat(m[1], 64)
+				y, _ := strconv.ParseFloat(m[2], 64)
+				pos = []float64{x, y}
+				continue
+			}
+			if m := assNumeric.FindStringSubmatch(tag); m != nil {
+				n, _ := strconv.Atoi(m[2])
+				switch m[1] {
+				case "an":
+					if n >= 1 && n <= 9 {
+						alignment = n
+					}
+				case "a":
+					alignment = legacyAlignment(n)
+				case "p":
+					drawing = n > 0
+				}
+				continue
+			}
+			if m := assToggle.FindStringSubmatch(tag); m != nil {
+				switch m[1] {
+				case "b":
+					current.bold = toggleValue(m[2], base.bold, true)
+				case "i":
+					current.italic = toggleValue(m[2], base.italic, false)
+				case "u":
+					current.underline = toggleValue(m[2], base.underline, false)
+				}
+				continue
+			}
+			if strings.HasPrefix(tag, "r") {
+				style := base
+				if named, ok := styles[strings.TrimSpace(tag[1:])]; ok {
+					style = named
+				}
+				current = assFormat{bold: style.bold, italic: style.italic, underline: style.underline}
+			}
+		}
+	}
+	return runs, alignment, pos
+}
+
+// toggleValue interpreta o argumento de \b, \i e \u: vazio volta ao estilo, 0 desliga, 1 liga.
+// \b também aceita o peso da fonte (600 ou mais é negrito).
+func toggleValue(arg string, styleValue, weight bool) bool {
+	if arg == "" {
+		return styleValue
+	}
+	n, _ := strconv.Atoi(arg)
+	if weight && n > 1 {
+		return n >= 600
+	}
+	return n == 1
+}
+
+// renderRuns monta o texto de cue do WebVTT, fechando as tags de cada trecho para manter o aninhamento válido
+func renderRuns(runs []assRun) string {
+	var b strings.Builder
+	for _, run := range runs {
+		text := escapeText(run.text)
+		if run.format.underline {
+			text = "<u>" + text + "</u>"
+		}
+		if run.format.italic {
+			text = "<i>" + text + "</i>"
+		}
+		if run.format.bold {
+			text = "<b>" + text + "</b>"
+		}
+		b.WriteString(text)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// positionSettings converte \pos (em porcentagem da tela) em configurações do WebVTT; o ponto de
+// ancoragem segue o alinhamento, como no ASS
+func positionSettings(alignment int, x, y float64) string {
+	x = min(max(x, 0), 100)
+	y = min(max(y, 0), 100)
+
+	lineAnchor := "end"
+	switch {
+	case alignment >= 7:
+		lineAnchor = "start"
+	case alignment >= 4:
+		lineAnchor = "center"
+	}
+	positionAnchor, align := "center", "center"
+	switch alignment % 3 {
+	case 1:
+		positionAnchor, align = "line-left", "start"
+	case 0:
+		positionAnchor, align = "line-right", "end"
+	}
+	return fmt.Sprintf("line:%s%%,%s position:%s%%,%s align:%s",
+		formatPercent(y), lineAnchor, formatPercent(x), positionAnchor, align)
+}
+
+func formatPercent(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,62 @@
+package subtitles
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// srtTag casa as tags HTML usadas em SRT (<i>, </b>, <font color="...">)
+	srtTag = regexp.MustCompile(`(?i)<\s*(/?)\s*([a-z]+)[^>]*>`)
+	// srtOverride casa blocos de override do ASS que aparecem em SRT ({\an8}, {\i1})
+	srtOverride = regexp.MustCompile(`\{\\[^}]*\}`)
+	srtAlign    = regexp.MustCompile(`\\an([1-9])`)
+)
+
+// ParseSRT lê uma legenda SubRip. Mantém <b>, <i> e <u>, descarta <font> e outras tags e converte
+// {\anN} no posicionamento equivalente.
+func ParseSRT(text string) []Cue {
+	var cues []Cue
+	for _, block := range strings.Split(text, "\n\n") {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+		for i, line := range lines {
+			if !strings.Contains(line, "-->") {
+				continue
+			}
+			start, end, _, ok := parseTiming(line)
+			if !ok {
+				break
+			}
+			body := strings.Join(lines[i+1:], "\n")
+			settings := ""
+			if m := srtAlign.FindStringSubmatch(body); m != nil {
+				an, _ := strconv.Atoi(m[1])
+				settings = alignmentSettings(an)
+			}
+			if text := srtText(body); text != "" {
+				cues = append(cues, Cue{Start: start, End: end, Text: text, Settings: settings})
+			}
+			break
+		}
+	}
+	return cues
+}
+
+// srtText converte o texto de uma fala SRT para o texto de cue do WebVTT
+func srtText(body string) string {
+	body = srtOverride.ReplaceAllString(body, "")
+
+	var b strings.Builder
+	last := 0
+	for _, m := range srtTag.FindAllStringSubmatchIndex(body, -1) {
+		b.WriteString(escapeText(body[last:m[0]]))
+		last = m[1]
+		switch name := strings.ToLower(body[m[4]:m[5]]); name {
+		case "b", "i", "u":
+			b.WriteString("<" + body[m[2]:m[3]] + name + ">")
+		}
+	}
+	b.WriteString(escapeText(body[last:]))
+	return strings.TrimSpace(b.String())
+}
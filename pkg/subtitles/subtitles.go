@@ -0,0 +1,230 @@
+// Package subtitles converte legendas SRT, ASS/SSA e WebVTT para WebVTT, o único formato que o
+// <track> dos players web entende. Cuida da codificação (legendas PT-BR antigas costumam vir em
+// Windows-1252), de estilos básicos (negrito, itálico, sublinhado), do posicionamento e de um
+// deslocamento de tempo para sincronizar com o vídeo.
+package subtitles
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Cue é uma fala da legenda
+type Cue struct {
+	Start    time.Duration
+	End      time.Duration
+	Text     string // Texto de cue do WebVTT: só <b>, <i> e <u>, com &, < e > escapados
+	Settings string // Configurações de posição do WebVTT ("line:0 align:start position:5%")
+}
+
+// ToWebVTT converte a legenda para WebVTT. format é "srt", "ass", "ssa" ou "vtt"; vazio ou
+// desconhecido detecta pelo conteúdo. offset é somado a todos os tempos (negativo adianta a legenda).
+func ToWebVTT(data []byte, format string, offset time.Duration) ([]byte, error) {
+	text := Decode(data)
+
+	format = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(format), "."))
+	switch format {
+	case "srt", "ass", "ssa", "vtt":
+	case "webvtt":
+		format = "vtt"
+	default:
+		format = DetectFormat(text)
+	}
+
+	var cues []Cue
+	switch format {
+	case "srt":
+		cues = ParseSRT(text)
+	case "ass", "ssa":
+		cues = ParseASS(text)
+	case "vtt":
+		cues = ParseVTT(text)
+	default:
+		return nil, fmt.Errorf("formato de legenda não reconhecido")
+	}
+	if len(cues) == 0 {
+		return nil, fmt.Errorf("legenda %s sem falas", format)
+	}
+	return WriteVTT(Shift(cues, offset)), nil
+}
+
+// Decode converte a legenda para UTF-8 com quebras de linha \n. BOM de UTF-8 e UTF-16 é
+// respeitado; sem BOM, o que não for UTF-8 válido é lido como Windows-1252.
+func Decode(data []byte) string {
+	var text string
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		text = string(data[3:])
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}), bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		decoded, err := unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder().Bytes(data)
+		if err != nil {
+			decoded = data
+		}
+		text = string(decoded)
+	case utf8.Valid(data):
+		text = string(data)
+	default:
+		decoded, _ := charmap.Windows1252.NewDecoder().Bytes(data)
+		text = string(decoded)
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+// DetectFormat identifica o formato pelo conteúdo ("srt", "ass", "vtt" ou "" se desconhecido)
+func DetectFormat(text string) string {
+	trimmed := strings.TrimSpace(text)
+	switch {
+	case strings.HasPrefix(trimmed, "WEBVTT"):
+		return "vtt"
+	case strings.HasPrefix(trimmed, "[Script Info]"), strings.Contains(text, "\nDialogue:"):
+		return "ass"
+	case strings.Contains(text, "-->"):
+		return "srt"
+	}
+	return ""
+}
+
+// Shift soma offset aos tempos. Falas que terminariam antes do início do vídeo são descartadas.
+func Shift(cues []Cue, offset time.Duration) []Cue {
+	if offset == 0 {
+		return cues
+	}
+	shifted := make([]Cue, 0, len(cues))
+	for _, cue := range cues {
+		cue.Start += offset
+		cue.End += offset
+		if cue.End <= 0 {
+			continue
+		}
+		cue.Start = max(cue.Start, 0)
+		shifted = append(shifted, cue)
+	}
+	return shifted
+}
+
+// WriteVTT escreve as falas em WebVTT, ordenadas pelo início
+func WriteVTT(cues []Cue) []byte {
+	sorted := append([]Cue(nil), cues...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	var b bytes.Buffer
+	b.WriteString("WEBVTT\n")
+	for _, cue := range sorted {
+		b.WriteString("\n" + formatTimestamp(cue.Start) + " --> " + formatTimestamp(cue.End))
+		if cue.Settings != "" {
+			b.WriteString(" " + cue.Settings)
+		}
+		b.WriteString("\n" + cue.Text + "\n")
+	}
+	return b.Bytes()
+}
+
+// ParseVTT lê um WebVTT (para aplicar o deslocamento); o texto das falas fica como está
+func ParseVTT(text string) []Cue {
+	var cues []Cue
+	for _, block := range strings.Split(text, "\n\n") {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+		for i, line := range lines {
+			if !strings.Contains(line, "-->") {
+				continue
+			}
+			start, end, settings, ok := parseTiming(line)
+			if ok {
+				cues = append(cues, Cue{Start: start, End: end, Settings: settings, Text: strings.Join(lines[i+1:], "\n")})
+			}
+			break
+		}
+	}
+	return cues
+}
+
+// parseTiming lê "início --> fim [configurações]" do SRT ou do WebVTT
+func parseTiming(line string) (start, end time.Duration, settings string, ok bool) {
+	first, rest, found := strings.Cut(line, "-->")
+	if !found {
+		return 0, 0, "", false
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return 0, 0, "", false
+	}
+	var err1, err2 error
+	start, err1 = parseTimestamp(first)
+	end, err2 = parseTimestamp(fields[0])
+	if err1 != nil || err2 != nil || end < start {
+		return 0, 0, "", false
+	}
+	// Só configurações do WebVTT (chave:valor); as coordenadas X1:... do SRT não se aplicam
+	var kept []string
+	for _, field := range fields[1:] {
+		key, _, _ := strings.Cut(field, ":")
+		switch key {
+		case "line", "position", "align", "size", "vertical", "region":
+			kept = append(kept, field)
+		}
+	}
+	return start, end, strings.Join(kept, " "), true
+}
+
+// parseTimestamp lê "HH:MM:SS,mmm", "HH:MM:SS.mmm" ou "MM:SS.mmm"
+func parseTimestamp(value string) (time.Duration, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", ".")
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("tempo inválido: %s", value)
+	}
+	seconds, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("tempo inválido: %s", value)
+	}
+	total := time.Duration(seconds * float64(time.Second))
+	units := []time.Duration{time.Minute, time.Hour}
+	for i, part := range parts[:len(parts)-1] {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return 0, fmt.Errorf("tempo inválido: %s", value)
+		}
+		total += time.Duration(n) * units[len(parts)-2-i]
+	}
+	return total.Round(time.Millisecond), nil
+}
+
+func formatTimestamp(d time.Duration) string {
+	d = d.Round(time.Millisecond)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60, d.Milliseconds()%1000)
+}
+
+// alignmentSettings converte o alinhamento do teclado numérico do ASS (\an1 a \an9) em configurações
+// do WebVTT: linha 7-9 no topo, 4-6 no meio e 1-3 embaixo (o padrão); coluna esquerda, centro ou direita
+func alignmentSettings(an int) string {
+	var settings []string
+	switch {
+	case an >= 7 && an <= 9:
+		settings = append(settings, "line:0")
+	case an >= 4 && an <= 6:
+		settings = append(settings, "line:50%,center")
+	}
+	switch an % 3 {
+	case 1:
+		settings = append(settings, "align:start", "position:5%")
+	case 0:
+		if an > 0 {
+			settings = append(settings, "align:end", "position:95%")
+		}
+	}
+	return strings.Join(settings, " ")
+}
+
+// escapeText escapa o texto de uma fala para o WebVTT
+func escapeText(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
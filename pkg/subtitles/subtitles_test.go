@@ -0,0 +1,113 @@
+package subtitles
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToWebVTT_SRTWithWindows1252AndOffset(t *testing.T) {
+	// "Não é você" em Windows-1252, com CRLF e tags do SRT
+	srt := []byte("1\r\n00:00:01,000 --> 00:00:03,500\r\n<i>N\xe3o</i> \xe9 <font color=\"#ff0\">voc\xea</font> & <b>eu</b>\r\n\r\n" +
+		"2\r\n00:00:04,000 --> 00:00:05,000\r\n{\\an8}Placa: <U>SA\xcdDA</U>\r\n\r\n" +
+		"3\r\n00:00:00,100 --> 00:00:00,900\r\nSome com o deslocamento\r\n")
+
+	out, err := ToWebVTT(srt, "", -500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("ToWebVTT() error = %v", err)
+	}
+	got := string(out)
+	for _, want := range []string{
+		"WEBVTT\n",
+		"\n00:00:00.500 --> 00:00:03.000\n<i>Não</i> é você &amp; <b>eu</b>\n",
+		"\n00:00:03.500 --> 00:00:04.500 line:0\nPlaca: <u>SAÍDA</u>\n",
+		"\n00:00:00.000 --> 00:00:00.400\nSome com o deslocamento\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q\n%s", want, got)
+		}
+	}
+
+	// A fala que terminaria antes do início some; as outras ficam em ordem
+	out, _ = ToWebVTT(srt, "srt", -time.Second)
+	if strings.Contains(string(out), "deslocamento") || !strings.HasPrefix(string(out), "WEBVTT\n\n00:00:00.000 --> 00:00:02.500") {
+		t.Errorf("ToWebVTT(-1s) =\n%s", out)
+	}
+}
+
+func TestToWebVTT_ASSStylesAndPositioning(t *testing.T) {
+	ass := "\ufeff[Script Info]\nScriptType: v4.00+\nPlayResX: 1920\nPlayResY: 1080\n\n" +
+		"[V4+ Styles]\n" +
+		"Format: Name, Fontname, Fontsize, PrimaryColour, Bold, Italic, Underline, Alignment, MarginV\n" +
+		"Style: Default,Arial,48,&H00FFFFFF,0,0,0,2,20\n" +
+		"Style: Sign,Arial,40,&H00FFFFFF,-1,0,0,8,20\n\n" +
+		"[Events]\n" +
+		"Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n" +
+		"Dialogue: 0,0:00:02.00,0:00:04.50,Default,,0,0,0,,Olá, {\\i1}mundo{\\i0}!\\NSegunda <linha>\n" +
+		"Comment: 0,0:00:02.00,0:00:04.50,Default,,0,0,0,,não aparece\n" +
+		"Dialogue: 0,0:00:01.00,0:00:03.00,Sign,,0,0,0,,Loja {\\b0}aberta\n" +
+		"Dialogue: 0,0:00:05.00,0:00:06.00,*Default,,0,0,0,,{\\an7\\pos(480,270)\\u1}Canto\n" +
+		"Dialogue: 0,0:00:05.00,0:00:06.00,Default,,0,0,0,,{\\p1}m 0 0 l 100 0 100 100{\\p0}\n" +
+		"Dialogue: 0,1:02:03.45,1:02:04.00,Default,,0,0,0,,{comentário}{\\fs20\\blur3\\an3}Fim\n"
+
+	out, err := ToWebVTT([]byte(ass), "ass", 0)
+	if err != nil {
+		t.Fatalf("ToWebVTT() error = %v", err)
+	}
+	got := string(out)
+	for _, want := range []string{
+		"\n00:00:01.000 --> 00:00:03.000 line:0\n<b>Loja </b>aberta\n",
+		"\n00:00:02.000 --> 00:00:04.500\nOlá, <i>mundo</i>!\nSegunda &lt;linha&gt;\n",
+		"\n00:00:05.000 --> 00:00:06.000 line:25%,start position:25%,line-left align:start\n<u>Canto</u>\n",
+		"\n01:02:03.450 --> 01:02:04.000 align:end position:95%\nFim\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "não aparece") || strings.Contains(got, "m 0 0") {
+		t.Errorf("comments and drawings should be skipped\n%s", got)
+	}
+	// A fala com \pos não pode aparecer duas vezes (a de desenho é descartada)
+	if strings.Count(got, "00:00:05.000 -->") != 1 {
+		t.Errorf("drawing-only cue should be dropped\n%s", got)
+	}
+}
+
+func TestToWebVTT_SSAAndDetection(t *testing.T) {
+	ssa := "[Script Info]\nScriptType: v4.00\n\n[V4 Styles]\n" +
+		"Format: Name, Fontname, Bold, Italic, Alignment\n" +
+		"Style: Top,Arial,0,-1,6\n\n[Events]\n" +
+		"Format: Marked, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n" +
+		"Dialogue: Marked=0,0:00:01.00,0:00:02.00,Top,,0,0,0,,Topo{\\a9}\n"
+	out, err := ToWebVTT([]byte(ssa), "", 0)
+	if err != nil {
+		t.Fatalf("ToWebVTT(ssa) error = %v", err)
+	}
+	// \a9 (SSA) é o meio à esquerda; o estilo é itálico
+	if want := "00:00:01.000 --> 00:00:02.000 line:50%,center align:start position:5%\n<i>Topo</i>\n"; !strings.Contains(string(out), want) {
+		t.Errorf("ToWebVTT(ssa) =\n%s\nwant %q", out, want)
+	}
+
+	vtt := "WEBVTT\n\nNOTE comentário\n\n01:02.000 --> 01:03.500 line:0\n<c.yellow>Oi</c>\n"
+	out, err = ToWebVTT([]byte(vtt), "webvtt", 2*time.Second)
+	if err != nil || !strings.Contains(string(out), "00:01:04.000 --> 00:01:05.500 line:0\n<c.yellow>Oi</c>\n") {
+		t.Errorf("ToWebVTT(vtt) = %q, %v", out, err)
+	}
+
+	// UTF-16 com BOM (comum em legendas exportadas no Windows)
+	utf16 := []byte{0xFF, 0xFE}
+	for _, r := range "1\n00:00:01,000 --> 00:00:02,000\nçã\n" {
+		utf16 = append(utf16, byte(r), byte(r>>8))
+	}
+	if out, err := ToWebVTT(utf16, "srt", 0); err != nil || !strings.Contains(string(out), "\nçã\n") {
+		t.Errorf("ToWebVTT(utf-16) = %q, %v", out, err)
+	}
+
+	if _, err := ToWebVTT([]byte("apenas texto"), "", 0); err == nil {
+		t.Error("ToWebVTT() should reject an unknown format")
+	}
+	if _, err := ToWebVTT([]byte("WEBVTT\n"), "vtt", 0); err == nil {
+		t.Error("ToWebVTT() should reject a subtitle without cues")
+	}
+}
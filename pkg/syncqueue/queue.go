@@ -0,0 +1,239 @@
+// Package syncqueue envia mutações para serviços externos (trackers, servidor social)
+// a partir de uma fila persistida no SQLite, com novas tentativas e backoff exponencial.
+package syncqueue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"GoAnimeGUI/pkg/retry"
+	"GoAnimeGUI/pkg/store"
+)
+
+const (
+	// MaxAttempts é o número de falhas seguidas antes de desistir de um item
+	MaxAttempts = 12
+
+	baseBackoff  = 30 * time.Second
+	maxBackoff   = 30 * time.Minute
+	pollInterval = time.Minute
+	batchSize    = 20
+)
+
+// Handler envia o payload de um item; retornar erro agenda uma nova tentativa
+// (erros marcados com retry.Permanent vão direto para o estado "failed")
+type Handler func(payload []byte) error
+
+// State é o resumo da fila para o frontend
+type State struct {
+	Pending int             `json:"pending"`
+	Failed  int             `json:"failed"`
+	Jobs    []store.SyncJob `json:"jobs"`
+}
+
+// Queue processa a fila de sincronização em background
+type Queue struct {
+	db       *store.DB
+	handlers map[string]Handler
+	wake     chan struct{}
+	onChange func(State)
+	mutex    sync.RWMutex
+}
+
+// New cria a fila sobre o banco informado
+func New(db *store.DB) *Queue {
+	return &Queue{
+		db:       db,
+		handlers: make(map[string]Handler),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Handle registra o handler de um tipo de item
+func (q *Queue) Handle(kind string, h Handler) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.handlers[kind] = h
+}
+
+// OnChange registra um callback chamado sempre que a fila muda
+func (q *Queue) OnChange(fn func(State)) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.onChange = fn
+}
+
+// Enqueue grava uma mutação; itens com o mesmo (kind, key) são substituídos pelo mais recente
+func (q *Queue) Enqueue(kind, key string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if err := q.db.EnqueueSync(kind, key, string(data)); err != nil {
+		return fmt.Errorf("erro ao enfileirar %s: %w", kind, err)
+	}
+	q.changed()
+	q.Kick()
+	return nil
+}
+
+// Kick acorda o worker para processar a fila imediatamente
+func (q *Queue) Kick() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Retry antecipa todos os itens, inclusive os que já tinham desistido
+func (q *Queue) Retry() error {
+	if err := q.db.RetrySyncJobs("", true); err != nil {
+		return err
+	}
+	q.changed()
+	q.Kick()
+	return nil
+}
+
+// Discard remove um item da fila
+func (q *Queue) Discard(id int64) error {
+	if err := q.db.DeleteSyncJob(id); err != nil {
+		return err
+	}
+	q.changed()
+	return nil
+}
+
+// State retorna o estado atual da fila
+func (q *Queue) State() (State, error) {
+	jobs, err := q.db.SyncJobs()
+	if err != nil {
+		return State{Jobs: []store.SyncJob{}}, err
+	}
+	state := State{Jobs: jobs}
+	for _, job := range jobs {
+		if job.State == store.SyncFailed {
+			state.Failed++
+		} else {
+			state.Pending++
+		}
+	}
+	return state, nil
+}
+
+// Run processa a fila até o contexto ser cancelado
+func (q *Queue) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-q.wake:
+		}
+
+		q.ProcessDue()
+
+		// Dorme até o próximo item vencer (no máximo pollInterval)
+		wait := pollInterval
+		if next, err := q.db.NextSyncAttempt(); err == nil && !next.IsZero() {
+			if d := time.Until(next); d < wait {
+				wait = d
+			}
+		}
+		if wait < time.Second {
+			wait = time.Second
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+	}
+}
+
+// ProcessDue envia os itens vencidos e retorna quantos foram concluídos
+func (q *Queue) ProcessDue() int {
+	jobs, err := q.db.DueSyncJobs(time.Now(), batchSize)
+	if err != nil {
+		fmt.Printf("[SyncQueue] Erro ao ler fila: %v\n", err)
+		return 0
+	}
+	if len(jobs) == 0 {
+		return 0
+	}
+
+	done := 0
+	succeeded := make(map[string]bool)
+	for _, job := range jobs {
+		q.mutex.RLock()
+		handler := q.handlers[job.Kind]
+		q.mutex.RUnlock()
+
+		if handler == nil {
+			q.fail(job, fmt.Errorf("nenhum handler para %q", job.Kind), true)
+			continue
+		}
+
+		if err := handler([]byte(job.Payload)); err != nil {
+			q.fail(job, err, retry.IsPermanent(err))
+			continue
+		}
+
+		if err := q.db.CompleteSyncJob(job); err != nil {
+			fmt.Printf("[SyncQueue] Erro ao concluir item %d: %v\n", job.ID, err)
+		}
+		succeeded[job.Kind] = true
+		done++
+	}
+
+	// O serviço respondeu: os itens do mesmo tipo que estavam em backoff podem ir agora
+	for kind := range succeeded {
+		q.db.RetrySyncJobs(kind, false)
+	}
+
+	q.changed()
+	return done
+}
+
+// fail registra a falha e agenda a próxima tentativa
+func (q *Queue) fail(job store.SyncJob, err error, permanent bool) {
+	if job.Attempts+1 >= MaxAttempts {
+		permanent = true
+	}
+	next := time.Now().Add(Backoff(job.Attempts + 1))
+	if err := q.db.FailSyncJob(job, err.Error(), next, permanent); err != nil {
+		fmt.Printf("[SyncQueue] Erro ao registrar falha do item %d: %v\n", job.ID, err)
+	}
+
+	if permanent {
+		fmt.Printf("[SyncQueue] %s %q falhou de vez: %v\n", job.Kind, job.Key, err)
+	} else {
+		fmt.Printf("[SyncQueue] %s %q falhou (tentativa %d), nova tentativa em %s: %v\n",
+			job.Kind, job.Key, job.Attempts+1, time.Until(next).Round(time.Second), err)
+	}
+}
+
+// Backoff retorna a espera antes da tentativa seguinte a n falhas (30s, 1m, 2m... até 30m)
+func Backoff(failures int) time.Duration {
+	return retry.Backoff(failures, baseBackoff, maxBackoff)
+}
+
+func (q *Queue) changed() {
+	q.mutex.RLock()
+	fn := q.onChange
+	q.mutex.RUnlock()
+	if fn == nil {
+		return
+	}
+	if state, err := q.State(); err == nil {
+		fn(state)
+	}
+}
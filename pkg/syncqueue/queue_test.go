@@ -0,0 +1,129 @@
+package syncqueue
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"GoAnimeGUI/pkg/retry"
+	"GoAnimeGUI/pkg/store"
+)
+
+func newTestQueue(t *testing.T) (*Queue, *store.DB) {
+	t.Helper()
+	db := store.OpenTemp(t)
+	return New(db), db
+}
+
+func TestQueue_CollapsesByKey(t *testing.T) {
+	q, _ := newTestQueue(t)
+
+	var sent []string
+	q.Handle("anilist", func(payload []byte) error {
+		sent = append(sent, string(payload))
+		return nil
+	})
+
+	q.Enqueue("anilist", "https://a/frieren", "ep1")
+	q.Enqueue("anilist", "https://a/frieren", "ep2")
+	q.Enqueue("anilist", "https://a/op", "ep7")
+
+	if done := q.ProcessDue(); done != 2 {
+		t.Fatalf("ProcessDue() = %d; want 2", done)
+	}
+	if len(sent) != 2 || sent[0] != `"ep2"` || sent[1] != `"ep7"` {
+		t.Errorf("sent = %v; want only the latest payload per key", sent)
+	}
+	if state, _ := q.State(); len(state.Jobs) != 0 {
+		t.Errorf("queue not empty after success: %+v", state.Jobs)
+	}
+}
+
+func TestQueue_RetryAndPermanentFailure(t *testing.T) {
+	q, db := newTestQueue(t)
+
+	offline := true
+	q.Handle("social_status", func(payload []byte) error {
+		if offline {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	q.Handle("mal", func(payload []byte) error {
+		return retry.Permanent(errors.New("401"))
+	})
+
+	q.Enqueue("social_status", "user", "watching")
+	q.Enqueue("mal", "https://a/frieren", "https://a/frieren")
+	q.ProcessDue()
+
+	state, _ := q.State()
+	if state.Pending != 1 || state.Failed != 1 {
+		t.Fatalf("state = %+v; want 1 pending, 1 failed", state)
+	}
+	for _, job := range state.Jobs {
+		if job.Kind == "social_status" && (job.Attempts != 1 || job.NextAttemptAt <= time.Now().Unix()) {
+			t.Errorf("retry not scheduled with backoff: %+v", job)
+		}
+	}
+
+	// Em backoff: nada para processar até a próxima tentativa vencer
+	if done := q.ProcessDue(); done != 0 {
+		t.Errorf("ProcessDue() during backoff = %d; want 0", done)
+	}
+
+	offline = false
+	db.RetrySyncJobs("social_status", false)
+	if done := q.ProcessDue(); done != 1 {
+		t.Errorf("ProcessDue() after reconnect = %d; want 1", done)
+	}
+
+	// O item falho só volta com Retry explícito
+	if state, _ := q.State(); state.Failed != 1 || state.Pending != 0 {
+		t.Errorf("state = %+v; want only the failed job", state)
+	}
+	q.Retry()
+	if state, _ := q.State(); state.Pending != 1 || state.Jobs[0].Attempts != 0 {
+		t.Errorf("state after Retry() = %+v", state)
+	}
+}
+
+func TestQueue_ReplacedWhileSending(t *testing.T) {
+	q, _ := newTestQueue(t)
+
+	calls := 0
+	q.Handle("anilist", func(payload []byte) error {
+		calls++
+		if calls == 1 {
+			// Nova alteração chega enquanto a anterior está sendo enviada
+			q.Enqueue("anilist", "https://a/frieren", "ep3")
+		}
+		return nil
+	})
+
+	q.Enqueue("anilist", "https://a/frieren", "ep2")
+	q.ProcessDue()
+
+	state, _ := q.State()
+	if len(state.Jobs) != 1 || state.Jobs[0].Payload != `"ep3"` {
+		t.Fatalf("state = %+v; want the newer payload still queued", state.Jobs)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{4, 4 * time.Minute},
+		{10, 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.failures); got != tt.want {
+			t.Errorf("Backoff(%d) = %v; want %v", tt.failures, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Package updates verifica em background se os animes da biblioteca ganharam episódios novos
+// e grava os avisos no feed de atualizações do banco.
+package updates
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"GoAnimeGUI/pkg/store"
+)
+
+const (
+	pollInterval = 15 * time.Minute
+	maxPerRound  = 50
+)
+
+// fetchDelay é a pausa entre animes para não martelar as fontes
+var fetchDelay = 2 * time.Second
+
+// FetchFunc retorna quantos episódios a fonte de uma entrada tem agora
+type FetchFunc func(item store.LibraryItem) (int, error)
+
+// Checker percorre a biblioteca periodicamente em busca de episódios novos
+type Checker struct {
+	db        *store.DB
+	fetch     FetchFunc
+	available func(source string) bool
+	interval  func() time.Duration
+	onUpdate  func(store.LibraryUpdate)
+	wake      chan struct{}
+	mutex     sync.Mutex // Uma verificação por vez
+}
+
+// New cria o verificador. available informa se a fonte está fora de cooldown (nil = sempre);
+// interval retorna o tempo mínimo entre verificações de um mesmo anime (<= 0 desativa).
+func New(db *store.DB, fetch FetchFunc, available func(source string) bool, interval func() time.Duration) *Checker {
+	if available == nil {
+		available = func(string) bool { return true }
+	}
+	return &Checker{
+		db:        db,
+		fetch:     fetch,
+		available: available,
+		interval:  interval,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// OnUpdate registra um callback chamado para cada anime com episódios novos
+func (c *Checker) OnUpdate(fn func(store.LibraryUpdate)) {
+	c.onUpdate = fn
+}
+
+// Kick antecipa a próxima verificação
+func (c *Checker) Kick() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run verifica a biblioteca até o contexto ser cancelado
+func (c *Checker) Run(ctx context.Context) {
+	// Espera o app terminar de carregar antes da primeira rodada
+	timer := time.NewTimer(time.Minute)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-c.wake:
+		}
+
+		c.CheckDue(ctx)
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(pollInterval)
+	}
+}
+
+// CheckDue verifica as entradas cuja última verificação venceu e retorna os avisos criados
+func (c *Checker) CheckDue(ctx context.Context) []store.LibraryUpdate {
+	interval := c.interval()
+	if interval <= 0 {
+		return nil
+	}
+	return c.check(ctx, time.Now().Add(-interval))
+}
+
+// CheckAll verifica toda a biblioteca agora, ignorando o intervalo
+func (c *Checker) CheckAll(ctx context.Context) []store.LibraryUpdate {
+	// checked_at tem precisão de segundos: inclui o que foi verificado agora há pouco
+	return c.check(ctx, time.Now().Add(time.Second))
+}
+
+func (c *Checker) check(ctx context.Context, checkedBefore time.Time) []store.LibraryUpdate {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	updates := []store.LibraryUpdate{}
+	items, err := c.db.UpdateCheckItems(checkedBefore, maxPerRound)
+	if err != nil {
+		fmt.Printf("[Updates] Erro ao ler biblioteca: %v\n", err)
+		return updates
+	}
+
+	for i, item := range items {
+		// Fonte em cooldown: fica para a próxima rodada sem contar como verificada
+		if !c.available(item.Source) {
+			continue
+		}
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return updates
+			case <-time.After(fetchDelay):
+			}
+		}
+
+		count, err := c.fetch(item)
+		if err != nil {
+			fmt.Printf("[Updates] Erro ao verificar %s (%s): %v\n", item.Title, item.Source, err)
+			continue
+		}
+
+		update, err := c.db.RecordEpisodeCount(item.URL, count)
+		if err != nil {
+			fmt.Printf("[Updates] Erro ao salvar contagem de %s: %v\n", item.Title, err)
+			continue
+		}
+		if update == nil {
+			continue
+		}
+		update.Title, update.Image, update.Source = item.Title, item.Image, item.Source
+		fmt.Printf("[Updates] %s: episódios %d-%d disponíveis\n", item.Title, update.EpisodeFrom, update.EpisodeTo)
+		updates = append(updates, *update)
+		if c.onUpdate != nil {
+			c.onUpdate(*update)
+		}
+	}
+	return updates
+}
@@ -0,0 +1,122 @@
+package updates
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"GoAnimeGUI/pkg/store"
+)
+
+func newTestDB(t *testing.T) *store.DB {
+	t.Helper()
+	db := store.OpenTemp(t)
+
+	user := &store.UserData{
+		Username: "tester",
+		Favorites: []store.SavedAnime{
+			{Title: "Frieren", URL: "https://a/frieren", Source: "AllAnime"},
+			{Title: "One Piece", URL: "https://b/op", Source: "AnimeFire"},
+			{Title: "Naruto", URL: "https://a/naruto", Source: "AllAnime"},
+		},
+	}
+	if err := db.SaveUser(user); err != nil {
+		t.Fatalf("SaveUser() error = %v", err)
+	}
+	if err := db.SetLibraryStatus("https://a/naruto", store.StatusCompleted); err != nil {
+		t.Fatalf("SetLibraryStatus() error = %v", err)
+	}
+	return db
+}
+
+func TestChecker_DetectsNewEpisodes(t *testing.T) {
+	fetchDelay = 0
+	db := newTestDB(t)
+
+	counts := map[string]int{"https://a/frieren": 10, "https://b/op": 1100}
+	fetched := map[string]int{}
+	fetch := func(item store.LibraryItem) (int, error) {
+		fetched[item.URL]++
+		return counts[item.URL], nil
+	}
+	animeFireDown := false
+	available := func(source string) bool { return !(animeFireDown && source == "AnimeFire") }
+	c := New(db, fetch, available, func() time.Duration { return time.Hour })
+
+	var notified []store.LibraryUpdate
+	c.OnUpdate(func(u store.LibraryUpdate) { notified = append(notified, u) })
+	ctx := context.Background()
+
+	// Primeira rodada só define a referência; concluídos não são verificados
+	if updates := c.CheckAll(ctx); len(updates) != 0 {
+		t.Fatalf("first check = %+v; want no updates", updates)
+	}
+	if fetched["https://a/naruto"] != 0 {
+		t.Errorf("completed entry was fetched")
+	}
+
+	// Dentro do intervalo nada é buscado de novo
+	c.CheckDue(ctx)
+	if fetched["https://a/frieren"] != 1 {
+		t.Errorf("fetched frieren %d times; want 1", fetched["https://a/frieren"])
+	}
+
+	counts["https://a/frieren"] = 12
+	counts["https://b/op"] = 1101
+	animeFireDown = true
+	updates := c.CheckAll(ctx)
+	if len(updates) != 1 || updates[0].AnimeURL != "https://a/frieren" ||
+		updates[0].EpisodeFrom != 11 || updates[0].EpisodeTo != 12 || updates[0].Title != "Frieren" {
+		t.Fatalf("updates = %+v; want frieren 11-12 only", updates)
+	}
+	if len(notified) != 1 {
+		t.Errorf("notified %d times; want 1", len(notified))
+	}
+
+	// A fonte voltou: o anime pulado é verificado na rodada seguinte
+	animeFireDown = false
+	if updates := c.CheckAll(ctx); len(updates) != 1 || updates[0].AnimeURL != "https://b/op" {
+		t.Fatalf("updates after recovery = %+v; want one piece", updates)
+	}
+
+	if n, _ := db.UnseenUpdateCount(); n != 2 {
+		t.Errorf("UnseenUpdateCount() = %d; want 2", n)
+	}
+	db.MarkAnimeUpdatesSeen("https://a/frieren")
+	feed, _ := db.LibraryUpdates(true, 10)
+	if len(feed) != 1 || feed[0].Title != "One Piece" {
+		t.Errorf("LibraryUpdates(unseen) = %+v; want only one piece", feed)
+	}
+}
+
+func TestChecker_FetchErrorKeepsBaseline(t *testing.T) {
+	fetchDelay = 0
+	db := newTestDB(t)
+
+	count, fail := 10, false
+	fetch := func(item store.LibraryItem) (int, error) {
+		if fail {
+			return 0, errors.New("timeout")
+		}
+		return count, nil
+	}
+	c := New(db, fetch, nil, func() time.Duration { return time.Hour })
+	ctx := context.Background()
+
+	c.CheckAll(ctx)
+	fail = true
+	c.CheckAll(ctx)
+
+	// Contagem menor de uma fonte instável não gera aviso depois
+	fail, count = false, 3
+	c.CheckAll(ctx)
+	count = 10
+	if updates := c.CheckAll(ctx); len(updates) != 0 {
+		t.Errorf("updates = %+v; want none", updates)
+	}
+	count = 11
+	if updates := c.CheckAll(ctx); len(updates) != 2 {
+		t.Errorf("updates = %+v; want one per watched entry", updates)
+	}
+}
@@ -9,10 +9,13 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"GoAnimeGUI/pkg/hostrules"
 )
 
 var httpClient = &http.Client{
-	Timeout: 10 * time.Second,
+	Transport: hostrules.Default().Transport(nil),
+	Timeout:   10 * time.Second,
 	CheckRedirect: func(req *http.Request, via []*http.Request) error {
 		return nil // Segue redirects
 	},
@@ -30,7 +33,8 @@ func ExtractVideoURL(pageURL string) (string, error) {
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
 	req.Header.Set("Accept-Language", "pt-BR,pt;q=0.9,en-US;q=0.8,en;q=0.7")
-	req.Header.Set("Referer", "https://animefire.plus/")
+	req.Header.Set("Referer", "https://animefire.plus/") // Padrão; as regras do host sobrescrevem
+	hostrules.Default().Apply(req)
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
@@ -133,7 +137,8 @@ func extractStreamFromVideoPage(videoPageURL string) (string, error) {
 	}
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 	req.Header.Set("Accept", "application/json, text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	req.Header.Set("Referer", "https://animefire.plus/")
+	req.Header.Set("Referer", "https://animefire.plus/") // Padrão; as regras do host sobrescrevem
+	hostrules.Default().Apply(req)
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
@@ -39,6 +39,7 @@ func (a *App) InitEmbeddedPlayer() error {
 			"position": position,
 			"duration": duration,
 		})
+		a.recordPlaybackPosition(position, duration)
 	}
 
 	player.OnSkipSegment = func(segment embeddedplayer.SkipSegment) {
@@ -70,6 +71,9 @@ func (a *App) getShaderPath() string {
 func (a *App) PlayerLoad(url string, title string) error {
 	player := embeddedplayer.GetPlayer()
 
+	// Vídeo avulso: não grava progresso em nenhum episódio
+	a.clearPlayingEpisode()
+
 	if err := player.LoadURL(url); err != nil {
 		return err
 	}
@@ -0,0 +1,237 @@
+// progress_methods.go - Métodos de progresso de episódios para o frontend
+// Guarda a posição exata de cada episódio e monta a lista "continuar assistindo"
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"GoAnimeGUI/pkg/embeddedplayer"
+	"GoAnimeGUI/pkg/store"
+)
+
+// positionSaveInterval é o intervalo mínimo entre gravações da posição no banco
+const positionSaveInterval = 10 * time.Second
+
+// playbackTracker guarda qual episódio está tocando no player integrado
+type playbackTracker struct {
+	mu       sync.Mutex
+	episode  *store.WatchedEpisode
+	lastSave time.Time
+}
+
+var playback = &playbackTracker{}
+
+// ContinueWatchingItem é um item da lista "continuar assistindo"
+type ContinueWatchingItem struct {
+	AnimeTitle   string  `json:"animeTitle"`
+	AnimeImage   string  `json:"animeImage"`
+	AnimeURL     string  `json:"animeUrl"`
+	EpisodeTitle string  `json:"episodeTitle"`
+	EpisodeURL   string  `json:"episodeUrl"`
+	EpisodeNum   int     `json:"episodeNum"`
+	Position     float64 `json:"position"` // Segundos para retomar (0 = do início)
+	Duration     float64 `json:"duration"`
+	Progress     int     `json:"progress"`
+	IsNext       bool    `json:"isNext"` // true = próximo episódio (o anterior foi concluído)
+	WatchedAt    string  `json:"watchedAt"`
+}
+
+// ==============================
+// POSIÇÃO DE RETOMADA
+// ==============================
+
+// GetResumePosition retorna a posição (segundos) de onde o episódio deve continuar
+func (a *App) GetResumePosition(episodeURL string) float64 {
+	ep, err := store.GetWatchedEpisode(episodeURL)
+	if err != nil || ep == nil {
+		return 0
+	}
+	return ep.ResumePosition()
+}
+
+// PlayerLoadEpisode carrega um episódio no player integrado retomando de onde parou.
+// A posição passa a ser gravada automaticamente durante a reprodução.
+func (a *App) PlayerLoadEpisode(url string, episode store.WatchedEpisode) error {
+	if episode.EpisodeURL == "" {
+		return fmt.Errorf("episódio sem URL")
+	}
+
+	start := a.GetResumePosition(episode.EpisodeURL)
+	a.AddToWatchHistory(episode)
+
+	playback.mu.Lock()
+	playback.episode = &episode
+	playback.lastSave = time.Time{}
+	playback.mu.Unlock()
+
+	if start > 0 {
+		fmt.Printf("[Progress] Retomando %s em %.0fs\n", episode.EpisodeURL, start)
+	}
+	return embeddedplayer.GetPlayer().LoadURLAt(url, start)
+}
+
+// SaveEpisodePosition grava a posição de um episódio (usado pelos players web)
+func (a *App) SaveEpisodePosition(episodeURL string, position float64, duration float64) error {
+	prev, _ := store.GetWatchedEpisode(episodeURL)
+	if err := store.UpdateWatchPosition(episodeURL, position, duration); err != nil {
+		return err
+	}
+
+	// Episódio acabou de passar do ponto de "assistido"
+	if prev != nil && !prev.IsCompleted() && store.ProgressPercent(position, duration) >= store.CompletedProgress {
+		a.onEpisodeFinished(*prev)
+	}
+	return nil
+}
+
+// onEpisodeFinished atualiza o progresso da biblioteca, marca a cópia offline como assistida e avisa os trackers
+func (a *App) onEpisodeFinished(ep store.WatchedEpisode) {
+	if ep.AnimeURL == "" || ep.EpisodeNum <= 0 {
+		return
+	}
+	db, err := store.Default()
+	if err != nil {
+		return
+	}
+	db.MarkOfflineWatched(ep.EpisodeURL)
+	if err := db.SetLibraryProgress(ep.AnimeURL, ep.EpisodeNum); err != nil {
+		return // Anime fora da biblioteca
+	}
+	fmt.Printf("[Progress] Episódio %d de %s concluído\n", ep.EpisodeNum, ep.AnimeTitle)
+	a.onLibraryChanged(ep.AnimeURL)
+}
+
+// recordPlaybackPosition é chamado pelo OnTimeUpdate do player integrado
+func (a *App) recordPlaybackPosition(position, duration float64) {
+	playback.mu.Lock()
+	ep := playback.episode
+	if ep == nil || duration <= 0 || time.Since(playback.lastSave) < positionSaveInterval {
+		playback.mu.Unlock()
+		return
+	}
+	playback.lastSave = time.Now()
+	episodeURL := ep.EpisodeURL
+	playback.mu.Unlock()
+
+	if err := a.SaveEpisodePosition(episodeURL, position, duration); err != nil {
+		fmt.Printf("[Progress] Erro ao salvar posição: %v\n", err)
+	}
+}
+
+// clearPlayingEpisode esquece o episódio atual (player parado ou outro vídeo carregado)
+func (a *App) clearPlayingEpisode() {
+	playback.mu.Lock()
+	playback.episode = nil
+	playback.mu.Unlock()
+}
+
+// ==============================
+// CONTINUAR ASSISTINDO
+// ==============================
+
+// GetContinueWatching retorna, por anime, o último episódio não terminado
+// ou o próximo episódio quando o último foi concluído
+func (a *App) GetContinueWatching(limit int) []ContinueWatchingItem {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	// Animes terminados são descartados depois da busca, então o LIMIT não pode ir para o SQL:
+	// lê todos e processa em lotes até juntar limit itens
+	last, err := store.LastWatchedPerAnime(0)
+	if err != nil {
+		fmt.Printf("[Progress] Erro ao ler histórico: %v\n", err)
+		return []ContinueWatchingItem{}
+	}
+
+	result := make([]ContinueWatchingItem, 0, limit)
+	for start := 0; start < len(last) && len(result) < limit; start += limit {
+		end := start + limit
+		if end > len(last) {
+			end = len(last)
+		}
+		result = append(result, a.continueWatchingBatch(last[start:end])...)
+	}
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// continueWatchingBatch monta os itens de um lote, na mesma ordem, sem os animes terminados
+func (a *App) continueWatchingBatch(last []store.WatchedEpisode) []ContinueWatchingItem {
+	items := make([]*ContinueWatchingItem, len(last))
+	sem := make(chan struct{}, 3) // Limita buscas de episódios em paralelo
+	var wg sync.WaitGroup
+
+	for i, ep := range last {
+		if !ep.IsCompleted() {
+			items[i] = &ContinueWatchingItem{
+				AnimeTitle:   ep.AnimeTitle,
+				AnimeImage:   ep.AnimeImage,
+				AnimeURL:     ep.AnimeURL,
+				EpisodeTitle: ep.EpisodeTitle,
+				EpisodeURL:   ep.EpisodeURL,
+				EpisodeNum:   ep.EpisodeNum,
+				Position:     ep.ResumePosition(),
+				Duration:     ep.Duration,
+				Progress:     ep.Progress,
+				WatchedAt:    ep.WatchedAt,
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(idx int, ep store.WatchedEpisode) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			next := a.findNextEpisode(ep)
+			if next == nil {
+				return // Anime terminado (ou episódios indisponíveis)
+			}
+			items[idx] = &ContinueWatchingItem{
+				AnimeTitle:   ep.AnimeTitle,
+				AnimeImage:   ep.AnimeImage,
+				AnimeURL:     ep.AnimeURL,
+				EpisodeTitle: next.Title,
+				EpisodeURL:   next.URL,
+				EpisodeNum:   next.Number,
+				IsNext:       true,
+				WatchedAt:    ep.WatchedAt,
+			}
+		}(i, ep)
+	}
+	wg.Wait()
+
+	result := make([]ContinueWatchingItem, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			result = append(result, *item)
+		}
+	}
+	return result
+}
+
+// findNextEpisode busca o episódio seguinte ao informado na lista do anime
+func (a *App) findNextEpisode(ep store.WatchedEpisode) *store.Episode {
+	episodes, err := a.GetEpisodes(ep.AnimeURL)
+	if err != nil || len(episodes) == 0 {
+		return nil
+	}
+
+	sorted := make([]store.Episode, len(episodes))
+	copy(sorted, episodes)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Number < sorted[j].Number })
+
+	for i := range sorted {
+		if sorted[i].Number > ep.EpisodeNum {
+			return &sorted[i]
+		}
+	}
+	return nil
+}
@@ -14,6 +14,9 @@ var friendSystem *social.FriendSystem
 func getFriendSystem() *social.FriendSystem {
 	if friendSystem == nil {
 		friendSystem = social.GetFriendSystem()
+		if syncQueue != nil {
+			friendSystem.SetOutbox(syncQueue)
+		}
 	}
 	return friendSystem
 }
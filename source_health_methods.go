@@ -0,0 +1,16 @@
+// source_health_methods.go - Diagnóstico da saúde das fontes de streaming
+// Smart Router, busca paralela e verificação de episódios reportam no mesmo registro (pkg/sourcehealth)
+package main
+
+import "GoAnimeGUI/pkg/sourcehealth"
+
+// GetSourceHealthHistory retorna, para cada fonte, o estado atual (cooldown, último erro)
+// e a taxa de sucesso hora a hora das últimas horas (0 = todo o histórico guardado)
+func (a *App) GetSourceHealthHistory(hours int) []sourcehealth.History {
+	return sourcehealth.Default().History(hours)
+}
+
+// ResetSourceHealth zera falhas e cooldown de uma fonte para que ela volte a ser tentada
+func (a *App) ResetSourceHealth(source string) {
+	sourcehealth.Default().ResetSource(source)
+}
@@ -0,0 +1,102 @@
+// stream_methods.go - Busca de streams com cancelamento e lista de candidatos
+// Cada página de episódio abre as buscas com um id próprio e chama CancelStreamSearch(id) ao sair,
+// sem interromper as buscas de outras páginas
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"GoAnimeGUI/pkg/consumet"
+	"GoAnimeGUI/pkg/smartrouter"
+)
+
+// streamSearch é uma busca em andamento e o id da página que a abriu
+type streamSearch struct {
+	id     string
+	cancel context.CancelFunc
+}
+
+var (
+	streamSearchMutex sync.Mutex
+	streamSearches    = make(map[*streamSearch]struct{})
+)
+
+// beginStreamSearch abre o context de uma busca; done deve ser chamado quando ela terminar.
+// Buscas sem id não são canceláveis pelo frontend (só pelo encerramento do app).
+func (a *App) beginStreamSearch(searchID string) (ctx context.Context, done func()) {
+	parent := a.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	search := &streamSearch{id: searchID, cancel: cancel}
+
+	streamSearchMutex.Lock()
+	streamSearches[search] = struct{}{}
+	streamSearchMutex.Unlock()
+
+	return ctx, func() {
+		streamSearchMutex.Lock()
+		delete(streamSearches, search)
+		streamSearchMutex.Unlock()
+		cancel()
+	}
+}
+
+// CancelStreamSearch interrompe as buscas de stream abertas com searchID
+func (a *App) CancelStreamSearch(searchID string) {
+	if searchID == "" {
+		return
+	}
+	streamSearchMutex.Lock()
+	defer streamSearchMutex.Unlock()
+	for search := range streamSearches {
+		if search.id == searchID {
+			search.cancel()
+			delete(streamSearches, search)
+		}
+	}
+}
+
+// GetStreamCandidates busca em todas as fontes do Smart Router e retorna os streams em ordem de preferência
+// (language: "dub", "sub" ou vazio; quality: "1080p", "720p"... ou "auto"), para o player
+// passar ao próximo se um falhar sem refazer a busca. searchID identifica a página que buscou.
+func (a *App) GetStreamCandidates(searchID, animeTitle string, episodeNumber int, language, quality string) ([]smartrouter.Candidate, error) {
+	ctx, done := a.beginStreamSearch(searchID)
+	defer done()
+	return a.streamRouter.GetStreamCandidates(ctx, smartrouter.StreamRequest{
+		AnimeTitle:    animeTitle,
+		EpisodeNumber: episodeNumber,
+		Language:      strings.ToLower(language),
+		Quality:       quality,
+	})
+}
+
+// consumetCandidates converte as fontes do Consumet (uma por qualidade) em candidatos
+func consumetCandidates(info *consumet.StreamingInfo, subOrDub string) []smartrouter.Candidate {
+	language := ""
+	switch subOrDub {
+	case "dub":
+		language = smartrouter.LanguageDub
+	case "sub":
+		language = smartrouter.LanguageSub
+	}
+
+	candidates := make([]smartrouter.Candidate, 0, len(info.Sources))
+	for _, source := range info.Sources {
+		format := smartrouter.FormatMP4
+		if source.IsM3U8 {
+			format = smartrouter.FormatHLS
+		}
+		candidates = append(candidates, smartrouter.Candidate{
+			URL:      source.URL,
+			Quality:  source.Quality,
+			Language: language,
+			Format:   format,
+			Headers:  info.Headers,
+		})
+	}
+	return candidates
+}
@@ -0,0 +1,42 @@
+// subtitle_methods.go - Legendas convertidas para WebVTT (pkg/subtitles)
+// O <track> do player só entende WebVTT: legendas SRT e ASS/SSA passam pelo /subtitle do proxy
+// local, que detecta a codificação (Windows-1252 ou UTF-8), converte e aplica o deslocamento
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"GoAnimeGUI/pkg/subtitles"
+)
+
+// GetSubtitleURL retorna a URL local (/subtitle) que entrega a legenda em WebVTT.
+// format é "srt", "ass", "ssa" ou "vtt" (extensions.Subtitle.Format); vazio detecta sozinho.
+// O frontend acrescenta &offset=segundos para sincronizar com o vídeo.
+func (a *App) GetSubtitleURL(subtitleURL, format string) (string, error) {
+	if err := a.startVideoProxy(); err != nil {
+		return "", err
+	}
+
+	endpoint := videoProxyGuard.URL(fmt.Sprintf("http://127.0.0.1:%d/subtitle", a.proxyPort), subtitleURL)
+	if format != "" {
+		endpoint += "&format=" + url.QueryEscape(format)
+	}
+	return endpoint, nil
+}
+
+// VPSGetSubtitleVTT baixa uma legenda pelo servidor VPS e a converte para WebVTT,
+// deslocada em offsetSeconds (negativo adianta a legenda)
+func (a *App) VPSGetSubtitleVTT(downloadURL, source, format string, offsetSeconds float64) (string, error) {
+	data, err := a.VPSDownloadSubtitle(downloadURL, source)
+	if err != nil {
+		return "", err
+	}
+
+	vtt, err := subtitles.ToWebVTT(data, format, time.Duration(offsetSeconds*float64(time.Second)))
+	if err != nil {
+		return "", fmt.Errorf("erro ao converter legenda: %w", err)
+	}
+	return string(vtt), nil
+}
@@ -0,0 +1,115 @@
+// syncqueue_methods.go - Métodos da fila de sincronização para o frontend
+// Atualizações de trackers e do servidor social passam por uma fila persistente
+// que sobrevive a quedas de rede e reinícios do aplicativo
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"GoAnimeGUI/pkg/retry"
+	"GoAnimeGUI/pkg/social"
+	"GoAnimeGUI/pkg/store"
+	"GoAnimeGUI/pkg/syncqueue"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Tipos de item dos trackers (os do servidor social ficam em pkg/social)
+const (
+	syncKindAniList = "anilist"
+	syncKindMAL     = "mal"
+)
+
+// syncQueue é nil se o banco não estiver disponível; nesse caso os envios são diretos
+var syncQueue *syncqueue.Queue
+
+// initSyncQueue cria a fila, registra os handlers e inicia o worker
+func (a *App) initSyncQueue() {
+	db, err := store.Default()
+	if err != nil {
+		fmt.Printf("[SyncQueue] Banco indisponível, envios sem fila: %v\n", err)
+		return
+	}
+
+	q := syncqueue.New(db)
+	q.Handle(syncKindAniList, trackerHandler(a.pushAniListEntry))
+	q.Handle(syncKindMAL, trackerHandler(a.pushMALEntry))
+
+	socialHandler := func(payload []byte) error {
+		err := getFriendSystem().SendQueued(payload)
+		if errors.Is(err, social.ErrRejected) {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	q.Handle(social.OutboxKindStatus, socialHandler)
+	q.Handle(social.OutboxKindReport, socialHandler)
+
+	q.OnChange(func(state syncqueue.State) {
+		runtime.EventsEmit(a.ctx, "syncqueue:changed", state)
+	})
+
+	// O sistema social é criado sob demanda e recebe a fila em getFriendSystem
+	syncQueue = q
+	go q.Run(a.ctx)
+}
+
+// trackerHandler adapta um envio de tracker (payload = URL do anime) para a fila
+func trackerHandler(push func(animeURL string) error) syncqueue.Handler {
+	return func(payload []byte) error {
+		var animeURL string
+		if err := json.Unmarshal(payload, &animeURL); err != nil {
+			return retry.Permanent(err)
+		}
+		return push(animeURL)
+	}
+}
+
+// enqueueSync grava o item na fila, ou envia direto em background se não houver fila
+func enqueueSync(kind, animeURL string, push func(animeURL string) error) {
+	if syncQueue == nil {
+		go func() {
+			if err := push(animeURL); err != nil {
+				fmt.Printf("[SyncQueue] Erro ao enviar %s: %v\n", kind, err)
+			}
+		}()
+		return
+	}
+	if err := syncQueue.Enqueue(kind, animeURL, animeURL); err != nil {
+		fmt.Printf("[SyncQueue] %v\n", err)
+	}
+}
+
+// ==============================
+// ESTADO DA FILA
+// ==============================
+
+// GetSyncQueue retorna os itens pendentes e falhos da fila de sincronização
+func (a *App) GetSyncQueue() syncqueue.State {
+	if syncQueue == nil {
+		return syncqueue.State{Jobs: []store.SyncJob{}}
+	}
+	state, err := syncQueue.State()
+	if err != nil {
+		fmt.Printf("[SyncQueue] Erro ao ler fila: %v\n", err)
+	}
+	return state
+}
+
+// RetrySyncQueue tenta enviar agora todos os itens, inclusive os que falharam
+func (a *App) RetrySyncQueue() error {
+	if syncQueue == nil {
+		return nil
+	}
+	return syncQueue.Retry()
+}
+
+// DiscardSyncJob remove um item da fila sem enviá-lo
+func (a *App) DiscardSyncJob(id int64) error {
+	if syncQueue == nil {
+		return nil
+	}
+	return syncQueue.Discard(id)
+}
@@ -0,0 +1,88 @@
+// tracker_methods.go - Partes comuns dos trackers (AniList e MyAnimeList)
+// Resultado de sincronização e envio de alterações da biblioteca
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"GoAnimeGUI/pkg/anilist"
+	"GoAnimeGUI/pkg/mal"
+)
+
+// TrackerRemoteEntry é um anime da lista remota que não está na biblioteca local
+type TrackerRemoteEntry struct {
+	ID       int    `json:"id"` // ID no tracker
+	Title    string `json:"title"`
+	Image    string `json:"image"`
+	Status   string `json:"status"`
+	Progress int    `json:"progress"`
+	Episodes int    `json:"episodes"`
+}
+
+// TrackerConflict registra um anime em que o tracker tinha mais progresso que o histórico local
+type TrackerConflict struct {
+	AnimeURL       string `json:"animeUrl"`
+	Title          string `json:"title"`
+	LocalProgress  int    `json:"localProgress"`
+	RemoteProgress int    `json:"remoteProgress"`
+}
+
+// TrackerSyncResult resume uma sincronização
+type TrackerSyncResult struct {
+	Linked     int                  `json:"linked"` // Entradas vinculadas automaticamente pelo título
+	Pulled     int                  `json:"pulled"` // Entradas locais atualizadas com dados do tracker
+	Pushed     int                  `json:"pushed"` // Entradas enviadas para o tracker
+	RemoteOnly []TrackerRemoteEntry `json:"remoteOnly"`
+	Conflicts  []TrackerConflict    `json:"conflicts"`
+	Errors     []string             `json:"errors"`
+}
+
+func newTrackerSyncResult() TrackerSyncResult {
+	return TrackerSyncResult{
+		RemoteOnly: []TrackerRemoteEntry{},
+		Conflicts:  []TrackerConflict{},
+		Errors:     []string{},
+	}
+}
+
+func (r TrackerSyncResult) log(tracker string) {
+	fmt.Printf("[%s] Sincronização: %d vinculados, %d atualizados localmente, %d enviados, %d só no tracker, %d conflitos\n",
+		tracker, r.Linked, r.Pulled, r.Pushed, len(r.RemoteOnly), len(r.Conflicts))
+}
+
+// onLibraryChanged coloca a entrada alterada na fila de cada tracker conectado.
+// A fila guarda só a chave: o estado enviado é sempre o mais recente da biblioteca.
+func (a *App) onLibraryChanged(animeURL string) {
+	if anilist.GetAuth().IsLoggedIn() {
+		enqueueSync(syncKindAniList, animeURL, a.pushAniListEntry)
+	}
+	if mal.GetAuth().IsLoggedIn() {
+		enqueueSync(syncKindMAL, animeURL, a.pushMALEntry)
+	}
+}
+
+// titleMatchesAny compara o título com os candidatos ignorando pontuação, espaços e caixa
+func titleMatchesAny(title string, candidates ...string) bool {
+	normalized := normalizeTitleForMatch(title)
+	if normalized == "" {
+		return false
+	}
+	for _, candidate := range candidates {
+		if normalizeTitleForMatch(candidate) == normalized {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeTitleForMatch(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
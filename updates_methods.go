@@ -0,0 +1,154 @@
+// updates_methods.go - Verificação de novos episódios da biblioteca
+// Um worker em background relê a lista de episódios dos animes em andamento e avisa o frontend
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"GoAnimeGUI/pkg/store"
+	"GoAnimeGUI/pkg/updates"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// updateChecker é nil se o banco não estiver disponível
+var updateChecker *updates.Checker
+
+// UpdatesFeed é o feed de novos episódios para o frontend
+type UpdatesFeed struct {
+	Unseen int                   `json:"unseen"` // Animes com avisos não vistos (badge)
+	Items  []store.LibraryUpdate `json:"items"`
+}
+
+// initUpdateChecker cria o verificador e inicia o worker
+func (a *App) initUpdateChecker() {
+	db, err := store.Default()
+	if err != nil {
+		fmt.Printf("[Updates] Banco indisponível, verificação desativada: %v\n", err)
+		return
+	}
+
+	updateChecker = updates.New(db, a.fetchEpisodeCount, a.IsSourceAvailable, a.updateCheckInterval)
+	updateChecker.OnUpdate(func(update store.LibraryUpdate) {
+		runtime.EventsEmit(a.ctx, "anime:new-episodes", update)
+		a.emitUpdatesChanged()
+	})
+	go updateChecker.Run(a.ctx)
+}
+
+// updateCheckInterval lê o intervalo das configurações do usuário já carregado
+func (a *App) updateCheckInterval() time.Duration {
+	if a.User == nil || a.User.Settings.UpdateCheckHours == 0 {
+		return store.DefaultUpdateCheckHours * time.Hour
+	}
+	return time.Duration(a.User.Settings.UpdateCheckHours) * time.Hour
+}
+
+// fetchEpisodeCount busca a lista atual de episódios ignorando o cache em memória.
+// O resultado registra a saúde da fonte, então fontes com erro entram em cooldown.
+func (a *App) fetchEpisodeCount(item store.LibraryItem) (int, error) {
+	source := item.Source
+	a.cacheMutex.Lock()
+	delete(a.episodesCache, item.URL)
+	delete(a.episodesCache, fmt.Sprintf("%s:%s", source, item.URL))
+	a.cacheMutex.Unlock()
+
+	var eps []store.Episode
+	var err error
+	if source == "" {
+		eps, err = a.GetEpisodes(item.URL)
+	} else {
+		eps, err = a.GetEpisodesForSource(item.URL, source)
+	}
+	if err == nil && len(eps) == 0 {
+		err = fmt.Errorf("nenhum episódio retornado")
+	}
+	if err != nil {
+		if source != "" {
+			a.recordSourceFailure(source, err.Error())
+		}
+		return 0, err
+	}
+	if source != "" {
+		a.recordSourceSuccess(source)
+	}
+
+	// Numeração da fonte quando existir; senão, a quantidade
+	count := len(eps)
+	for _, ep := range eps {
+		if ep.Number > count {
+			count = ep.Number
+		}
+	}
+	return count, nil
+}
+
+func (a *App) emitUpdatesChanged() {
+	db, err := store.Default()
+	if err != nil {
+		return
+	}
+	if n, err := db.UnseenUpdateCount(); err == nil {
+		runtime.EventsEmit(a.ctx, "updates:changed", n)
+	}
+}
+
+// ==============================
+// FEED DE ATUALIZAÇÕES
+// ==============================
+
+// GetLibraryUpdates retorna o feed de novos episódios (mais recentes primeiro)
+func (a *App) GetLibraryUpdates(unseenOnly bool) (UpdatesFeed, error) {
+	feed := UpdatesFeed{Items: []store.LibraryUpdate{}}
+	db, err := store.Default()
+	if err != nil {
+		return feed, err
+	}
+	if feed.Items, err = db.LibraryUpdates(unseenOnly, 100); err != nil {
+		return feed, err
+	}
+	feed.Unseen, err = db.UnseenUpdateCount()
+	return feed, err
+}
+
+// MarkLibraryUpdatesSeen marca avisos como vistos (lista vazia marca todos)
+func (a *App) MarkLibraryUpdatesSeen(ids []int64) error {
+	db, err := store.Default()
+	if err != nil {
+		return err
+	}
+	if err := db.MarkUpdatesSeen(ids); err != nil {
+		return err
+	}
+	a.emitUpdatesChanged()
+	return nil
+}
+
+// MarkAnimeUpdatesSeen marca como vistos os avisos de um anime ao abrir a página dele
+func (a *App) MarkAnimeUpdatesSeen(animeURL string) error {
+	db, err := store.Default()
+	if err != nil {
+		return err
+	}
+	if err := db.MarkAnimeUpdatesSeen(animeURL); err != nil {
+		return err
+	}
+	a.emitUpdatesChanged()
+	return nil
+}
+
+// CheckLibraryUpdates verifica toda a biblioteca agora, em background.
+// Os avisos chegam pelos eventos "anime:new-episodes" e "updates:changed".
+func (a *App) CheckLibraryUpdates() error {
+	if updateChecker == nil {
+		return fmt.Errorf("verificação de atualizações indisponível")
+	}
+	go func(ctx context.Context) {
+		found := updateChecker.CheckAll(ctx)
+		fmt.Printf("[Updates] Verificação manual concluída: %d anime(s) com episódios novos\n", len(found))
+		runtime.EventsEmit(a.ctx, "updates:checked", len(found))
+	}(a.ctx)
+	return nil
+}
@@ -0,0 +1,144 @@
+// video_proxy_methods.go - Streams registrados no proxy de vídeo
+// Cada stream ganha um token próprio (/video/{token}) com a URL, os headers da fonte e uma validade,
+// então prefetch, picture-in-picture e prévia da watch party não derrubam o vídeo em reprodução.
+// Segmentos e pedaços de MP4 ficam num cache em disco, com read-ahead durante a reprodução.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"GoAnimeGUI/internal/proxy"
+	"GoAnimeGUI/pkg/hostrules"
+	"GoAnimeGUI/pkg/segmentcache"
+	"GoAnimeGUI/pkg/store"
+)
+
+// videoSessions guarda os streams registrados no proxy local
+var videoSessions = proxy.NewSessions(proxy.DefaultSessionTTL)
+
+// videoProxyTransport é a conexão compartilhada pelos clientes de /video/
+var videoProxyTransport = &http.Transport{
+	MaxIdleConnsPerHost:   10,
+	IdleConnTimeout:       90 * time.Second,
+	ResponseHeaderTimeout: 30 * time.Second,
+}
+
+// videoProxyClient busca os streams (sem timeout total: segmentos e MP4 podem ser grandes)
+var videoProxyClient = &http.Client{
+	Transport: hostrules.Default().Transport(videoProxyTransport),
+}
+
+// videoProxyGuard assina as URLs de /proxy e /video/ e barra destinos na rede local (SSRF)
+var videoProxyGuard = proxy.NewGuard()
+
+// videoChildClient busca segmentos, chaves e variantes de streams com origem pública:
+// a playlist não pode levar o proxy para a rede local
+var videoChildClient = &http.Client{
+	Transport:     hostrules.Default().Transport(videoProxyGuard.Transport(videoProxyTransport)),
+	CheckRedirect: videoProxyGuard.CheckRedirect,
+}
+
+// genericProxyClient busca as URLs de /proxy: só endereços públicos e poucos redirects
+var genericProxyClient = &http.Client{
+	Timeout:       30 * time.Second,
+	Transport:     hostrules.Default().Transport(videoProxyGuard.Transport(nil)),
+	CheckRedirect: videoProxyGuard.CheckRedirect,
+}
+
+// videoStreams é o handler de /video/ (nil até o proxy iniciar)
+var videoStreams *proxy.StreamHandler
+
+// VideoCacheStats são as métricas do cache de vídeo para o frontend
+type VideoCacheStats struct {
+	segmentcache.Stats
+	Prefetched int64 `json:"prefetched"` // Recursos baixados à frente pelo read-ahead
+}
+
+// videoStreamHandler cria o handler de /video/ e inicia a limpeza das sessões expiradas
+func (a *App) videoStreamHandler() *proxy.StreamHandler {
+	ctx := a.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	go videoSessions.Run(ctx)
+	a.applyVideoCacheSettings()
+
+	videoStreams = &proxy.StreamHandler{
+		Sessions:    videoSessions,
+		Client:      videoProxyClient,
+		ChildClient: videoChildClient,
+		Guard:       videoProxyGuard,
+		BaseURL:     func() string { return fmt.Sprintf("http://127.0.0.1:%d", a.proxyPort) },
+		Prepare: func(req *http.Request, session *proxy.Session) {
+			setVideoRequestHeaders(req, session.URL)
+		},
+		Cache:     segmentcache.Default(),
+		ReadAhead: a.videoReadAhead,
+	}
+	return videoStreams
+}
+
+// applyVideoCacheSettings aplica o tamanho máximo do cache de vídeo das configurações
+func (a *App) applyVideoCacheSettings() {
+	cache := segmentcache.Default()
+	if cache == nil {
+		return
+	}
+	mb := store.DefaultVideoCacheMB
+	if a.User != nil && a.User.Settings.VideoCacheMB != 0 {
+		mb = max(a.User.Settings.VideoCacheMB, 0) // -1 desativa
+	}
+	cache.SetMaxBytes(int64(mb) << 20)
+}
+
+// videoReadAhead lê das configurações quantos segmentos baixar à frente
+func (a *App) videoReadAhead() int {
+	if a.User == nil || a.User.Settings.VideoReadAhead == 0 {
+		return store.DefaultVideoReadAhead
+	}
+	return a.User.Settings.VideoReadAhead
+}
+
+// GetVideoCacheStats retorna as métricas do cache de vídeo (acertos, tamanho, read-ahead)
+func (a *App) GetVideoCacheStats() VideoCacheStats {
+	var stats VideoCacheStats
+	if cache := segmentcache.Default(); cache != nil {
+		stats.Stats = cache.Stats()
+	}
+	if videoStreams != nil {
+		stats.Prefetched = videoStreams.Prefetched()
+	}
+	return stats
+}
+
+// ClearVideoCache apaga os segmentos guardados em disco
+func (a *App) ClearVideoCache() {
+	if cache := segmentcache.Default(); cache != nil {
+		cache.Clear()
+	}
+}
+
+// GetProxyURLForVideo retorna a URL do proxy local para um vídeo
+func (a *App) GetProxyURLForVideo(videoURL string) (string, error) {
+	return a.GetProxyURLForStream(videoURL, nil)
+}
+
+// GetProxyURLForStream registra um stream com os headers exigidos pela fonte
+// (extensions.VideoSource.Headers ou SmartStreamResult.Headers) e retorna a URL local dele
+func (a *App) GetProxyURLForStream(videoURL string, headers map[string]string) (string, error) {
+	if err := a.startVideoProxy(); err != nil {
+		return "", err
+	}
+
+	session, err := videoSessions.Register(videoURL, headers)
+	if err != nil {
+		return "", fmt.Errorf("erro ao registrar stream no proxy: %w", err)
+	}
+
+	proxyURL := fmt.Sprintf("http://127.0.0.1:%d/video/%s", a.proxyPort, session.Token)
+	fmt.Printf("[GetProxyURLForStream] Stream registrado: %s\n", videoURL)
+	return proxyURL, nil
+}